@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// Uso do subcomando de auditoria
+const auditUsage = "uso: audit verify | checkpoint"
+
+// runAudit executa o subcomando de verificação do log de auditoria
+func runAudit(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(auditUsage)
+	}
+	
+	auditService := services.NewAuditService(models.NewAuditLogRepository(db), models.NewLoginAttemptRepository(db))
+	
+	switch args[0] {
+	case "verify":
+		result, err := auditService.VerifyChain(context.Background())
+		if err != nil {
+			return err
+		}
+		
+		output, _ := json.MarshalIndent(result, "", "  ")
+		fmt.Println(string(output))
+		
+		// Cadeia quebrada: encerrar com código de saída diferente de zero, pelo ponto de saída de main
+		if !result.Valid {
+			return fmt.Errorf("cadeia de auditoria quebrada no registro %d: %s", *result.BrokenAt, result.Reason)
+		}
+		fmt.Printf("Cadeia de auditoria íntegra: %d registros verificados\n", result.CheckedEntries)
+	case "checkpoint":
+		checkpoint, err := auditService.CreateCheckpoint(context.Background())
+		if err != nil {
+			return err
+		}
+		if checkpoint == nil {
+			fmt.Println("Nenhum registro novo desde o último ponto de verificação")
+			return nil
+		}
+		fmt.Printf("Ponto de verificação %d gravado até o registro %d (kid=%s)\n", checkpoint.ID, checkpoint.LastAuditID, checkpoint.KeyID)
+	default:
+		return fmt.Errorf(auditUsage)
+	}
+	
+	return nil
+}
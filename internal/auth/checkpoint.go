@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// checkpointSubject identifica os pontos de verificação do log de auditoria, que não podem ser usados como tokens
+const checkpointSubject = "audit-checkpoint"
+
+// CheckpointClaims representa o conteúdo assinado de um ponto de verificação do log de auditoria
+type CheckpointClaims struct {
+	LastAuditID int64  `json:"last_audit_id"`
+	LastHash    string `json:"last_hash"`
+	Entries     int64  `json:"entries"`
+	jwt.RegisteredClaims
+}
+
+// SignCheckpoint assina um ponto de verificação do log de auditoria com a chave ativa dos tokens,
+// retornando o JWS compacto e o kid da chave. Com chaves assimétricas, a assinatura pode ser
+// verificada por terceiros com as chaves públicas publicadas no JWKS.
+func SignCheckpoint(lastAuditID int64, lastHash string, entries int64) (string, string, error) {
+	ks := currentKeys()
+	if ks == nil {
+		return "", "", ErrNoKeys
+	}
+	
+	claims := &CheckpointClaims{
+		LastAuditID: lastAuditID,
+		LastHash:    lastHash,
+		Entries:     entries,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			Issuer:   "api-seguradoras",
+			Subject:  checkpointSubject,
+		},
+	}
+	
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.kid
+	
+	signature, err := token.SignedString(ks.signing.signer)
+	if err != nil {
+		return "", "", fmt.Errorf("erro ao assinar ponto de verificação: %v", err)
+	}
+	
+	return signature, ks.signing.kid, nil
+}
+
+// VerifyCheckpoint verifica a assinatura de um ponto de verificação e retorna o conteúdo assinado.
+// Retorna ErrUnknownKey se a chave que assinou o ponto de verificação não estiver mais configurada.
+func VerifyCheckpoint(signature string) (*CheckpointClaims, error) {
+	ks := currentKeys()
+	if ks == nil {
+		return nil, ErrNoKeys
+	}
+	
+	claims := &CheckpointClaims{}
+	token, err := jwt.ParseWithClaims(signature, claims, ks.keyFunc, jwt.WithValidMethods(ks.methods()))
+	if err != nil {
+		if errors.Is(err, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+		return nil, err
+	}
+	if !token.Valid || claims.Subject != checkpointSubject {
+		return nil, errors.New("ponto de verificação inválido")
+	}
+	
+	return claims, nil
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"time"
@@ -8,10 +10,6 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// Chave secreta para assinar os tokens JWT
-// Em produção, isso deve ser uma variável de ambiente ou configuração segura
-var jwtKey = []byte("sua_chave_secreta_muito_segura_e_longa")
-
 // Constantes para configuração de tokens
 const (
 	TokenExpiration     = 24 * time.Hour    // Tempo de expiração do token
@@ -19,98 +17,144 @@ const (
 	RefreshTokenExpiration = 7 * 24 * time.Hour // Tempo de expiração do refresh token (7 dias)
 )
 
-// Claims representa as claims do JWT
-type Claims struct {
+// Identity representa os dados do usuário embutidos nos tokens
+type Identity struct {
 	UserID       int64  `json:"user_id"`
 	Username     string `json:"username"`
 	TipoPerfilID int    `json:"tipo_perfil_id"`
-	TokenType    string `json:"token_type"` // "access" ou "refresh"
+	IdSeguradora int64  `json:"id_seguradora"`
+	AdminERP     bool   `json:"admin_erp"`
+}
+
+// Claims representa as claims do JWT
+type Claims struct {
+	Identity
+	TokenType string `json:"token_type"` // "access" ou "refresh"
+	SessionID string `json:"sid"`        // Família de refresh tokens (sessão) à qual o token pertence
 	jwt.RegisteredClaims
 }
 
-// GenerateToken gera um novo token JWT para um usuário
-func GenerateToken(userID int64, username string, tipoPerfilID int) (string, error) {
-	return generateTokenWithType(userID, username, tipoPerfilID, "access", TokenExpiration)
+// NewSessionID gera um identificador aleatório para uma nova sessão (família de refresh tokens)
+func NewSessionID() (string, error) {
+	return randomID()
 }
 
-// GenerateRefreshToken gera um novo refresh token JWT para um usuário
-func GenerateRefreshToken(userID int64, username string, tipoPerfilID int) (string, error) {
-	return generateTokenWithType(userID, username, tipoPerfilID, "refresh", RefreshTokenExpiration)
+// GenerateToken gera um novo token JWT de acesso para um usuário, vinculado a uma sessão
+func GenerateToken(identity Identity, sessionID string) (string, error) {
+	tokenString, _, err := generateTokenWithType(identity, sessionID, "access", TokenExpiration)
+	return tokenString, err
+}
+
+// GenerateRefreshToken gera um novo refresh token JWT para um usuário, vinculado a uma sessão.
+// As claims são retornadas para que o token (jti e expiração) seja persistido.
+func GenerateRefreshToken(identity Identity, sessionID string) (string, *Claims, error) {
+	return generateTokenWithType(identity, sessionID, "refresh", RefreshTokenExpiration)
 }
 
 // generateTokenWithType gera um token com tipo e duração específicos
-func generateTokenWithType(userID int64, username string, tipoPerfilID int, tokenType string, expiration time.Duration) (string, error) {
+func generateTokenWithType(identity Identity, sessionID, tokenType string, expiration time.Duration) (string, *Claims, error) {
 	// Define o tempo de expiração do token
 	expirationTime := time.Now().Add(expiration)
 	
+	// Identificador único do token (jti)
+	tokenID, err := randomID()
+	if err != nil {
+		return "", nil, err
+	}
+	
 	// Cria as claims
 	claims := &Claims{
-		UserID:       userID,
-		Username:     username,
-		TipoPerfilID: tipoPerfilID,
-		TokenType:    tokenType,
+		Identity:  identity,
+		TokenType: tokenType,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "api-seguradoras",
-			Subject:   fmt.Sprintf("%d", userID),
+			Subject:   fmt.Sprintf("%d", identity.UserID),
 		},
 	}
 	
-	// Cria o token com as claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	// Obtém a chave de assinatura ativa
+	ks := currentKeys()
+	if ks == nil {
+		return "", nil, ErrNoKeys
+	}
+	
+	// Cria o token com as claims, identificando a chave no cabeçalho
+	token := jwt.NewWithClaims(ks.signing.method, claims)
+	token.Header["kid"] = ks.signing.kid
 	
-	// Assina o token com a chave secreta
-	tokenString, err := token.SignedString(jwtKey)
+	// Assina o token com a chave ativa
+	tokenString, err := token.SignedString(ks.signing.signer)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	
-	return tokenString, nil
+	return tokenString, claims, nil
+}
+
+// randomID gera um identificador aleatório de 128 bits em hexadecimal
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar identificador: %v", err)
+	}
+	return hex.EncodeToString(b), nil
 }
 
 // ValidateToken valida um token JWT e retorna as claims
 func ValidateToken(tokenString string) (*Claims, error) {
-	// Parse do token
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verifica se o método de assinatura é o esperado
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
-		}
-		return jwtKey, nil
-	})
-	
+	claims, err := parseToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
 	
-	// Verifica se o token é válido
-	if !token.Valid {
-		return nil, errors.New("token inválido")
-	}
-	
 	// Verifica se é um token de acesso
 	if claims.TokenType != "access" {
 		return nil, errors.New("tipo de token inválido")
 	}
 	
+	// Tokens sem sessão não podem ser revogados e não são aceitos
+	if claims.SessionID == "" {
+		return nil, errors.New("token sem sessão")
+	}
+	
 	return claims, nil
 }
 
 // ValidateRefreshToken valida um refresh token JWT e retorna as claims
 func ValidateRefreshToken(tokenString string) (*Claims, error) {
-	// Parse do token
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		// Verifica se o método de assinatura é o esperado
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
-		}
-		return jwtKey, nil
-	})
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Verifica se é um refresh token
+	if claims.TokenType != "refresh" {
+		return nil, errors.New("tipo de token inválido")
+	}
+	
+	// Refresh tokens devem identificar o token (jti) e a sessão para a rotação
+	if claims.ID == "" || claims.SessionID == "" {
+		return nil, errors.New("refresh token sem identificação de sessão")
+	}
 	
+	return claims, nil
+}
+
+// parseToken valida a assinatura (pela chave indicada no kid) e as datas de um token e retorna as claims
+func parseToken(tokenString string) (*Claims, error) {
+	ks := currentKeys()
+	if ks == nil {
+		return nil, ErrNoKeys
+	}
+	
+	// Parse do token, aceitando apenas os algoritmos das chaves configuradas
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, ks.keyFunc, jwt.WithValidMethods(ks.methods()))
 	if err != nil {
 		return nil, err
 	}
@@ -120,11 +164,6 @@ func ValidateRefreshToken(tokenString string) (*Claims, error) {
 		return nil, errors.New("token inválido")
 	}
 	
-	// Verifica se é um refresh token
-	if claims.TokenType != "refresh" {
-		return nil, errors.New("tipo de token inválido")
-	}
-	
 	return claims, nil
 }
 
@@ -139,25 +178,3 @@ func ShouldRefreshToken(claims *Claims) bool {
 	return false
 }
 
-// RefreshToken gera um novo token a partir de um refresh token válido
-func RefreshToken(refreshTokenString string) (string, string, error) {
-	// Valida o refresh token
-	claims, err := ValidateRefreshToken(refreshTokenString)
-	if err != nil {
-		return "", "", err
-	}
-	
-	// Gera um novo token de acesso
-	newAccessToken, err := GenerateToken(claims.UserID, claims.Username, claims.TipoPerfilID)
-	if err != nil {
-		return "", "", err
-	}
-	
-	// Gera um novo refresh token
-	newRefreshToken, err := GenerateRefreshToken(claims.UserID, claims.Username, claims.TipoPerfilID)
-	if err != nil {
-		return "", "", err
-	}
-	
-	return newAccessToken, newRefreshToken, nil
-}
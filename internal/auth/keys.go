@@ -0,0 +1,446 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/config"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Tamanhos mínimos aceitos para as chaves
+const (
+	minSecretLength = 32   // bytes do segredo HS256
+	minRSAKeyBits   = 2048 // bits da chave RSA
+)
+
+// ErrNoKeys indica que as chaves JWT ainda não foram carregadas
+var ErrNoKeys = errors.New("chaves JWT não configuradas")
+
+// ErrUnknownKey indica que o kid de um token não corresponde a nenhuma chave de verificação
+var ErrUnknownKey = errors.New("chave de assinatura desconhecida")
+
+// key representa uma chave de assinatura ou verificação de tokens
+type key struct {
+	kid      string
+	method   jwt.SigningMethod
+	signer   interface{} // Chave privada ou segredo (apenas a chave de assinatura)
+	verifier interface{} // Chave pública ou segredo
+}
+
+// KeySet representa a chave de assinatura ativa e as chaves aceitas na verificação
+type KeySet struct {
+	signing *key
+	verify  map[string]*key
+}
+
+// JWK representa uma chave pública no formato JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS representa um conjunto de chaves públicas (RFC 7517)
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// Chaves em uso pela aplicação
+var (
+	keysMu sync.RWMutex
+	keys   *KeySet
+)
+
+// SetKeySet define as chaves usadas para assinar e verificar os tokens
+func SetKeySet(ks *KeySet) {
+	keysMu.Lock()
+	defer keysMu.Unlock()
+	keys = ks
+}
+
+// KeysLoaded indica se as chaves JWT foram carregadas
+func KeysLoaded() bool {
+	return currentKeys() != nil
+}
+
+// currentKeys retorna as chaves em uso
+func currentKeys() *KeySet {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys
+}
+
+// LoadKeySet carrega a chave de assinatura e as chaves de verificação a partir da configuração
+func LoadKeySet(cfg config.JWTConfig) (*KeySet, error) {
+	if !cfg.HasSigningKey() {
+		return nil, ErrNoKeys
+	}
+	
+	// Carregar a chave de assinatura ativa
+	var signing *key
+	var err error
+	switch cfg.Algorithm {
+	case jwt.SigningMethodHS256.Alg():
+		if cfg.PrivateKeyFile != "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE não é usado com %s; defina JWT_SECRET", cfg.Algorithm)
+		}
+		signing, err = newSecretKey(cfg.KeyID, cfg.Secret)
+	case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg(), jwt.SigningMethodEdDSA.Alg():
+		if cfg.PrivateKeyFile == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE é obrigatório com %s", cfg.Algorithm)
+		}
+		signing, err = loadPrivateKey(cfg.KeyID, cfg.Algorithm, cfg.PrivateKeyFile)
+	default:
+		return nil, fmt.Errorf("algoritmo JWT não suportado: %s (use HS256, RS256, ES256 ou EdDSA)", cfg.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+	
+	ks := &KeySet{
+		signing: signing,
+		verify:  map[string]*key{signing.kid: signing},
+	}
+	
+	// Carregar as chaves anteriores, ainda aceitas na verificação durante a rotação
+	for kid, file := range cfg.VerificationKeys {
+		k, err := loadPublicKey(kid, file)
+		if err != nil {
+			return nil, err
+		}
+		if err := ks.addVerificationKey(k); err != nil {
+			return nil, err
+		}
+	}
+	for kid, secret := range cfg.PreviousSecrets {
+		k, err := newSecretKey(kid, secret)
+		if err != nil {
+			return nil, err
+		}
+		if err := ks.addVerificationKey(k); err != nil {
+			return nil, err
+		}
+	}
+	
+	return ks, nil
+}
+
+// NewEphemeralKeySet gera uma chave HS256 aleatória, válida apenas enquanto a aplicação estiver em execução.
+// Deve ser usada somente em desenvolvimento, quando nenhuma chave foi configurada.
+func NewEphemeralKeySet() (*KeySet, error) {
+	secret := make([]byte, minSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("erro ao gerar chave JWT temporária: %v", err)
+	}
+	
+	kid, err := randomID()
+	if err != nil {
+		return nil, err
+	}
+	
+	signing := &key{kid: "dev-" + kid, method: jwt.SigningMethodHS256, signer: secret, verifier: secret}
+	return &KeySet{signing: signing, verify: map[string]*key{signing.kid: signing}}, nil
+}
+
+// SigningKeyID retorna o kid da chave de assinatura ativa
+func (ks *KeySet) SigningKeyID() string {
+	return ks.signing.kid
+}
+
+// Algorithm retorna o algoritmo da chave de assinatura ativa
+func (ks *KeySet) Algorithm() string {
+	return ks.signing.method.Alg()
+}
+
+// JWKS retorna as chaves públicas de verificação (chaves HS256 nunca são publicadas)
+func (ks *KeySet) JWKS() JWKS {
+	kids := make([]string, 0, len(ks.verify))
+	for kid := range ks.verify {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	
+	jwks := JWKS{Keys: []JWK{}}
+	for _, kid := range kids {
+		if jwk, ok := publicJWK(ks.verify[kid]); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	
+	return jwks
+}
+
+// PublicJWKS retorna as chaves públicas de verificação em uso
+func PublicJWKS() (JWKS, error) {
+	ks := currentKeys()
+	if ks == nil {
+		return JWKS{}, ErrNoKeys
+	}
+	return ks.JWKS(), nil
+}
+
+// addVerificationKey adiciona uma chave aceita na verificação, recusando kids duplicados
+func (ks *KeySet) addVerificationKey(k *key) error {
+	if _, exists := ks.verify[k.kid]; exists {
+		return fmt.Errorf("kid de chave JWT duplicado: %s", k.kid)
+	}
+	ks.verify[k.kid] = k
+	return nil
+}
+
+// methods retorna os algoritmos aceitos na verificação
+func (ks *KeySet) methods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	for _, k := range ks.verify {
+		if alg := k.method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+// keyFunc seleciona a chave de verificação pelo kid do cabeçalho do token
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token sem identificador de chave (kid)")
+	}
+	
+	k, ok := ks.verify[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, kid)
+	}
+	
+	// O algoritmo do token deve ser o da chave, evitando a troca de algoritmos
+	if token.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+	}
+	
+	return k.verifier, nil
+}
+
+// newSecretKey cria uma chave HS256 a partir de um segredo
+func newSecretKey(kid, secret string) (*key, error) {
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("segredo JWT muito curto: mínimo de %d caracteres", minSecretLength)
+	}
+	
+	// kid padrão derivado do segredo (sem revelá-lo)
+	if kid == "" {
+		sum := sha256.Sum256([]byte(secret))
+		kid = "hs256-" + hex.EncodeToString(sum[:8])
+	}
+	
+	return &key{kid: kid, method: jwt.SigningMethodHS256, signer: []byte(secret), verifier: []byte(secret)}, nil
+}
+
+// loadPrivateKey carrega a chave privada de assinatura de um arquivo PEM
+func loadPrivateKey(kid, algorithm, file string) (*key, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	
+	signer, err := parsePrivateKey(file, block)
+	if err != nil {
+		return nil, err
+	}
+	
+	k, err := newPublicKey(kid, signer.Public())
+	if err != nil {
+		return nil, fmt.Errorf("%v (%s)", err, file)
+	}
+	if k.method.Alg() != algorithm {
+		return nil, fmt.Errorf("a chave %s é do tipo %s, mas JWT_ALGORITHM é %s", file, k.method.Alg(), algorithm)
+	}
+	
+	k.signer = signer
+	return k, nil
+}
+
+// loadPublicKey carrega uma chave pública de verificação de um arquivo PEM
+// (também aceita um certificado ou o arquivo da chave privada, usando a sua parte pública)
+func loadPublicKey(kid, file string) (*key, error) {
+	block, err := readPEM(file)
+	if err != nil {
+		return nil, err
+	}
+	
+	var public interface{}
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			public = cert.PublicKey
+		}
+	default:
+		var signer crypto.Signer
+		if signer, err = parsePrivateKey(file, block); err != nil {
+			return nil, err
+		}
+		public = signer.Public()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave pública JWT %s: %v", file, err)
+	}
+	
+	k, err := newPublicKey(kid, public)
+	if err != nil {
+		return nil, fmt.Errorf("%v (%s)", err, file)
+	}
+	return k, nil
+}
+
+// parsePrivateKey interpreta uma chave privada RSA, ECDSA ou Ed25519 (PKCS#1, SEC 1 ou PKCS#8)
+func parsePrivateKey(file string, block *pem.Block) (crypto.Signer, error) {
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	default:
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler chave privada JWT %s: %v", file, err)
+	}
+	
+	signer, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("tipo de chave privada JWT não suportado em %s", file)
+	}
+	
+	return signer, nil
+}
+
+// newPublicKey cria uma chave de verificação a partir de uma chave pública, identificando o algoritmo pelo tipo
+func newPublicKey(kid string, public interface{}) (*key, error) {
+	k := &key{kid: kid, verifier: public}
+	
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("chave RSA muito curta: mínimo de %d bits", minRSAKeyBits)
+		}
+		k.method = jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if pub.Curve != elliptic.P256() {
+			return nil, errors.New("chave ECDSA não suportada: use a curva P-256 (ES256)")
+		}
+		k.method = jwt.SigningMethodES256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("tipo de chave JWT não suportado")
+	}
+	
+	// kid padrão: thumbprint da chave pública (RFC 7638)
+	if k.kid == "" {
+		jwk, _ := publicJWK(k)
+		k.kid = thumbprint(jwk)
+	}
+	
+	return k, nil
+}
+
+// readPEM lê o primeiro bloco PEM de um arquivo
+func readPEM(file string) (*pem.Block, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler arquivo de chave JWT: %v", err)
+	}
+	
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("arquivo de chave JWT sem bloco PEM: %s", file)
+	}
+	
+	return block, nil
+}
+
+// publicJWK converte a chave pública de verificação para o formato JWK
+func publicJWK(k *key) (JWK, bool) {
+	jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+	
+	switch pub := k.verifier.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		jwk.Kty = "EC"
+		jwk.Crv = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		jwk.Y = base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	default:
+		return JWK{}, false
+	}
+	
+	return jwk, true
+}
+
+// thumbprint calcula o thumbprint SHA-256 de uma JWK (RFC 7638)
+func thumbprint(jwk JWK) string {
+	// Somente os membros obrigatórios, em ordem lexicográfica
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	case "EC":
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		}{jwk.Crv, jwk.Kty, jwk.X, jwk.Y}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	
+	data, _ := json.Marshal(members)
+	sum := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/config"
+)
+
+// segredoAtual e segredoAnterior são segredos HS256 com o tamanho mínimo exigido
+const (
+	segredoAtual    = "segredo-atual-com-32-caracteres!"
+	segredoAnterior = "segredo-anterior-com-32-caracter"
+)
+
+// writePrivateKey grava a chave privada em um arquivo PEM (PKCS#8) temporário e retorna o caminho
+func writePrivateKey(t *testing.T, signer crypto.Signer) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatalf("erro ao codificar chave privada: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "chave.pem")
+	if err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("erro ao gravar chave privada: %v", err)
+	}
+	return file
+}
+
+// signToken assina um token de acesso válido com o algoritmo, o kid e a chave informados
+func signToken(t *testing.T, method jwt.SigningMethod, kid string, signer interface{}) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, &Claims{
+		Identity:         Identity{UserID: 1, Username: "maria"},
+		TokenType:        "access",
+		SessionID:        "sessao",
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(TokenExpiration))},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString(signer)
+	if err != nil {
+		t.Fatalf("erro ao assinar token: %v", err)
+	}
+	return tokenString
+}
+
+// loadKeySet carrega as chaves da configuração e as coloca em uso durante o teste
+func loadKeySet(t *testing.T, cfg config.JWTConfig) *KeySet {
+	t.Helper()
+
+	ks, err := LoadKeySet(cfg)
+	if err != nil {
+		t.Fatalf("erro ao carregar chaves: %v", err)
+	}
+	SetKeySet(ks)
+	t.Cleanup(func() { SetKeySet(nil) })
+	return ks
+}
+
+func TestKeyFuncFixaOAlgoritmoDoKid(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, minRSAKeyBits)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave RSA: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	if err != nil {
+		t.Fatalf("erro ao codificar chave pública: %v", err)
+	}
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+
+	loadKeySet(t, config.JWTConfig{
+		Algorithm:       "RS256",
+		KeyID:           "rsa-atual",
+		PrivateKeyFile:  writePrivateKey(t, rsaKey),
+		PreviousSecrets: map[string]string{"hs-anterior": segredoAnterior},
+	})
+
+	// erro vazio indica um token válido
+	tests := []struct {
+		name  string
+		token string
+		erro  string
+	}{
+		{"RS256 com o kid da chave RSA", signToken(t, jwt.SigningMethodRS256, "rsa-atual", rsaKey), ""},
+		{"HS256 com o kid do segredo anterior", signToken(t, jwt.SigningMethodHS256, "hs-anterior", []byte(segredoAnterior)), ""},
+		{"HS256 assinado com a chave pública RSA", signToken(t, jwt.SigningMethodHS256, "rsa-atual", publicPEM), "método de assinatura inesperado: HS256"},
+		{"HS256 com o kid da chave RSA", signToken(t, jwt.SigningMethodHS256, "rsa-atual", []byte(segredoAnterior)), "método de assinatura inesperado: HS256"},
+		{"RS256 com o kid do segredo anterior", signToken(t, jwt.SigningMethodRS256, "hs-anterior", rsaKey), "método de assinatura inesperado: RS256"},
+		{"kid desconhecido", signToken(t, jwt.SigningMethodRS256, "rsa-removida", rsaKey), ErrUnknownKey.Error()},
+		{"sem kid", signToken(t, jwt.SigningMethodRS256, "", rsaKey), "sem identificador de chave"},
+		{"sem assinatura", signToken(t, jwt.SigningMethodNone, "rsa-atual", jwt.UnsafeAllowNoneSignatureType), "signing method none is invalid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateToken(tt.token)
+			if tt.erro == "" && err != nil {
+				t.Errorf("ValidateToken() erro = %v, esperado válido", err)
+			}
+			if tt.erro != "" && (err == nil || !strings.Contains(err.Error(), tt.erro)) {
+				t.Errorf("ValidateToken() erro = %v, esperado %q", err, tt.erro)
+			}
+		})
+	}
+}
+
+func TestRotacaoDeChaves(t *testing.T) {
+	anterior, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave ECDSA: %v", err)
+	}
+	_, atual, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave Ed25519: %v", err)
+	}
+	arquivoAnterior := writePrivateKey(t, anterior)
+
+	// Token emitido com a chave ES256, antes da rotação
+	loadKeySet(t, config.JWTConfig{Algorithm: "ES256", KeyID: "es-2025", PrivateKeyFile: arquivoAnterior})
+	emitidoAntes, err := GenerateToken(Identity{UserID: 1, Username: "maria"}, "sessao")
+	if err != nil {
+		t.Fatalf("erro ao gerar token: %v", err)
+	}
+
+	// Após a rotação, os novos tokens usam a chave EdDSA e os anteriores continuam aceitos
+	ks := loadKeySet(t, config.JWTConfig{
+		Algorithm:        "EdDSA",
+		KeyID:            "ed-2026",
+		PrivateKeyFile:   writePrivateKey(t, atual),
+		VerificationKeys: map[string]string{"es-2025": arquivoAnterior},
+	})
+	if ks.SigningKeyID() != "ed-2026" || ks.Algorithm() != "EdDSA" {
+		t.Errorf("chave de assinatura %s (%s), esperada ed-2026 (EdDSA)", ks.SigningKeyID(), ks.Algorithm())
+	}
+	emitidoDepois, err := GenerateToken(Identity{UserID: 1, Username: "maria"}, "sessao")
+	if err != nil {
+		t.Fatalf("erro ao gerar token: %v", err)
+	}
+	for nome, token := range map[string]string{"anterior": emitidoAntes, "atual": emitidoDepois} {
+		if _, err := ValidateToken(token); err != nil {
+			t.Errorf("token %s recusado após a rotação: %v", nome, err)
+		}
+	}
+
+	// Retirada a chave anterior da verificação, os tokens dela deixam de valer
+	loadKeySet(t, config.JWTConfig{Algorithm: "EdDSA", KeyID: "ed-2026", PrivateKeyFile: writePrivateKey(t, atual)})
+	if _, err := ValidateToken(emitidoAntes); err == nil {
+		t.Error("token da chave retirada aceito")
+	}
+
+	// Um kid fora das chaves de verificação é recusado mesmo com um algoritmo aceito
+	if _, err := ValidateToken(signToken(t, jwt.SigningMethodEdDSA, "ed-2025", atual)); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("kid desconhecido: erro %v, esperado %v", err, ErrUnknownKey)
+	}
+
+	// Kids duplicados entre a chave ativa e as anteriores são recusados
+	_, err = LoadKeySet(config.JWTConfig{Algorithm: "HS256", KeyID: "hs", Secret: segredoAtual, PreviousSecrets: map[string]string{"hs": segredoAnterior}})
+	if err == nil {
+		t.Error("kid duplicado aceito")
+	}
+}
+
+func TestJWKSSemSegredos(t *testing.T) {
+	_, ed, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave Ed25519: %v", err)
+	}
+	anterior, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave ECDSA: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  config.JWTConfig
+		kids []string
+	}{
+		{"somente HS256", config.JWTConfig{Algorithm: "HS256", Secret: segredoAtual, PreviousSecrets: map[string]string{"hs-anterior": segredoAnterior}}, nil},
+		{"EdDSA com segredo e chave ES256 anteriores", config.JWTConfig{
+			Algorithm:        "EdDSA",
+			KeyID:            "ed-atual",
+			PrivateKeyFile:   writePrivateKey(t, ed),
+			PreviousSecrets:  map[string]string{"hs-anterior": segredoAnterior},
+			VerificationKeys: map[string]string{"es-anterior": writePrivateKey(t, anterior)},
+		}, []string{"ed-atual", "es-anterior"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ks, err := LoadKeySet(tt.cfg)
+			if err != nil {
+				t.Fatalf("erro ao carregar chaves: %v", err)
+			}
+			jwks := ks.JWKS()
+			if jwks.Keys == nil || len(jwks.Keys) != len(tt.kids) {
+				t.Fatalf("JWKS com %d chaves, esperadas %v", len(jwks.Keys), tt.kids)
+			}
+			for i, jwk := range jwks.Keys {
+				if jwk.Kid != tt.kids[i] || jwk.Kty == "oct" || jwk.Alg == "HS256" {
+					t.Errorf("chave %d do JWKS = %+v, esperado kid %s sem segredo", i, jwk, tt.kids[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package clientip
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	mu      sync.RWMutex
+	trusted []*net.IPNet
+)
+
+// SetTrustedProxies define os proxies confiáveis (IPs ou faixas CIDR). Os cabeçalhos X-Forwarded-For
+// e X-Real-IP só são considerados quando a conexão vem de um deles; sem proxies configurados, o IP
+// do cliente é sempre o endereço da conexão.
+func SetTrustedProxies(proxies []string) error {
+	var redes []*net.IPNet
+	for _, proxy := range proxies {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return fmt.Errorf("proxy confiável inválido: %q (use um IP ou uma faixa CIDR)", proxy)
+			}
+			bits := 8 * net.IPv4len
+			if ip.To4() == nil {
+				bits = 8 * net.IPv6len
+			}
+			redes = append(redes, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		
+		_, rede, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return fmt.Errorf("proxy confiável inválido: %q (use um IP ou uma faixa CIDR)", proxy)
+		}
+		redes = append(redes, rede)
+	}
+	
+	mu.Lock()
+	trusted = redes
+	mu.Unlock()
+	return nil
+}
+
+// isTrusted informa se o IP pertence a um dos proxies confiáveis
+func isTrusted(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, rede := range trusted {
+		if rede.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// FromRequest obtém o endereço IP do cliente, sem a porta. Atrás de um proxy confiável, o IP é o
+// último endereço não confiável de X-Forwarded-For (os anteriores podem ter sido enviados pelo
+// próprio cliente) ou, na falta dele, o de X-Real-IP.
+func FromRequest(r *http.Request) string {
+	remoto := host(r.RemoteAddr)
+	if !isTrusted(net.ParseIP(remoto)) {
+		return remoto
+	}
+	
+	if encaminhado := r.Header.Values("X-Forwarded-For"); len(encaminhado) > 0 {
+		enderecos := strings.Split(strings.Join(encaminhado, ","), ",")
+		for i := len(enderecos) - 1; i >= 0; i-- {
+			ip := net.ParseIP(host(strings.TrimSpace(enderecos[i])))
+			if ip == nil {
+				// Entrada inválida: os endereços anteriores a ela não são confiáveis
+				break
+			}
+			if !isTrusted(ip) {
+				return ip.String()
+			}
+		}
+	}
+	
+	if ip := net.ParseIP(host(strings.TrimSpace(r.Header.Get("X-Real-IP")))); ip != nil {
+		return ip.String()
+	}
+	return remoto
+}
+
+// host remove a porta do endereço, quando houver
+func host(addr string) string {
+	if h, _, err := net.SplitHostPort(addr); err == nil {
+		return h
+	}
+	return strings.Trim(addr, "[]")
+}
@@ -0,0 +1,53 @@
+package clientip
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	if err := SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"}); err != nil {
+		t.Fatalf("erro ao configurar os proxies confiáveis: %v", err)
+	}
+	t.Cleanup(func() { SetTrustedProxies(nil) })
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"sem proxy remove a porta", "203.0.113.5:51234", "", "", "203.0.113.5"},
+		{"outra porta, mesmo IP", "203.0.113.5:51999", "", "", "203.0.113.5"},
+		{"IPv6 sem a porta", "[2001:db8::1]:443", "", "", "2001:db8::1"},
+		{"cabeçalhos ignorados fora de proxy confiável", "203.0.113.5:51234", "198.51.100.1", "198.51.100.2", "203.0.113.5"},
+		{"X-Forwarded-For de proxy confiável", "10.1.2.3:8080", "198.51.100.1", "", "198.51.100.1"},
+		{"último endereço não confiável", "10.1.2.3:8080", "1.2.3.4, 198.51.100.1, 10.9.9.9", "", "198.51.100.1"},
+		{"X-Real-IP de proxy confiável", "192.168.1.10:8080", "", "198.51.100.2", "198.51.100.2"},
+		{"cabeçalhos inválidos", "10.1.2.3:8080", "lixo", "lixo", "10.1.2.3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := FromRequest(r); got != tt.want {
+				t.Errorf("FromRequest() = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesInvalido(t *testing.T) {
+	for _, proxy := range []string{"proxy.local", "10.0.0.0/33"} {
+		if err := SetTrustedProxies([]string{proxy}); err == nil {
+			t.Errorf("SetTrustedProxies(%q) aceito, esperado erro", proxy)
+		}
+	}
+}
@@ -2,16 +2,88 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// Ambientes de execução da aplicação
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Armazenamentos disponíveis para a limitação de taxa
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStoreDatabase = "database"
+	RateLimitStoreMySQL    = "mysql" // Nome anterior de RateLimitStoreDatabase, ainda aceito
+)
+
+// Políticas da fila do log de auditoria cheia
+const (
+	AuditOverflowBlock = "block"
+	AuditOverflowDrop  = "drop"
+	AuditOverflowSpill = "spill"
 )
 
 // Config armazena as configurações da aplicação
 type Config struct {
-	DatabaseURL string
-	ServerPort  int
+	Environment    string
+	DatabaseDriver dialect.Dialect // mysql, postgres ou sqlite
+	DatabaseURL    string
+	QueryTimeout   time.Duration // Tempo limite de cada consulta ao banco (0 desativa)
+	ServerPort     int
+	JWT            JWTConfig
+	HTTP           HTTPConfig
+	RateLimitStore string   // memory (por instância) ou database (compartilhado entre instâncias)
+	TrustedProxies []string // Proxies (IPs ou faixas CIDR) cujos cabeçalhos X-Forwarded-For e X-Real-IP são aceitos
+	MetricsToken   string   // Token exigido em /metrics (vazio: acesso livre)
+	LogLevel       string   // debug, info, warn ou error
+	LogFormat      string   // json ou text
+
+	AuditCheckpointInterval time.Duration // Intervalo entre os pontos de verificação do log de auditoria (0 desativa)
+	AuditQueueSize          int           // Capacidade da fila de gravação assíncrona do log de auditoria (0 grava diretamente)
+	AuditBatchSize          int           // Máximo de entradas do log de auditoria gravadas por INSERT
+	AuditFlushInterval      time.Duration // Intervalo máximo até a gravação de um lote incompleto
+	AuditOverflow           string        // Política com a fila cheia: block, drop ou spill
+	AuditSpillFile          string        // Arquivo de transbordo do log de auditoria
+}
+
+// JWTConfig armazena as chaves de assinatura e verificação dos tokens JWT
+type JWTConfig struct {
+	Algorithm        string            // HS256, RS256, ES256 ou EdDSA
+	KeyID            string            // kid da chave de assinatura (opcional; derivado da chave se vazio)
+	Secret           string            // Segredo da chave HS256
+	PrivateKeyFile   string            // Arquivo PEM da chave privada (RS256, ES256, EdDSA)
+	VerificationKeys map[string]string // Chaves públicas anteriores aceitas na verificação: kid -> arquivo PEM
+	PreviousSecrets  map[string]string // Segredos HS256 anteriores aceitos na verificação: kid -> segredo
+}
+
+// HTTPConfig armazena os tempos limite do servidor HTTP e do encerramento
+type HTTPConfig struct {
+	ReadHeaderTimeout time.Duration // Leitura dos cabeçalhos da requisição
+	ReadTimeout       time.Duration // Leitura da requisição completa, incluindo o corpo
+	WriteTimeout      time.Duration // Escrita da resposta
+	IdleTimeout       time.Duration // Conexões keep-alive ociosas
+	MaxHeaderBytes    int           // Tamanho máximo dos cabeçalhos da requisição
+	ShutdownTimeout   time.Duration // Espera pelas requisições em andamento no encerramento
+}
+
+// HasSigningKey indica se uma chave de assinatura foi configurada
+func (c JWTConfig) HasSigningKey() bool {
+	return c.Secret != "" || c.PrivateKeyFile != ""
+}
+
+// IsProduction indica se a aplicação está sendo executada em produção
+func (c *Config) IsProduction() bool {
+	return c.Environment == EnvProduction
 }
 
 // Load carrega as configurações da aplicação
@@ -21,7 +93,147 @@ func Load() (*Config, error) {
 		fmt.Println("Arquivo .env não encontrado, usando variáveis de ambiente do sistema")
 	}
 
-	// Configuração do banco de dados
+	// Banco de dados
+	dbDriver, err := dialect.Parse(getEnv("DB_DRIVER", string(dialect.MySQL)))
+	if err != nil {
+		return nil, fmt.Errorf("DB_DRIVER inválido: %v", err)
+	}
+	dbURL := os.Getenv("DATABASE_URL")
+	if dbURL == "" {
+		dbURL = buildDatabaseURL(dbDriver)
+	}
+	queryTimeout, err := time.ParseDuration(getEnv("DB_QUERY_TIMEOUT", "5s"))
+	if err != nil || queryTimeout < 0 {
+		return nil, fmt.Errorf("DB_QUERY_TIMEOUT inválido: use uma duração como 5s ou 500ms (0 desativa)")
+	}
+
+	// Porta do servidor
+	serverPort, err := strconv.Atoi(getEnv("SERVER_PORT", "8080"))
+	if err != nil {
+		return nil, fmt.Errorf("porta do servidor inválida: %v", err)
+	}
+
+	// Tempos limite do servidor HTTP
+	httpConfig, err := loadHTTPConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	// Ambiente de execução
+	environment := strings.ToLower(getEnv("APP_ENV", EnvDevelopment))
+	if environment != EnvDevelopment && environment != EnvProduction {
+		return nil, fmt.Errorf("APP_ENV inválido: %s (use %s ou %s)", environment, EnvDevelopment, EnvProduction)
+	}
+
+	// Nível e formato do log
+	logLevel := strings.ToLower(getEnv("LOG_LEVEL", "info"))
+	if logLevel != "debug" && logLevel != "info" && logLevel != "warn" && logLevel != "error" {
+		return nil, fmt.Errorf("LOG_LEVEL inválido: %s (use debug, info, warn ou error)", logLevel)
+	}
+	logFormat := strings.ToLower(getEnv("LOG_FORMAT", "json"))
+	if logFormat != "json" && logFormat != "text" {
+		return nil, fmt.Errorf("LOG_FORMAT inválido: %s (use json ou text)", logFormat)
+	}
+
+	// Armazenamento da limitação de taxa
+	rateLimitStore := strings.ToLower(getEnv("RATE_LIMIT_STORE", RateLimitStoreMemory))
+	if rateLimitStore == RateLimitStoreMySQL {
+		rateLimitStore = RateLimitStoreDatabase
+	}
+	if rateLimitStore != RateLimitStoreMemory && rateLimitStore != RateLimitStoreDatabase {
+		return nil, fmt.Errorf("RATE_LIMIT_STORE inválido: %s (use %s ou %s)", rateLimitStore, RateLimitStoreMemory, RateLimitStoreDatabase)
+	}
+
+	// Intervalo dos pontos de verificação do log de auditoria
+	auditCheckpointInterval, err := time.ParseDuration(getEnv("AUDIT_CHECKPOINT_INTERVAL", "1h"))
+	if err != nil || auditCheckpointInterval < 0 {
+		return nil, fmt.Errorf("AUDIT_CHECKPOINT_INTERVAL inválido: use uma duração como 30m ou 1h (0 desativa)")
+	}
+
+	// Gravação assíncrona do log de auditoria
+	auditQueueSize, err := strconv.Atoi(getEnv("AUDIT_QUEUE_SIZE", "10000"))
+	if err != nil || auditQueueSize < 0 {
+		return nil, fmt.Errorf("AUDIT_QUEUE_SIZE inválido: use um número maior ou igual a zero (0 grava diretamente)")
+	}
+	auditBatchSize, err := strconv.Atoi(getEnv("AUDIT_BATCH_SIZE", "100"))
+	if err != nil || auditBatchSize <= 0 {
+		return nil, fmt.Errorf("AUDIT_BATCH_SIZE inválido: use um número maior que zero")
+	}
+	auditFlushInterval, err := time.ParseDuration(getEnv("AUDIT_FLUSH_INTERVAL", "1s"))
+	if err != nil || auditFlushInterval <= 0 {
+		return nil, fmt.Errorf("AUDIT_FLUSH_INTERVAL inválido: use uma duração como 500ms ou 1s")
+	}
+	auditOverflow := strings.ToLower(getEnv("AUDIT_OVERFLOW_POLICY", AuditOverflowBlock))
+	if auditOverflow != AuditOverflowBlock && auditOverflow != AuditOverflowDrop && auditOverflow != AuditOverflowSpill {
+		return nil, fmt.Errorf("AUDIT_OVERFLOW_POLICY inválido: %s (use %s, %s ou %s)", auditOverflow, AuditOverflowBlock, AuditOverflowDrop, AuditOverflowSpill)
+	}
+	auditSpillFile := getEnv("AUDIT_SPILL_FILE", "audit-spill.jsonl")
+
+	// Chaves dos tokens JWT
+	verificationKeys, err := parseKeyList("JWT_VERIFICATION_KEYS")
+	if err != nil {
+		return nil, err
+	}
+	previousSecrets, err := parseKeyList("JWT_PREVIOUS_SECRETS")
+	if err != nil {
+		return nil, err
+	}
+
+	jwtConfig := JWTConfig{
+		Algorithm:        getEnv("JWT_ALGORITHM", "HS256"),
+		KeyID:            os.Getenv("JWT_KEY_ID"),
+		Secret:           os.Getenv("JWT_SECRET"),
+		PrivateKeyFile:   os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		VerificationKeys: verificationKeys,
+		PreviousSecrets:  previousSecrets,
+	}
+
+	// Em produção, a chave de assinatura é obrigatória
+	if environment == EnvProduction && !jwtConfig.HasSigningKey() {
+		return nil, fmt.Errorf("nenhuma chave JWT configurada: defina JWT_SECRET ou JWT_PRIVATE_KEY_FILE em produção")
+	}
+
+	return &Config{
+		Environment:    environment,
+		DatabaseDriver: dbDriver,
+		DatabaseURL:    dbURL,
+		QueryTimeout:   queryTimeout,
+		ServerPort:     serverPort,
+		JWT:            jwtConfig,
+		HTTP:           httpConfig,
+		RateLimitStore: rateLimitStore,
+		TrustedProxies: parseList(os.Getenv("TRUSTED_PROXIES")),
+		MetricsToken:   os.Getenv("METRICS_TOKEN"),
+		LogLevel:       logLevel,
+		LogFormat:      logFormat,
+
+		AuditCheckpointInterval: auditCheckpointInterval,
+		AuditQueueSize:          auditQueueSize,
+		AuditBatchSize:          auditBatchSize,
+		AuditFlushInterval:      auditFlushInterval,
+		AuditOverflow:           auditOverflow,
+		AuditSpillFile:          auditSpillFile,
+	}, nil
+}
+
+// buildDatabaseURL monta a string de conexão do banco de dados a partir das variáveis DB_*
+func buildDatabaseURL(d dialect.Dialect) string {
+	switch d {
+	case dialect.SQLite:
+		// Chaves estrangeiras ativas, espera por bloqueios e transações de escrita serializadas
+		path := getEnv("DB_PATH", "estudogo.db")
+		return fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_txlock=immediate&_journal_mode=WAL", path)
+	case dialect.Postgres:
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(getEnv("DB_USER", "postgres"), os.Getenv("DB_PASS")),
+			Host:     getEnv("DB_HOST", "localhost") + ":" + getEnv("DB_PORT", "5432"),
+			Path:     "/" + getEnv("DB_NAME", "estudogo"),
+			RawQuery: "sslmode=" + getEnv("DB_SSLMODE", "disable"),
+		}
+		return u.String()
+	}
+
 	dbUser := getEnv("DB_USER", "klebe351_kleberGo")
 	dbPass := getEnv("DB_PASS", "D05m09@123")
 	dbHost := getEnv("DB_HOST", "br38.hostgator.com.br")
@@ -29,19 +241,74 @@ func Load() (*Config, error) {
 	dbName := getEnv("DB_NAME", "klebe351_portalSeguradora")
 
 	// Montar string de conexão MySQL
-	dbURL := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", 
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", 
 		dbUser, dbPass, dbHost, dbPort, dbName)
+}
 
-	// Porta do servidor
-	serverPort, err := strconv.Atoi(getEnv("SERVER_PORT", "8080"))
-	if err != nil {
-		return nil, fmt.Errorf("porta do servidor inválida: %v", err)
+// loadHTTPConfig lê os tempos limite do servidor HTTP e do encerramento
+func loadHTTPConfig() (HTTPConfig, error) {
+	var cfg HTTPConfig
+	var err error
+
+	durations := []struct {
+		key          string
+		defaultValue string
+		target       *time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", "5s", &cfg.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", "30s", &cfg.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", "60s", &cfg.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", "120s", &cfg.IdleTimeout},
+		{"SHUTDOWN_TIMEOUT", "30s", &cfg.ShutdownTimeout},
+	}
+	for _, d := range durations {
+		*d.target, err = time.ParseDuration(getEnv(d.key, d.defaultValue))
+		if err != nil || *d.target <= 0 {
+			return cfg, fmt.Errorf("%s inválido: use uma duração maior que zero, como 30s", d.key)
+		}
 	}
 
-	return &Config{
-		DatabaseURL: dbURL,
-		ServerPort:  serverPort,
-	}, nil
+	cfg.MaxHeaderBytes, err = strconv.Atoi(getEnv("HTTP_MAX_HEADER_BYTES", "1048576"))
+	if err != nil || cfg.MaxHeaderBytes <= 0 {
+		return cfg, fmt.Errorf("HTTP_MAX_HEADER_BYTES inválido: use um número de bytes maior que zero")
+	}
+
+	return cfg, nil
+}
+
+// parseKeyList lê uma variável de ambiente no formato "kid1=valor1,kid2=valor2"
+func parseKeyList(key string) (map[string]string, error) {
+	keys := make(map[string]string)
+
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return keys, nil
+	}
+
+	for _, entry := range strings.Split(value, ",") {
+		kid, v, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		kid, v = strings.TrimSpace(kid), strings.TrimSpace(v)
+		if !ok || kid == "" || v == "" {
+			return nil, fmt.Errorf("%s inválido: use o formato kid=valor separado por vírgulas", key)
+		}
+		if _, exists := keys[kid]; exists {
+			return nil, fmt.Errorf("%s inválido: kid duplicado %s", key, kid)
+		}
+		keys[kid] = v
+	}
+
+	return keys, nil
+}
+
+// parseList lê uma lista de valores separados por vírgulas, ignorando os vazios
+func parseList(value string) []string {
+	var list []string
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
 }
 
 // getEnv obtém uma variável de ambiente ou retorna um valor padrão
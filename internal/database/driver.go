@@ -0,0 +1,207 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// dialectConnector adapta as conexões do driver ao dialeto do banco: converte os marcadores "?" das
+// instruções (PostgreSQL) e os instantes informados nos parâmetros (SQLite, sempre em UTC, para que
+// possam ser comparados com CURRENT_TIMESTAMP)
+type dialectConnector struct {
+	connector driver.Connector
+	dialect   dialect.Dialect
+}
+
+// Connect abre uma nova conexão com o banco de dados
+func (c *dialectConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dialectConn{Conn: conn, dialect: c.dialect}, nil
+}
+
+// Driver retorna o driver do banco de dados
+func (c *dialectConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// dsnConnector abre as conexões de um driver que não oferece um driver.Connector
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+// Connect abre uma nova conexão com o banco de dados
+func (c dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+// Driver retorna o driver do banco de dados
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// dialectConn repassa as operações à conexão do driver, adaptando as instruções e os parâmetros
+type dialectConn struct {
+	driver.Conn
+	dialect dialect.Dialect
+}
+
+// Prepare prepara uma instrução
+func (c *dialectConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(c.dialect.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	return &dialectStmt{Stmt: stmt, dialect: c.dialect}, nil
+}
+
+// PrepareContext prepara uma instrução
+func (c *dialectConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	preparer, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+
+	stmt, err := preparer.PrepareContext(ctx, c.dialect.Rebind(query))
+	if err != nil {
+		return nil, err
+	}
+	return &dialectStmt{Stmt: stmt, dialect: c.dialect}, nil
+}
+
+// BeginTx inicia uma transação
+func (c *dialectConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+// QueryContext executa uma consulta diretamente, sem preparar a instrução
+func (c *dialectConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return queryer.QueryContext(ctx, c.dialect.Rebind(query), namedArgs(c.dialect, args))
+}
+
+// ExecContext executa uma instrução diretamente, sem prepará-la
+func (c *dialectConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	return execer.ExecContext(ctx, c.dialect.Rebind(query), namedArgs(c.dialect, args))
+}
+
+// Ping verifica se a conexão está ativa
+func (c *dialectConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession prepara a conexão para ser reutilizada pelo pool
+func (c *dialectConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid indica se a conexão pode ser devolvida ao pool
+func (c *dialectConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue repassa a conversão dos parâmetros ao driver, quando suportada
+func (c *dialectConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// dialectStmt repassa a execução de uma instrução preparada, adaptando os parâmetros
+type dialectStmt struct {
+	driver.Stmt
+	dialect dialect.Dialect
+}
+
+// Exec executa a instrução preparada
+func (s *dialectStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.Stmt.Exec(valueArgs(s.dialect, args))
+}
+
+// Query executa a consulta preparada
+func (s *dialectStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.Stmt.Query(valueArgs(s.dialect, args))
+}
+
+// ExecContext executa a instrução preparada
+func (s *dialectStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		return execer.ExecContext(ctx, namedArgs(s.dialect, args))
+	}
+	return s.Exec(namedToValues(args))
+}
+
+// QueryContext executa a consulta preparada
+func (s *dialectStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		return queryer.QueryContext(ctx, namedArgs(s.dialect, args))
+	}
+	return s.Query(namedToValues(args))
+}
+
+// namedArgs converte os instantes dos parâmetros para UTC no SQLite
+func namedArgs(d dialect.Dialect, args []driver.NamedValue) []driver.NamedValue {
+	if d != dialect.SQLite {
+		return args
+	}
+
+	converted := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		if t, ok := arg.Value.(time.Time); ok {
+			arg.Value = t.UTC()
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// valueArgs converte os instantes dos parâmetros para UTC no SQLite
+func valueArgs(d dialect.Dialect, args []driver.Value) []driver.Value {
+	if d != dialect.SQLite {
+		return args
+	}
+
+	converted := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			arg = t.UTC()
+		}
+		converted[i] = arg
+	}
+	return converted
+}
+
+// namedToValues descarta os nomes dos parâmetros, para os drivers sem suporte a parâmetros nomeados
+func namedToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// recordingDriver registra as instruções e os parâmetros recebidos pelas conexões
+type recordingDriver struct {
+	queries []string
+	args    [][]driver.NamedValue
+}
+
+func (d *recordingDriver) Open(string) (driver.Conn, error) {
+	return &recordingConn{driver: d}, nil
+}
+
+func (d *recordingDriver) record(query string, args []driver.NamedValue) {
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, args)
+}
+
+// recordingConn é uma conexão que executa instruções diretamente ou preparadas, sem banco de dados
+type recordingConn struct {
+	driver *recordingDriver
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{driver: c.driver, query: query}, nil
+}
+
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *recordingConn) Commit() error             { return nil }
+func (c *recordingConn) Rollback() error           { return nil }
+
+func (c *recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.driver.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.driver.record(query, args)
+	return emptyRows{}, nil
+}
+
+// recordingStmt é uma instrução preparada da recordingConn
+type recordingStmt struct {
+	driver *recordingDriver
+	query  string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.driver.record(s.query, valuesToNamed(args))
+	return driver.RowsAffected(1), nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.driver.record(s.query, valuesToNamed(args))
+	return emptyRows{}, nil
+}
+
+func valuesToNamed(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// emptyRows é um resultado de consulta sem linhas
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string              { return []string{"id"} }
+func (emptyRows) Close() error                   { return nil }
+func (emptyRows) Next(dest []driver.Value) error { return io.EOF }
+
+// openRecording abre um pool sobre o recordingDriver adaptado ao dialeto informado
+func openRecording(t *testing.T, d dialect.Dialect) (*sql.DB, *recordingDriver) {
+	t.Helper()
+
+	rec := &recordingDriver{}
+	db := sql.OpenDB(&dialectConnector{connector: dsnConnector{driver: rec}, dialect: d})
+	t.Cleanup(func() { db.Close() })
+	return db, rec
+}
+
+func TestDialectConnRebind(t *testing.T) {
+	db, rec := openRecording(t, dialect.Postgres)
+	ctx := context.Background()
+
+	// Execução direta, consulta direta, instrução preparada e transação
+	if _, err := db.ExecContext(ctx, "UPDATE eventos SET Descricao = '?' WHERE idCodigoEvento = ? AND idSeguradora = ?", 1, 2); err != nil {
+		t.Fatalf("erro na execução: %v", err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT id FROM eventos WHERE id = ?", 1)
+	if err != nil {
+		t.Fatalf("erro na consulta: %v", err)
+	}
+	rows.Close()
+	stmt, err := db.PrepareContext(ctx, "DELETE FROM eventos WHERE id = ?")
+	if err != nil {
+		t.Fatalf("erro ao preparar: %v", err)
+	}
+	if _, err := stmt.ExecContext(ctx, 1); err != nil {
+		t.Fatalf("erro na instrução preparada: %v", err)
+	}
+	stmt.Close()
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("erro ao iniciar transação: %v", err)
+	}
+	if _, err := tx.ExecContext(ctx, "INSERT INTO eventos (Evento, Descricao) VALUES (?, ?)", 101, "x"); err != nil {
+		t.Fatalf("erro na transação: %v", err)
+	}
+	tx.Commit()
+
+	want := []string{
+		"UPDATE eventos SET Descricao = '?' WHERE idCodigoEvento = $1 AND idSeguradora = $2",
+		"SELECT id FROM eventos WHERE id = $1",
+		"DELETE FROM eventos WHERE id = $1",
+		"INSERT INTO eventos (Evento, Descricao) VALUES ($1, $2)",
+	}
+	if len(rec.queries) != len(want) {
+		t.Fatalf("instruções recebidas pelo driver = %q, esperado %q", rec.queries, want)
+	}
+	for i := range want {
+		if rec.queries[i] != want[i] {
+			t.Errorf("instrução %d = %q, esperado %q", i, rec.queries[i], want[i])
+		}
+	}
+}
+
+func TestDialectConnSQLite(t *testing.T) {
+	db, rec := openRecording(t, dialect.SQLite)
+
+	// O SQLite mantém os marcadores e recebe os instantes em UTC
+	instante := time.Date(2024, 1, 31, 10, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+	if _, err := db.Exec("DELETE FROM sessoes WHERE expires_at < ?", instante); err != nil {
+		t.Fatalf("erro na execução: %v", err)
+	}
+
+	if len(rec.queries) != 1 || rec.queries[0] != "DELETE FROM sessoes WHERE expires_at < ?" {
+		t.Fatalf("instruções recebidas pelo driver = %q", rec.queries)
+	}
+	got, ok := rec.args[0][0].Value.(time.Time)
+	if !ok || got.Location() != time.UTC || !got.Equal(instante) {
+		t.Errorf("parâmetro recebido = %v, esperado %v em UTC", rec.args[0][0].Value, instante)
+	}
+}
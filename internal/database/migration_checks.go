@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// migrationChecks são verificações executadas antes de aplicar uma migração. Em vez de alterar dados
+// para que a migração possa ser aplicada, elas a interrompem com a lista dos registros que a impedem,
+// que devem ser corrigidos antes de uma nova tentativa.
+var migrationChecks = map[int]func(q queryer) error{
+	10: checkConfigsAtivasUnicas,
+	11: checkLotesComSeguradora,
+}
+
+// checkConfigsAtivasUnicas verifica se há no máximo uma configuração ativa por sistema contábil,
+// objeto de contabilização e evento, condição do índice único da migração 0010
+func checkConfigsAtivasUnicas(q queryer) error {
+	query := `
+	SELECT c.idSistemaContabil, c.idObjetoContabilizacao, c.idCodigoEvento, c.idSistemaContabilConfig
+	FROM sistema_contabil_config c
+	WHERE c.ativo = TRUE AND EXISTS (
+		SELECT 1 FROM sistema_contabil_config o
+		WHERE o.idSistemaContabil = c.idSistemaContabil
+		AND o.idObjetoContabilizacao = c.idObjetoContabilizacao
+		AND o.idCodigoEvento = c.idCodigoEvento
+		AND o.ativo = TRUE
+		AND o.idSistemaContabilConfig <> c.idSistemaContabilConfig
+	)
+	ORDER BY c.idSistemaContabil, c.idObjetoContabilizacao, c.idCodigoEvento, c.idSistemaContabilConfig`
+	
+	rows, err := q.QueryContext(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar configurações ativas duplicadas: %v", err)
+	}
+	defer rows.Close()
+	
+	// Configurações duplicadas agrupadas por combinação, na ordem da consulta
+	var combinacoes []string
+	var configs []string
+	var atual string
+	for rows.Next() {
+		var sistema, objeto, evento, config int64
+		if err := rows.Scan(&sistema, &objeto, &evento, &config); err != nil {
+			return fmt.Errorf("erro ao ler configuração duplicada: %v", err)
+		}
+		
+		combinacao := fmt.Sprintf("sistema contábil %d, objeto de contabilização %d, evento %d", sistema, objeto, evento)
+		if combinacao != atual && atual != "" {
+			combinacoes = append(combinacoes, fmt.Sprintf("%s (configurações %s)", atual, strings.Join(configs, ", ")))
+			configs = nil
+		}
+		atual = combinacao
+		configs = append(configs, fmt.Sprintf("%d", config))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre configurações duplicadas: %v", err)
+	}
+	if atual == "" {
+		return nil
+	}
+	combinacoes = append(combinacoes, fmt.Sprintf("%s (configurações %s)", atual, strings.Join(configs, ", ")))
+	
+	return fmt.Errorf("há mais de uma configuração de sistema contábil ativa para a mesma combinação; desative ou exclua as duplicadas e aplique a migração novamente: %s",
+		strings.Join(combinacoes, "; "))
+}
+
+// checkLotesComSeguradora verifica se a seguradora de cada lote de lançamentos pode ser definida pela
+// migração 0011: a dos seus lançamentos ou, nos lotes sem lançamentos, a do usuário que os enviou
+func checkLotesComSeguradora(q queryer) error {
+	query := `
+	SELECT l.id_lote
+	FROM lotes_lancamento l
+	WHERE NOT EXISTS (SELECT 1 FROM lancamentos la WHERE la.id_lote = l.id_lote)
+	AND NOT EXISTS (SELECT 1 FROM usuarios u WHERE u.id = l.id_usuario)
+	ORDER BY l.id_lote`
+	
+	rows, err := q.QueryContext(context.Background(), query)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar a seguradora dos lotes de lançamentos: %v", err)
+	}
+	defer rows.Close()
+	
+	var lotes []string
+	for rows.Next() {
+		var lote int64
+		if err := rows.Scan(&lote); err != nil {
+			return fmt.Errorf("erro ao ler lote de lançamentos: %v", err)
+		}
+		lotes = append(lotes, fmt.Sprintf("%d", lote))
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre lotes de lançamentos: %v", err)
+	}
+	if len(lotes) == 0 {
+		return nil
+	}
+	
+	return fmt.Errorf("há lotes de lançamentos sem lançamentos e sem usuário cadastrado, cuja seguradora não pode ser definida; associe-os a um usuário da seguradora (id_usuario) ou exclua-os e aplique a migração novamente: lotes %s",
+		strings.Join(lotes, ", "))
+}
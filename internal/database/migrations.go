@@ -0,0 +1,642 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// Scripts de migração embutidos no binário, em um diretório por banco de dados (migrations/mysql,
+// migrations/postgres e migrations/sqlite). Cada versão possui um script de aplicação
+// (NNNN_nome.up.sql) e um de reversão (NNNN_nome.down.sql).
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// Nome do lock do MySQL que impede duas instâncias de migrarem ao mesmo tempo
+const migrationLockName = "schema_migrations"
+
+// Chave do advisory lock do PostgreSQL com a mesma finalidade
+const migrationAdvisoryLockKey = 7250001
+
+// Tempo máximo de espera pelo lock de migração (em segundos)
+const migrationLockTimeout = 60
+
+// Formato dos nomes dos arquivos de migração
+var migrationFileRegex = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Migration representa uma versão do esquema do banco de dados
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// MigrationStatus representa a situação de uma migração no banco de dados
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+	Checksum  string     `json:"checksum"`
+	Modified  bool       `json:"modified"` // Script alterado depois de aplicado
+}
+
+// appliedMigration representa um registro da tabela schema_migrations
+type appliedMigration struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Migrator aplica e reverte as migrações do esquema
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator cria um novo migrador com as migrações embutidas no binário para o banco de dados configurado
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, path.Join("migrations", string(dialect.Current())))
+	if err != nil {
+		return nil, err
+	}
+	
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Migrate aplica todas as migrações pendentes
+func Migrate(db *sql.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	
+	_, err = migrator.Up()
+	return err
+}
+
+// LatestVersion retorna a versão mais recente disponível
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up aplica todas as migrações pendentes e retorna as versões aplicadas
+func (m *Migrator) Up() ([]int, error) {
+	return m.To(m.LatestVersion())
+}
+
+// Down reverte a última migração aplicada e retorna a versão revertida
+func (m *Migrator) Down() ([]int, error) {
+	var reverted []int
+	
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.verify(conn)
+		if err != nil {
+			return err
+		}
+		
+		current := currentVersion(applied)
+		if current == 0 {
+			return nil
+		}
+		
+		reverted, err = m.migrate(conn, applied, previousVersion(applied, current))
+		return err
+	})
+	
+	return reverted, err
+}
+
+// To aplica ou reverte as migrações até a versão informada e retorna as versões afetadas
+func (m *Migrator) To(target int) ([]int, error) {
+	if target < 0 || (target > 0 && m.find(target) == nil) {
+		return nil, fmt.Errorf("versão de migração inexistente: %d", target)
+	}
+	
+	var changed []int
+	
+	err := m.withLock(func(conn *sql.Conn) error {
+		applied, err := m.verify(conn)
+		if err != nil {
+			return err
+		}
+		
+		changed, err = m.migrate(conn, applied, target)
+		return err
+	})
+	
+	return changed, err
+}
+
+// Status retorna a situação de todas as migrações conhecidas
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.ensureTable(m.db); err != nil {
+		return nil, err
+	}
+	
+	applied, err := m.applied(context.Background(), m.db)
+	if err != nil {
+		return nil, err
+	}
+	
+	var status []MigrationStatus
+	
+	for _, migration := range m.migrations {
+		s := MigrationStatus{
+			Version:  migration.Version,
+			Name:     migration.Name,
+			Checksum: migration.Checksum,
+		}
+		if a, ok := applied[migration.Version]; ok {
+			appliedAt := a.AppliedAt
+			s.Applied = true
+			s.AppliedAt = &appliedAt
+			s.Modified = a.Checksum != migration.Checksum
+		}
+		status = append(status, s)
+	}
+	
+	return status, nil
+}
+
+// Pending retorna a versão atual do esquema e as versões ainda não aplicadas (ou aplicadas com o script
+// alterado depois), com a consulta limitada pelo contexto. Ao contrário de Status, não cria a tabela de
+// controle de migrações.
+func (m *Migrator) Pending(ctx context.Context) (int, []int, error) {
+	applied, err := m.applied(ctx, m.db)
+	if err != nil {
+		return 0, nil, err
+	}
+	
+	var pending []int
+	for _, migration := range m.migrations {
+		if a, ok := applied[migration.Version]; !ok || a.Checksum != migration.Checksum {
+			pending = append(pending, migration.Version)
+		}
+	}
+	
+	return currentVersion(applied), pending, nil
+}
+
+// migrate aplica (em ordem crescente) ou reverte (em ordem decrescente) as migrações até a versão alvo
+func (m *Migrator) migrate(conn *sql.Conn, applied map[int]appliedMigration, target int) ([]int, error) {
+	var changed []int
+	
+	// Aplicar as migrações pendentes até a versão alvo
+	for _, migration := range m.migrations {
+		if migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		
+		// A verificação, o script e o registro da migração formam uma única unidade
+		err := inMigrationTx(conn, func(q queryer) error {
+			// Verificar os dados que impediriam a migração
+			if check := migrationChecks[migration.Version]; check != nil {
+				if err := check(q); err != nil {
+					return err
+				}
+			}
+			
+			if err := execScript(q, migration.Up); err != nil {
+				return err
+			}
+			
+			_, err := q.ExecContext(
+				context.Background(),
+				"INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)",
+				migration.Version, migration.Name, migration.Checksum,
+			)
+			if err != nil {
+				return fmt.Errorf("erro ao registrar migração: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return changed, fmt.Errorf("erro ao aplicar migração %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		
+		changed = append(changed, migration.Version)
+	}
+	
+	// Reverter as migrações aplicadas acima da versão alvo
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		migration := m.migrations[i]
+		if migration.Version <= target {
+			break
+		}
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		
+		err := inMigrationTx(conn, func(q queryer) error {
+			if err := execScript(q, migration.Down); err != nil {
+				return err
+			}
+			
+			_, err := q.ExecContext(context.Background(), "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+			if err != nil {
+				return fmt.Errorf("erro ao remover registro da migração: %v", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return changed, fmt.Errorf("erro ao reverter migração %04d_%s: %v", migration.Version, migration.Name, err)
+		}
+		
+		changed = append(changed, migration.Version)
+	}
+	
+	return changed, nil
+}
+
+// inMigrationTx executa a aplicação ou a reversão de uma migração em uma transação da conexão de migração,
+// de modo que uma falha no meio do script não deixe o esquema alterado pela metade nem o registro em
+// schema_migrations divergente dele. No MySQL, cada instrução DDL confirma implicitamente a transação em
+// curso: as instruções são executadas diretamente na conexão e, se uma delas falhar, as anteriores
+// permanecem aplicadas e devem ser desfeitas manualmente antes de uma nova tentativa.
+func inMigrationTx(conn *sql.Conn, fn func(q queryer) error) error {
+	ctx := context.Background()
+	
+	if dialect.Current() == dialect.MySQL {
+		return fn(conn)
+	}
+	
+	// No SQLite, o PRAGMA foreign_keys não tem efeito dentro de uma transação: as chaves estrangeiras são
+	// desativadas antes dela, para que as tabelas possam ser recriadas, e conferidas antes da confirmação
+	foreignKeys := false
+	if dialect.Current() == dialect.SQLite {
+		if err := conn.QueryRowContext(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys); err != nil {
+			return fmt.Errorf("erro ao consultar chaves estrangeiras: %v", err)
+		}
+		if foreignKeys {
+			if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+				return fmt.Errorf("erro ao desativar chaves estrangeiras: %v", err)
+			}
+			defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+		}
+	}
+	
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %v", err)
+	}
+	defer tx.Rollback()
+	
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if foreignKeys {
+		if err := checkForeignKeys(tx); err != nil {
+			return err
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %v", err)
+	}
+	return nil
+}
+
+// checkForeignKeys verifica, no SQLite, se restou alguma chave estrangeira sem o registro referenciado
+func checkForeignKeys(q queryer) error {
+	rows, err := q.QueryContext(context.Background(), "PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("erro ao verificar chaves estrangeiras: %v", err)
+	}
+	defer rows.Close()
+	
+	// Uma entrada por tabela e tabela referenciada, e não por registro
+	var tabelas []string
+	vistas := make(map[string]bool)
+	for rows.Next() {
+		var tabela, referenciada string
+		var linha sql.NullInt64
+		var indice int
+		if err := rows.Scan(&tabela, &linha, &referenciada, &indice); err != nil {
+			return fmt.Errorf("erro ao ler chave estrangeira inválida: %v", err)
+		}
+		chave := fmt.Sprintf("%s (referência a %s)", tabela, referenciada)
+		if !vistas[chave] {
+			vistas[chave] = true
+			tabelas = append(tabelas, chave)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre chaves estrangeiras inválidas: %v", err)
+	}
+	if len(tabelas) > 0 {
+		return fmt.Errorf("chaves estrangeiras sem o registro referenciado: %s", strings.Join(tabelas, ", "))
+	}
+	return nil
+}
+
+// withLock executa a função com o lock de migração obtido em uma conexão dedicada
+func (m *Migrator) withLock(fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	
+	// O lock pertence à conexão, por isso todas as operações usam a mesma conexão
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("erro ao obter conexão para migração: %v", err)
+	}
+	defer conn.Close()
+	
+	release, err := acquireMigrationLock(ctx, conn)
+	if err != nil {
+		return err
+	}
+	defer release()
+	
+	if err := m.ensureTable(conn); err != nil {
+		return err
+	}
+	
+	return fn(conn)
+}
+
+// acquireMigrationLock obtém o lock de migração do banco de dados e retorna a função que o libera.
+// O SQLite dispensa o lock: o arquivo do banco é usado por uma única instância da aplicação.
+func acquireMigrationLock(ctx context.Context, conn *sql.Conn) (func(), error) {
+	switch dialect.Current() {
+	case dialect.SQLite:
+		return func() {}, nil
+	case dialect.Postgres:
+		// O advisory lock não tem tempo de espera: a tentativa é repetida até o tempo máximo
+		deadline := time.Now().Add(migrationLockTimeout * time.Second)
+		for {
+			var acquired bool
+			err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(?)", migrationAdvisoryLockKey).Scan(&acquired)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao obter lock de migração: %v", err)
+			}
+			if acquired {
+				break
+			}
+			if time.Now().After(deadline) {
+				return nil, fmt.Errorf("não foi possível obter o lock de migração: outra instância está executando migrações")
+			}
+			time.Sleep(time.Second)
+		}
+		return func() {
+			conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", migrationAdvisoryLockKey)
+		}, nil
+	}
+	
+	var acquired sql.NullInt64
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", migrationLockName, migrationLockTimeout).Scan(&acquired)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter lock de migração: %v", err)
+	}
+	if !acquired.Valid || acquired.Int64 != 1 {
+		return nil, fmt.Errorf("não foi possível obter o lock de migração: outra instância está executando migrações")
+	}
+	return func() {
+		conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", migrationLockName)
+	}, nil
+}
+
+// verify confere se os scripts das migrações aplicadas não foram alterados
+func (m *Migrator) verify(conn *sql.Conn) (map[int]appliedMigration, error) {
+	applied, err := m.applied(context.Background(), conn)
+	if err != nil {
+		return nil, err
+	}
+	
+	for version, a := range applied {
+		migration := m.find(version)
+		if migration == nil {
+			return nil, fmt.Errorf("migração %04d_%s aplicada no banco não existe neste binário", version, a.Name)
+		}
+		if migration.Checksum != a.Checksum {
+			return nil, fmt.Errorf("checksum da migração %04d_%s não confere com o registrado no banco", version, migration.Name)
+		}
+	}
+	
+	return applied, nil
+}
+
+// queryer representa uma conexão ou pool capaz de executar consultas
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// ensureTable cria a tabela de controle de migrações se não existir
+func (m *Migrator) ensureTable(q queryer) error {
+	query := `
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		checksum CHAR(64) NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);`
+	
+	if _, err := q.ExecContext(context.Background(), query); err != nil {
+		return fmt.Errorf("erro ao criar tabela schema_migrations: %v", err)
+	}
+	
+	return nil
+}
+
+// applied retorna as migrações registradas na tabela schema_migrations
+func (m *Migrator) applied(ctx context.Context, q queryer) (map[int]appliedMigration, error) {
+	rows, err := q.QueryContext(ctx, "SELECT version, name, checksum, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar migrações aplicadas: %v", err)
+	}
+	defer rows.Close()
+	
+	applied := make(map[int]appliedMigration)
+	
+	for rows.Next() {
+		var a appliedMigration
+		if err := rows.Scan(&a.Version, &a.Name, &a.Checksum, &a.AppliedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler migração aplicada: %v", err)
+		}
+		applied[a.Version] = a
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre migrações aplicadas: %v", err)
+	}
+	
+	return applied, nil
+}
+
+// find busca uma migração pela versão
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// currentVersion retorna a maior versão aplicada
+func currentVersion(applied map[int]appliedMigration) int {
+	current := 0
+	for version := range applied {
+		if version > current {
+			current = version
+		}
+	}
+	return current
+}
+
+// previousVersion retorna a maior versão aplicada abaixo da versão informada
+func previousVersion(applied map[int]appliedMigration, version int) int {
+	previous := 0
+	for v := range applied {
+		if v < version && v > previous {
+			previous = v
+		}
+	}
+	return previous
+}
+
+// loadMigrations lê e valida os scripts de migração do diretório informado
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler migrações: %v", err)
+	}
+	
+	byVersion := make(map[int]*Migration)
+	
+	for _, entry := range entries {
+		matches := migrationFileRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("nome de arquivo de migração inválido: %s", entry.Name())
+		}
+		
+		version, _ := strconv.Atoi(matches[1])
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler migração %s: %v", entry.Name(), err)
+		}
+		
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = migration
+		} else if migration.Name != matches[2] {
+			return nil, fmt.Errorf("nomes divergentes para a migração %04d: %s e %s", version, migration.Name, matches[2])
+		}
+		
+		if matches[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+	
+	var migrations []Migration
+	
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migração %04d_%s deve ter os scripts up e down", migration.Version, migration.Name)
+		}
+		
+		// O checksum cobre os dois scripts para detectar qualquer alteração após a aplicação
+		sum := sha256.Sum256([]byte(migration.Up + "\x00" + migration.Down))
+		migration.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *migration)
+	}
+	
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	
+	return migrations, nil
+}
+
+// execScript executa cada instrução de um script de migração
+func execScript(q queryer, script string) error {
+	for _, statement := range splitStatements(script) {
+		if _, err := q.ExecContext(context.Background(), statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements separa as instruções de um script SQL pelo ";", ignorando comentários de linha,
+// ";" dentro de textos entre aspas ou entre $$ (corpo de funções do PostgreSQL) e ";" do corpo de
+// gatilhos do SQLite (CREATE TRIGGER ... BEGIN ... END)
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote rune
+	dollar := false
+	
+	lines := strings.Split(script, "\n")
+	for _, line := range lines {
+		// Ignorar comentários de linha inteira
+		if quote == 0 && !dollar && strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		
+		for i, c := range line {
+			switch {
+			case c == '$' && quote == 0 && strings.HasPrefix(line[i:], "$$"):
+				dollar = !dollar
+			case dollar:
+			case quote != 0:
+				if c == quote {
+					quote = 0
+				}
+			case c == '\'' || c == '"' || c == '`':
+				quote = c
+			case c == ';' && !triggerBodyOpen(current.String()):
+				if statement := strings.TrimSpace(current.String()); statement != "" {
+					statements = append(statements, statement)
+				}
+				current.Reset()
+				continue
+			}
+			current.WriteRune(c)
+		}
+		current.WriteRune('\n')
+	}
+	
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	
+	return statements
+}
+
+// triggerBodyOpen indica se a instrução é um gatilho cujo corpo (BEGIN ... END) ainda não terminou
+func triggerBodyOpen(statement string) bool {
+	statement = strings.ToUpper(strings.TrimSpace(statement))
+	if !strings.HasPrefix(statement, "CREATE TRIGGER") || !strings.Contains(statement, "BEGIN") {
+		return false
+	}
+	return !strings.HasSuffix(statement, "END")
+}
@@ -0,0 +1,134 @@
+-- Esquema inicial da aplicação
+
+-- Tabela de tipos de perfil
+CREATE TABLE IF NOT EXISTS tipo_perfil (
+	id_tipo_perfil INT AUTO_INCREMENT PRIMARY KEY,
+	perfil VARCHAR(100) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+-- Tabela de seguradoras
+CREATE TABLE IF NOT EXISTS seguradoras (
+	id_seguradora INT AUTO_INCREMENT PRIMARY KEY,
+	seguradora VARCHAR(100) NOT NULL,
+	nome_abreviado VARCHAR(50),
+	codigo_susep VARCHAR(20),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+-- Tabela de usuários
+CREATE TABLE IF NOT EXISTS usuarios (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	nome VARCHAR(100) NOT NULL,
+	email VARCHAR(100) NOT NULL UNIQUE,
+	login VARCHAR(50) NOT NULL UNIQUE,
+	senha VARCHAR(255) NOT NULL,
+	idTipoPerfil INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	AdminERP BOOLEAN DEFAULT FALSE,
+	bloqueado BOOLEAN DEFAULT FALSE,
+	bloqueado_ate DATETIME NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idTipoPerfil) REFERENCES tipo_perfil(id_tipo_perfil),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+-- Tabela de tentativas de login
+CREATE TABLE IF NOT EXISTS login_attempts (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	login VARCHAR(50) NOT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	success BOOLEAN NOT NULL,
+	attempt_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_login (login),
+	INDEX idx_ip_address (ip_address),
+	INDEX idx_attempt_time (attempt_time)
+);
+
+-- Tabela de auditoria
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INT AUTO_INCREMENT PRIMARY KEY,
+	user_id INT NULL,
+	username VARCHAR(50) NULL,
+	action VARCHAR(100) NOT NULL,
+	entity_type VARCHAR(50) NOT NULL,
+	entity_id VARCHAR(50) NULL,
+	details TEXT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_user_id (user_id),
+	INDEX idx_action (action),
+	INDEX idx_entity_type (entity_type),
+	INDEX idx_created_at (created_at)
+);
+
+-- Tabela de eventos
+CREATE TABLE IF NOT EXISTS eventos (
+	idCodigoEvento INT AUTO_INCREMENT PRIMARY KEY,
+	Evento INT NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+-- Tabela de objeto contabilização
+CREATE TABLE IF NOT EXISTS objeto_contabilizacao (
+	idObjetoContabilizacao INT AUTO_INCREMENT PRIMARY KEY,
+	ObjetoContabilizacao VARCHAR(100) NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+-- Tabela de objeto contabilização evento
+CREATE TABLE IF NOT EXISTS objeto_contabilizacao_evento (
+	idObjetoContabilizacaoEvento INT AUTO_INCREMENT PRIMARY KEY,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+-- Tabela de sistema contábil
+CREATE TABLE IF NOT EXISTS sistema_contabil (
+	idSistemaContabil INT AUTO_INCREMENT PRIMARY KEY,
+	SistemaContabil VARCHAR(100) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+-- Tabela de configuração de sistema contábil
+CREATE TABLE IF NOT EXISTS sistema_contabil_config (
+	idSistemaContabilConfig INT AUTO_INCREMENT PRIMARY KEY,
+	idSistemaContabil INT NOT NULL,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
@@ -0,0 +1,21 @@
+-- Permissões por tipo de perfil
+
+-- Tabela de permissões
+CREATE TABLE IF NOT EXISTS permissoes (
+	id_permissao INT AUTO_INCREMENT PRIMARY KEY,
+	nome VARCHAR(100) NOT NULL UNIQUE,
+	descricao VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+-- Tabela de permissões por tipo de perfil
+CREATE TABLE IF NOT EXISTS tipo_perfil_permissao (
+	id_tipo_perfil INT NOT NULL,
+	id_permissao INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id_tipo_perfil, id_permissao),
+	FOREIGN KEY (id_tipo_perfil) REFERENCES tipo_perfil(id_tipo_perfil),
+	FOREIGN KEY (id_permissao) REFERENCES permissoes(id_permissao)
+);
@@ -0,0 +1,13 @@
+-- Plano de contas por seguradora e sistema contábil, com as contas de débito e crédito das configurações (reversão)
+
+ALTER TABLE lancamento_partidas
+	DROP FOREIGN KEY fk_partida_conta,
+	DROP COLUMN id_conta;
+
+ALTER TABLE sistema_contabil_config
+	DROP FOREIGN KEY fk_scc_conta_debito,
+	DROP FOREIGN KEY fk_scc_conta_credito,
+	DROP COLUMN idContaDebito,
+	DROP COLUMN idContaCredito;
+
+DROP TABLE IF EXISTS plano_contas;
@@ -0,0 +1,32 @@
+-- Plano de contas por seguradora e sistema contábil, com as contas de débito e crédito das configurações
+
+-- Tabela do plano de contas
+CREATE TABLE IF NOT EXISTS plano_contas (
+	idConta INT AUTO_INCREMENT PRIMARY KEY,
+	idSeguradora INT NOT NULL,
+	idSistemaContabil INT NOT NULL,
+	Codigo VARCHAR(50) NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	Natureza CHAR(1) NOT NULL,
+	Tipo CHAR(1) NOT NULL,
+	idContaPai INT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	UNIQUE KEY uk_plano_contas_codigo (idSeguradora, idSistemaContabil, Codigo),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora),
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idContaPai) REFERENCES plano_contas(idConta)
+);
+
+-- Contas de débito e crédito das configurações de sistema contábil
+ALTER TABLE sistema_contabil_config
+	ADD COLUMN idContaDebito INT NULL,
+	ADD COLUMN idContaCredito INT NULL,
+	ADD CONSTRAINT fk_scc_conta_debito FOREIGN KEY (idContaDebito) REFERENCES plano_contas(idConta),
+	ADD CONSTRAINT fk_scc_conta_credito FOREIGN KEY (idContaCredito) REFERENCES plano_contas(idConta);
+
+-- Conta de cada partida dos lançamentos
+ALTER TABLE lancamento_partidas
+	ADD COLUMN id_conta INT NULL,
+	ADD CONSTRAINT fk_partida_conta FOREIGN KEY (id_conta) REFERENCES plano_contas(idConta);
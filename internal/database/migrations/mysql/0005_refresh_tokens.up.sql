@@ -0,0 +1,19 @@
+-- Refresh tokens persistidos, agrupados em famílias (sessões) para rotação e revogação
+
+-- Tabela de refresh tokens
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id_refresh_token INT AUTO_INCREMENT PRIMARY KEY,
+	jti VARCHAR(64) NOT NULL UNIQUE,
+	familia VARCHAR(64) NOT NULL,
+	id_usuario INT NOT NULL,
+	expires_at DATETIME NOT NULL,
+	rotated_at DATETIME NULL,
+	revoked_at DATETIME NULL,
+	motivo_revogacao VARCHAR(50) NULL,
+	ip_address VARCHAR(45) NOT NULL DEFAULT '',
+	user_agent VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	INDEX idx_refresh_token_familia (familia),
+	INDEX idx_refresh_token_usuario (id_usuario),
+	FOREIGN KEY (id_usuario) REFERENCES usuarios(id)
+);
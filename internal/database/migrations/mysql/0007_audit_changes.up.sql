@@ -0,0 +1,6 @@
+-- Alterações estruturadas (valor anterior e novo de cada campo) no log de auditoria
+
+ALTER TABLE audit_log ADD COLUMN changes TEXT NULL AFTER ip_address;
+
+-- Índice para a consulta do histórico de um registro
+CREATE INDEX idx_audit_log_entity ON audit_log (entity_type, entity_id);
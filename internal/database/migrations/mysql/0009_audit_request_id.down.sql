@@ -0,0 +1,5 @@
+-- ID da requisição (X-Request-ID) no log de auditoria (reversão)
+
+DROP INDEX idx_audit_log_request_id ON audit_log;
+
+ALTER TABLE audit_log DROP COLUMN request_id;
@@ -0,0 +1,5 @@
+-- Uma única configuração ativa por sistema contábil, objeto de contabilização e evento (reversão)
+
+ALTER TABLE sistema_contabil_config
+	DROP INDEX uk_sistema_contabil_config_ativa,
+	DROP COLUMN chave_ativa;
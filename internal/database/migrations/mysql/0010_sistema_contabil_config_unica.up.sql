@@ -0,0 +1,10 @@
+-- Uma única configuração ativa por sistema contábil, objeto de contabilização e evento
+
+-- Configurações ativas duplicadas não são desativadas aqui: a migração é interrompida antes deste script,
+-- com a lista das duplicadas, que devem ser resolvidas manualmente (checkConfigsAtivasUnicas)
+
+-- O MySQL não tem índices parciais: a coluna gerada é NULL nas configurações inativas, que não
+-- participam da chave única
+ALTER TABLE sistema_contabil_config
+	ADD COLUMN chave_ativa TINYINT GENERATED ALWAYS AS (IF(ativo, 1, NULL)) STORED,
+	ADD UNIQUE KEY uk_sistema_contabil_config_ativa (idSistemaContabil, idObjetoContabilizacao, idCodigoEvento, chave_ativa);
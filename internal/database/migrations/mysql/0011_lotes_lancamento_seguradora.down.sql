@@ -0,0 +1,6 @@
+-- Seguradora dos lotes de lançamentos (reversão)
+
+ALTER TABLE lotes_lancamento
+	DROP FOREIGN KEY fk_lote_seguradora,
+	DROP INDEX idx_lote_seguradora,
+	DROP COLUMN idSeguradora;
@@ -0,0 +1,24 @@
+-- Seguradora dos lotes de lançamentos, para restringir a consulta dos lotes à seguradora do usuário
+
+ALTER TABLE lotes_lancamento ADD COLUMN idSeguradora INT NULL AFTER id_lote;
+
+-- A seguradora de cada lote é a dos seus lançamentos
+UPDATE lotes_lancamento l
+JOIN (
+	SELECT id_lote, MIN(idSeguradora) AS idSeguradora
+	FROM lancamentos
+	GROUP BY id_lote
+) s ON s.id_lote = l.id_lote
+SET l.idSeguradora = s.idSeguradora;
+
+-- Lotes sem lançamentos (nenhuma transação aceita) são mantidos no histórico, com a seguradora do
+-- usuário que os enviou; a migração é interrompida antes se algum lote não tiver nenhuma das duas
+UPDATE lotes_lancamento l
+JOIN usuarios u ON u.id = l.id_usuario
+SET l.idSeguradora = u.idSeguradora
+WHERE l.idSeguradora IS NULL;
+
+ALTER TABLE lotes_lancamento
+	MODIFY idSeguradora INT NOT NULL,
+	ADD INDEX idx_lote_seguradora (idSeguradora),
+	ADD CONSTRAINT fk_lote_seguradora FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora);
@@ -0,0 +1,3 @@
+-- Entradas do log de auditoria ainda não encadeadas (reversão)
+
+DROP TABLE IF EXISTS audit_log_pendente;
@@ -0,0 +1,13 @@
+-- Esquema inicial da aplicação (reversão)
+
+DROP TABLE IF EXISTS sistema_contabil_config;
+DROP TABLE IF EXISTS sistema_contabil;
+DROP TABLE IF EXISTS objeto_contabilizacao_evento;
+DROP TABLE IF EXISTS objeto_contabilizacao;
+DROP TABLE IF EXISTS eventos;
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS usuarios;
+DROP TABLE IF EXISTS seguradoras;
+DROP TABLE IF EXISTS tipo_perfil;
+DROP FUNCTION IF EXISTS set_updated_at();
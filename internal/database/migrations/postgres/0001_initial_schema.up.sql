@@ -0,0 +1,168 @@
+-- Esquema inicial da aplicação
+
+-- Atualização automática de updated_at (equivalente ao ON UPDATE CURRENT_TIMESTAMP do MySQL)
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS TRIGGER AS $$
+BEGIN
+	NEW.updated_at = CURRENT_TIMESTAMP;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Tabela de tipos de perfil
+CREATE TABLE IF NOT EXISTS tipo_perfil (
+	id_tipo_perfil SERIAL PRIMARY KEY,
+	perfil VARCHAR(100) NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+CREATE TRIGGER trg_tipo_perfil_updated_at BEFORE UPDATE ON tipo_perfil
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de seguradoras
+CREATE TABLE IF NOT EXISTS seguradoras (
+	id_seguradora SERIAL PRIMARY KEY,
+	seguradora VARCHAR(100) NOT NULL,
+	nome_abreviado VARCHAR(50),
+	codigo_susep VARCHAR(20),
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+CREATE TRIGGER trg_seguradoras_updated_at BEFORE UPDATE ON seguradoras
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de usuários
+CREATE TABLE IF NOT EXISTS usuarios (
+	id SERIAL PRIMARY KEY,
+	nome VARCHAR(100) NOT NULL,
+	email VARCHAR(100) NOT NULL UNIQUE,
+	login VARCHAR(50) NOT NULL UNIQUE,
+	senha VARCHAR(255) NOT NULL,
+	idTipoPerfil INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	AdminERP BOOLEAN DEFAULT FALSE,
+	bloqueado BOOLEAN DEFAULT FALSE,
+	bloqueado_ate TIMESTAMPTZ NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idTipoPerfil) REFERENCES tipo_perfil(id_tipo_perfil),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER trg_usuarios_updated_at BEFORE UPDATE ON usuarios
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de tentativas de login
+CREATE TABLE IF NOT EXISTS login_attempts (
+	id SERIAL PRIMARY KEY,
+	login VARCHAR(50) NOT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	success BOOLEAN NOT NULL,
+	attempt_time TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login ON login_attempts (login);
+CREATE INDEX IF NOT EXISTS idx_ip_address ON login_attempts (ip_address);
+CREATE INDEX IF NOT EXISTS idx_attempt_time ON login_attempts (attempt_time);
+
+-- Tabela de auditoria
+CREATE TABLE IF NOT EXISTS audit_log (
+	id SERIAL PRIMARY KEY,
+	user_id INT NULL,
+	username VARCHAR(50) NULL,
+	action VARCHAR(100) NOT NULL,
+	entity_type VARCHAR(50) NOT NULL,
+	entity_id VARCHAR(50) NULL,
+	details TEXT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_id ON audit_log (user_id);
+CREATE INDEX IF NOT EXISTS idx_action ON audit_log (action);
+CREATE INDEX IF NOT EXISTS idx_entity_type ON audit_log (entity_type);
+CREATE INDEX IF NOT EXISTS idx_created_at ON audit_log (created_at);
+
+-- Tabela de eventos
+CREATE TABLE IF NOT EXISTS eventos (
+	idCodigoEvento SERIAL PRIMARY KEY,
+	Evento INT NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER trg_eventos_updated_at BEFORE UPDATE ON eventos
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de objeto contabilização
+CREATE TABLE IF NOT EXISTS objeto_contabilizacao (
+	idObjetoContabilizacao SERIAL PRIMARY KEY,
+	ObjetoContabilizacao VARCHAR(100) NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER trg_objeto_contabilizacao_updated_at BEFORE UPDATE ON objeto_contabilizacao
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de objeto contabilização evento
+CREATE TABLE IF NOT EXISTS objeto_contabilizacao_evento (
+	idObjetoContabilizacaoEvento SERIAL PRIMARY KEY,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER trg_objeto_contabilizacao_evento_updated_at BEFORE UPDATE ON objeto_contabilizacao_evento
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de sistema contábil
+CREATE TABLE IF NOT EXISTS sistema_contabil (
+	idSistemaContabil SERIAL PRIMARY KEY,
+	SistemaContabil VARCHAR(100) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER trg_sistema_contabil_updated_at BEFORE UPDATE ON sistema_contabil
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de configuração de sistema contábil
+CREATE TABLE IF NOT EXISTS sistema_contabil_config (
+	idSistemaContabilConfig SERIAL PRIMARY KEY,
+	idSistemaContabil INT NOT NULL,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER trg_sistema_contabil_config_updated_at BEFORE UPDATE ON sistema_contabil_config
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
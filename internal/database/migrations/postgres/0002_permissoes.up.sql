@@ -0,0 +1,24 @@
+-- Permissões por tipo de perfil
+
+-- Tabela de permissões
+CREATE TABLE IF NOT EXISTS permissoes (
+	id_permissao SERIAL PRIMARY KEY,
+	nome VARCHAR(100) NOT NULL UNIQUE,
+	descricao VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+CREATE TRIGGER trg_permissoes_updated_at BEFORE UPDATE ON permissoes
+	FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+-- Tabela de permissões por tipo de perfil
+CREATE TABLE IF NOT EXISTS tipo_perfil_permissao (
+	id_tipo_perfil INT NOT NULL,
+	id_permissao INT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id_tipo_perfil, id_permissao),
+	FOREIGN KEY (id_tipo_perfil) REFERENCES tipo_perfil(id_tipo_perfil),
+	FOREIGN KEY (id_permissao) REFERENCES permissoes(id_permissao)
+);
@@ -0,0 +1,5 @@
+-- Lançamentos contábeis gerados a partir das configurações de sistema contábil (reversão)
+
+DROP TABLE IF EXISTS lancamento_partidas;
+DROP TABLE IF EXISTS lancamentos;
+DROP TABLE IF EXISTS lotes_lancamento;
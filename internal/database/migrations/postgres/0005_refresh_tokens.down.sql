@@ -0,0 +1,3 @@
+-- Refresh tokens persistidos, agrupados em famílias (sessões) para rotação e revogação (reversão)
+
+DROP TABLE IF EXISTS refresh_tokens;
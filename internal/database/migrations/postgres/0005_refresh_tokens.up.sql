@@ -0,0 +1,20 @@
+-- Refresh tokens persistidos, agrupados em famílias (sessões) para rotação e revogação
+
+-- Tabela de refresh tokens
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	id_refresh_token SERIAL PRIMARY KEY,
+	jti VARCHAR(64) NOT NULL UNIQUE,
+	familia VARCHAR(64) NOT NULL,
+	id_usuario INT NOT NULL,
+	expires_at TIMESTAMPTZ NOT NULL,
+	rotated_at TIMESTAMPTZ NULL,
+	revoked_at TIMESTAMPTZ NULL,
+	motivo_revogacao VARCHAR(50) NULL,
+	ip_address VARCHAR(45) NOT NULL DEFAULT '',
+	user_agent VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (id_usuario) REFERENCES usuarios(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_familia ON refresh_tokens (familia);
+CREATE INDEX IF NOT EXISTS idx_refresh_token_usuario ON refresh_tokens (id_usuario);
@@ -0,0 +1,4 @@
+-- Contadores e bloqueios da limitação de taxa compartilhados entre instâncias (reversão)
+
+DROP TABLE IF EXISTS rate_limit_bloqueios;
+DROP TABLE IF EXISTS rate_limit_contadores;
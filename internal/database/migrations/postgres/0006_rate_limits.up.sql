@@ -0,0 +1,21 @@
+-- Contadores e bloqueios da limitação de taxa compartilhados entre instâncias
+-- (instantes em milissegundos Unix, calculados pela aplicação)
+
+-- Tabela de contadores por chave e janela
+CREATE TABLE IF NOT EXISTS rate_limit_contadores (
+	chave VARCHAR(191) NOT NULL,
+	inicio_janela BIGINT NOT NULL,
+	contador INT NOT NULL DEFAULT 0,
+	expira_em BIGINT NOT NULL,
+	PRIMARY KEY (chave, inicio_janela)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_contadores_expira ON rate_limit_contadores (expira_em);
+
+-- Tabela de bloqueios por chave
+CREATE TABLE IF NOT EXISTS rate_limit_bloqueios (
+	chave VARCHAR(191) NOT NULL PRIMARY KEY,
+	bloqueado_ate BIGINT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limit_bloqueios_ate ON rate_limit_bloqueios (bloqueado_ate);
@@ -0,0 +1,5 @@
+-- ID da requisição (X-Request-ID) no log de auditoria (reversão)
+
+DROP INDEX IF EXISTS idx_audit_log_request_id;
+
+ALTER TABLE audit_log DROP COLUMN request_id;
@@ -0,0 +1,6 @@
+-- ID da requisição (X-Request-ID) no log de auditoria, para rastrear uma chamada de ponta a ponta
+
+ALTER TABLE audit_log ADD COLUMN request_id VARCHAR(64) NULL;
+
+-- Índice para a consulta das entradas de uma requisição
+CREATE INDEX idx_audit_log_request_id ON audit_log (request_id);
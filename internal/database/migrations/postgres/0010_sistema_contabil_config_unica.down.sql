@@ -0,0 +1,3 @@
+-- Uma única configuração ativa por sistema contábil, objeto de contabilização e evento (reversão)
+
+DROP INDEX IF EXISTS uk_sistema_contabil_config_ativa;
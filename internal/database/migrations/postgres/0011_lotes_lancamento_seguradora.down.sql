@@ -0,0 +1,5 @@
+-- Seguradora dos lotes de lançamentos (reversão)
+
+DROP INDEX IF EXISTS idx_lote_seguradora;
+
+ALTER TABLE lotes_lancamento DROP COLUMN idSeguradora;
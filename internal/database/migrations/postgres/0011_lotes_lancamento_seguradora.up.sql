@@ -0,0 +1,21 @@
+-- Seguradora dos lotes de lançamentos, para restringir a consulta dos lotes à seguradora do usuário
+
+ALTER TABLE lotes_lancamento ADD COLUMN idSeguradora INT NULL;
+
+-- A seguradora de cada lote é a dos seus lançamentos
+UPDATE lotes_lancamento SET idSeguradora = (
+	SELECT MIN(l.idSeguradora) FROM lancamentos l WHERE l.id_lote = lotes_lancamento.id_lote
+);
+
+-- Lotes sem lançamentos (nenhuma transação aceita) são mantidos no histórico, com a seguradora do
+-- usuário que os enviou; a migração é interrompida antes se algum lote não tiver nenhuma das duas
+UPDATE lotes_lancamento SET idSeguradora = (
+	SELECT u.idSeguradora FROM usuarios u WHERE u.id = lotes_lancamento.id_usuario
+)
+WHERE idSeguradora IS NULL;
+
+ALTER TABLE lotes_lancamento
+	ALTER COLUMN idSeguradora SET NOT NULL,
+	ADD CONSTRAINT fk_lote_seguradora FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora);
+
+CREATE INDEX idx_lote_seguradora ON lotes_lancamento (idSeguradora);
@@ -0,0 +1,16 @@
+-- Entradas do log de auditoria gravadas nas transações de negócio, ainda não encadeadas.
+-- São encadeadas em audit_log após a confirmação da transação, sem bloquear o topo da cadeia nela.
+
+CREATE TABLE IF NOT EXISTS audit_log_pendente (
+	id SERIAL PRIMARY KEY,
+	user_id INT NULL,
+	username VARCHAR(50) NULL,
+	action VARCHAR(100) NOT NULL,
+	entity_type VARCHAR(50) NOT NULL,
+	entity_id VARCHAR(50) NULL,
+	details TEXT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	request_id VARCHAR(64) NULL,
+	changes TEXT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
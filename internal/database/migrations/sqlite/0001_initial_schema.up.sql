@@ -0,0 +1,185 @@
+-- Esquema inicial da aplicação
+-- (updated_at é atualizado por gatilhos, equivalentes ao ON UPDATE CURRENT_TIMESTAMP do MySQL)
+
+-- Tabela de tipos de perfil
+CREATE TABLE IF NOT EXISTS tipo_perfil (
+	id_tipo_perfil INTEGER PRIMARY KEY AUTOINCREMENT,
+	perfil VARCHAR(100) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_tipo_perfil_updated_at AFTER UPDATE ON tipo_perfil
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE tipo_perfil SET updated_at = CURRENT_TIMESTAMP WHERE id_tipo_perfil = NEW.id_tipo_perfil;
+END;
+
+-- Tabela de seguradoras
+CREATE TABLE IF NOT EXISTS seguradoras (
+	id_seguradora INTEGER PRIMARY KEY AUTOINCREMENT,
+	seguradora VARCHAR(100) NOT NULL,
+	nome_abreviado VARCHAR(50),
+	codigo_susep VARCHAR(20),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_seguradoras_updated_at AFTER UPDATE ON seguradoras
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE seguradoras SET updated_at = CURRENT_TIMESTAMP WHERE id_seguradora = NEW.id_seguradora;
+END;
+
+-- Tabela de usuários
+CREATE TABLE IF NOT EXISTS usuarios (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	nome VARCHAR(100) NOT NULL,
+	email VARCHAR(100) NOT NULL UNIQUE,
+	login VARCHAR(50) NOT NULL UNIQUE,
+	senha VARCHAR(255) NOT NULL,
+	idTipoPerfil INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	AdminERP BOOLEAN DEFAULT FALSE,
+	bloqueado BOOLEAN DEFAULT FALSE,
+	bloqueado_ate DATETIME NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idTipoPerfil) REFERENCES tipo_perfil(id_tipo_perfil),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_usuarios_updated_at AFTER UPDATE ON usuarios
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE usuarios SET updated_at = CURRENT_TIMESTAMP WHERE id = NEW.id;
+END;
+
+-- Tabela de tentativas de login
+CREATE TABLE IF NOT EXISTS login_attempts (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	login VARCHAR(50) NOT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	success BOOLEAN NOT NULL,
+	attempt_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login ON login_attempts (login);
+CREATE INDEX IF NOT EXISTS idx_ip_address ON login_attempts (ip_address);
+CREATE INDEX IF NOT EXISTS idx_attempt_time ON login_attempts (attempt_time);
+
+-- Tabela de auditoria
+CREATE TABLE IF NOT EXISTS audit_log (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INT NULL,
+	username VARCHAR(50) NULL,
+	action VARCHAR(100) NOT NULL,
+	entity_type VARCHAR(50) NOT NULL,
+	entity_id VARCHAR(50) NULL,
+	details TEXT NULL,
+	ip_address VARCHAR(45) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_id ON audit_log (user_id);
+CREATE INDEX IF NOT EXISTS idx_action ON audit_log (action);
+CREATE INDEX IF NOT EXISTS idx_entity_type ON audit_log (entity_type);
+CREATE INDEX IF NOT EXISTS idx_created_at ON audit_log (created_at);
+
+-- Tabela de eventos
+CREATE TABLE IF NOT EXISTS eventos (
+	idCodigoEvento INTEGER PRIMARY KEY AUTOINCREMENT,
+	Evento INT NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_eventos_updated_at AFTER UPDATE ON eventos
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE eventos SET updated_at = CURRENT_TIMESTAMP WHERE idCodigoEvento = NEW.idCodigoEvento;
+END;
+
+-- Tabela de objeto contabilização
+CREATE TABLE IF NOT EXISTS objeto_contabilizacao (
+	idObjetoContabilizacao INTEGER PRIMARY KEY AUTOINCREMENT,
+	ObjetoContabilizacao VARCHAR(100) NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_objeto_contabilizacao_updated_at AFTER UPDATE ON objeto_contabilizacao
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE objeto_contabilizacao SET updated_at = CURRENT_TIMESTAMP WHERE idObjetoContabilizacao = NEW.idObjetoContabilizacao;
+END;
+
+-- Tabela de objeto contabilização evento
+CREATE TABLE IF NOT EXISTS objeto_contabilizacao_evento (
+	idObjetoContabilizacaoEvento INTEGER PRIMARY KEY AUTOINCREMENT,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_objeto_contabilizacao_evento_updated_at AFTER UPDATE ON objeto_contabilizacao_evento
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE objeto_contabilizacao_evento SET updated_at = CURRENT_TIMESTAMP WHERE idObjetoContabilizacaoEvento = NEW.idObjetoContabilizacaoEvento;
+END;
+
+-- Tabela de sistema contábil
+CREATE TABLE IF NOT EXISTS sistema_contabil (
+	idSistemaContabil INTEGER PRIMARY KEY AUTOINCREMENT,
+	SistemaContabil VARCHAR(100) NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_sistema_contabil_updated_at AFTER UPDATE ON sistema_contabil
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE sistema_contabil SET updated_at = CURRENT_TIMESTAMP WHERE idSistemaContabil = NEW.idSistemaContabil;
+END;
+
+-- Tabela de configuração de sistema contábil
+CREATE TABLE IF NOT EXISTS sistema_contabil_config (
+	idSistemaContabilConfig INTEGER PRIMARY KEY AUTOINCREMENT,
+	idSistemaContabil INT NOT NULL,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_sistema_contabil_config_updated_at AFTER UPDATE ON sistema_contabil_config
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE sistema_contabil_config SET updated_at = CURRENT_TIMESTAMP WHERE idSistemaContabilConfig = NEW.idSistemaContabilConfig;
+END;
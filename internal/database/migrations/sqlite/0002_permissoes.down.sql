@@ -0,0 +1,4 @@
+-- Permissões por tipo de perfil (reversão)
+
+DROP TABLE IF EXISTS tipo_perfil_permissao;
+DROP TABLE IF EXISTS permissoes;
@@ -0,0 +1,27 @@
+-- Permissões por tipo de perfil
+
+-- Tabela de permissões
+CREATE TABLE IF NOT EXISTS permissoes (
+	id_permissao INTEGER PRIMARY KEY AUTOINCREMENT,
+	nome VARCHAR(100) NOT NULL UNIQUE,
+	descricao VARCHAR(255) NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_permissoes_updated_at AFTER UPDATE ON permissoes
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE permissoes SET updated_at = CURRENT_TIMESTAMP WHERE id_permissao = NEW.id_permissao;
+END;
+
+-- Tabela de permissões por tipo de perfil
+CREATE TABLE IF NOT EXISTS tipo_perfil_permissao (
+	id_tipo_perfil INT NOT NULL,
+	id_permissao INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id_tipo_perfil, id_permissao),
+	FOREIGN KEY (id_tipo_perfil) REFERENCES tipo_perfil(id_tipo_perfil),
+	FOREIGN KEY (id_permissao) REFERENCES permissoes(id_permissao)
+);
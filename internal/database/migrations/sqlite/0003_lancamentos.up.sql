@@ -0,0 +1,48 @@
+-- Lançamentos contábeis gerados a partir das configurações de sistema contábil
+
+-- Tabela de lotes de lançamentos
+CREATE TABLE IF NOT EXISTS lotes_lancamento (
+	id_lote INTEGER PRIMARY KEY AUTOINCREMENT,
+	id_usuario INT NULL,
+	total_transacoes INT NOT NULL DEFAULT 0,
+	total_aceitas INT NOT NULL DEFAULT 0,
+	total_rejeitadas INT NOT NULL DEFAULT 0,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_lote_usuario ON lotes_lancamento (id_usuario);
+
+-- Tabela de lançamentos (um por transação e sistema contábil de destino)
+CREATE TABLE IF NOT EXISTS lancamentos (
+	id_lancamento INTEGER PRIMARY KEY AUTOINCREMENT,
+	id_lote INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	idSistemaContabil INT NOT NULL,
+	idSistemaContabilConfig INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idObjetoContabilizacao INT NOT NULL,
+	data_movimento DATE NOT NULL,
+	valor DECIMAL(18,2) NOT NULL,
+	documento_referencia VARCHAR(100) NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (id_lote) REFERENCES lotes_lancamento(id_lote),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora),
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idSistemaContabilConfig) REFERENCES sistema_contabil_config(idSistemaContabilConfig),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao)
+);
+
+CREATE INDEX IF NOT EXISTS idx_lancamento_lote ON lancamentos (id_lote);
+CREATE INDEX IF NOT EXISTS idx_lancamento_documento ON lancamentos (documento_referencia);
+
+-- Tabela de partidas (débito e crédito) de cada lançamento
+CREATE TABLE IF NOT EXISTS lancamento_partidas (
+	id_partida INTEGER PRIMARY KEY AUTOINCREMENT,
+	id_lancamento INT NOT NULL,
+	natureza CHAR(1) NOT NULL,
+	valor DECIMAL(18,2) NOT NULL,
+	FOREIGN KEY (id_lancamento) REFERENCES lancamentos(id_lancamento)
+);
+
+CREATE INDEX IF NOT EXISTS idx_partida_lancamento ON lancamento_partidas (id_lancamento);
@@ -0,0 +1,52 @@
+-- Plano de contas por seguradora e sistema contábil, com as contas de débito e crédito das configurações (reversão)
+-- O SQLite não remove colunas usadas em chaves estrangeiras: as tabelas são recriadas sem elas, com a
+-- verificação das chaves estrangeiras desativada durante a troca.
+
+PRAGMA foreign_keys = OFF;
+
+CREATE TABLE lancamento_partidas_nova (
+	id_partida INTEGER PRIMARY KEY AUTOINCREMENT,
+	id_lancamento INT NOT NULL,
+	natureza CHAR(1) NOT NULL,
+	valor DECIMAL(18,2) NOT NULL,
+	FOREIGN KEY (id_lancamento) REFERENCES lancamentos(id_lancamento)
+);
+
+INSERT INTO lancamento_partidas_nova (id_partida, id_lancamento, natureza, valor)
+	SELECT id_partida, id_lancamento, natureza, valor FROM lancamento_partidas;
+
+DROP TABLE lancamento_partidas;
+ALTER TABLE lancamento_partidas_nova RENAME TO lancamento_partidas;
+
+CREATE INDEX IF NOT EXISTS idx_partida_lancamento ON lancamento_partidas (id_lancamento);
+
+CREATE TABLE sistema_contabil_config_nova (
+	idSistemaContabilConfig INTEGER PRIMARY KEY AUTOINCREMENT,
+	idSistemaContabil INT NOT NULL,
+	idObjetoContabilizacao INT NOT NULL,
+	idCodigoEvento INT NOT NULL,
+	idSeguradora INT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idObjetoContabilizacao) REFERENCES objeto_contabilizacao(idObjetoContabilizacao),
+	FOREIGN KEY (idCodigoEvento) REFERENCES eventos(idCodigoEvento),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora)
+);
+
+INSERT INTO sistema_contabil_config_nova (idSistemaContabilConfig, idSistemaContabil, idObjetoContabilizacao, idCodigoEvento, idSeguradora, created_at, updated_at, ativo)
+	SELECT idSistemaContabilConfig, idSistemaContabil, idObjetoContabilizacao, idCodigoEvento, idSeguradora, created_at, updated_at, ativo FROM sistema_contabil_config;
+
+DROP TABLE sistema_contabil_config;
+ALTER TABLE sistema_contabil_config_nova RENAME TO sistema_contabil_config;
+
+CREATE TRIGGER IF NOT EXISTS trg_sistema_contabil_config_updated_at AFTER UPDATE ON sistema_contabil_config
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE sistema_contabil_config SET updated_at = CURRENT_TIMESTAMP WHERE idSistemaContabilConfig = NEW.idSistemaContabilConfig;
+END;
+
+PRAGMA foreign_keys = ON;
+
+DROP TABLE IF EXISTS plano_contas;
@@ -0,0 +1,33 @@
+-- Plano de contas por seguradora e sistema contábil, com as contas de débito e crédito das configurações
+
+-- Tabela do plano de contas
+CREATE TABLE IF NOT EXISTS plano_contas (
+	idConta INTEGER PRIMARY KEY AUTOINCREMENT,
+	idSeguradora INT NOT NULL,
+	idSistemaContabil INT NOT NULL,
+	Codigo VARCHAR(50) NOT NULL,
+	Descricao VARCHAR(255) NOT NULL,
+	Natureza CHAR(1) NOT NULL,
+	Tipo CHAR(1) NOT NULL,
+	idContaPai INT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	ativo BOOLEAN DEFAULT TRUE,
+	CONSTRAINT uk_plano_contas_codigo UNIQUE (idSeguradora, idSistemaContabil, Codigo),
+	FOREIGN KEY (idSeguradora) REFERENCES seguradoras(id_seguradora),
+	FOREIGN KEY (idSistemaContabil) REFERENCES sistema_contabil(idSistemaContabil),
+	FOREIGN KEY (idContaPai) REFERENCES plano_contas(idConta)
+);
+
+CREATE TRIGGER IF NOT EXISTS trg_plano_contas_updated_at AFTER UPDATE ON plano_contas
+	FOR EACH ROW WHEN NEW.updated_at IS OLD.updated_at
+BEGIN
+	UPDATE plano_contas SET updated_at = CURRENT_TIMESTAMP WHERE idConta = NEW.idConta;
+END;
+
+-- Contas de débito e crédito das configurações de sistema contábil
+ALTER TABLE sistema_contabil_config ADD COLUMN idContaDebito INT NULL CONSTRAINT fk_scc_conta_debito REFERENCES plano_contas(idConta);
+ALTER TABLE sistema_contabil_config ADD COLUMN idContaCredito INT NULL CONSTRAINT fk_scc_conta_credito REFERENCES plano_contas(idConta);
+
+-- Conta de cada partida dos lançamentos
+ALTER TABLE lancamento_partidas ADD COLUMN id_conta INT NULL CONSTRAINT fk_partida_conta REFERENCES plano_contas(idConta);
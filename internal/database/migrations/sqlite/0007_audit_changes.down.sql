@@ -0,0 +1,5 @@
+-- Alterações estruturadas (valor anterior e novo de cada campo) no log de auditoria (reversão)
+
+DROP INDEX IF EXISTS idx_audit_log_entity;
+
+ALTER TABLE audit_log DROP COLUMN changes;
@@ -0,0 +1,7 @@
+-- Encadeamento por hash do log de auditoria e pontos de verificação assinados (reversão)
+
+DROP TABLE IF EXISTS audit_checkpoints;
+DROP TABLE IF EXISTS audit_chain_head;
+
+ALTER TABLE audit_log DROP COLUMN hash;
+ALTER TABLE audit_log DROP COLUMN prev_hash;
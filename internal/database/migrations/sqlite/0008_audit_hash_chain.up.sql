@@ -0,0 +1,27 @@
+-- Encadeamento por hash do log de auditoria (evidência de adulteração) e pontos de verificação assinados
+
+-- Hash de cada entrada (conteúdo + hash da entrada anterior); registros anteriores ficam sem hash
+ALTER TABLE audit_log ADD COLUMN prev_hash CHAR(64) NULL;
+ALTER TABLE audit_log ADD COLUMN hash CHAR(64) NULL;
+
+-- Última entrada encadeada (linha única, bloqueada durante a inclusão de cada entrada)
+CREATE TABLE IF NOT EXISTS audit_chain_head (
+	id TINYINT NOT NULL PRIMARY KEY,
+	last_id INT NOT NULL DEFAULT 0,
+	last_hash CHAR(64) NOT NULL DEFAULT ''
+);
+
+INSERT INTO audit_chain_head (id, last_id, last_hash) VALUES (1, 0, '');
+
+-- Pontos de verificação periódicos, assinados com a chave ativa dos tokens JWT
+CREATE TABLE IF NOT EXISTS audit_checkpoints (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	last_audit_id INT NOT NULL,
+	last_hash CHAR(64) NOT NULL,
+	entries BIGINT NOT NULL,
+	key_id VARCHAR(100) NOT NULL,
+	signature TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_checkpoints_last_audit_id ON audit_checkpoints (last_audit_id);
@@ -0,0 +1,9 @@
+-- Uma única configuração ativa por sistema contábil, objeto de contabilização e evento
+
+-- Configurações ativas duplicadas não são desativadas aqui: a migração é interrompida antes deste script,
+-- com a lista das duplicadas, que devem ser resolvidas manualmente (checkConfigsAtivasUnicas)
+
+-- Índice parcial: as configurações inativas não participam da chave única
+CREATE UNIQUE INDEX uk_sistema_contabil_config_ativa
+	ON sistema_contabil_config (idSistemaContabil, idObjetoContabilizacao, idCodigoEvento)
+	WHERE ativo = TRUE;
@@ -0,0 +1,19 @@
+-- Seguradora dos lotes de lançamentos, para restringir a consulta dos lotes à seguradora do usuário
+
+-- O SQLite não altera a nulidade de uma coluna existente e não remove colunas com chave estrangeira:
+-- a seguradora é sempre gravada pela aplicação, a partir da seguradora dos lançamentos
+ALTER TABLE lotes_lancamento ADD COLUMN idSeguradora INT NULL;
+
+-- A seguradora de cada lote é a dos seus lançamentos
+UPDATE lotes_lancamento SET idSeguradora = (
+	SELECT MIN(l.idSeguradora) FROM lancamentos l WHERE l.id_lote = lotes_lancamento.id_lote
+);
+
+-- Lotes sem lançamentos (nenhuma transação aceita) são mantidos no histórico, com a seguradora do
+-- usuário que os enviou; a migração é interrompida antes se algum lote não tiver nenhuma das duas
+UPDATE lotes_lancamento SET idSeguradora = (
+	SELECT u.idSeguradora FROM usuarios u WHERE u.id = lotes_lancamento.id_usuario
+)
+WHERE idSeguradora IS NULL;
+
+CREATE INDEX idx_lote_seguradora ON lotes_lancamento (idSeguradora);
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// newSQLiteDB cria um banco SQLite temporário, sem migrações aplicadas
+func newSQLiteDB(t *testing.T, params string) *sql.DB {
+	t.Helper()
+
+	dialect.Set(dialect.SQLite)
+	db, err := Connect(dialect.SQLite, "file:"+filepath.Join(t.TempDir(), "teste.db")+params)
+	if err != nil {
+		t.Fatalf("erro ao conectar ao SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMigracaoConfigsAtivasDuplicadas(t *testing.T) {
+	// Sem chaves estrangeiras: as configurações são gravadas sem os registros referenciados
+	db := newSQLiteDB(t, "")
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("erro ao carregar migrações: %v", err)
+	}
+	if _, err := migrator.To(9); err != nil {
+		t.Fatalf("erro ao migrar até a versão 9: %v", err)
+	}
+
+	configs := []struct {
+		sistema, objeto, evento int
+		ativo                   bool
+	}{
+		{1, 1, 1, true},
+		{1, 1, 1, true},
+		{1, 1, 1, false},
+		{1, 1, 2, true},
+		{2, 1, 1, true},
+		{2, 1, 1, true},
+	}
+	for _, c := range configs {
+		_, err := db.Exec(
+			"INSERT INTO sistema_contabil_config (idSistemaContabil, idObjetoContabilizacao, idCodigoEvento, idSeguradora, ativo) VALUES (?, ?, ?, 1, ?)",
+			c.sistema, c.objeto, c.evento, c.ativo,
+		)
+		if err != nil {
+			t.Fatalf("erro ao gravar configuração: %v", err)
+		}
+	}
+
+	// A migração é interrompida com a lista das duplicadas, sem desativar nenhuma
+	_, err = migrator.Up()
+	if err == nil {
+		t.Fatal("migração aplicada com configurações ativas duplicadas")
+	}
+	for _, trecho := range []string{
+		"sistema contábil 1, objeto de contabilização 1, evento 1 (configurações 1, 2)",
+		"sistema contábil 2, objeto de contabilização 1, evento 1 (configurações 5, 6)",
+	} {
+		if !strings.Contains(err.Error(), trecho) {
+			t.Errorf("erro %q não lista %q", err, trecho)
+		}
+	}
+	var ativas int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sistema_contabil_config WHERE ativo = TRUE").Scan(&ativas); err != nil {
+		t.Fatalf("erro ao contar configurações: %v", err)
+	}
+	if ativas != 5 {
+		t.Errorf("%d configurações ativas após a falha, esperado 5", ativas)
+	}
+
+	// Resolvidas as duplicadas, a migração é aplicada
+	if _, err := db.Exec("UPDATE sistema_contabil_config SET ativo = FALSE WHERE idSistemaContabilConfig IN (2, 6)"); err != nil {
+		t.Fatalf("erro ao desativar configurações: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("erro ao aplicar migrações: %v", err)
+	}
+}
+
+func TestMigracaoLotesSemLancamentos(t *testing.T) {
+	// Sem chaves estrangeiras: o usuário é gravado sem o tipo de perfil e a seguradora
+	db := newSQLiteDB(t, "")
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("erro ao carregar migrações: %v", err)
+	}
+	if _, err := migrator.To(10); err != nil {
+		t.Fatalf("erro ao migrar até a versão 10: %v", err)
+	}
+
+	for _, query := range []string{
+		"INSERT INTO usuarios (id, nome, email, login, senha, idTipoPerfil, idSeguradora) VALUES (1, 'Maria', 'maria@exemplo.com.br', 'maria', 'x', 1, 7)",
+		"INSERT INTO lotes_lancamento (id_lote, id_usuario, total_transacoes, total_rejeitadas) VALUES (1, 1, 2, 2)",
+		"INSERT INTO lotes_lancamento (id_lote, id_usuario, total_transacoes, total_rejeitadas) VALUES (2, NULL, 1, 1)",
+	} {
+		if _, err := db.Exec(query); err != nil {
+			t.Fatalf("erro ao gravar %q: %v", query, err)
+		}
+	}
+
+	// A migração é interrompida com a lista dos lotes sem seguradora, sem excluir nenhum
+	_, err = migrator.Up()
+	if err == nil || !strings.Contains(err.Error(), "lotes 2") {
+		t.Fatalf("erro = %v, esperada a lista dos lotes sem seguradora", err)
+	}
+
+	// Associado o lote a um usuário, a migração mantém os lotes com a seguradora do usuário
+	if _, err := db.Exec("UPDATE lotes_lancamento SET id_usuario = 1 WHERE id_lote = 2"); err != nil {
+		t.Fatalf("erro ao associar lote: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("erro ao aplicar migrações: %v", err)
+	}
+	var lotes, comSeguradora int
+	if err := db.QueryRow("SELECT COUNT(*), SUM(CASE WHEN idSeguradora = 7 THEN 1 ELSE 0 END) FROM lotes_lancamento").Scan(&lotes, &comSeguradora); err != nil {
+		t.Fatalf("erro ao contar lotes: %v", err)
+	}
+	if lotes != 2 || comSeguradora != 2 {
+		t.Errorf("%d lotes, %d com a seguradora do usuário, esperados 2 e 2", lotes, comSeguradora)
+	}
+}
+
+func TestMigracoesIdaEVolta(t *testing.T) {
+	db := newSQLiteDB(t, "?_foreign_keys=on")
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("erro ao carregar migrações: %v", err)
+	}
+
+	applied, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("erro ao aplicar migrações: %v", err)
+	}
+	if len(applied) != migrator.LatestVersion() {
+		t.Errorf("%d migrações aplicadas, esperadas %d", len(applied), migrator.LatestVersion())
+	}
+
+	// Sem migrações pendentes, Up não altera nada
+	if applied, err := migrator.Up(); err != nil || len(applied) != 0 {
+		t.Errorf("segunda execução de Up = %v, %v, esperado nenhuma migração", applied, err)
+	}
+
+	// Down reverte somente a última migração
+	reverted, err := migrator.Down()
+	if err != nil {
+		t.Fatalf("erro ao reverter migração: %v", err)
+	}
+	if len(reverted) != 1 || reverted[0] != migrator.LatestVersion() {
+		t.Errorf("Down reverteu %v, esperado [%d]", reverted, migrator.LatestVersion())
+	}
+
+	// Todas as migrações podem ser revertidas e aplicadas novamente
+	if _, err := migrator.To(0); err != nil {
+		t.Fatalf("erro ao reverter todas as migrações: %v", err)
+	}
+	var tabelas int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name NOT IN ('schema_migrations', 'sqlite_sequence')").Scan(&tabelas); err != nil {
+		t.Fatalf("erro ao contar tabelas: %v", err)
+	}
+	if tabelas != 0 {
+		t.Errorf("%d tabelas após reverter todas as migrações, esperado 0", tabelas)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("erro ao aplicar migrações novamente: %v", err)
+	}
+
+	// Versão inexistente
+	if _, err := migrator.To(migrator.LatestVersion() + 1); err == nil {
+		t.Error("migração para versão inexistente aceita")
+	}
+}
+
+func TestMigracaoInterrompidaNaoFicaPelaMetade(t *testing.T) {
+	db := newSQLiteDB(t, "?_foreign_keys=on")
+	db.SetMaxOpenConns(1)
+
+	// A migração 2 falha na última instrução ou deixa uma chave estrangeira sem o registro referenciado
+	for _, tc := range []struct {
+		script string
+		erro   string
+	}{
+		{"CREATE TABLE partidas (id INT, id_conta INT REFERENCES contas(id));\nINSERT INTO inexistente VALUES (1);", "no such table: inexistente"},
+		{"CREATE TABLE partidas (id INT, id_conta INT REFERENCES contas(id));\nINSERT INTO partidas VALUES (1, 99);", "partidas (referência a contas)"},
+	} {
+		migrations, err := loadMigrations(fstest.MapFS{
+			"m/0001_contas.up.sql":     {Data: []byte("CREATE TABLE contas (id INT PRIMARY KEY);")},
+			"m/0001_contas.down.sql":   {Data: []byte("DROP TABLE contas;")},
+			"m/0002_partidas.up.sql":   {Data: []byte(tc.script)},
+			"m/0002_partidas.down.sql": {Data: []byte("DROP TABLE partidas;")},
+		}, "m")
+		if err != nil {
+			t.Fatalf("erro ao carregar migrações: %v", err)
+		}
+		migrator := &Migrator{db: db, migrations: migrations}
+
+		if _, err := migrator.Up(); err == nil || !strings.Contains(err.Error(), tc.erro) {
+			t.Errorf("erro %v, esperado %q", err, tc.erro)
+		}
+		if current, pending, err := migrator.Pending(context.Background()); err != nil || current != 1 || len(pending) != 1 {
+			t.Errorf("Pending = %d, %v, %v, esperado 1, [2]", current, pending, err)
+		}
+	}
+
+	// Nenhuma instrução da migração interrompida permanece
+	var tabelas int
+	if err := db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'partidas'").Scan(&tabelas); err != nil {
+		t.Fatalf("erro ao contar tabelas: %v", err)
+	}
+	if tabelas != 0 {
+		t.Error("tabela da migração interrompida mantida")
+	}
+
+	// As chaves estrangeiras voltam a ser verificadas na conexão
+	var foreignKeys bool
+	if err := db.QueryRow("PRAGMA foreign_keys").Scan(&foreignKeys); err != nil || !foreignKeys {
+		t.Errorf("chaves estrangeiras ativas = %v, %v, esperado true", foreignKeys, err)
+	}
+}
+
+func TestMigracaoAlteradaDepoisDeAplicada(t *testing.T) {
+	db := newSQLiteDB(t, "?_foreign_keys=on")
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("erro ao carregar migrações: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("erro ao aplicar migrações: %v", err)
+	}
+
+	// Simular a alteração do script da migração 1 depois de aplicada
+	if _, err := db.Exec("UPDATE schema_migrations SET checksum = 'alterado' WHERE version = 1"); err != nil {
+		t.Fatalf("erro ao alterar checksum: %v", err)
+	}
+
+	if _, err := migrator.Up(); err == nil || !strings.Contains(err.Error(), "checksum da migração 0001") {
+		t.Errorf("Up com migração alterada: %v, esperado erro de checksum", err)
+	}
+	if _, err := migrator.Down(); err == nil {
+		t.Error("Down com migração alterada aceito")
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		t.Fatalf("erro ao consultar situação: %v", err)
+	}
+	for _, s := range status {
+		if !s.Applied || s.Modified != (s.Version == 1) {
+			t.Errorf("situação da migração %04d_%s: aplicada %v, alterada %v", s.Version, s.Name, s.Applied, s.Modified)
+		}
+	}
+
+	current, pending, err := migrator.Pending(context.Background())
+	if err != nil {
+		t.Fatalf("erro ao consultar pendentes: %v", err)
+	}
+	if current != migrator.LatestVersion() || len(pending) != 1 || pending[0] != 1 {
+		t.Errorf("Pending = %d, %v, esperado %d, [1]", current, pending, migrator.LatestVersion())
+	}
+}
+
+func TestMigracoesDosBancos(t *testing.T) {
+	// Todos os bancos possuem as mesmas versões, em sequência
+	versoes := make(map[dialect.Dialect][]string)
+	for _, d := range []dialect.Dialect{dialect.MySQL, dialect.Postgres, dialect.SQLite} {
+		migrations, err := loadMigrations(migrationFiles, "migrations/"+string(d))
+		if err != nil {
+			t.Fatalf("%s: erro ao carregar migrações: %v", d, err)
+		}
+		for i, m := range migrations {
+			if m.Version != i+1 {
+				t.Errorf("%s: migração %04d_%s fora de sequência", d, m.Version, m.Name)
+			}
+			versoes[d] = append(versoes[d], m.Name)
+		}
+	}
+
+	sqlite := strings.Join(versoes[dialect.SQLite], ",")
+	for _, d := range []dialect.Dialect{dialect.MySQL, dialect.Postgres} {
+		if got := strings.Join(versoes[d], ","); got != sqlite {
+			t.Errorf("migrações de %s (%s) diferem das do SQLite (%s)", d, got, sqlite)
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- Comentário; ignorado
+CREATE TABLE a (id INT, nome VARCHAR(10) DEFAULT 'x;y');
+CREATE FUNCTION f() RETURNS trigger AS $$
+BEGIN
+	NEW.updated_at = NOW();
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER t AFTER UPDATE ON a
+BEGIN
+	UPDATE a SET nome = 'z' WHERE id = NEW.id;
+	DELETE FROM a WHERE id = 0;
+END;
+INSERT INTO a VALUES (1, "a;b")`
+
+	statements := splitStatements(script)
+	if len(statements) != 4 {
+		t.Fatalf("%d instruções, esperadas 4: %q", len(statements), statements)
+	}
+	for i, prefix := range []string{"CREATE TABLE a", "CREATE FUNCTION f()", "CREATE TRIGGER t", "INSERT INTO a"} {
+		if !strings.HasPrefix(statements[i], prefix) {
+			t.Errorf("instrução %d = %q, esperado início %q", i, statements[i], prefix)
+		}
+	}
+	if !strings.HasSuffix(statements[2], "END") {
+		t.Errorf("corpo do gatilho separado: %q", statements[2])
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 
 	// Removendo a importação não utilizada
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -41,6 +42,11 @@ func SeedInitialData(db *sql.DB) error {
 		}
 	}
 
+	// Criar o catálogo de permissões e as permissões padrão dos perfis
+	if err := seedPermissions(db); err != nil {
+		return err
+	}
+
 	// Verificar se já existem seguradoras
 	err = db.QueryRow("SELECT COUNT(*) FROM seguradoras").Scan(&count)
 	if err != nil {
@@ -91,3 +97,57 @@ func SeedInitialData(db *sql.DB) error {
 
 	return nil
 }
+
+// seedPermissions cria as permissões padrão que ainda não existem e as concede aos perfis padrão.
+// Permissões já existentes não são alteradas, para não sobrescrever ajustes feitos pela API.
+func seedPermissions(db *sql.DB) error {
+	for _, recurso := range models.RecursosProtegidos {
+		// Leitura: concedida ao Administrador e ao Usuário (exceto usuários, perfis, auditoria e status)
+		perfis := []string{"Administrador"}
+		if recurso != "usuarios" && recurso != "tipos-perfil" && recurso != "auditoria" && recurso != "status" {
+			perfis = append(perfis, "Usuário")
+		}
+		if err := seedPermission(db, models.PermissaoLeitura(recurso), "Consultar "+recurso, perfis); err != nil {
+			return err
+		}
+		
+		// Escrita: concedida apenas ao Administrador
+		if err := seedPermission(db, models.PermissaoEscrita(recurso), "Criar, alterar e excluir "+recurso, []string{"Administrador"}); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// seedPermission cria uma permissão, caso ainda não exista, concedendo-a aos perfis informados
+func seedPermission(db *sql.DB, nome, descricao string, perfis []string) error {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM permissoes WHERE nome = ?", nome).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("erro ao verificar permissão %s: %v", nome, err)
+	}
+	if count > 0 {
+		return nil
+	}
+	
+	log.Printf("Criando permissão %s...", nome)
+	
+	_, err = db.Exec("INSERT INTO permissoes (nome, descricao, ativo) VALUES (?, ?, ?)", nome, descricao, true)
+	if err != nil {
+		return fmt.Errorf("erro ao criar permissão %s: %v", nome, err)
+	}
+	
+	for _, perfil := range perfis {
+		_, err = db.Exec(`
+		INSERT INTO tipo_perfil_permissao (id_tipo_perfil, id_permissao)
+		SELECT tp.id_tipo_perfil, p.id_permissao
+		FROM tipo_perfil tp, permissoes p
+		WHERE tp.perfil = ? AND p.nome = ?`, perfil, nome)
+		if err != nil {
+			return fmt.Errorf("erro ao conceder permissão %s ao perfil %s: %v", nome, perfil, err)
+		}
+	}
+	
+	return nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// permissoesDoPerfil retorna as permissões concedidas ao perfil informado
+func permissoesDoPerfil(t *testing.T, db *sql.DB, perfil string) map[string]bool {
+	t.Helper()
+
+	rows, err := db.Query(`
+	SELECT p.nome
+	FROM permissoes p
+	JOIN tipo_perfil_permissao tpp ON tpp.id_permissao = p.id_permissao
+	JOIN tipo_perfil tp ON tp.id_tipo_perfil = tpp.id_tipo_perfil
+	WHERE tp.perfil = ?`, perfil)
+	if err != nil {
+		t.Fatalf("erro ao consultar permissões do perfil %s: %v", perfil, err)
+	}
+	defer rows.Close()
+
+	permissoes := make(map[string]bool)
+	for rows.Next() {
+		var nome string
+		if err := rows.Scan(&nome); err != nil {
+			t.Fatalf("erro ao ler permissão: %v", err)
+		}
+		permissoes[nome] = true
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("erro ao ler permissões: %v", err)
+	}
+	return permissoes
+}
+
+func TestSeedPermissoesPadrao(t *testing.T) {
+	db := newSQLiteDB(t, "?_foreign_keys=on")
+	if err := Migrate(db); err != nil {
+		t.Fatalf("erro ao aplicar as migrações: %v", err)
+	}
+
+	// A carga inicial pode ser executada novamente sem duplicar permissões
+	for i := 0; i < 2; i++ {
+		if err := SeedInitialData(db); err != nil {
+			t.Fatalf("erro na carga inicial: %v", err)
+		}
+	}
+
+	administrador := permissoesDoPerfil(t, db, "Administrador")
+	usuario := permissoesDoPerfil(t, db, "Usuário")
+	restritos := map[string]bool{"usuarios": true, "tipos-perfil": true, "auditoria": true, "status": true}
+
+	for _, recurso := range models.RecursosProtegidos {
+		leitura, escrita := models.PermissaoLeitura(recurso), models.PermissaoEscrita(recurso)
+		if !administrador[leitura] || !administrador[escrita] {
+			t.Errorf("Administrador sem %s ou %s", leitura, escrita)
+		}
+		if usuario[leitura] == restritos[recurso] {
+			t.Errorf("Usuário com %s = %v, esperado %v", leitura, usuario[leitura], !restritos[recurso])
+		}
+		if usuario[escrita] {
+			t.Errorf("Usuário com %s", escrita)
+		}
+	}
+
+	var total int
+	if err := db.QueryRow("SELECT COUNT(*) FROM permissoes").Scan(&total); err != nil {
+		t.Fatalf("erro ao contar permissões: %v", err)
+	}
+	if total != 2*len(models.RecursosProtegidos) {
+		t.Errorf("%d permissões, esperadas %d", total, 2*len(models.RecursosProtegidos))
+	}
+}
@@ -0,0 +1,192 @@
+package dialect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Dialect identifica o banco de dados da aplicação. As instruções SQL dos repositórios são escritas
+// com o marcador "?" e a sintaxe comum aos bancos suportados; as diferenças ficam nos métodos abaixo.
+type Dialect string
+
+// Bancos de dados suportados
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+	SQLite   Dialect = "sqlite"
+)
+
+// current é o dialeto do banco de dados configurado (MySQL por padrão)
+var current atomic.Value
+
+// Parse converte o nome do banco de dados no dialeto correspondente
+func Parse(name string) (Dialect, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "mysql":
+		return MySQL, nil
+	case "postgres", "postgresql":
+		return Postgres, nil
+	case "sqlite", "sqlite3":
+		return SQLite, nil
+	}
+	return "", fmt.Errorf("banco de dados não suportado: %s (use %s, %s ou %s)", name, MySQL, Postgres, SQLite)
+}
+
+// Set define o dialeto do banco de dados usado pelos repositórios
+func Set(d Dialect) {
+	current.Store(d)
+}
+
+// Current retorna o dialeto do banco de dados configurado
+func Current() Dialect {
+	if d, ok := current.Load().(Dialect); ok {
+		return d
+	}
+	return MySQL
+}
+
+// DriverName retorna o nome do driver registrado no pacote database/sql
+func (d Dialect) DriverName() string {
+	switch d {
+	case Postgres:
+		return "postgres"
+	case SQLite:
+		return "sqlite3"
+	}
+	return "mysql"
+}
+
+// Rebind converte os marcadores "?" da instrução para o formato do banco ($1, $2... no PostgreSQL),
+// ignorando os que estiverem dentro de textos entre aspas
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+
+	var quote rune
+	n := 0
+	for _, c := range query {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?':
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+
+	return b.String()
+}
+
+// MinutesAgo retorna a expressão da data e hora de n minutos atrás
+func (d Dialect) MinutesAgo(n int) string {
+	switch d {
+	case Postgres:
+		return fmt.Sprintf("NOW() - INTERVAL '%d minutes'", n)
+	case SQLite:
+		return fmt.Sprintf("datetime('now', '-%d minutes')", n)
+	}
+	return fmt.Sprintf("DATE_SUB(NOW(), INTERVAL %d MINUTE)", n)
+}
+
+// ForUpdate retorna a cláusula que bloqueia as linhas lidas até o fim da transação. O SQLite não
+// bloqueia linhas: as transações de escrita são serializadas (BEGIN IMMEDIATE).
+func (d Dialect) ForUpdate() string {
+	if d == SQLite {
+		return ""
+	}
+	return " FOR UPDATE"
+}
+
+// InsertIgnore adapta um INSERT para ignorar as linhas que violam uma chave única ou primária
+func (d Dialect) InsertIgnore(query string) string {
+	switch d {
+	case Postgres:
+		return query + " ON CONFLICT DO NOTHING"
+	case SQLite:
+		return strings.Replace(query, "INSERT INTO", "INSERT OR IGNORE INTO", 1)
+	}
+	return strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+}
+
+// Upsert retorna a cláusula que atualiza a linha existente quando o INSERT viola a chave informada
+// (colunas separadas por vírgula). As atribuições devem qualificar as colunas da linha existente com o
+// nome da tabela e usar Excluded para os valores do INSERT.
+func (d Dialect) Upsert(key, assignments string) string {
+	if d == MySQL {
+		return "ON DUPLICATE KEY UPDATE " + assignments
+	}
+	return "ON CONFLICT (" + key + ") DO UPDATE SET " + assignments
+}
+
+// Excluded retorna a referência ao valor da coluna informado no INSERT, nas atribuições de Upsert
+func (d Dialect) Excluded(column string) string {
+	if d == MySQL {
+		return "VALUES(" + column + ")"
+	}
+	return "excluded." + column
+}
+
+// Greatest retorna a expressão do maior entre os valores informados
+func (d Dialect) Greatest(values ...string) string {
+	if d == SQLite {
+		return "MAX(" + strings.Join(values, ", ") + ")"
+	}
+	return "GREATEST(" + strings.Join(values, ", ") + ")"
+}
+
+// Contains retorna a condição de busca por parte do texto da coluna (LIKE com o marcador "?"),
+// sem diferenciar maiúsculas de minúsculas e com "\" como caractere de escape
+func (d Dialect) Contains(column string) string {
+	switch d {
+	case Postgres:
+		return column + " ILIKE ?"
+	case SQLite:
+		return column + ` LIKE ? ESCAPE '\'`
+	}
+	return column + " LIKE ?"
+}
+
+// DeleteLimit retorna um DELETE que remove no máximo o número de linhas do último marcador "?"
+func (d Dialect) DeleteLimit(table, where string) string {
+	switch d {
+	case Postgres:
+		return fmt.Sprintf("DELETE FROM %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT ?)", table, table, where)
+	case SQLite:
+		return fmt.Sprintf("DELETE FROM %s WHERE rowid IN (SELECT rowid FROM %s WHERE %s LIMIT ?)", table, table, where)
+	}
+	return fmt.Sprintf("DELETE FROM %s WHERE %s LIMIT ?", table, where)
+}
+
+// Returning retorna a cláusula que devolve a coluna gerada pelo INSERT, nos bancos cujo driver não
+// informa o último ID inserido (PostgreSQL). Nos demais, retorna vazio e o ID é obtido do resultado.
+func (d Dialect) Returning(column string) string {
+	if d == Postgres {
+		return " RETURNING " + column
+	}
+	return ""
+}
+
+// NullsFirst retorna o complemento do ORDER BY que ordena os valores nulos antes dos demais
+// (depois, na ordem decrescente), como o MySQL e o SQLite fazem por padrão
+func (d Dialect) NullsFirst(desc bool) string {
+	if d != Postgres {
+		return ""
+	}
+	if desc {
+		return " NULLS LAST"
+	}
+	return " NULLS FIRST"
+}
@@ -0,0 +1,80 @@
+package dialect
+
+import "testing"
+
+func TestRebind(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{
+			"marcadores numerados em ordem",
+			Postgres,
+			"SELECT * FROM eventos WHERE idSeguradora = ? AND Evento = ? LIMIT ?",
+			"SELECT * FROM eventos WHERE idSeguradora = $1 AND Evento = $2 LIMIT $3",
+		},
+		{
+			"marcadores dentro de textos preservados",
+			Postgres,
+			`UPDATE eventos SET Descricao = 'O que?' WHERE "coluna?" = ? AND idCodigoEvento = ?`,
+			`UPDATE eventos SET Descricao = 'O que?' WHERE "coluna?" = $1 AND idCodigoEvento = $2`,
+		},
+		{
+			"aspas duplicadas dentro do texto",
+			Postgres,
+			"SELECT 'it''s ?', ?",
+			"SELECT 'it''s ?', $1",
+		},
+		{
+			"mais de nove marcadores",
+			Postgres,
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)",
+		},
+		{
+			"texto com caracteres não ASCII",
+			Postgres,
+			"SELECT 'ação' WHERE descricao = ?",
+			"SELECT 'ação' WHERE descricao = $1",
+		},
+		{
+			"instrução sem marcadores",
+			Postgres,
+			"SELECT 1",
+			"SELECT 1",
+		},
+		{
+			"MySQL mantém os marcadores",
+			MySQL,
+			"SELECT * FROM eventos WHERE idCodigoEvento = ?",
+			"SELECT * FROM eventos WHERE idCodigoEvento = ?",
+		},
+		{
+			"SQLite mantém os marcadores",
+			SQLite,
+			"SELECT * FROM eventos WHERE idCodigoEvento = ?",
+			"SELECT * FROM eventos WHERE idCodigoEvento = ?",
+		},
+	} {
+		if got := tc.dialect.Rebind(tc.query); got != tc.want {
+			t.Errorf("%s: Rebind(%q) = %q, esperado %q", tc.name, tc.query, got, tc.want)
+		}
+	}
+}
+
+func TestRebindClausulasDoDialeto(t *testing.T) {
+	// As cláusulas geradas pelo dialeto também usam o marcador "?"
+	got := Postgres.Rebind("SELECT id FROM eventos WHERE " + Postgres.Contains("Descricao") + " AND ativo = ?")
+	want := "SELECT id FROM eventos WHERE Descricao ILIKE $1 AND ativo = $2"
+	if got != want {
+		t.Errorf("Contains = %q, esperado %q", got, want)
+	}
+
+	got = Postgres.Rebind(Postgres.DeleteLimit("login_attempts", "created_at < ?"))
+	want = "DELETE FROM login_attempts WHERE ctid IN (SELECT ctid FROM login_attempts WHERE created_at < $1 LIMIT $2)"
+	if got != want {
+		t.Errorf("DeleteLimit = %q, esperado %q", got, want)
+	}
+}
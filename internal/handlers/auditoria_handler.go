@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// AuditoriaHandler gerencia as consultas ao log de auditoria e às tentativas de login.
+// O acesso é restrito a administradores do ERP, pois os registros abrangem todas as seguradoras.
+type AuditoriaHandler struct {
+	repo         models.AuditLogStore
+	auditService *services.AuditService
+}
+
+// NewAuditoriaHandler cria um novo handler do log de auditoria
+func NewAuditoriaHandler(repo models.AuditLogStore, auditService *services.AuditService) *AuditoriaHandler {
+	return &AuditoriaHandler{
+		repo:         repo,
+		auditService: auditService,
+	}
+}
+
+// HandleAuditoria gerencia todas as requisições relacionadas ao log de auditoria
+func (h *AuditoriaHandler) HandleAuditoria(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Somente administradores do ERP podem consultar a auditoria
+	if adminERP, _ := middleware.GetAdminERPFromContext(r.Context()); !adminERP {
+		_ = h.auditService.LogAction(
+			r.Context(),
+			r,
+			"PERMISSION_DENIED",
+			"AUDITORIA",
+			"",
+			fmt.Sprintf("Tentativa de %s em %s sem ser administrador", r.Method, r.URL.Path),
+		)
+		problem.Write(w, r, http.StatusForbidden, "Acesso restrito a administradores")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	parts := strings.Split(strings.TrimSuffix(r.URL.Path, "/"), "/")
+
+	switch {
+	// Log de auditoria: /auditoria
+	case len(parts) == 2:
+		h.getAuditLogs(w, r)
+	// Exportação do log de auditoria: /auditoria/export
+	case len(parts) == 3 && parts[2] == "export":
+		h.exportAuditLogs(w, r)
+	// Tentativas de login: /auditoria/tentativas-login
+	case len(parts) == 3 && parts[2] == "tentativas-login":
+		h.getLoginAttempts(w, r)
+	// Exportação das tentativas de login: /auditoria/tentativas-login/export
+	case len(parts) == 4 && parts[2] == "tentativas-login" && parts[3] == "export":
+		h.exportLoginAttempts(w, r)
+	// Verificação da cadeia de hashes: /auditoria/verificar
+	case len(parts) == 3 && parts[2] == "verificar":
+		h.verifyChain(w, r)
+	// Pontos de verificação assinados: /auditoria/checkpoints
+	case len(parts) == 3 && parts[2] == "checkpoints":
+		h.getCheckpoints(w, r)
+	// Contadores da gravação assíncrona: /auditoria/fila
+	case len(parts) == 3 && parts[2] == "fila":
+		h.getWriterStats(w, r)
+	default:
+		problem.Write(w, r, http.StatusNotFound, "Recurso não encontrado")
+	}
+}
+
+// getAuditLogs retorna uma página do log de auditoria, com ordenação e filtros
+func (h *AuditoriaHandler) getAuditLogs(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	logs, err := h.repo.GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar registros de auditoria")
+		return
+	}
+
+	json.NewEncoder(w).Encode(logs)
+}
+
+// getLoginAttempts retorna uma página das tentativas de login, com ordenação e filtros
+func (h *AuditoriaHandler) getLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	attempts, err := h.repo.GetLoginAttempts(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar tentativas de login")
+		return
+	}
+
+	json.NewEncoder(w).Encode(attempts)
+}
+
+// verifyChain percorre a cadeia de hashes do log de auditoria e informa o primeiro encadeamento quebrado
+func (h *AuditoriaHandler) verifyChain(w http.ResponseWriter, r *http.Request) {
+	// A verificação percorre todo o log e pode exceder o tempo limite de escrita do servidor
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	result, err := h.auditService.VerifyChain(r.Context())
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao verificar a cadeia de auditoria")
+		return
+	}
+
+	// Registrar na auditoria
+	detalhes := fmt.Sprintf("Cadeia íntegra: %d registros verificados", result.CheckedEntries)
+	if !result.Valid {
+		detalhes = fmt.Sprintf("Cadeia quebrada no registro %d: %s", *result.BrokenAt, result.Reason)
+	}
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"VERIFY_CHAIN",
+		"AUDITORIA",
+		"",
+		detalhes,
+	)
+
+	json.NewEncoder(w).Encode(result)
+}
+
+// getCheckpoints retorna uma página dos pontos de verificação assinados, com ordenação e filtros
+func (h *AuditoriaHandler) getCheckpoints(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	checkpoints, err := h.auditService.GetCheckpoints(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar pontos de verificação")
+		return
+	}
+
+	json.NewEncoder(w).Encode(checkpoints)
+}
+
+// getWriterStats retorna os contadores da gravação assíncrona do log de auditoria
+func (h *AuditoriaHandler) getWriterStats(w http.ResponseWriter, r *http.Request) {
+	stats := h.auditService.WriterStats()
+	if stats == nil {
+		problem.Write(w, r, http.StatusNotFound, "Gravação assíncrona da auditoria desativada")
+		return
+	}
+
+	json.NewEncoder(w).Encode(stats)
+}
+
+// exportAuditLogs exporta os registros de auditoria filtrados em CSV ou JSON Lines
+func (h *AuditoriaHandler) exportAuditLogs(w http.ResponseWriter, r *http.Request) {
+	exportar(w, r, h.auditService, r.URL.Query(), exportacao[models.AuditLog]{
+		entityType: "AUDITORIA",
+		nome:       "auditoria",
+		formatos:   []string{formatoExportacaoCSV, formatoExportacaoJSONL},
+		cabecalho:  []string{"id", "user_id", "username", "action", "entity_type", "entity_id", "details", "ip_address", "request_id", "changes", "prev_hash", "hash", "created_at"},
+		linha: func(l models.AuditLog) []string {
+			userID := ""
+			if l.UserID != nil {
+				userID = strconv.FormatInt(*l.UserID, 10)
+			}
+			return []string{
+				strconv.FormatInt(l.ID, 10),
+				userID,
+				l.Username,
+				l.Action,
+				l.EntityType,
+				l.EntityID,
+				l.Details,
+				l.IPAddress,
+				l.RequestID,
+				string(l.Changes),
+				l.PrevHash,
+				l.Hash,
+				l.CreatedAt.Format(time.RFC3339),
+			}
+		},
+		export: h.repo.Export,
+	})
+}
+
+// exportLoginAttempts exporta as tentativas de login filtradas em CSV ou JSON Lines
+func (h *AuditoriaHandler) exportLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	exportar(w, r, h.auditService, r.URL.Query(), exportacao[models.LoginAttempt]{
+		entityType: "AUDITORIA",
+		nome:       "tentativas-login",
+		formatos:   []string{formatoExportacaoCSV, formatoExportacaoJSONL},
+		cabecalho:  []string{"id", "login", "ip_address", "success", "attempt_time"},
+		linha: func(a models.LoginAttempt) []string {
+			return []string{
+				strconv.FormatInt(a.ID, 10),
+				a.Login,
+				a.IPAddress,
+				strconv.FormatBool(a.Success),
+				a.AttemptTime.Format(time.RFC3339),
+			}
+		},
+		export: h.repo.ExportLoginAttempts,
+	})
+}
@@ -1,14 +1,18 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/metrics"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
@@ -38,17 +42,24 @@ type RefreshResponse struct {
 	ExpiresIn    int    `json:"expires_in"` // Tempo de expiração em segundos
 }
 
+// LogoutAllResponse representa a resposta do encerramento de todas as sessões
+type LogoutAllResponse struct {
+	SessoesRevogadas int64 `json:"sessoes_revogadas"`
+}
+
 // AuthHandler gerencia requisições relacionadas a autenticação
 type AuthHandler struct {
-	repo        *models.UsuarioRepository
-	auditService *services.AuditService
+	repo           models.UsuarioStore
+	sessionService *services.SessionService
+	auditService   *services.AuditService
 }
 
 // NewAuthHandler cria um novo handler de autenticação
-func NewAuthHandler(db *sql.DB) *AuthHandler {
+func NewAuthHandler(repo models.UsuarioStore, sessionService *services.SessionService, auditService *services.AuditService) *AuthHandler {
 	return &AuthHandler{
-		repo:        models.NewUsuarioRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:           repo,
+		sessionService: sessionService,
+		auditService:   auditService,
 	}
 }
 
@@ -56,33 +67,34 @@ func NewAuthHandler(db *sql.DB) *AuthHandler {
 func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	// Verificar se o método é POST
 	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 	
 	// Decodificar os dados da requisição
 	var loginReq LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 	
 	// Verificar se o login e senha foram fornecidos
 	if loginReq.Login == "" || loginReq.Senha == "" {
-		http.Error(w, "Login e senha são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Login e senha são obrigatórios")
 		return
 	}
 	
 	// Verificar se a conta está bloqueada
-	locked, blockedUntil, err := h.auditService.IsAccountLocked(loginReq.Login)
+	locked, blockedUntil, err := h.auditService.IsAccountLocked(r.Context(), loginReq.Login)
 	if err != nil {
 		// Registrar erro, mas continuar para verificar as credenciais
-		fmt.Printf("Erro ao verificar bloqueio de conta: %v\n", err)
+		slog.ErrorContext(r.Context(), "Erro ao verificar bloqueio de conta", "login", loginReq.Login, "error", err)
 	}
 	
 	if locked {
 		// Registrar tentativa de login em conta bloqueada
 		_ = h.auditService.LogLoginAttempt(r, loginReq.Login, false)
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginLocked).Inc()
 		
 		// Calcular tempo restante de bloqueio
 		remainingTime := blockedUntil.Sub(time.Now())
@@ -90,7 +102,7 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		
 		// Responder com erro
 		errorMsg := fmt.Sprintf("Conta bloqueada temporariamente. Tente novamente em %d minutos.", minutes)
-		http.Error(w, errorMsg, http.StatusTooManyRequests)
+		problem.Write(w, r, http.StatusTooManyRequests, errorMsg)
 		
 		// Registrar na auditoria
 		_ = h.auditService.LogAction(
@@ -109,12 +121,13 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	exceeded, blockedUntil, err := h.auditService.CheckLoginAttempts(r, loginReq.Login)
 	if err != nil {
 		// Registrar erro, mas continuar para verificar as credenciais
-		fmt.Printf("Erro ao verificar tentativas de login: %v\n", err)
+		slog.ErrorContext(r.Context(), "Erro ao verificar tentativas de login", "login", loginReq.Login, "error", err)
 	}
 	
 	if exceeded {
 		// Registrar tentativa de login que excedeu o limite
 		_ = h.auditService.LogLoginAttempt(r, loginReq.Login, false)
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginAttemptsExceeded).Inc()
 		
 		// Calcular tempo de bloqueio
 		remainingTime := blockedUntil.Sub(time.Now())
@@ -122,7 +135,7 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		
 		// Responder com erro
 		errorMsg := fmt.Sprintf("Muitas tentativas de login. Conta bloqueada por %d minutos.", minutes)
-		http.Error(w, errorMsg, http.StatusTooManyRequests)
+		problem.Write(w, r, http.StatusTooManyRequests, errorMsg)
 		
 		// Registrar na auditoria
 		_ = h.auditService.LogAction(
@@ -138,14 +151,15 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Verificar as credenciais
-	usuario, err := h.repo.VerifyPassword(loginReq.Login, loginReq.Senha)
+	usuario, err := h.repo.VerifyPassword(r.Context(), loginReq.Login, loginReq.Senha)
 	
 	// Registrar tentativa de login
 	loginSuccess := err == nil
 	_ = h.auditService.LogLoginAttempt(r, loginReq.Login, loginSuccess)
 	
 	if err != nil {
-		http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
+		metrics.LoginAttempts.WithLabelValues(metrics.LoginInvalid).Inc()
+		problem.Write(w, r, http.StatusUnauthorized, "Credenciais inválidas")
 		
 		// Registrar na auditoria
 		_ = h.auditService.LogAction(
@@ -160,34 +174,37 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Gerar token JWT
-	accessToken, err := auth.GenerateToken(usuario.ID, usuario.Login, usuario.IdTipoPerfil)
-	if err != nil {
-		http.Error(w, "Erro ao gerar token", http.StatusInternalServerError)
-		return
+	// Dados do usuário embutidos nos tokens
+	identity := auth.Identity{
+		UserID:       usuario.ID,
+		Username:     usuario.Login,
+		TipoPerfilID: usuario.IdTipoPerfil,
+		IdSeguradora: int64(usuario.IdSeguradora),
+		AdminERP:     usuario.AdminERP,
 	}
 	
-	// Gerar refresh token
-	refreshToken, err := auth.GenerateRefreshToken(usuario.ID, usuario.Login, usuario.IdTipoPerfil)
+	// Iniciar uma nova sessão, gerando o token JWT e o refresh token persistido
+	tokens, err := h.sessionService.Start(r, identity)
 	if err != nil {
-		http.Error(w, "Erro ao gerar refresh token", http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao gerar tokens")
 		return
 	}
 	
 	// Registrar login bem-sucedido na auditoria
+	metrics.LoginAttempts.WithLabelValues(metrics.LoginSuccess).Inc()
 	_ = h.auditService.LogAction(
 		r.Context(),
 		r,
 		"LOGIN_SUCCESS",
 		"USER",
 		fmt.Sprintf("%d", usuario.ID),
-		"Login bem-sucedido",
+		"Login bem-sucedido (sessão "+tokens.SessionID+")",
 	)
 	
 	// Preparar resposta
 	response := LoginResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		Usuario:      *usuario,
 		ExpiresIn:    int(auth.TokenExpiration.Seconds()),
 	}
@@ -204,27 +221,45 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 	// Verificar se o método é POST
 	if r.Method != http.MethodPost {
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 	
 	// Decodificar os dados da requisição
 	var refreshReq RefreshRequest
 	if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 	
 	// Verificar se o token foi fornecido
 	if refreshReq.RefreshToken == "" {
-		http.Error(w, "Refresh token é obrigatório", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Refresh token é obrigatório")
 		return
 	}
 	
-	// Validar o refresh token e gerar novos tokens
-	accessToken, newRefreshToken, err := auth.RefreshToken(refreshReq.RefreshToken)
+	// Validar e consumir o refresh token, gerando novos tokens na mesma sessão
+	tokens, claims, err := h.sessionService.Refresh(r, refreshReq.RefreshToken)
 	if err != nil {
-		http.Error(w, "Refresh token inválido ou expirado", http.StatusUnauthorized)
+		// Identificar o usuário do token, quando disponível
+		entityID := ""
+		if claims != nil {
+			entityID = fmt.Sprintf("%d", claims.UserID)
+		}
+		
+		// Reuso de um refresh token já rotacionado: a sessão inteira foi revogada
+		if errors.Is(err, models.ErrRefreshTokenReutilizado) {
+			_ = h.auditService.LogAction(
+				r.Context(),
+				r,
+				"REFRESH_TOKEN_REUSE",
+				"AUTH",
+				entityID,
+				"Reuso de refresh token detectado: sessão "+claims.SessionID+" revogada",
+			)
+			problem.Write(w, r, http.StatusUnauthorized, "Refresh token já utilizado: sessão revogada")
+			return
+		}
 		
 		// Registrar na auditoria
 		_ = h.auditService.LogAction(
@@ -232,16 +267,18 @@ func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 			r,
 			"TOKEN_REFRESH_FAILED",
 			"AUTH",
-			"",
+			entityID,
 			"Falha ao renovar token: " + err.Error(),
 		)
 		
+		if claims != nil && !errors.Is(err, models.ErrRefreshTokenInvalido) {
+			problem.Error(w, r, err, "Erro ao renovar token")
+			return
+		}
+		problem.Write(w, r, http.StatusUnauthorized, "Refresh token inválido ou expirado")
 		return
 	}
 	
-	// Extrair informações do usuário do token para auditoria
-	claims, _ := auth.ValidateRefreshToken(refreshReq.RefreshToken)
-	
 	// Registrar refresh de token na auditoria
 	_ = h.auditService.LogAction(
 		r.Context(),
@@ -254,8 +291,8 @@ func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 	
 	// Preparar resposta
 	response := RefreshResponse{
-		AccessToken:  accessToken,
-		RefreshToken: newRefreshToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 		ExpiresIn:    int(auth.TokenExpiration.Seconds()),
 	}
 	
@@ -266,3 +303,100 @@ func (h *AuthHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
 	// Enviar resposta
 	json.NewEncoder(w).Encode(response)
 }
+
+// HandleLogout encerra a sessão do token de acesso da requisição
+func (h *AuthHandler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	// Verificar se o método é POST
+	if r.Method != http.MethodPost {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+	
+	sessionID, ok := middleware.GetSessionIDFromContext(r.Context())
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, "Sessão não identificada")
+		return
+	}
+	
+	// Revogar todos os refresh tokens da sessão
+	if err := h.sessionService.Logout(r.Context(), sessionID); err != nil {
+		problem.Error(w, r, err, "Erro ao encerrar sessão")
+		return
+	}
+	
+	// Registrar na auditoria
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"LOGOUT",
+		"AUTH",
+		fmt.Sprintf("%d", userID),
+		"Sessão "+sessionID+" encerrada",
+	)
+	
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleLogoutAll encerra todas as sessões do usuário autenticado
+func (h *AuthHandler) HandleLogoutAll(w http.ResponseWriter, r *http.Request) {
+	// Verificar se o método é POST
+	if r.Method != http.MethodPost {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+	
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		problem.Write(w, r, http.StatusUnauthorized, "Usuário não identificado")
+		return
+	}
+	
+	// Revogar os refresh tokens de todas as sessões do usuário
+	revogadas, err := h.sessionService.LogoutAll(r.Context(), userID, models.MotivoRevogacaoLogoutGeral)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao encerrar sessões")
+		return
+	}
+	
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"LOGOUT_ALL",
+		"AUTH",
+		fmt.Sprintf("%d", userID),
+		fmt.Sprintf("Encerradas %d sessões do usuário", revogadas),
+	)
+	
+	// Definir cabeçalho de resposta
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	
+	// Enviar resposta
+	json.NewEncoder(w).Encode(LogoutAllResponse{SessoesRevogadas: revogadas})
+}
+
+// HandleJWKS publica as chaves públicas usadas na verificação dos tokens (RFC 7517).
+// Chaves simétricas (HS256) nunca são publicadas; nesse caso o conjunto é vazio.
+func HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	// Verificar se o método é GET
+	if r.Method != http.MethodGet {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+	
+	jwks, err := auth.PublicJWKS()
+	if err != nil {
+		problem.Write(w, r, http.StatusServiceUnavailable, fmt.Sprintf("Erro ao obter chaves: %v", err))
+		return
+	}
+	
+	// Definir cabeçalho de resposta, permitindo cache curto para acompanhar rotações
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	
+	// Enviar resposta
+	json.NewEncoder(w).Encode(jwks)
+}
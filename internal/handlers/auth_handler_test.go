@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+func TestLogoutELogoutGeral(t *testing.T) {
+	ks, err := auth.NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("erro ao gerar chaves: %v", err)
+	}
+	auth.SetKeySet(ks)
+
+	env := newTestEnv(t)
+	sessions := services.NewSessionService(env.stores.RefreshTokens)
+	h := NewAuthHandler(env.stores.Usuarios, sessions, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	ctx := context.Background()
+
+	// Duas sessões do mesmo usuário
+	var sessionIDs []string
+	for i := 0; i < 2; i++ {
+		pair, err := sessions.Start(httptest.NewRequest(http.MethodPost, "/auth/login", nil), auth.Identity{
+			UserID:       usuario.ID,
+			Username:     usuario.Login,
+			TipoPerfilID: usuario.IdTipoPerfil,
+			IdSeguradora: env.seguradoraA,
+		})
+		if err != nil {
+			t.Fatalf("erro ao iniciar sessão: %v", err)
+		}
+		sessionIDs = append(sessionIDs, pair.SessionID)
+	}
+
+	// withSession coloca no contexto a sessão do token de acesso, como o middleware de autenticação
+	withSession := func(r *http.Request, sessionID string) *http.Request {
+		return r.WithContext(context.WithValue(r.Context(), middleware.SessionIDKey, sessionID))
+	}
+	ativa := func(sessionID string) bool {
+		t.Helper()
+		ativa, err := sessions.IsSessionActive(ctx, sessionID)
+		if err != nil {
+			t.Fatalf("erro ao verificar sessão: %v", err)
+		}
+		return ativa
+	}
+
+	// Somente POST é aceito
+	w := serve(h.HandleLogout, withSession(newRequest(http.MethodGet, "/auth/logout", "", usuario.ID, env.seguradoraA), sessionIDs[0]))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET /auth/logout: status %d, esperado 405", w.Code)
+	}
+
+	// O logout encerra a sessão da requisição e mantém a outra
+	w = serve(h.HandleLogout, withSession(newRequest(http.MethodPost, "/auth/logout", "", usuario.ID, env.seguradoraA), sessionIDs[0]))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("POST /auth/logout: status %d, corpo %s", w.Code, w.Body.String())
+	}
+	if ativa(sessionIDs[0]) || !ativa(sessionIDs[1]) {
+		t.Errorf("sessões ativas após o logout: %v e %v, esperado false e true", ativa(sessionIDs[0]), ativa(sessionIDs[1]))
+	}
+
+	// Sem sessão no contexto, o logout é recusado
+	w = serve(h.HandleLogout, newRequest(http.MethodPost, "/auth/logout", "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("POST /auth/logout sem sessão: status %d, esperado 401", w.Code)
+	}
+
+	// O logout geral encerra as sessões restantes e informa quantas foram revogadas
+	w = serve(h.HandleLogoutAll, withSession(newRequest(http.MethodPost, "/auth/logout-all", "", usuario.ID, env.seguradoraA), sessionIDs[1]))
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /auth/logout-all: status %d, corpo %s", w.Code, w.Body.String())
+	}
+	var resposta LogoutAllResponse
+	decode(t, w, &resposta)
+	if resposta.SessoesRevogadas != 1 || ativa(sessionIDs[1]) {
+		t.Errorf("%d sessões revogadas, sessão restante ativa = %v, esperado 1 e false", resposta.SessoesRevogadas, ativa(sessionIDs[1]))
+	}
+
+	userID := fmt.Sprintf("%d", usuario.ID)
+	for _, action := range []string{"LOGOUT", "LOGOUT_ALL"} {
+		if n := env.countAudit(t, action, "AUTH", userID); n != 1 {
+			t.Errorf("%d entradas %s, esperada 1", n, action)
+		}
+	}
+}
@@ -1,54 +1,53 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // EventoHandler gerencia requisições relacionadas a eventos
 type EventoHandler struct {
-	repo         *models.EventoRepository
+	repo         models.EventoStore
 	auditService *services.AuditService
 }
 
 // NewEventoHandler cria um novo handler de eventos
-func NewEventoHandler(db *sql.DB) *EventoHandler {
+func NewEventoHandler(repo models.EventoStore, auditService *services.AuditService) *EventoHandler {
 	return &EventoHandler{
-		repo:         models.NewEventoRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *EventoHandler) tenantRepo(r *http.Request) models.EventoStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleEvento gerencia todas as requisições relacionadas a eventos
 func (h *EventoHandler) HandleEvento(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Verificar se há um ID na URL para operações específicas
 	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) > 2 && parts[1] == "eventos" && parts[2] != "" {
-		id, err := strconv.ParseInt(parts[2], 10, 64)
-		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+
+	// Importação em lote de eventos em CSV ou XLSX
+	if len(parts) > 2 && parts[1] == "eventos" && parts[2] == "importar" {
+		if r.Method == http.MethodPost {
+			h.importEventos(w, r)
 			return
 		}
 
-		switch r.Method {
-		case http.MethodGet:
-			h.getEventoByID(w, r, id)
-		case http.MethodPut:
-			h.updateEvento(w, r, id)
-		case http.MethodDelete:
-			h.deleteEvento(w, r, id)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
@@ -56,7 +55,7 @@ func (h *EventoHandler) HandleEvento(w http.ResponseWriter, r *http.Request) {
 	if len(parts) > 3 && parts[1] == "eventos" && parts[2] == "seguradora" && parts[3] != "" {
 		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
 		if err != nil {
-			http.Error(w, "ID de seguradora inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID de seguradora inválido")
 			return
 		}
 
@@ -65,7 +64,38 @@ func (h *EventoHandler) HandleEvento(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Verificar se há um ID na URL para operações específicas
+	if len(parts) > 2 && parts[1] == "eventos" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Histórico de alterações: /eventos/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getEventoHistorico(w, r, id)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.getEventoByID(w, r, id)
+		case http.MethodPut:
+			h.updateEvento(w, r, id)
+		case http.MethodDelete:
+			h.deleteEvento(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
 		return
 	}
 
@@ -76,15 +106,21 @@ func (h *EventoHandler) HandleEvento(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		h.createEvento(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getEventos retorna todos os eventos
+// getEventos retorna uma página de eventos, com ordenação e filtros
 func (h *EventoHandler) getEventos(w http.ResponseWriter, r *http.Request) {
-	eventos, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	eventos, err := h.tenantRepo(r).GetAll(r.Context(), opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar eventos: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar eventos")
 		return
 	}
 
@@ -95,7 +131,7 @@ func (h *EventoHandler) getEventos(w http.ResponseWriter, r *http.Request) {
 		"LIST",
 		"EVENTOS",
 		"",
-		fmt.Sprintf("Listados %d eventos", len(eventos)),
+		fmt.Sprintf("Listados %d eventos", len(eventos.Items)),
 	)
 
 	json.NewEncoder(w).Encode(eventos)
@@ -103,13 +139,9 @@ func (h *EventoHandler) getEventos(w http.ResponseWriter, r *http.Request) {
 
 // getEventoByID retorna um evento específico pelo ID
 func (h *EventoHandler) getEventoByID(w http.ResponseWriter, r *http.Request, id int64) {
-	evento, err := h.repo.GetByID(id)
+	evento, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar evento")
 		return
 	}
 
@@ -128,9 +160,19 @@ func (h *EventoHandler) getEventoByID(w http.ResponseWriter, r *http.Request, id
 
 // getEventosBySeguradora retorna eventos de uma seguradora específica
 func (h *EventoHandler) getEventosBySeguradora(w http.ResponseWriter, r *http.Request, idSeguradora int64) {
-	eventos, err := h.repo.GetBySeguradora(idSeguradora)
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar eventos por seguradora: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	eventos, err := h.tenantRepo(r).GetBySeguradora(r.Context(), idSeguradora, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "EVENTO", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao buscar eventos por seguradora")
 		return
 	}
 
@@ -141,7 +183,7 @@ func (h *EventoHandler) getEventosBySeguradora(w http.ResponseWriter, r *http.Re
 		"LIST",
 		"EVENTOS",
 		fmt.Sprintf("seguradora/%d", idSeguradora),
-		fmt.Sprintf("Listados %d eventos da seguradora %d", len(eventos), idSeguradora),
+		fmt.Sprintf("Listados %d eventos da seguradora %d", len(eventos.Items), idSeguradora),
 	)
 
 	json.NewEncoder(w).Encode(eventos)
@@ -151,13 +193,13 @@ func (h *EventoHandler) getEventosBySeguradora(w http.ResponseWriter, r *http.Re
 func (h *EventoHandler) createEvento(w http.ResponseWriter, r *http.Request) {
 	var evento models.Evento
 	if err := json.NewDecoder(r.Body).Decode(&evento); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if evento.Evento <= 0 || evento.Descricao == "" || evento.IdSeguradora <= 0 {
-		http.Error(w, "Evento, descrição e ID da seguradora são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Evento, descrição e ID da seguradora são obrigatórios")
 		return
 	}
 
@@ -166,42 +208,25 @@ func (h *EventoHandler) createEvento(w http.ResponseWriter, r *http.Request) {
 		evento.Ativo = true
 	}
 
-	if err := h.repo.Create(&evento); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar evento: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &evento); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "EVENTO", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar evento")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"CREATE",
-		"EVENTO",
-		fmt.Sprintf("%d", evento.ID),
-		fmt.Sprintf("Criado evento: %d (%s)", evento.Evento, evento.Descricao),
-	)
-
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(evento)
 }
 
 // updateEvento atualiza um evento existente
 func (h *EventoHandler) updateEvento(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o evento existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var evento models.Evento
 	if err := json.NewDecoder(r.Body).Decode(&evento); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -209,25 +234,19 @@ func (h *EventoHandler) updateEvento(w http.ResponseWriter, r *http.Request, id
 	evento.ID = id
 
 	// Atualizar o evento
-	if err := h.repo.Update(&evento); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar evento: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Update(r.Context(), &evento); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "EVENTO", fmt.Sprintf("%d", evento.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar evento")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"UPDATE",
-		"EVENTO",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Atualizado evento: %d (%s)", evento.Evento, evento.Descricao),
-	)
-
 	// Buscar o evento atualizado
-	updatedEvento, err := h.repo.GetByID(id)
+	updatedEvento, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar evento atualizado: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar evento atualizado")
 		return
 	}
 
@@ -236,33 +255,32 @@ func (h *EventoHandler) updateEvento(w http.ResponseWriter, r *http.Request, id
 
 // deleteEvento remove um evento
 func (h *EventoHandler) deleteEvento(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o evento existe
-	evento, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Excluir o evento
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "EVENTO", fmt.Sprintf("%d", id))
+			return
 		}
+		problem.Error(w, r, err, "Erro ao excluir evento")
 		return
 	}
 
-	// Excluir o evento
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir evento: %v", err), http.StatusInternalServerError)
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importEventos importa em lote os eventos de uma seguradora (colunas evento, descricao e ativo, opcional)
+func (h *EventoHandler) importEventos(w http.ResponseWriter, r *http.Request) {
+	importarLote(w, r, h.auditService, "EVENTOS", []string{"evento", "descricao"}, h.tenantRepo(r).Import)
+}
+
+// getEventoHistorico retorna o histórico de alterações de um evento
+func (h *EventoHandler) getEventoHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar evento")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"DELETE",
-		"EVENTO",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Desativado evento: %d (%s)", evento.Evento, evento.Descricao),
-	)
-
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	writeHistorico(w, r, h.auditService, models.EntidadeEvento, id)
 }
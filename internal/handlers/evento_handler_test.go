@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+func TestEventosPorSeguradora(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewEventoHandler(env.stores.Eventos, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	env.createContabil(t, env.seguradoraA)
+
+	// /eventos/seguradora/{id} não pode ser confundida com /eventos/{id}
+	target := fmt.Sprintf("/eventos/seguradora/%d", env.seguradoraA)
+	w := serve(h.HandleEvento, newRequest(http.MethodGet, target, "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, corpo %s", target, w.Code, w.Body.String())
+	}
+	var eventos models.Page[models.Evento]
+	decode(t, w, &eventos)
+	if eventos.Total != 1 {
+		t.Errorf("eventos da seguradora: %d, esperado 1", eventos.Total)
+	}
+
+	// A listagem de outra seguradora é recusada e registrada na auditoria
+	target = fmt.Sprintf("/eventos/seguradora/%d", env.seguradoraB)
+	w = serve(h.HandleEvento, newRequest(http.MethodGet, target, "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("GET %s: status %d, esperado 403", target, w.Code)
+	}
+	if n := env.countAudit(t, "CROSS_TENANT_DENIED", "EVENTO", fmt.Sprintf("seguradora/%d", env.seguradoraB)); n != 1 {
+		t.Errorf("%d entradas CROSS_TENANT_DENIED, esperada 1", n)
+	}
+}
+
+func TestEventoEscritaEmOutraSeguradora(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewEventoHandler(env.stores.Eventos, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	outra := env.createContabil(t, env.seguradoraB)
+	id := fmt.Sprintf("%d", outra.evento.ID)
+
+	// Alterar um evento de outra seguradora, informando a própria seguradora no corpo
+	body := fmt.Sprintf(`{"evento":101,"descricao":"Alterado","idSeguradora":%d,"ativo":true}`, env.seguradoraA)
+	w := serve(h.HandleEvento, newRequest(http.MethodPut, "/eventos/"+id, body, usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("PUT /eventos/%s: status %d, esperado 403, corpo %s", id, w.Code, w.Body.String())
+	}
+
+	w = serve(h.HandleEvento, newRequest(http.MethodDelete, "/eventos/"+id, "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("DELETE /eventos/%s: status %d, esperado 403, corpo %s", id, w.Code, w.Body.String())
+	}
+
+	if n := env.countAudit(t, "CROSS_TENANT_DENIED", "EVENTO", id); n != 2 {
+		t.Errorf("%d entradas CROSS_TENANT_DENIED, esperadas 2", n)
+	}
+
+	// O evento não foi alterado
+	evento, err := env.stores.Eventos.GetByID(context.Background(), outra.evento.ID)
+	if err != nil {
+		t.Fatalf("erro ao buscar evento: %v", err)
+	}
+	if evento.Descricao != outra.evento.Descricao || !evento.Ativo {
+		t.Errorf("evento de outra seguradora alterado: %+v", evento)
+	}
+
+	// Um evento inexistente continua resultando em 404
+	w = serve(h.HandleEvento, newRequest(http.MethodDelete, "/eventos/999", "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("DELETE /eventos/999: status %d, esperado 404", w.Code)
+	}
+}
+
+// csvEventos monta um arquivo CSV de importação com a quantidade de eventos informada
+func csvEventos(quantidade int) string {
+	var b strings.Builder
+	b.WriteString("evento;descricao\n")
+	for i := 1; i <= quantidade; i++ {
+		fmt.Fprintf(&b, "%d;Evento %d\n", i, i)
+	}
+	return b.String()
+}
+
+// xlsxEventos monta um arquivo XLSX de importação com a quantidade de eventos informada
+func xlsxEventos(t *testing.T, quantidade int) string {
+	t.Helper()
+
+	arquivo := excelize.NewFile()
+	defer arquivo.Close()
+	planilha := arquivo.GetSheetName(0)
+	arquivo.SetSheetRow(planilha, "A1", &[]interface{}{"evento", "descricao"})
+	for i := 1; i <= quantidade; i++ {
+		celula, _ := excelize.CoordinatesToCellName(1, i+1)
+		arquivo.SetSheetRow(planilha, celula, &[]interface{}{i, fmt.Sprintf("Evento %d", i)})
+	}
+	var buf bytes.Buffer
+	if err := arquivo.Write(&buf); err != nil {
+		t.Fatalf("erro ao gerar XLSX: %v", err)
+	}
+	return buf.String()
+}
+
+func TestImportarEventosLimiteDeLinhas(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewEventoHandler(env.stores.Eventos, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	target := fmt.Sprintf("/eventos/importar?idSeguradora=%d", env.seguradoraA)
+
+	// No limite, o arquivo é aceito
+	w := serve(h.HandleEvento, newRequest(http.MethodPost, target+"&simular=true", csvEventos(models.MaxLinhasImportacao), usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusOK {
+		t.Fatalf("importação de %d linhas: status %d, corpo %s", models.MaxLinhasImportacao, w.Code, w.Body.String())
+	}
+
+	// Acima do limite, CSV e XLSX são recusados e nada é gravado
+	for formato, arquivo := range map[string]string{
+		models.FormatoImportacaoCSV:  csvEventos(models.MaxLinhasImportacao + 1),
+		models.FormatoImportacaoXLSX: xlsxEventos(t, models.MaxLinhasImportacao+1),
+	} {
+		w := serve(h.HandleEvento, newRequest(http.MethodPost, target+"&formato="+formato, arquivo, usuario.ID, env.seguradoraA))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("importação %s acima do limite: status %d, esperado 422, corpo %s", formato, w.Code, w.Body.String())
+		}
+	}
+	page, err := env.stores.Eventos.GetAll(context.Background(), models.ListOptions{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("erro ao listar eventos: %v", err)
+	}
+	if page.Total != 0 {
+		t.Errorf("%d eventos gravados, esperado 0", page.Total)
+	}
+}
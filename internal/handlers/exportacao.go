@@ -0,0 +1,286 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// Formatos de exportação
+const (
+	formatoExportacaoCSV   = "csv"
+	formatoExportacaoJSONL = "jsonl"
+	formatoExportacaoJSON  = "json"
+	formatoExportacaoXLSX  = "xlsx"
+)
+
+// contentTypesExportacao define o Content-Type da resposta de cada formato de exportação
+var contentTypesExportacao = map[string]string{
+	formatoExportacaoCSV:   "text/csv; charset=utf-8",
+	formatoExportacaoJSONL: "application/x-ndjson",
+	formatoExportacaoJSON:  "application/json",
+	formatoExportacaoXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// exportacao descreve a exportação em streaming dos registros de uma listagem
+type exportacao[T any] struct {
+	entityType string                                                         // Entidade registrada na auditoria
+	nome       string                                                         // Prefixo do nome do arquivo exportado
+	formatos   []string                                                       // Formatos aceitos (o primeiro é o padrão)
+	cabecalho  []string                                                       // Colunas dos formatos tabulares (CSV e XLSX)
+	linha      func(T) []string                                               // Valores das colunas de um registro
+	export     func(context.Context, models.ListOptions, func(T) error) error // Percorre os registros que atendem aos filtros
+}
+
+// exportar grava em streaming, no formato do parâmetro format, os registros que atendem aos
+// filtros da query string. Erros de validação só podem ser respondidos com 400 antes do
+// primeiro registro; depois disso, a resposta é interrompida e o erro registrado no log.
+func exportar[T any](w http.ResponseWriter, r *http.Request, auditService *services.AuditService, query url.Values, e exportacao[T]) {
+	// O formato não é um filtro da listagem
+	formato := strings.ToLower(query.Get("format"))
+	query.Del("format")
+	if formato == "" {
+		formato = e.formatos[0]
+	}
+	aceito := false
+	for _, f := range e.formatos {
+		if f == formato {
+			aceito = true
+			break
+		}
+	}
+	if !aceito {
+		problem.Write(w, r, http.StatusBadRequest, fmt.Sprintf("Formato de exportação inválido (use %s)", strings.Join(e.formatos, ", ")))
+		return
+	}
+
+	opts, err := models.ParseListOptions(query)
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	escritor := novoEscritorExportacao(formato, w)
+	iniciado := false
+	total := 0
+
+	// Os cabeçalhos da resposta só são enviados quando a consulta for aceita
+	iniciar := func() error {
+		if iniciado {
+			return nil
+		}
+		iniciado = true
+
+		// Exportações grandes podem exceder o tempo limite de escrita do servidor
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+		arquivo := fmt.Sprintf("%s-%s.%s", e.nome, time.Now().Format("20060102-150405"), formato)
+		w.Header().Set("Content-Type", contentTypesExportacao[formato])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, arquivo))
+		w.WriteHeader(http.StatusOK)
+
+		return escritor.iniciar(e.cabecalho)
+	}
+
+	err = e.export(r.Context(), opts, func(item T) error {
+		if err := iniciar(); err != nil {
+			return err
+		}
+		total++
+		return escritor.gravar(item, e.linha(item))
+	})
+	if err == nil {
+		err = iniciar()
+	}
+	if err == nil {
+		err = escritor.finalizar()
+	}
+
+	if err != nil {
+		escritor.descartar()
+		if !iniciado {
+			problem.Error(w, r, err, "Erro ao exportar registros")
+			return
+		}
+		slog.ErrorContext(r.Context(), "Erro ao exportar registros", "export", e.nome, "error", err)
+	}
+
+	// Registrar na auditoria
+	_ = auditService.LogAction(
+		r.Context(),
+		r,
+		"EXPORT",
+		e.entityType,
+		e.nome,
+		fmt.Sprintf("Exportados %d registros em %s (filtros: %s)", total, formato, query.Encode()),
+	)
+}
+
+// escritorExportacao grava os registros exportados em um formato
+type escritorExportacao interface {
+	iniciar(cabecalho []string) error
+	gravar(item interface{}, valores []string) error
+	finalizar() error
+	descartar()
+}
+
+// novoEscritorExportacao cria o escritor do formato informado
+func novoEscritorExportacao(formato string, w io.Writer) escritorExportacao {
+	switch formato {
+	case formatoExportacaoJSONL:
+		return &escritorJSONL{encoder: json.NewEncoder(w)}
+	case formatoExportacaoJSON:
+		return &escritorJSON{w: w, encoder: json.NewEncoder(w)}
+	case formatoExportacaoXLSX:
+		return &escritorXLSX{w: w}
+	default:
+		return &escritorCSV{writer: csv.NewWriter(w)}
+	}
+}
+
+// celulasSeguras neutraliza os valores que uma planilha interpretaria como fórmula (iniciados por
+// =, +, -, @, tabulação ou retorno de carro), prefixando-os com um apóstrofo. Os valores vêm de
+// campos preenchidos pelos usuários, como descrições e detalhes da auditoria.
+func celulasSeguras(valores []string) []string {
+	seguros := make([]string, len(valores))
+	for i, valor := range valores {
+		if valor != "" && strings.ContainsRune("=+-@\t\r", rune(valor[0])) {
+			valor = "'" + valor
+		}
+		seguros[i] = valor
+	}
+	return seguros
+}
+
+// escritorCSV grava os registros em CSV, com uma linha de cabeçalho
+type escritorCSV struct {
+	writer *csv.Writer
+}
+
+func (e *escritorCSV) iniciar(cabecalho []string) error {
+	return e.writer.Write(cabecalho)
+}
+
+func (e *escritorCSV) gravar(_ interface{}, valores []string) error {
+	return e.writer.Write(celulasSeguras(valores))
+}
+
+func (e *escritorCSV) finalizar() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *escritorCSV) descartar() {}
+
+// escritorJSONL grava um objeto JSON por linha (JSON Lines)
+type escritorJSONL struct {
+	encoder *json.Encoder
+}
+
+func (e *escritorJSONL) iniciar(_ []string) error {
+	return nil
+}
+
+func (e *escritorJSONL) gravar(item interface{}, _ []string) error {
+	return e.encoder.Encode(item)
+}
+
+func (e *escritorJSONL) finalizar() error {
+	return nil
+}
+
+func (e *escritorJSONL) descartar() {}
+
+// escritorJSON grava os registros como um array JSON, um elemento por vez
+type escritorJSON struct {
+	w       io.Writer
+	encoder *json.Encoder
+	total   int
+}
+
+func (e *escritorJSON) iniciar(_ []string) error {
+	_, err := io.WriteString(e.w, "[\n")
+	return err
+}
+
+func (e *escritorJSON) gravar(item interface{}, _ []string) error {
+	if e.total > 0 {
+		if _, err := io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.total++
+	return e.encoder.Encode(item)
+}
+
+func (e *escritorJSON) finalizar() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func (e *escritorJSON) descartar() {}
+
+// escritorXLSX grava os registros na primeira planilha de um arquivo XLSX. As linhas são gravadas
+// pelo StreamWriter do excelize, que as mantém em um arquivo temporário acima de um limite de
+// memória; o arquivo é enviado ao cliente ao final da exportação.
+type escritorXLSX struct {
+	w       io.Writer
+	arquivo *excelize.File
+	stream  *excelize.StreamWriter
+	linha   int
+}
+
+func (e *escritorXLSX) iniciar(cabecalho []string) error {
+	e.arquivo = excelize.NewFile()
+	stream, err := e.arquivo.NewStreamWriter(e.arquivo.GetSheetName(0))
+	if err != nil {
+		return fmt.Errorf("erro ao criar planilha: %w", err)
+	}
+	e.stream = stream
+	return e.gravar(nil, cabecalho)
+}
+
+func (e *escritorXLSX) gravar(_ interface{}, valores []string) error {
+	e.linha++
+	celula, err := excelize.CoordinatesToCellName(1, e.linha)
+	if err != nil {
+		return err
+	}
+
+	row := make([]interface{}, len(valores))
+	for i, valor := range celulasSeguras(valores) {
+		row[i] = valor
+	}
+	return e.stream.SetRow(celula, row)
+}
+
+func (e *escritorXLSX) finalizar() error {
+	defer e.descartar()
+
+	if err := e.stream.Flush(); err != nil {
+		return fmt.Errorf("erro ao gravar planilha: %w", err)
+	}
+	_, err := e.arquivo.WriteTo(e.w)
+	return err
+}
+
+// descartar remove os arquivos temporários de uma exportação interrompida
+func (e *escritorXLSX) descartar() {
+	if e.arquivo != nil {
+		e.arquivo.Close()
+		e.arquivo = nil
+	}
+}
@@ -1,14 +1,18 @@
 package handlers
 
 import (
-	"database/sql"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
@@ -17,20 +21,179 @@ func HomeHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "API Go com MySQL - Use /usuarios para acessar a API")
 }
 
+// denyCrossTenant responde 403 e registra na auditoria uma tentativa de acesso a dados de outra seguradora
+func denyCrossTenant(w http.ResponseWriter, r *http.Request, auditService *services.AuditService, entityType, entityID string) {
+	// Registrar na auditoria
+	_ = auditService.LogAction(
+		r.Context(),
+		r,
+		"CROSS_TENANT_DENIED",
+		entityType,
+		entityID,
+		fmt.Sprintf("Tentativa de %s em dados de outra seguradora", r.Method),
+	)
+
+	problem.WriteCode(w, r, http.StatusForbidden, problem.CodeCrossTenant, "Acesso negado: dados de outra seguradora")
+}
+
+// writeHistorico responde com o histórico de alterações de um registro, com paginação e filtros.
+// O handler deve verificar antes se o registro é visível para o usuário.
+func writeHistorico(w http.ResponseWriter, r *http.Request, auditService *services.AuditService, entityType string, id int64) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	historico, err := auditService.GetHistorico(r.Context(), entityType, id, opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar histórico de alterações")
+		return
+	}
+
+	// Registrar na auditoria
+	_ = auditService.LogAction(
+		r.Context(),
+		r,
+		"READ",
+		entityType,
+		fmt.Sprintf("%d", id),
+		"Consulta de histórico de alterações",
+	)
+
+	json.NewEncoder(w).Encode(historico)
+}
+
+// Tamanho máximo dos arquivos de importação em lote (5 MB)
+const maxImportacaoLote = 5 << 20
+
+// arquivoExcedido responde 413 se o erro de leitura do arquivo indicar que a requisição passou do tamanho
+// máximo definido por http.MaxBytesReader
+func arquivoExcedido(w http.ResponseWriter, r *http.Request, err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(err, &maxBytesErr) {
+		return false
+	}
+	problem.Write(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("Arquivo excede o tamanho máximo de %d MB", maxBytesErr.Limit>>20))
+	return true
+}
+
+// importarLote importa em lote, para a seguradora do parâmetro idSeguradora, os registros de um arquivo
+// CSV ou XLSX enviado no corpo da requisição ou no campo "arquivo" de um formulário multipart. O formato
+// é o do parâmetro formato ou deduzido da extensão do arquivo (padrão: CSV). Com simular=true, as linhas
+// são apenas validadas. Linhas inválidas e arquivos com mais de models.MaxLinhasImportacao linhas são
+// respondidos com 422 e nenhum registro é gravado; arquivos acima de 5 MB, com 413.
+func importarLote(w http.ResponseWriter, r *http.Request, auditService *services.AuditService, entityType string, colunas []string, importar func(ctx context.Context, idSeguradora int64, linhas []models.LinhaArquivo, simular bool) (*models.ResultadoImportacao, error)) {
+	query := r.URL.Query()
+
+	idSeguradora, err := strconv.ParseInt(query.Get("idSeguradora"), 10, 64)
+	if err != nil || idSeguradora <= 0 {
+		problem.Write(w, r, http.StatusBadRequest, "Parâmetro idSeguradora inválido")
+		return
+	}
+	simular := false
+	if valor := query.Get("simular"); valor != "" {
+		if simular, err = strconv.ParseBool(valor); err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "Parâmetro simular inválido")
+			return
+		}
+	}
+
+	// Obter o arquivo
+	formato := strings.ToLower(query.Get("formato"))
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportacaoLote)
+	var arquivo io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("arquivo")
+		if err != nil {
+			if arquivoExcedido(w, r, err) {
+				return
+			}
+			problem.Write(w, r, http.StatusBadRequest, "Arquivo não informado no campo 'arquivo'")
+			return
+		}
+		defer file.Close()
+		arquivo = file
+		if formato == "" && strings.HasSuffix(strings.ToLower(header.Filename), ".xlsx") {
+			formato = models.FormatoImportacaoXLSX
+		}
+	}
+	if formato == "" {
+		formato = models.FormatoImportacaoCSV
+	}
+
+	linhas, err := models.ParseArquivoImportacao(arquivo, formato, colunas)
+	if err != nil {
+		if arquivoExcedido(w, r, err) {
+			return
+		}
+		if errors.Is(err, models.ErrImportacaoExcedida) {
+			problem.Write(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		problem.Write(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	resultado, err := importar(r.Context(), idSeguradora, linhas, simular)
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, auditService, entityType, fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao importar registros")
+		return
+	}
+
+	if len(resultado.Erros) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(resultado)
+		return
+	}
+
+	if simular {
+		json.NewEncoder(w).Encode(resultado)
+		return
+	}
+
+	// Registrar na auditoria
+	_ = auditService.LogAction(
+		r.Context(),
+		r,
+		"IMPORT",
+		entityType,
+		fmt.Sprintf("seguradora/%d", idSeguradora),
+		fmt.Sprintf("Importados %d registros de um arquivo %s", resultado.Criados, formato),
+	)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resultado)
+}
+
 // UserHandler gerencia requisições relacionadas a usuários
 type UserHandler struct {
-	repo         *models.UsuarioRepository
-	auditService *services.AuditService
+	repo           models.UsuarioStore
+	unitOfWork     models.UnitOfWork
+	sessionService *services.SessionService
+	auditService   *services.AuditService
 }
 
 // NewUserHandler cria um novo handler de usuários
-func NewUserHandler(db *sql.DB) *UserHandler {
+func NewUserHandler(repo models.UsuarioStore, unitOfWork models.UnitOfWork, sessionService *services.SessionService, auditService *services.AuditService) *UserHandler {
 	return &UserHandler{
-		repo:         models.NewUsuarioRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:           repo,
+		unitOfWork:     unitOfWork,
+		sessionService: sessionService,
+		auditService:   auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *UserHandler) tenantRepo(r *http.Request) models.UsuarioStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleUsers gerencia todas as requisições relacionadas a usuários
 func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -40,7 +203,30 @@ func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
 	if len(parts) > 2 && parts[1] == "usuarios" && parts[2] != "" {
 		id, err := strconv.ParseInt(parts[2], 10, 64)
 		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Sessões do usuário: /usuarios/{id}/sessoes
+		if len(parts) > 3 && parts[3] == "sessoes" {
+			switch r.Method {
+			case http.MethodGet:
+				h.getSessoesUsuario(w, r, id)
+			case http.MethodDelete:
+				h.revokeSessoesUsuario(w, r, id)
+			default:
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+			}
+			return
+		}
+
+		// Histórico de alterações: /usuarios/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getUserHistorico(w, r, id)
 			return
 		}
 
@@ -52,7 +238,7 @@ func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
 		case http.MethodDelete:
 			h.deleteUser(w, r, id)
 		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		}
 		return
 	}
@@ -64,15 +250,21 @@ func (h *UserHandler) HandleUsers(w http.ResponseWriter, r *http.Request) {
 	case http.MethodPost:
 		h.createUser(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getUsers retorna todos os usuários
+// getUsers retorna uma página de usuários, com ordenação e filtros
 func (h *UserHandler) getUsers(w http.ResponseWriter, r *http.Request) {
-	usuarios, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	usuarios, err := h.tenantRepo(r).GetAll(r.Context(), opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar usuários: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar usuários")
 		return
 	}
 
@@ -83,7 +275,7 @@ func (h *UserHandler) getUsers(w http.ResponseWriter, r *http.Request) {
 		"LIST",
 		"USUARIOS",
 		"",
-		fmt.Sprintf("Listados %d usuários", len(usuarios)),
+		fmt.Sprintf("Listados %d usuários", len(usuarios.Items)),
 	)
 
 	json.NewEncoder(w).Encode(usuarios)
@@ -91,13 +283,9 @@ func (h *UserHandler) getUsers(w http.ResponseWriter, r *http.Request) {
 
 // getUserByID retorna um usuário específico pelo ID
 func (h *UserHandler) getUserByID(w http.ResponseWriter, r *http.Request, id int64) {
-	usuario, err := h.repo.GetByID(id)
+	usuario, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar usuário")
 		return
 	}
 
@@ -118,13 +306,13 @@ func (h *UserHandler) getUserByID(w http.ResponseWriter, r *http.Request, id int
 func (h *UserHandler) createUser(w http.ResponseWriter, r *http.Request) {
 	var usuario models.Usuario
 	if err := json.NewDecoder(r.Body).Decode(&usuario); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if usuario.Nome == "" || usuario.Email == "" || usuario.Login == "" || usuario.Senha == "" {
-		http.Error(w, "Nome, email, login e senha são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Nome, email, login e senha são obrigatórios")
 		return
 	}
 
@@ -133,21 +321,15 @@ func (h *UserHandler) createUser(w http.ResponseWriter, r *http.Request) {
 		usuario.Ativo = true
 	}
 
-	if err := h.repo.Create(&usuario); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar usuário: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &usuario); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "USUARIO", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar usuário")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"CREATE",
-		"USUARIO",
-		fmt.Sprintf("%d", usuario.ID),
-		fmt.Sprintf("Criado usuário: %s (%s)", usuario.Nome, usuario.Email),
-	)
-
 	// Não retornar a senha na resposta
 	usuario.Senha = ""
 
@@ -157,83 +339,108 @@ func (h *UserHandler) createUser(w http.ResponseWriter, r *http.Request) {
 
 // updateUser atualiza um usuário existente
 func (h *UserHandler) updateUser(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o usuário existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var usuario models.Usuario
 	if err := json.NewDecoder(r.Body).Decode(&usuario); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Garantir que o ID seja o mesmo
 	usuario.ID = id
 
-	// Atualizar o usuário
-	if err := h.repo.Update(&usuario); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar usuário: %v", err), http.StatusInternalServerError)
+	// Atualizar o usuário, a senha (se fornecida) e a auditoria em uma única transação
+	repo := h.tenantRepo(r)
+	err := h.unitOfWork.Do(r.Context(), func(ctx context.Context) error {
+		if err := repo.Update(ctx, &usuario); err != nil {
+			return err
+		}
+		if usuario.Senha == "" {
+			return nil
+		}
+
+		if err := repo.UpdatePassword(ctx, id, usuario.Senha); err != nil {
+			return err
+		}
+		return h.auditService.LogAction(ctx, r, "UPDATE_PASSWORD", "USUARIO", fmt.Sprintf("%d", id), "Senha do usuário alterada")
+	})
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "USUARIO", fmt.Sprintf("%d", usuario.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar usuário")
+		return
+	}
+
+	// Buscar o usuário atualizado
+	updatedUser, err := h.tenantRepo(r).GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar usuário atualizado")
 		return
 	}
 
-	// Se a senha foi fornecida, atualizá-la separadamente
-	senhaAlterada := false
-	if usuario.Senha != "" {
-		if err := h.repo.UpdatePassword(id, usuario.Senha); err != nil {
-			http.Error(w, fmt.Sprintf("Erro ao atualizar senha: %v", err), http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(updatedUser)
+}
+
+// deleteUser remove um usuário
+func (h *UserHandler) deleteUser(w http.ResponseWriter, r *http.Request, id int64) {
+	// Excluir o usuário
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "USUARIO", fmt.Sprintf("%d", id))
 			return
 		}
-		senhaAlterada = true
+		problem.Error(w, r, err, "Erro ao excluir usuário")
+		return
 	}
 
-	// Registrar na auditoria
-	detalhes := fmt.Sprintf("Atualizado usuário: %s (%s)", usuario.Nome, usuario.Email)
-	if senhaAlterada {
-		detalhes += " - Senha alterada"
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getSessoesUsuario retorna as sessões ativas de um usuário
+func (h *UserHandler) getSessoesUsuario(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o usuário existe e pertence à seguradora da requisição
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar usuário")
+		return
 	}
+
+	sessoes, err := h.sessionService.GetSessoesAtivas(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar sessões do usuário")
+		return
+	}
+	if sessoes == nil {
+		sessoes = []models.RefreshToken{}
+	}
+
+	// Registrar na auditoria
 	_ = h.auditService.LogAction(
 		r.Context(),
 		r,
-		"UPDATE",
-		"USUARIO",
+		"LIST",
+		"SESSOES",
 		fmt.Sprintf("%d", id),
-		detalhes,
+		fmt.Sprintf("Listadas %d sessões ativas do usuário", len(sessoes)),
 	)
 
-	// Buscar o usuário atualizado
-	updatedUser, err := h.repo.GetByID(id)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar usuário atualizado: %v", err), http.StatusInternalServerError)
-		return
-	}
-
-	json.NewEncoder(w).Encode(updatedUser)
+	json.NewEncoder(w).Encode(sessoes)
 }
 
-// deleteUser remove um usuário
-func (h *UserHandler) deleteUser(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o usuário existe
-	usuario, err := h.repo.GetByID(id)
+// revokeSessoesUsuario revoga todas as sessões de um usuário (ação administrativa)
+func (h *UserHandler) revokeSessoesUsuario(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o usuário existe e pertence à seguradora da requisição
+	usuario, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar usuário")
 		return
 	}
 
-	// Excluir o usuário
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir usuário: %v", err), http.StatusInternalServerError)
+	revogadas, err := h.sessionService.LogoutAll(r.Context(), id, models.MotivoRevogacaoAdmin)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao revogar sessões do usuário")
 		return
 	}
 
@@ -241,12 +448,22 @@ func (h *UserHandler) deleteUser(w http.ResponseWriter, r *http.Request, id int6
 	_ = h.auditService.LogAction(
 		r.Context(),
 		r,
-		"DELETE",
+		"REVOKE_SESSIONS",
 		"USUARIO",
 		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Desativado usuário: %s (%s)", usuario.Nome, usuario.Email),
+		fmt.Sprintf("Revogadas %d sessões do usuário %s", revogadas, usuario.Login),
 	)
 
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	json.NewEncoder(w).Encode(LogoutAllResponse{SessoesRevogadas: revogadas})
+}
+
+// getUserHistorico retorna o histórico de alterações de um usuário
+func (h *UserHandler) getUserHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar usuário")
+		return
+	}
+
+	writeHistorico(w, r, h.auditService, models.EntidadeUsuario, id)
 }
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// testEnv reúne os armazenamentos em memória e o serviço de auditoria usados pelos testes dos handlers,
+// com duas seguradoras e um tipo de perfil cadastrados
+type testEnv struct {
+	stores       *models.Stores
+	auditService *services.AuditService
+	seguradoraA  int64
+	seguradoraB  int64
+	tipoPerfil   int64
+}
+
+// newTestEnv cria o ambiente de teste sobre os armazenamentos em memória
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	stores := models.NewMemoryStores()
+	env := &testEnv{
+		stores:       stores,
+		auditService: services.NewAuditService(stores.AuditLog, stores.LoginAttempts),
+	}
+
+	ctx := context.Background()
+	seguradoras := []*models.Seguradora{
+		{Nome: "Seguradora A", NomeAbreviado: "SEGA", Ativo: true},
+		{Nome: "Seguradora B", NomeAbreviado: "SEGB", Ativo: true},
+	}
+	for _, s := range seguradoras {
+		if err := stores.Seguradoras.Create(ctx, s); err != nil {
+			t.Fatalf("erro ao criar seguradora: %v", err)
+		}
+	}
+	env.seguradoraA, env.seguradoraB = seguradoras[0].ID, seguradoras[1].ID
+
+	tipoPerfil := &models.TipoPerfil{Perfil: "Operador", Ativo: true}
+	if err := stores.TiposPerfil.Create(ctx, tipoPerfil); err != nil {
+		t.Fatalf("erro ao criar tipo de perfil: %v", err)
+	}
+	env.tipoPerfil = tipoPerfil.ID
+
+	return env
+}
+
+// createUsuario cria um usuário ativo na seguradora informada
+func (env *testEnv) createUsuario(t *testing.T, login string, idSeguradora int64) *models.Usuario {
+	t.Helper()
+
+	usuario := &models.Usuario{
+		Nome:         "Usuário " + login,
+		Email:        login + "@exemplo.com.br",
+		Login:        login,
+		Senha:        "Senha@Forte123",
+		IdTipoPerfil: int(env.tipoPerfil),
+		IdSeguradora: int(idSeguradora),
+		Ativo:        true,
+	}
+	if err := env.stores.Usuarios.Create(context.Background(), usuario); err != nil {
+		t.Fatalf("erro ao criar usuário: %v", err)
+	}
+	return usuario
+}
+
+// contabilFixture reúne os cadastros de uma seguradora usados na geração de lançamentos
+type contabilFixture struct {
+	sistema *models.SistemaContabil
+	evento  *models.Evento
+	objeto  *models.ObjetoContabilizacao
+	config  *models.SistemaContabilConfig
+}
+
+// createContabil cadastra na seguradora informada um sistema contábil com duas contas analíticas,
+// o evento 101, o objeto PREMIO, a relação entre eles e a configuração ativa que os contabiliza
+func (env *testEnv) createContabil(t *testing.T, idSeguradora int64) *contabilFixture {
+	t.Helper()
+
+	ctx := context.Background()
+	f := &contabilFixture{
+		sistema: &models.SistemaContabil{SistemaContabil: "SAP", IdSeguradora: idSeguradora, Ativo: true},
+		evento:  &models.Evento{Evento: 101, Descricao: "Emissão de apólice", IdSeguradora: idSeguradora, Ativo: true},
+		objeto:  &models.ObjetoContabilizacao{ObjetoContabilizacao: "PREMIO", Descricao: "Prêmio emitido", IdSeguradora: idSeguradora, Ativo: true},
+	}
+	if err := env.stores.SistemasContabeis.Create(ctx, f.sistema); err != nil {
+		t.Fatalf("erro ao criar sistema contábil: %v", err)
+	}
+	if err := env.stores.Eventos.Create(ctx, f.evento); err != nil {
+		t.Fatalf("erro ao criar evento: %v", err)
+	}
+	if err := env.stores.ObjetosContabilizacao.Create(ctx, f.objeto); err != nil {
+		t.Fatalf("erro ao criar objeto de contabilização: %v", err)
+	}
+	relacao := &models.ObjetoContabilizacaoEvento{IdObjetoContabilizacao: f.objeto.ID, IdCodigoEvento: f.evento.ID, IdSeguradora: idSeguradora, Ativo: true}
+	if err := env.stores.ObjetosContabilizacaoEvento.Create(ctx, relacao); err != nil {
+		t.Fatalf("erro ao relacionar objeto e evento: %v", err)
+	}
+
+	var contas []int64
+	for _, codigo := range []string{"1.1", "2.1"} {
+		conta := &models.ContaContabil{
+			IdSeguradora:      idSeguradora,
+			IdSistemaContabil: f.sistema.ID,
+			Codigo:            codigo,
+			Descricao:         "Conta " + codigo,
+			Natureza:          models.NaturezaDebito,
+			Tipo:              models.TipoContaAnalitica,
+			Ativo:             true,
+		}
+		if err := env.stores.PlanoContas.Create(ctx, conta); err != nil {
+			t.Fatalf("erro ao criar conta contábil: %v", err)
+		}
+		contas = append(contas, conta.ID)
+	}
+
+	f.config = &models.SistemaContabilConfig{
+		IdSistemaContabil:      f.sistema.ID,
+		IdObjetoContabilizacao: f.objeto.ID,
+		IdCodigoEvento:         f.evento.ID,
+		IdSeguradora:           idSeguradora,
+		IdContaDebito:          &contas[0],
+		IdContaCredito:         &contas[1],
+		Ativo:                  true,
+	}
+	if err := env.stores.SistemasContabeisConfig.Create(ctx, f.config); err != nil {
+		t.Fatalf("erro ao criar configuração de sistema contábil: %v", err)
+	}
+
+	return f
+}
+
+// newRequest cria uma requisição autenticada por um usuário comum da seguradora informada, com o
+// escopo de seguradora que os middlewares de autenticação e de seguradora colocariam no contexto
+func newRequest(method, target, body string, userID, idSeguradora int64) *http.Request {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	r := httptest.NewRequest(method, target, reader)
+
+	ctx := context.WithValue(r.Context(), middleware.UserIDKey, userID)
+	ctx = context.WithValue(ctx, middleware.UsernameKey, "teste")
+	ctx = context.WithValue(ctx, middleware.IdSeguradoraKey, idSeguradora)
+	ctx = context.WithValue(ctx, middleware.AdminERPKey, false)
+	ctx = context.WithValue(ctx, middleware.TenantScopeKey, models.TenantScope{IdSeguradora: idSeguradora})
+	return r.WithContext(ctx)
+}
+
+// serve executa a requisição no handler e retorna a resposta gravada
+func serve(handler http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+// decode decodifica o corpo JSON da resposta
+func decode(t *testing.T, w *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("erro ao decodificar resposta %q: %v", w.Body.String(), err)
+	}
+}
+
+// countAudit conta as entradas do log de auditoria com a ação e o registro informados
+func (env *testEnv) countAudit(t *testing.T, action, entityType, entityID string) int64 {
+	t.Helper()
+
+	opts := models.ListOptions{Page: 1, PageSize: models.DefaultPageSize}.
+		WithFilter("action", action).
+		WithFilter("entity_type", entityType).
+		WithFilter("entity_id", entityID)
+	page, err := env.stores.AuditLog.GetAll(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("erro ao buscar o log de auditoria: %v", err)
+	}
+	return page.Total
+}
+
+func TestUserHistorico(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewUserHandler(env.stores.Usuarios, env.stores.UnitOfWork, services.NewSessionService(env.stores.RefreshTokens), env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+
+	// Alterar o usuário pela API, gerando a segunda entrada do histórico
+	body := `{"nome":"Maria Souza","email":"maria@exemplo.com.br","login":"maria","idTipoPerfil":1,"idSeguradora":1,"ativo":true}`
+	w := serve(h.HandleUsers, newRequest(http.MethodPut, "/usuarios/1", body, usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT /usuarios/1: status %d, corpo %s", w.Code, w.Body.String())
+	}
+
+	w = serve(h.HandleUsers, newRequest(http.MethodGet, "/usuarios/1/historico", "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /usuarios/1/historico: status %d, corpo %s", w.Code, w.Body.String())
+	}
+
+	var historico models.Page[models.AuditLog]
+	decode(t, w, &historico)
+	if historico.Total != 2 || len(historico.Items) != 2 {
+		t.Fatalf("histórico com %d entradas (total %d), esperadas 2", len(historico.Items), historico.Total)
+	}
+	acoes := map[string]bool{}
+	for _, entry := range historico.Items {
+		acoes[entry.Action] = true
+		if entry.EntityType != models.EntidadeUsuario || entry.EntityID != "1" {
+			t.Errorf("entrada de outro registro no histórico: %s %s", entry.EntityType, entry.EntityID)
+		}
+	}
+	if !acoes[models.AcaoCriacao] || !acoes[models.AcaoAlteracao] {
+		t.Errorf("ações do histórico %v, esperadas criação e alteração", acoes)
+	}
+
+	// O histórico de um usuário de outra seguradora não é visível
+	w = serve(h.HandleUsers, newRequest(http.MethodGet, "/usuarios/1/historico", "", usuario.ID, env.seguradoraB))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("histórico de outra seguradora: status %d, esperado 404", w.Code)
+	}
+
+	// Somente GET é permitido no histórico
+	w = serve(h.HandleUsers, newRequest(http.MethodPost, "/usuarios/1/historico", "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /usuarios/1/historico: status %d, esperado 405", w.Code)
+	}
+}
+
+func TestUpdateUsuarioSenhaFraca(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewUserHandler(env.stores.Usuarios, env.stores.UnitOfWork, services.NewSessionService(env.stores.RefreshTokens), env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+
+	// Uma senha fora da política é um erro de validação (400), e não um erro interno
+	body := `{"nome":"Maria","email":"maria@exemplo.com.br","login":"maria","senha":"fraca","idTipoPerfil":1,"idSeguradora":1,"ativo":true}`
+	w := serve(h.HandleUsers, newRequest(http.MethodPut, "/usuarios/1", body, usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("PUT /usuarios/1 com senha fraca: status %d, esperado 400, corpo %s", w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "senha") {
+		t.Errorf("resposta sem o campo senha: %s", w.Body.String())
+	}
+}
+
+func TestImportacaoAcimaDoTamanhoMaximo(t *testing.T) {
+	env := newTestEnv(t)
+	eventos := NewEventoHandler(env.stores.Eventos, env.auditService)
+	planoContas := NewPlanoContasHandler(env.stores.PlanoContas, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	contabil := env.createContabil(t, env.seguradoraA)
+
+	// Um arquivo CSV de 6 MB, acima do limite de 5 MB das importações
+	arquivo := "codigo;descricao;natureza;tipo\n" + strings.Repeat("1;Conta;D;A\n", 6<<20/12)
+	var formulario bytes.Buffer
+	mw := multipart.NewWriter(&formulario)
+	parte, _ := mw.CreateFormFile("arquivo", "arquivo.csv")
+	io.WriteString(parte, arquivo)
+	mw.Close()
+
+	for _, tc := range []struct {
+		name        string
+		handler     http.HandlerFunc
+		target      string
+		body        string
+		contentType string
+	}{
+		{"eventos no corpo", eventos.HandleEvento, fmt.Sprintf("/eventos/importar?idSeguradora=%d", env.seguradoraA), arquivo, "text/csv"},
+		{"eventos em formulário", eventos.HandleEvento, fmt.Sprintf("/eventos/importar?idSeguradora=%d", env.seguradoraA), formulario.String(), mw.FormDataContentType()},
+		{"plano de contas no corpo", planoContas.HandlePlanoContas, fmt.Sprintf("/plano-contas/importar?idSeguradora=%d&idSistemaContabil=%d", env.seguradoraA, contabil.sistema.ID), arquivo, "text/csv"},
+		{"plano de contas em formulário", planoContas.HandlePlanoContas, fmt.Sprintf("/plano-contas/importar?idSeguradora=%d&idSistemaContabil=%d", env.seguradoraA, contabil.sistema.ID), formulario.String(), mw.FormDataContentType()},
+	} {
+		r := newRequest(http.MethodPost, tc.target, tc.body, usuario.ID, env.seguradoraA)
+		r.Header.Set("Content-Type", tc.contentType)
+		w := serve(tc.handler, r)
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: status %d, esperado 413, corpo %s", tc.name, w.Code, w.Body.String())
+		}
+	}
+}
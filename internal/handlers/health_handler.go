@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/database"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/security"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// Tempo máximo da verificação de conexão com o banco na prontidão
+const readinessPingTimeout = 2 * time.Second
+
+// Resultado das verificações de prontidão
+const (
+	checkOK     = "ok"
+	statusReady = "ready"
+	statusFail  = "unavailable"
+)
+
+// ReadinessResponse representa o resultado da verificação de prontidão
+type ReadinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"` // Verificação -> "ok" ou motivo da falha
+}
+
+// StatusResponse representa a situação detalhada da aplicação
+type StatusResponse struct {
+	Version       string                     `json:"version"`
+	GoVersion     string                     `json:"go_version"`
+	StartedAt     time.Time                  `json:"started_at"`
+	Uptime        string                     `json:"uptime"`
+	UptimeSeconds int64                      `json:"uptime_seconds"`
+	Goroutines    int                        `json:"goroutines"`
+	Database      DatabasePoolStatus         `json:"database"`
+	Migrations    MigrationsStatus           `json:"migrations"`
+	RateLimit     RateLimitStatus            `json:"rate_limit"`
+	CSRFTokens    int                        `json:"csrf_tokens"`
+	AuditWriter   *services.AuditWriterStats `json:"audit_writer,omitempty"` // Ausente com a gravação direta
+}
+
+// DatabasePoolStatus representa as estatísticas do pool de conexões com o banco
+type DatabasePoolStatus struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDurationMs     int64  `json:"wait_duration_ms"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+	Error              string `json:"error,omitempty"` // Falha na verificação de conexão
+}
+
+// MigrationsStatus representa a versão do esquema do banco
+type MigrationsStatus struct {
+	Current int    `json:"current"`
+	Latest  int    `json:"latest"`
+	Pending []int  `json:"pending"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RateLimitStatus representa o armazenamento da limitação de taxa
+type RateLimitStatus struct {
+	Store    string `json:"store"`
+	Counters int64  `json:"counters"`
+	Blocks   int64  `json:"blocks"`
+	Error    string `json:"error,omitempty"`
+}
+
+// HealthHandler gerencia as verificações de vida e de prontidão usadas pelo orquestrador
+// e a situação detalhada da aplicação, restrita a administradores
+type HealthHandler struct {
+	db             *sql.DB
+	migrator       *database.Migrator
+	rateLimitStore security.RateLimitStore
+	csrf           *security.CSRFProtection
+	auditService   *services.AuditService
+	version        string
+	startedAt      time.Time
+}
+
+// NewHealthHandler cria um novo handler de verificações de saúde
+func NewHealthHandler(db *sql.DB, version string, rateLimitStore security.RateLimitStore, csrf *security.CSRFProtection, auditService *services.AuditService) (*HealthHandler, error) {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+
+	return &HealthHandler{
+		db:             db,
+		migrator:       migrator,
+		rateLimitStore: rateLimitStore,
+		csrf:           csrf,
+		auditService:   auditService,
+		version:        version,
+		startedAt:      time.Now(),
+	}, nil
+}
+
+// HandleHealthz indica que o processo está no ar, sem verificar dependências
+func (h *HealthHandler) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(map[string]string{"status": checkOK})
+}
+
+// HandleReadyz indica se a aplicação pode receber requisições: banco acessível, migrações
+// aplicadas e chaves de assinatura carregadas. Responde 503 se alguma verificação falhar, com um
+// motivo fixo por verificação; o erro do banco é registrado somente no log.
+func (h *HealthHandler) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	response := ReadinessResponse{Status: statusReady, Checks: make(map[string]string)}
+	fail := func(check, reason string) {
+		response.Status = statusFail
+		response.Checks[check] = reason
+	}
+
+	// Conexão com o banco de dados
+	ctx, cancel := context.WithTimeout(r.Context(), readinessPingTimeout)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		slog.ErrorContext(r.Context(), "Erro ao verificar a conexão com o banco de dados na prontidão", "error", err)
+		fail("database", "banco de dados indisponível")
+	} else {
+		response.Checks["database"] = checkOK
+	}
+
+	// Migrações (somente com o banco acessível)
+	if response.Checks["database"] == checkOK {
+		if _, pending, err := h.migrator.Pending(ctx); err != nil {
+			slog.ErrorContext(r.Context(), "Erro ao consultar as migrações na prontidão", "error", err)
+			fail("migrations", "erro ao consultar as migrações aplicadas")
+		} else if len(pending) > 0 {
+			fail("migrations", fmt.Sprintf("migrações pendentes: %v", pending))
+		} else {
+			response.Checks["migrations"] = checkOK
+		}
+	} else {
+		fail("migrations", "não verificado: banco de dados indisponível")
+	}
+
+	// Chaves de assinatura dos tokens
+	if auth.KeysLoaded() {
+		response.Checks["jwt_keys"] = checkOK
+	} else {
+		fail("jwt_keys", "chaves JWT não carregadas")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if response.Status != statusReady {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleStatus retorna a situação detalhada da aplicação (somente administradores do ERP)
+func (h *HealthHandler) HandleStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	// Somente administradores do ERP podem consultar a situação da aplicação
+	if adminERP, _ := middleware.GetAdminERPFromContext(r.Context()); !adminERP {
+		_ = h.auditService.LogAction(
+			r.Context(),
+			r,
+			"PERMISSION_DENIED",
+			"STATUS",
+			"",
+			fmt.Sprintf("Tentativa de %s em %s sem ser administrador", r.Method, r.URL.Path),
+		)
+		problem.Write(w, r, http.StatusForbidden, "Acesso restrito a administradores")
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	uptime := time.Since(h.startedAt)
+	status := StatusResponse{
+		Version:       h.version,
+		GoVersion:     runtime.Version(),
+		StartedAt:     h.startedAt,
+		Uptime:        uptime.Truncate(time.Second).String(),
+		UptimeSeconds: int64(uptime.Seconds()),
+		Goroutines:    runtime.NumGoroutine(),
+		CSRFTokens:    h.csrf.Size(),
+		AuditWriter:   h.auditService.WriterStats(),
+	}
+
+	// Pool de conexões com o banco de dados
+	stats := h.db.Stats()
+	status.Database = DatabasePoolStatus{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), readinessPingTimeout)
+	defer cancel()
+	if err := h.db.PingContext(ctx); err != nil {
+		status.Database.Error = err.Error()
+	}
+
+	// Versão do esquema
+	status.Migrations.Latest = h.migrator.LatestVersion()
+	current, pending, err := h.migrator.Pending(ctx)
+	if err != nil {
+		status.Migrations.Error = err.Error()
+	}
+	status.Migrations.Current = current
+	status.Migrations.Pending = pending
+	if status.Migrations.Pending == nil {
+		status.Migrations.Pending = []int{}
+	}
+
+	// Armazenamento da limitação de taxa
+	status.RateLimit.Store = "memory"
+	if _, ok := h.rateLimitStore.(*security.MySQLRateLimitStore); ok {
+		status.RateLimit.Store = "database"
+	}
+	counters, blocks, err := h.rateLimitStore.Size()
+	if err != nil {
+		status.RateLimit.Error = err.Error()
+	}
+	status.RateLimit.Counters = counters
+	status.RateLimit.Blocks = blocks
+
+	json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/database"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+func TestReadyzSemDetalhesDoErro(t *testing.T) {
+	env := newTestEnv(t)
+	ks, err := auth.NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("erro ao gerar chaves: %v", err)
+	}
+	auth.SetKeySet(ks)
+
+	anterior := dialect.Current()
+	dialect.Set(dialect.SQLite)
+	t.Cleanup(func() { dialect.Set(anterior) })
+	db, err := database.Connect(dialect.SQLite, "file:"+filepath.Join(t.TempDir(), "teste.db"))
+	if err != nil {
+		t.Fatalf("erro ao conectar ao SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	h, err := NewHealthHandler(db, "teste", nil, nil, env.auditService)
+	if err != nil {
+		t.Fatalf("erro ao criar handler: %v", err)
+	}
+
+	readyz := func() ReadinessResponse {
+		t.Helper()
+		w := serve(h.HandleReadyz, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("GET /readyz: status %d, esperado 503", w.Code)
+		}
+		if strings.Contains(w.Body.String(), "schema_migrations") || strings.Contains(w.Body.String(), "closed") {
+			t.Errorf("resposta com detalhes do erro: %s", w.Body.String())
+		}
+		var response ReadinessResponse
+		decode(t, w, &response)
+		return response
+	}
+
+	// Sem a tabela de controle, a consulta às migrações falha e o motivo é fixo
+	response := readyz()
+	if response.Checks["database"] != checkOK || response.Checks["migrations"] != "erro ao consultar as migrações aplicadas" {
+		t.Errorf("verificações = %v", response.Checks)
+	}
+
+	// Com o banco indisponível, o erro da conexão não é exposto
+	db.Close()
+	response = readyz()
+	if response.Checks["database"] != "banco de dados indisponível" || response.Checks["jwt_keys"] != checkOK {
+		t.Errorf("verificações = %v", response.Checks)
+	}
+}
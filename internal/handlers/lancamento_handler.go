@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// LancamentoHandler gerencia requisições relacionadas a lançamentos contábeis
+type LancamentoHandler struct {
+	repo              models.LancamentoStore
+	lancamentoService *services.LancamentoService
+	auditService      *services.AuditService
+}
+
+// NewLancamentoHandler cria um novo handler de lançamentos contábeis
+func NewLancamentoHandler(repo models.LancamentoStore, lancamentoService *services.LancamentoService, auditService *services.AuditService) *LancamentoHandler {
+	return &LancamentoHandler{
+		repo:              repo,
+		lancamentoService: lancamentoService,
+		auditService:      auditService,
+	}
+}
+
+// tenantRepo retorna o repositório restrito à seguradora da requisição
+func (h *LancamentoHandler) tenantRepo(r *http.Request) models.LancamentoStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context()))
+}
+
+// HandleLancamento gerencia todas as requisições relacionadas a lançamentos contábeis
+func (h *LancamentoHandler) HandleLancamento(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	parts := strings.Split(r.URL.Path, "/")
+
+	// Consulta de um lote: /lancamentos/lotes/{id}
+	if len(parts) > 3 && parts[1] == "lancamentos" && parts[2] == "lotes" && parts[3] != "" {
+		id, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID de lote inválido")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			h.getLote(w, r, id)
+			return
+		}
+
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Consulta de um lançamento: /lancamentos/{id}
+	if len(parts) > 2 && parts[1] == "lancamentos" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			h.getLancamentoByID(w, r, id)
+			return
+		}
+
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Listagem e geração de lançamentos: /lancamentos
+	switch r.Method {
+	case http.MethodGet:
+		h.getLancamentos(w, r)
+	case http.MethodPost:
+		h.gerarLancamentos(w, r)
+	default:
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+	}
+}
+
+// gerarLancamentos gera os lançamentos contábeis das transações de negócio informadas
+func (h *LancamentoHandler) gerarLancamentos(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Transacoes []models.TransacaoNegocio `json:"transacoes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(r.Context())
+	scope := middleware.GetTenantScopeFromContext(r.Context())
+
+	resultado, err := h.lancamentoService.Gerar(r.Context(), scope, userID, request.Transacoes)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrLoteVazio), errors.Is(err, services.ErrLoteExcedido):
+			problem.Write(w, r, http.StatusBadRequest, err.Error())
+		case errors.Is(err, models.ErrCrossTenant):
+			denyCrossTenant(w, r, h.auditService, "LANCAMENTO", "")
+		default:
+			problem.Error(w, r, err, "Erro ao gerar lançamentos")
+		}
+		return
+	}
+
+	// Se nenhuma transação foi aceita, nenhum lote é gravado e a requisição não é processável
+	if resultado.Lote == nil {
+		// Registrar na auditoria
+		_ = h.auditService.LogAction(
+			r.Context(),
+			r,
+			"GENERATE",
+			"LOTE_LANCAMENTO",
+			"",
+			fmt.Sprintf("Nenhuma das %d transações foi aceita", len(request.Transacoes)),
+		)
+
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(resultado)
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"GENERATE",
+		"LOTE_LANCAMENTO",
+		fmt.Sprintf("%d", resultado.Lote.ID),
+		fmt.Sprintf("Gerados %d lançamentos de %d transações (%d rejeitadas)",
+			len(resultado.Lancamentos), resultado.Lote.TotalTransacoes, resultado.Lote.TotalRejeitadas),
+	)
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resultado)
+}
+
+// getLote retorna um lote com os seus lançamentos
+func (h *LancamentoHandler) getLote(w http.ResponseWriter, r *http.Request, id int64) {
+	repo := h.tenantRepo(r)
+
+	lote, err := repo.GetLoteByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar lote de lançamentos")
+		return
+	}
+
+	lancamentos, err := repo.GetByLote(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar lançamentos do lote")
+		return
+	}
+	if lancamentos == nil {
+		lancamentos = []models.Lancamento{}
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"READ",
+		"LOTE_LANCAMENTO",
+		fmt.Sprintf("%d", id),
+		"Consulta de lote de lançamentos",
+	)
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"lote":        lote,
+		"lancamentos": lancamentos,
+	})
+}
+
+// getLancamentos retorna uma página de lançamentos, com ordenação e filtros
+func (h *LancamentoHandler) getLancamentos(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	lancamentos, err := h.tenantRepo(r).GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar lançamentos")
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"LIST",
+		"LANCAMENTOS",
+		"",
+		fmt.Sprintf("Listados %d lançamentos", len(lancamentos.Items)),
+	)
+
+	json.NewEncoder(w).Encode(lancamentos)
+}
+
+// getLancamentoByID retorna um lançamento específico pelo ID
+func (h *LancamentoHandler) getLancamentoByID(w http.ResponseWriter, r *http.Request, id int64) {
+	lancamento, err := h.tenantRepo(r).GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar lançamento")
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"READ",
+		"LANCAMENTO",
+		fmt.Sprintf("%d", id),
+		"Consulta de lançamento",
+	)
+
+	json.NewEncoder(w).Encode(lancamento)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// newLancamentoHandler cria o handler de lançamentos sobre os armazenamentos em memória
+func newLancamentoHandler(env *testEnv) *LancamentoHandler {
+	stores := env.stores
+	lancamentoService := services.NewLancamentoService(stores.Eventos, stores.ObjetosContabilizacao, stores.SistemasContabeisConfig, stores.Lancamentos)
+	return NewLancamentoHandler(stores.Lancamentos, lancamentoService, env.auditService)
+}
+
+func TestGetLoteRestritoASeguradora(t *testing.T) {
+	env := newTestEnv(t)
+	env.createContabil(t, env.seguradoraA)
+	h := newLancamentoHandler(env)
+
+	body := `{"transacoes":[{"evento":101,"objetoContabilizacao":"PREMIO","valor":150.75,"dataMovimento":"2024-01-31","documentoReferencia":"APOLICE-1"}]}`
+	w := serve(h.HandleLancamento, newRequest(http.MethodPost, "/lancamentos", body, 1, env.seguradoraA))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /lancamentos: status %d, corpo %s", w.Code, w.Body.String())
+	}
+	var resultado services.ResultadoGeracao
+	decode(t, w, &resultado)
+	if resultado.Lote == nil {
+		t.Fatalf("lote ausente na resposta")
+	}
+	if resultado.Lote.IdSeguradora != env.seguradoraA {
+		t.Errorf("lote da seguradora %d, esperada %d", resultado.Lote.IdSeguradora, env.seguradoraA)
+	}
+
+	path := fmt.Sprintf("/lancamentos/lotes/%d", resultado.Lote.ID)
+	w = serve(h.HandleLancamento, newRequest(http.MethodGet, path, "", 1, env.seguradoraA))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, corpo %s", path, w.Code, w.Body.String())
+	}
+
+	// O lote de outra seguradora não é visível
+	w = serve(h.HandleLancamento, newRequest(http.MethodGet, path, "", 2, env.seguradoraB))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("GET %s de outra seguradora: status %d, esperado 404", path, w.Code)
+	}
+}
+
+func TestGerarLancamentosSemTransacoesAceitas(t *testing.T) {
+	env := newTestEnv(t)
+	env.createContabil(t, env.seguradoraA)
+	h := newLancamentoHandler(env)
+
+	body := `{"transacoes":[{"evento":999,"objetoContabilizacao":"PREMIO","valor":10,"dataMovimento":"2024-01-31","documentoReferencia":"APOLICE-1"}]}`
+	w := serve(h.HandleLancamento, newRequest(http.MethodPost, "/lancamentos", body, 1, env.seguradoraA))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("POST /lancamentos: status %d, esperado 422", w.Code)
+	}
+	var resultado services.ResultadoGeracao
+	decode(t, w, &resultado)
+	if resultado.Lote != nil || len(resultado.Rejeitadas) != 1 {
+		t.Errorf("resultado com lote %v e %d rejeitadas, esperado sem lote e 1 rejeitada", resultado.Lote, len(resultado.Rejeitadas))
+	}
+
+	// Nenhum lote foi gravado
+	_, err := env.stores.Lancamentos.GetLoteByID(context.Background(), 1)
+	if !errors.Is(err, models.ErrNotFound) {
+		t.Errorf("lote gravado sem transações aceitas (erro %v)", err)
+	}
+}
+
+func TestListarLancamentosDaSeguradora(t *testing.T) {
+	env := newTestEnv(t)
+	env.createContabil(t, env.seguradoraA)
+	h := newLancamentoHandler(env)
+
+	body := `{"transacoes":[{"evento":101,"objetoContabilizacao":"PREMIO","valor":150.75,"dataMovimento":"2024-01-31","documentoReferencia":"APOLICE-1"}]}`
+	w := serve(h.HandleLancamento, newRequest(http.MethodPost, "/lancamentos", body, 1, env.seguradoraA))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /lancamentos: status %d, corpo %s", w.Code, w.Body.String())
+	}
+
+	for _, tc := range []struct {
+		idSeguradora int64
+		total        int64
+	}{
+		{env.seguradoraA, 1},
+		{env.seguradoraB, 0},
+	} {
+		w = serve(h.HandleLancamento, newRequest(http.MethodGet, "/lancamentos?documentoReferencia~=APOLICE", "", 1, tc.idSeguradora))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /lancamentos: status %d, corpo %s", w.Code, w.Body.String())
+		}
+		var page models.Page[models.Lancamento]
+		decode(t, w, &page)
+		if page.Total != tc.total || int64(len(page.Items)) != tc.total {
+			t.Errorf("seguradora %d: total %d com %d itens, esperado %d", tc.idSeguradora, page.Total, len(page.Items), tc.total)
+		}
+	}
+
+	// Campo de filtro inválido
+	w = serve(h.HandleLancamento, newRequest(http.MethodGet, "/lancamentos?valor=10", "", 1, env.seguradoraA))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("GET /lancamentos?valor=10: status %d, esperado 400", w.Code)
+	}
+}
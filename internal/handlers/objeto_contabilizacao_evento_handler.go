@@ -1,71 +1,90 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // ObjetoContabilizacaoEventoHandler gerencia requisições relacionadas a relações entre objetos de contabilização e eventos
 type ObjetoContabilizacaoEventoHandler struct {
-	repo         *models.ObjetoContabilizacaoEventoRepository
+	repo         models.ObjetoContabilizacaoEventoStore
 	auditService *services.AuditService
 }
 
 // NewObjetoContabilizacaoEventoHandler cria um novo handler de relações entre objetos de contabilização e eventos
-func NewObjetoContabilizacaoEventoHandler(db *sql.DB) *ObjetoContabilizacaoEventoHandler {
+func NewObjetoContabilizacaoEventoHandler(repo models.ObjetoContabilizacaoEventoStore, auditService *services.AuditService) *ObjetoContabilizacaoEventoHandler {
 	return &ObjetoContabilizacaoEventoHandler{
-		repo:         models.NewObjetoContabilizacaoEventoRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *ObjetoContabilizacaoEventoHandler) tenantRepo(r *http.Request) models.ObjetoContabilizacaoEventoStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleObjetoContabilizacaoEvento gerencia todas as requisições relacionadas a relações entre objetos de contabilização e eventos
 func (h *ObjetoContabilizacaoEventoHandler) HandleObjetoContabilizacaoEvento(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Verificar se há um ID na URL para operações específicas
 	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) > 2 && parts[1] == "objetos-contabilizacao-eventos" && parts[2] != "" {
-		id, err := strconv.ParseInt(parts[2], 10, 64)
+
+	// Verificar se há um parâmetro de seguradora na URL
+	if len(parts) > 3 && parts[1] == "objetos-contabilizacao-eventos" && parts[2] == "seguradora" && parts[3] != "" {
+		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
 		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID de seguradora inválido")
 			return
 		}
 
-		switch r.Method {
-		case http.MethodGet:
-			h.getObjetoContabilizacaoEventoByID(w, r, id)
-		case http.MethodPut:
-			h.updateObjetoContabilizacaoEvento(w, r, id)
-		case http.MethodDelete:
-			h.deleteObjetoContabilizacaoEvento(w, r, id)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		if r.Method == http.MethodGet {
+			h.getObjetosContabilizacaoEventosBySeguradora(w, r, idSeguradora)
+			return
 		}
+
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
-	// Verificar se há um parâmetro de seguradora na URL
-	if len(parts) > 3 && parts[1] == "objetos-contabilizacao-eventos" && parts[2] == "seguradora" && parts[3] != "" {
-		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
+	// Verificar se há um ID na URL para operações específicas
+	if len(parts) > 2 && parts[1] == "objetos-contabilizacao-eventos" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
 		if err != nil {
-			http.Error(w, "ID de seguradora inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
 			return
 		}
 
-		if r.Method == http.MethodGet {
-			h.getObjetosContabilizacaoEventosBySeguradora(w, r, idSeguradora)
+		// Histórico de alterações: /objetos-contabilizacao-eventos/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getObjetoContabilizacaoEventoHistorico(w, r, id)
 			return
 		}
 
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		switch r.Method {
+		case http.MethodGet:
+			h.getObjetoContabilizacaoEventoByID(w, r, id)
+		case http.MethodPut:
+			h.updateObjetoContabilizacaoEvento(w, r, id)
+		case http.MethodDelete:
+			h.deleteObjetoContabilizacaoEvento(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
 		return
 	}
 
@@ -76,15 +95,21 @@ func (h *ObjetoContabilizacaoEventoHandler) HandleObjetoContabilizacaoEvento(w h
 	case http.MethodPost:
 		h.createObjetoContabilizacaoEvento(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getObjetosContabilizacaoEventos retorna todas as relações entre objetos de contabilização e eventos
+// getObjetosContabilizacaoEventos retorna uma página de relações entre objetos de contabilização e eventos, com ordenação e filtros
 func (h *ObjetoContabilizacaoEventoHandler) getObjetosContabilizacaoEventos(w http.ResponseWriter, r *http.Request) {
-	relacoes, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar relações: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	relacoes, err := h.tenantRepo(r).GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar relações")
 		return
 	}
 
@@ -95,7 +120,7 @@ func (h *ObjetoContabilizacaoEventoHandler) getObjetosContabilizacaoEventos(w ht
 		"LIST",
 		"OBJETOS_CONTABILIZACAO_EVENTOS",
 		"",
-		fmt.Sprintf("Listadas %d relações entre objetos de contabilização e eventos", len(relacoes)),
+		fmt.Sprintf("Listadas %d relações entre objetos de contabilização e eventos", len(relacoes.Items)),
 	)
 
 	json.NewEncoder(w).Encode(relacoes)
@@ -103,13 +128,9 @@ func (h *ObjetoContabilizacaoEventoHandler) getObjetosContabilizacaoEventos(w ht
 
 // getObjetoContabilizacaoEventoByID retorna uma relação específica pelo ID
 func (h *ObjetoContabilizacaoEventoHandler) getObjetoContabilizacaoEventoByID(w http.ResponseWriter, r *http.Request, id int64) {
-	relacao, err := h.repo.GetByID(id)
+	relacao, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar relação")
 		return
 	}
 
@@ -128,9 +149,19 @@ func (h *ObjetoContabilizacaoEventoHandler) getObjetoContabilizacaoEventoByID(w
 
 // getObjetosContabilizacaoEventosBySeguradora retorna relações de uma seguradora específica
 func (h *ObjetoContabilizacaoEventoHandler) getObjetosContabilizacaoEventosBySeguradora(w http.ResponseWriter, r *http.Request, idSeguradora int64) {
-	relacoes, err := h.repo.GetBySeguradora(idSeguradora)
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar relações por seguradora: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	relacoes, err := h.tenantRepo(r).GetBySeguradora(r.Context(), idSeguradora, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO_EVENTO", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao buscar relações por seguradora")
 		return
 	}
 
@@ -141,7 +172,7 @@ func (h *ObjetoContabilizacaoEventoHandler) getObjetosContabilizacaoEventosBySeg
 		"LIST",
 		"OBJETOS_CONTABILIZACAO_EVENTOS",
 		fmt.Sprintf("seguradora/%d", idSeguradora),
-		fmt.Sprintf("Listadas %d relações da seguradora %d", len(relacoes), idSeguradora),
+		fmt.Sprintf("Listadas %d relações da seguradora %d", len(relacoes.Items), idSeguradora),
 	)
 
 	json.NewEncoder(w).Encode(relacoes)
@@ -151,13 +182,13 @@ func (h *ObjetoContabilizacaoEventoHandler) getObjetosContabilizacaoEventosBySeg
 func (h *ObjetoContabilizacaoEventoHandler) createObjetoContabilizacaoEvento(w http.ResponseWriter, r *http.Request) {
 	var relacao models.ObjetoContabilizacaoEvento
 	if err := json.NewDecoder(r.Body).Decode(&relacao); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if relacao.IdObjetoContabilizacao <= 0 || relacao.IdCodigoEvento <= 0 || relacao.IdSeguradora <= 0 {
-		http.Error(w, "ID do objeto de contabilização, ID do evento e ID da seguradora são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "ID do objeto de contabilização, ID do evento e ID da seguradora são obrigatórios")
 		return
 	}
 
@@ -166,42 +197,25 @@ func (h *ObjetoContabilizacaoEventoHandler) createObjetoContabilizacaoEvento(w h
 		relacao.Ativo = true
 	}
 
-	if err := h.repo.Create(&relacao); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar relação: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &relacao); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO_EVENTO", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar relação")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"CREATE",
-		"OBJETO_CONTABILIZACAO_EVENTO",
-		fmt.Sprintf("%d", relacao.ID),
-		fmt.Sprintf("Criada relação entre objeto de contabilização %d e evento %d", relacao.IdObjetoContabilizacao, relacao.IdCodigoEvento),
-	)
-
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(relacao)
 }
 
 // updateObjetoContabilizacaoEvento atualiza uma relação existente
 func (h *ObjetoContabilizacaoEventoHandler) updateObjetoContabilizacaoEvento(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se a relação existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var relacao models.ObjetoContabilizacaoEvento
 	if err := json.NewDecoder(r.Body).Decode(&relacao); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -209,25 +223,19 @@ func (h *ObjetoContabilizacaoEventoHandler) updateObjetoContabilizacaoEvento(w h
 	relacao.ID = id
 
 	// Atualizar a relação
-	if err := h.repo.Update(&relacao); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar relação: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Update(r.Context(), &relacao); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO_EVENTO", fmt.Sprintf("%d", relacao.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar relação")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"UPDATE",
-		"OBJETO_CONTABILIZACAO_EVENTO",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Atualizada relação entre objeto de contabilização %d e evento %d", relacao.IdObjetoContabilizacao, relacao.IdCodigoEvento),
-	)
-
 	// Buscar a relação atualizada
-	updatedRelacao, err := h.repo.GetByID(id)
+	updatedRelacao, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar relação atualizada: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar relação atualizada")
 		return
 	}
 
@@ -236,33 +244,27 @@ func (h *ObjetoContabilizacaoEventoHandler) updateObjetoContabilizacaoEvento(w h
 
 // deleteObjetoContabilizacaoEvento remove uma relação
 func (h *ObjetoContabilizacaoEventoHandler) deleteObjetoContabilizacaoEvento(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se a relação existe
-	relacao, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Excluir a relação
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO_EVENTO", fmt.Sprintf("%d", id))
+			return
 		}
+		problem.Error(w, r, err, "Erro ao excluir relação")
 		return
 	}
 
-	// Excluir a relação
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir relação: %v", err), http.StatusInternalServerError)
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getObjetoContabilizacaoEventoHistorico retorna o histórico de alterações de uma relação entre objeto de contabilização e evento
+func (h *ObjetoContabilizacaoEventoHandler) getObjetoContabilizacaoEventoHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar relação")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"DELETE",
-		"OBJETO_CONTABILIZACAO_EVENTO",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Desativada relação entre objeto de contabilização %d e evento %d", relacao.IdObjetoContabilizacao, relacao.IdCodigoEvento),
-	)
-
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	writeHistorico(w, r, h.auditService, models.EntidadeObjetoContabilizacaoEvento, id)
 }
@@ -1,54 +1,53 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // ObjetoContabilizacaoHandler gerencia requisições relacionadas a objetos de contabilização
 type ObjetoContabilizacaoHandler struct {
-	repo         *models.ObjetoContabilizacaoRepository
+	repo         models.ObjetoContabilizacaoStore
 	auditService *services.AuditService
 }
 
 // NewObjetoContabilizacaoHandler cria um novo handler de objetos de contabilização
-func NewObjetoContabilizacaoHandler(db *sql.DB) *ObjetoContabilizacaoHandler {
+func NewObjetoContabilizacaoHandler(repo models.ObjetoContabilizacaoStore, auditService *services.AuditService) *ObjetoContabilizacaoHandler {
 	return &ObjetoContabilizacaoHandler{
-		repo:         models.NewObjetoContabilizacaoRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *ObjetoContabilizacaoHandler) tenantRepo(r *http.Request) models.ObjetoContabilizacaoStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleObjetoContabilizacao gerencia todas as requisições relacionadas a objetos de contabilização
 func (h *ObjetoContabilizacaoHandler) HandleObjetoContabilizacao(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Verificar se há um ID na URL para operações específicas
 	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) > 2 && parts[1] == "objetos-contabilizacao" && parts[2] != "" {
-		id, err := strconv.ParseInt(parts[2], 10, 64)
-		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+
+	// Importação em lote de objetos de contabilização em CSV ou XLSX
+	if len(parts) > 2 && parts[1] == "objetos-contabilizacao" && parts[2] == "importar" {
+		if r.Method == http.MethodPost {
+			h.importObjetosContabilizacao(w, r)
 			return
 		}
 
-		switch r.Method {
-		case http.MethodGet:
-			h.getObjetoContabilizacaoByID(w, r, id)
-		case http.MethodPut:
-			h.updateObjetoContabilizacao(w, r, id)
-		case http.MethodDelete:
-			h.deleteObjetoContabilizacao(w, r, id)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
@@ -56,7 +55,7 @@ func (h *ObjetoContabilizacaoHandler) HandleObjetoContabilizacao(w http.Response
 	if len(parts) > 3 && parts[1] == "objetos-contabilizacao" && parts[2] == "seguradora" && parts[3] != "" {
 		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
 		if err != nil {
-			http.Error(w, "ID de seguradora inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID de seguradora inválido")
 			return
 		}
 
@@ -65,7 +64,38 @@ func (h *ObjetoContabilizacaoHandler) HandleObjetoContabilizacao(w http.Response
 			return
 		}
 
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Verificar se há um ID na URL para operações específicas
+	if len(parts) > 2 && parts[1] == "objetos-contabilizacao" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Histórico de alterações: /objetos-contabilizacao/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getObjetoContabilizacaoHistorico(w, r, id)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.getObjetoContabilizacaoByID(w, r, id)
+		case http.MethodPut:
+			h.updateObjetoContabilizacao(w, r, id)
+		case http.MethodDelete:
+			h.deleteObjetoContabilizacao(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
 		return
 	}
 
@@ -76,15 +106,21 @@ func (h *ObjetoContabilizacaoHandler) HandleObjetoContabilizacao(w http.Response
 	case http.MethodPost:
 		h.createObjetoContabilizacao(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getObjetosContabilizacao retorna todos os objetos de contabilização
+// getObjetosContabilizacao retorna uma página de objetos de contabilização, com ordenação e filtros
 func (h *ObjetoContabilizacaoHandler) getObjetosContabilizacao(w http.ResponseWriter, r *http.Request) {
-	objetos, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	objetos, err := h.tenantRepo(r).GetAll(r.Context(), opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar objetos de contabilização: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar objetos de contabilização")
 		return
 	}
 
@@ -95,7 +131,7 @@ func (h *ObjetoContabilizacaoHandler) getObjetosContabilizacao(w http.ResponseWr
 		"LIST",
 		"OBJETOS_CONTABILIZACAO",
 		"",
-		fmt.Sprintf("Listados %d objetos de contabilização", len(objetos)),
+		fmt.Sprintf("Listados %d objetos de contabilização", len(objetos.Items)),
 	)
 
 	json.NewEncoder(w).Encode(objetos)
@@ -103,13 +139,9 @@ func (h *ObjetoContabilizacaoHandler) getObjetosContabilizacao(w http.ResponseWr
 
 // getObjetoContabilizacaoByID retorna um objeto de contabilização específico pelo ID
 func (h *ObjetoContabilizacaoHandler) getObjetoContabilizacaoByID(w http.ResponseWriter, r *http.Request, id int64) {
-	objeto, err := h.repo.GetByID(id)
+	objeto, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar objeto de contabilização")
 		return
 	}
 
@@ -128,9 +160,19 @@ func (h *ObjetoContabilizacaoHandler) getObjetoContabilizacaoByID(w http.Respons
 
 // getObjetosContabilizacaoBySeguradora retorna objetos de contabilização de uma seguradora específica
 func (h *ObjetoContabilizacaoHandler) getObjetosContabilizacaoBySeguradora(w http.ResponseWriter, r *http.Request, idSeguradora int64) {
-	objetos, err := h.repo.GetBySeguradora(idSeguradora)
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar objetos de contabilização por seguradora: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	objetos, err := h.tenantRepo(r).GetBySeguradora(r.Context(), idSeguradora, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao buscar objetos de contabilização por seguradora")
 		return
 	}
 
@@ -141,7 +183,7 @@ func (h *ObjetoContabilizacaoHandler) getObjetosContabilizacaoBySeguradora(w htt
 		"LIST",
 		"OBJETOS_CONTABILIZACAO",
 		fmt.Sprintf("seguradora/%d", idSeguradora),
-		fmt.Sprintf("Listados %d objetos de contabilização da seguradora %d", len(objetos), idSeguradora),
+		fmt.Sprintf("Listados %d objetos de contabilização da seguradora %d", len(objetos.Items), idSeguradora),
 	)
 
 	json.NewEncoder(w).Encode(objetos)
@@ -151,13 +193,13 @@ func (h *ObjetoContabilizacaoHandler) getObjetosContabilizacaoBySeguradora(w htt
 func (h *ObjetoContabilizacaoHandler) createObjetoContabilizacao(w http.ResponseWriter, r *http.Request) {
 	var objeto models.ObjetoContabilizacao
 	if err := json.NewDecoder(r.Body).Decode(&objeto); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if objeto.ObjetoContabilizacao == "" || objeto.Descricao == "" || objeto.IdSeguradora <= 0 {
-		http.Error(w, "Objeto de contabilização, descrição e ID da seguradora são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Objeto de contabilização, descrição e ID da seguradora são obrigatórios")
 		return
 	}
 
@@ -166,42 +208,25 @@ func (h *ObjetoContabilizacaoHandler) createObjetoContabilizacao(w http.Response
 		objeto.Ativo = true
 	}
 
-	if err := h.repo.Create(&objeto); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar objeto de contabilização: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &objeto); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar objeto de contabilização")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"CREATE",
-		"OBJETO_CONTABILIZACAO",
-		fmt.Sprintf("%d", objeto.ID),
-		fmt.Sprintf("Criado objeto de contabilização: %s (%s)", objeto.ObjetoContabilizacao, objeto.Descricao),
-	)
-
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(objeto)
 }
 
 // updateObjetoContabilizacao atualiza um objeto de contabilização existente
 func (h *ObjetoContabilizacaoHandler) updateObjetoContabilizacao(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o objeto existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var objeto models.ObjetoContabilizacao
 	if err := json.NewDecoder(r.Body).Decode(&objeto); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -209,25 +234,19 @@ func (h *ObjetoContabilizacaoHandler) updateObjetoContabilizacao(w http.Response
 	objeto.ID = id
 
 	// Atualizar o objeto
-	if err := h.repo.Update(&objeto); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar objeto de contabilização: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Update(r.Context(), &objeto); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO", fmt.Sprintf("%d", objeto.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar objeto de contabilização")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"UPDATE",
-		"OBJETO_CONTABILIZACAO",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Atualizado objeto de contabilização: %s (%s)", objeto.ObjetoContabilizacao, objeto.Descricao),
-	)
-
 	// Buscar o objeto atualizado
-	updatedObjeto, err := h.repo.GetByID(id)
+	updatedObjeto, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar objeto de contabilização atualizado: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar objeto de contabilização atualizado")
 		return
 	}
 
@@ -236,33 +255,33 @@ func (h *ObjetoContabilizacaoHandler) updateObjetoContabilizacao(w http.Response
 
 // deleteObjetoContabilizacao remove um objeto de contabilização
 func (h *ObjetoContabilizacaoHandler) deleteObjetoContabilizacao(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o objeto existe
-	objeto, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Excluir o objeto
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "OBJETO_CONTABILIZACAO", fmt.Sprintf("%d", id))
+			return
 		}
+		problem.Error(w, r, err, "Erro ao excluir objeto de contabilização")
 		return
 	}
 
-	// Excluir o objeto
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir objeto de contabilização: %v", err), http.StatusInternalServerError)
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importObjetosContabilizacao importa em lote os objetos de contabilização de uma seguradora
+// (colunas objeto_contabilizacao, descricao e ativo, opcional)
+func (h *ObjetoContabilizacaoHandler) importObjetosContabilizacao(w http.ResponseWriter, r *http.Request) {
+	importarLote(w, r, h.auditService, "OBJETOS_CONTABILIZACAO", []string{"objeto_contabilizacao", "descricao"}, h.tenantRepo(r).Import)
+}
+
+// getObjetoContabilizacaoHistorico retorna o histórico de alterações de um objeto de contabilização
+func (h *ObjetoContabilizacaoHandler) getObjetoContabilizacaoHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar objeto de contabilização")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"DELETE",
-		"OBJETO_CONTABILIZACAO",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Desativado objeto de contabilização: %s (%s)", objeto.ObjetoContabilizacao, objeto.Descricao),
-	)
-
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	writeHistorico(w, r, h.auditService, models.EntidadeObjetoContabilizacao, id)
 }
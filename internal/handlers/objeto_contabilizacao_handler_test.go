@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+func TestObjetoContabilizacaoCRUD(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewObjetoContabilizacaoHandler(env.stores.ObjetosContabilizacao, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	request := func(method, target, body string) *http.Request {
+		return newRequest(method, target, body, usuario.ID, env.seguradoraA)
+	}
+
+	// Criar
+	body := fmt.Sprintf(`{"objetoContabilizacao":"PREMIO","descricao":"Prêmio emitido","idSeguradora":%d,"ativo":true}`, env.seguradoraA)
+	w := serve(h.HandleObjetoContabilizacao, request(http.MethodPost, "/objetos-contabilizacao", body))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /objetos-contabilizacao: status %d, corpo %s", w.Code, w.Body.String())
+	}
+	var criado models.ObjetoContabilizacao
+	decode(t, w, &criado)
+	if criado.ID == 0 || criado.ObjetoContabilizacao != "PREMIO" {
+		t.Fatalf("objeto criado inválido: %+v", criado)
+	}
+	target := fmt.Sprintf("/objetos-contabilizacao/%d", criado.ID)
+
+	// Listar e consultar
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodGet, "/objetos-contabilizacao?objetoContabilizacao=PREMIO", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /objetos-contabilizacao: status %d, corpo %s", w.Code, w.Body.String())
+	}
+	var lista models.Page[models.ObjetoContabilizacao]
+	decode(t, w, &lista)
+	if lista.Total != 1 {
+		t.Errorf("listagem com %d objetos, esperado 1", lista.Total)
+	}
+
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodGet, target, ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, corpo %s", target, w.Code, w.Body.String())
+	}
+
+	// Alterar
+	body = fmt.Sprintf(`{"objetoContabilizacao":"PREMIO","descricao":"Prêmio líquido","idSeguradora":%d,"ativo":true}`, env.seguradoraA)
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodPut, target, body))
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT %s: status %d, corpo %s", target, w.Code, w.Body.String())
+	}
+	var alterado models.ObjetoContabilizacao
+	decode(t, w, &alterado)
+	if alterado.Descricao != "Prêmio líquido" {
+		t.Errorf("descrição alterada para %q", alterado.Descricao)
+	}
+
+	// Dados inválidos
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodPut, target, `{"objetoContabilizacao":"","idSeguradora":1}`))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("PUT %s com dados inválidos: status %d, esperado 400", target, w.Code)
+	}
+
+	// Excluir (desativação) e consultar o histórico
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodDelete, target, ""))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("DELETE %s: status %d, corpo %s", target, w.Code, w.Body.String())
+	}
+
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodGet, target+"/historico", ""))
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s/historico: status %d, corpo %s", target, w.Code, w.Body.String())
+	}
+	var historico models.Page[models.AuditLog]
+	decode(t, w, &historico)
+	if historico.Total != 3 {
+		t.Errorf("histórico com %d entradas, esperadas 3 (criação, alteração e exclusão)", historico.Total)
+	}
+
+	// Criar em outra seguradora é recusado e auditado
+	body = fmt.Sprintf(`{"objetoContabilizacao":"SINISTRO","descricao":"Sinistro avisado","idSeguradora":%d,"ativo":true}`, env.seguradoraB)
+	w = serve(h.HandleObjetoContabilizacao, request(http.MethodPost, "/objetos-contabilizacao", body))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("POST em outra seguradora: status %d, esperado 403", w.Code)
+	}
+}
@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
+)
+
+// Tamanho máximo do arquivo de importação do plano de contas (5 MB)
+const maxImportacaoPlanoContas = 5 << 20
+
+// PlanoContasHandler gerencia requisições relacionadas ao plano de contas
+type PlanoContasHandler struct {
+	repo         models.PlanoContasStore
+	auditService *services.AuditService
+}
+
+// NewPlanoContasHandler cria um novo handler do plano de contas
+func NewPlanoContasHandler(repo models.PlanoContasStore, auditService *services.AuditService) *PlanoContasHandler {
+	return &PlanoContasHandler{
+		repo:         repo,
+		auditService: auditService,
+	}
+}
+
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *PlanoContasHandler) tenantRepo(r *http.Request) models.PlanoContasStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
+// HandlePlanoContas gerencia todas as requisições relacionadas ao plano de contas
+func (h *PlanoContasHandler) HandlePlanoContas(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	parts := strings.Split(r.URL.Path, "/")
+
+	// Importação de um plano de contas em CSV
+	if len(parts) > 2 && parts[1] == "plano-contas" && parts[2] == "importar" {
+		if r.Method == http.MethodPost {
+			h.importPlanoContas(w, r)
+			return
+		}
+
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Verificar se há um parâmetro de sistema contábil na URL
+	if len(parts) > 3 && parts[1] == "plano-contas" && parts[2] == "sistema" && parts[3] != "" {
+		idSistemaContabil, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID de sistema contábil inválido")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			h.getContasBySistemaContabil(w, r, idSistemaContabil)
+			return
+		}
+
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Verificar se há um ID na URL para operações específicas
+	if len(parts) > 2 && parts[1] == "plano-contas" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Histórico de alterações: /plano-contas/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getContaHistorico(w, r, id)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.getContaByID(w, r, id)
+		case http.MethodPut:
+			h.updateConta(w, r, id)
+		case http.MethodDelete:
+			h.deleteConta(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
+		return
+	}
+
+	// Operações que não requerem ID específico
+	switch r.Method {
+	case http.MethodGet:
+		h.getContas(w, r)
+	case http.MethodPost:
+		h.createConta(w, r)
+	default:
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+	}
+}
+
+// getContas retorna uma página de contas do plano de contas, com ordenação e filtros
+func (h *PlanoContasHandler) getContas(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	contas, err := h.tenantRepo(r).GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar plano de contas")
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"LIST",
+		"PLANO_CONTAS",
+		"",
+		fmt.Sprintf("Listadas %d contas", len(contas.Items)),
+	)
+
+	json.NewEncoder(w).Encode(contas)
+}
+
+// getContasBySistemaContabil retorna o plano de contas de um sistema contábil
+func (h *PlanoContasHandler) getContasBySistemaContabil(w http.ResponseWriter, r *http.Request, idSistemaContabil int64) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	contas, err := h.tenantRepo(r).GetBySistemaContabil(r.Context(), idSistemaContabil, opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar plano de contas do sistema contábil")
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"LIST",
+		"PLANO_CONTAS",
+		fmt.Sprintf("sistema/%d", idSistemaContabil),
+		fmt.Sprintf("Listadas %d contas do sistema contábil %d", len(contas.Items), idSistemaContabil),
+	)
+
+	json.NewEncoder(w).Encode(contas)
+}
+
+// getContaByID retorna uma conta específica pelo ID
+func (h *PlanoContasHandler) getContaByID(w http.ResponseWriter, r *http.Request, id int64) {
+	conta, err := h.tenantRepo(r).GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar conta")
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"READ",
+		"CONTA_CONTABIL",
+		fmt.Sprintf("%d", id),
+		"Consulta de conta do plano de contas",
+	)
+
+	json.NewEncoder(w).Encode(conta)
+}
+
+// createConta cria uma nova conta no plano de contas
+func (h *PlanoContasHandler) createConta(w http.ResponseWriter, r *http.Request) {
+	var conta models.ContaContabil
+	if err := json.NewDecoder(r.Body).Decode(&conta); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	// Por padrão, se não for especificado, a conta é ativa
+	if !conta.Ativo {
+		conta.Ativo = true
+	}
+
+	if err := h.tenantRepo(r).Create(r.Context(), &conta); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "CONTA_CONTABIL", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar conta")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(conta)
+}
+
+// updateConta atualiza uma conta existente
+func (h *PlanoContasHandler) updateConta(w http.ResponseWriter, r *http.Request, id int64) {
+	// Decodificar os dados da requisição
+	var conta models.ContaContabil
+	if err := json.NewDecoder(r.Body).Decode(&conta); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	// Garantir que o ID seja o mesmo
+	conta.ID = id
+
+	// Atualizar a conta
+	if err := h.tenantRepo(r).Update(r.Context(), &conta); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "CONTA_CONTABIL", fmt.Sprintf("%d", conta.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar conta")
+		return
+	}
+
+	// Buscar a conta atualizada
+	updatedConta, err := h.tenantRepo(r).GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar conta atualizada")
+		return
+	}
+
+	json.NewEncoder(w).Encode(updatedConta)
+}
+
+// deleteConta desativa uma conta do plano de contas
+func (h *PlanoContasHandler) deleteConta(w http.ResponseWriter, r *http.Request, id int64) {
+	// Excluir a conta
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "CONTA_CONTABIL", fmt.Sprintf("%d", id))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao excluir conta")
+		return
+	}
+
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// importPlanoContas importa um plano de contas em CSV para uma seguradora e sistema contábil.
+// O arquivo pode ser enviado no corpo da requisição ou no campo "arquivo" de um formulário multipart.
+func (h *PlanoContasHandler) importPlanoContas(w http.ResponseWriter, r *http.Request) {
+	idSeguradora, err := strconv.ParseInt(r.URL.Query().Get("idSeguradora"), 10, 64)
+	if err != nil || idSeguradora <= 0 {
+		problem.Write(w, r, http.StatusBadRequest, "Parâmetro idSeguradora inválido")
+		return
+	}
+	idSistemaContabil, err := strconv.ParseInt(r.URL.Query().Get("idSistemaContabil"), 10, 64)
+	if err != nil || idSistemaContabil <= 0 {
+		problem.Write(w, r, http.StatusBadRequest, "Parâmetro idSistemaContabil inválido")
+		return
+	}
+
+	// Obter o arquivo CSV
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportacaoPlanoContas)
+	var arquivo io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("arquivo")
+		if err != nil {
+			if arquivoExcedido(w, r, err) {
+				return
+			}
+			problem.Write(w, r, http.StatusBadRequest, "Arquivo não informado no campo 'arquivo'")
+			return
+		}
+		defer file.Close()
+		arquivo = file
+	}
+
+	linhas, errosLeitura, err := models.ParsePlanoContasCSV(arquivo)
+	if err != nil {
+		if arquivoExcedido(w, r, err) {
+			return
+		}
+		problem.Write(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Linhas com estrutura inválida cancelam a importação antes de qualquer gravação
+	if len(errosLeitura) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(models.ResultadoImportacaoPlanoContas{Erros: errosLeitura})
+		return
+	}
+
+	resultado, err := h.tenantRepo(r).Import(r.Context(), idSeguradora, idSistemaContabil, linhas)
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "PLANO_CONTAS", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao importar plano de contas")
+		return
+	}
+
+	if len(resultado.Erros) > 0 {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(resultado)
+		return
+	}
+
+	// Registrar na auditoria
+	_ = h.auditService.LogAction(
+		r.Context(),
+		r,
+		"IMPORT",
+		"PLANO_CONTAS",
+		fmt.Sprintf("sistema/%d", idSistemaContabil),
+		fmt.Sprintf("Importado plano de contas: %d contas criadas, %d atualizadas", resultado.Criadas, resultado.Atualizadas),
+	)
+
+	json.NewEncoder(w).Encode(resultado)
+}
+
+// getContaHistorico retorna o histórico de alterações de uma conta do plano de contas
+func (h *PlanoContasHandler) getContaHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar conta")
+		return
+	}
+
+	writeHistorico(w, r, h.auditService, models.EntidadeContaContabil, id)
+}
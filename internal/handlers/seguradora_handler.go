@@ -1,28 +1,39 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // SeguradoraHandler gerencia requisições relacionadas a seguradoras
 type SeguradoraHandler struct {
-	repo *models.SeguradoraRepository
+	repo         models.SeguradoraStore
+	auditService *services.AuditService
 }
 
 // NewSeguradoraHandler cria um novo handler de seguradoras
-func NewSeguradoraHandler(db *sql.DB) *SeguradoraHandler {
+func NewSeguradoraHandler(repo models.SeguradoraStore, auditService *services.AuditService) *SeguradoraHandler {
 	return &SeguradoraHandler{
-		repo: models.NewSeguradoraRepository(db),
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *SeguradoraHandler) tenantRepo(r *http.Request) models.SeguradoraStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleSeguradora gerencia todas as requisições relacionadas a seguradoras
 func (h *SeguradoraHandler) HandleSeguradora(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -32,19 +43,29 @@ func (h *SeguradoraHandler) HandleSeguradora(w http.ResponseWriter, r *http.Requ
 	if len(parts) > 2 && parts[1] == "seguradoras" && parts[2] != "" {
 		id, err := strconv.ParseInt(parts[2], 10, 64)
 		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Histórico de alterações: /seguradoras/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getSeguradoraHistorico(w, r, id)
 			return
 		}
 
 		switch r.Method {
 		case http.MethodGet:
-			h.getSeguradoraByID(w, id)
+			h.getSeguradoraByID(w, r, id)
 		case http.MethodPut:
 			h.updateSeguradora(w, r, id)
 		case http.MethodDelete:
-			h.deleteSeguradora(w, id)
+			h.deleteSeguradora(w, r, id)
 		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		}
 		return
 	}
@@ -56,15 +77,21 @@ func (h *SeguradoraHandler) HandleSeguradora(w http.ResponseWriter, r *http.Requ
 	case http.MethodPost:
 		h.createSeguradora(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getSeguradoras retorna todas as seguradoras
+// getSeguradoras retorna uma página de seguradoras, com ordenação e filtros
 func (h *SeguradoraHandler) getSeguradoras(w http.ResponseWriter, r *http.Request) {
-	seguradoras, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar seguradoras: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	seguradoras, err := h.tenantRepo(r).GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar seguradoras")
 		return
 	}
 
@@ -72,14 +99,10 @@ func (h *SeguradoraHandler) getSeguradoras(w http.ResponseWriter, r *http.Reques
 }
 
 // getSeguradoraByID retorna uma seguradora específica pelo ID
-func (h *SeguradoraHandler) getSeguradoraByID(w http.ResponseWriter, id int64) {
-	seguradora, err := h.repo.GetByID(id)
+func (h *SeguradoraHandler) getSeguradoraByID(w http.ResponseWriter, r *http.Request, id int64) {
+	seguradora, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar seguradora")
 		return
 	}
 
@@ -90,13 +113,13 @@ func (h *SeguradoraHandler) getSeguradoraByID(w http.ResponseWriter, id int64) {
 func (h *SeguradoraHandler) createSeguradora(w http.ResponseWriter, r *http.Request) {
 	var seguradora models.Seguradora
 	if err := json.NewDecoder(r.Body).Decode(&seguradora); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if seguradora.Nome == "" {
-		http.Error(w, "Nome da seguradora é obrigatório", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Nome da seguradora é obrigatório")
 		return
 	}
 
@@ -105,8 +128,12 @@ func (h *SeguradoraHandler) createSeguradora(w http.ResponseWriter, r *http.Requ
 		seguradora.Ativo = true
 	}
 
-	if err := h.repo.Create(&seguradora); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar seguradora: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &seguradora); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SEGURADORA", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar seguradora")
 		return
 	}
 
@@ -116,21 +143,10 @@ func (h *SeguradoraHandler) createSeguradora(w http.ResponseWriter, r *http.Requ
 
 // updateSeguradora atualiza uma seguradora existente
 func (h *SeguradoraHandler) updateSeguradora(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se a seguradora existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var seguradora models.Seguradora
 	if err := json.NewDecoder(r.Body).Decode(&seguradora); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -138,15 +154,19 @@ func (h *SeguradoraHandler) updateSeguradora(w http.ResponseWriter, r *http.Requ
 	seguradora.ID = id
 
 	// Atualizar a seguradora
-	if err := h.repo.Update(&seguradora); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar seguradora: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Update(r.Context(), &seguradora); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SEGURADORA", fmt.Sprintf("%d", seguradora.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar seguradora")
 		return
 	}
 
 	// Buscar a seguradora atualizada
-	updatedSeguradora, err := h.repo.GetByID(id)
+	updatedSeguradora, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar seguradora atualizada: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar seguradora atualizada")
 		return
 	}
 
@@ -154,24 +174,28 @@ func (h *SeguradoraHandler) updateSeguradora(w http.ResponseWriter, r *http.Requ
 }
 
 // deleteSeguradora remove uma seguradora
-func (h *SeguradoraHandler) deleteSeguradora(w http.ResponseWriter, id int64) {
-	// Verificar se a seguradora existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+func (h *SeguradoraHandler) deleteSeguradora(w http.ResponseWriter, r *http.Request, id int64) {
+	// Excluir a seguradora
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SEGURADORA", fmt.Sprintf("%d", id))
+			return
 		}
+		problem.Error(w, r, err, "Erro ao excluir seguradora")
 		return
 	}
 
-	// Excluir a seguradora
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir seguradora: %v", err), http.StatusInternalServerError)
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getSeguradoraHistorico retorna o histórico de alterações de uma seguradora
+func (h *SeguradoraHandler) getSeguradoraHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar seguradora")
 		return
 	}
 
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	writeHistorico(w, r, h.auditService, models.EntidadeSeguradora, id)
 }
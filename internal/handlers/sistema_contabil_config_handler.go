@@ -1,54 +1,53 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // SistemaContabilConfigHandler gerencia requisições relacionadas a configurações de sistema contábil
 type SistemaContabilConfigHandler struct {
-	repo         *models.SistemaContabilConfigRepository
+	repo         models.SistemaContabilConfigStore
 	auditService *services.AuditService
 }
 
 // NewSistemaContabilConfigHandler cria um novo handler de configurações de sistema contábil
-func NewSistemaContabilConfigHandler(db *sql.DB) *SistemaContabilConfigHandler {
+func NewSistemaContabilConfigHandler(repo models.SistemaContabilConfigStore, auditService *services.AuditService) *SistemaContabilConfigHandler {
 	return &SistemaContabilConfigHandler{
-		repo:         models.NewSistemaContabilConfigRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *SistemaContabilConfigHandler) tenantRepo(r *http.Request) models.SistemaContabilConfigStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleSistemaContabilConfig gerencia todas as requisições relacionadas a configurações de sistema contábil
 func (h *SistemaContabilConfigHandler) HandleSistemaContabilConfig(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Verificar se há um ID na URL para operações específicas
 	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) > 2 && parts[1] == "sistemas-contabeis-config" && parts[2] != "" {
-		id, err := strconv.ParseInt(parts[2], 10, 64)
-		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+
+	// Exportação da matriz de configurações em CSV, XLSX ou JSON
+	if len(parts) > 2 && parts[1] == "sistemas-contabeis-config" && parts[2] == "export" {
+		if r.Method == http.MethodGet {
+			h.exportSistemasContabeisConfig(w, r)
 			return
 		}
 
-		switch r.Method {
-		case http.MethodGet:
-			h.getSistemaContabilConfigByID(w, r, id)
-		case http.MethodPut:
-			h.updateSistemaContabilConfig(w, r, id)
-		case http.MethodDelete:
-			h.deleteSistemaContabilConfig(w, r, id)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
-		}
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
@@ -56,7 +55,7 @@ func (h *SistemaContabilConfigHandler) HandleSistemaContabilConfig(w http.Respon
 	if len(parts) > 3 && parts[1] == "sistemas-contabeis-config" && parts[2] == "seguradora" && parts[3] != "" {
 		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
 		if err != nil {
-			http.Error(w, "ID de seguradora inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID de seguradora inválido")
 			return
 		}
 
@@ -65,7 +64,38 @@ func (h *SistemaContabilConfigHandler) HandleSistemaContabilConfig(w http.Respon
 			return
 		}
 
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		return
+	}
+
+	// Verificar se há um ID na URL para operações específicas
+	if len(parts) > 2 && parts[1] == "sistemas-contabeis-config" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Histórico de alterações: /sistemas-contabeis-config/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getSistemaContabilConfigHistorico(w, r, id)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.getSistemaContabilConfigByID(w, r, id)
+		case http.MethodPut:
+			h.updateSistemaContabilConfig(w, r, id)
+		case http.MethodDelete:
+			h.deleteSistemaContabilConfig(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
 		return
 	}
 
@@ -73,7 +103,7 @@ func (h *SistemaContabilConfigHandler) HandleSistemaContabilConfig(w http.Respon
 	if len(parts) > 3 && parts[1] == "sistemas-contabeis-config" && parts[2] == "sistema" && parts[3] != "" {
 		idSistemaContabil, err := strconv.ParseInt(parts[3], 10, 64)
 		if err != nil {
-			http.Error(w, "ID de sistema contábil inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID de sistema contábil inválido")
 			return
 		}
 
@@ -82,7 +112,7 @@ func (h *SistemaContabilConfigHandler) HandleSistemaContabilConfig(w http.Respon
 			return
 		}
 
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
@@ -93,15 +123,21 @@ func (h *SistemaContabilConfigHandler) HandleSistemaContabilConfig(w http.Respon
 	case http.MethodPost:
 		h.createSistemaContabilConfig(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getSistemasContabeisConfig retorna todas as configurações de sistema contábil
+// getSistemasContabeisConfig retorna uma página de configurações de sistema contábil, com ordenação e filtros
 func (h *SistemaContabilConfigHandler) getSistemasContabeisConfig(w http.ResponseWriter, r *http.Request) {
-	configs, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	configs, err := h.tenantRepo(r).GetAll(r.Context(), opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar configurações de sistema contábil: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar configurações de sistema contábil")
 		return
 	}
 
@@ -112,21 +148,78 @@ func (h *SistemaContabilConfigHandler) getSistemasContabeisConfig(w http.Respons
 		"LIST",
 		"SISTEMAS_CONTABEIS_CONFIG",
 		"",
-		fmt.Sprintf("Listadas %d configurações de sistema contábil", len(configs)),
+		fmt.Sprintf("Listadas %d configurações de sistema contábil", len(configs.Items)),
 	)
 
 	json.NewEncoder(w).Encode(configs)
 }
 
+// exportSistemasContabeisConfig exporta as configurações filtradas, com os nomes do sistema contábil e do
+// objeto de contabilização e o número e a descrição do evento. Os parâmetros seguradora e sistema
+// filtram pela seguradora e pelo sistema contábil; os demais filtros e a ordenação seguem a listagem.
+func (h *SistemaContabilConfigHandler) exportSistemasContabeisConfig(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	if v := query.Get("seguradora"); v != "" {
+		idSeguradora, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID de seguradora inválido")
+			return
+		}
+
+		// Verificar se a seguradora consultada é visível para o usuário
+		scope := middleware.GetTenantScopeFromContext(r.Context())
+		if !scope.Allows(idSeguradora) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL_CONFIG", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		query.Del("seguradora")
+		query.Set("idSeguradora", v)
+	}
+
+	if v := query.Get("sistema"); v != "" {
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID de sistema contábil inválido")
+			return
+		}
+		query.Del("sistema")
+		query.Set("idSistemaContabil", v)
+	}
+
+	exportar(w, r, h.auditService, query, exportacao[models.SistemaContabilConfig]{
+		entityType: "SISTEMAS_CONTABEIS_CONFIG",
+		nome:       "sistemas-contabeis-config",
+		formatos:   []string{formatoExportacaoCSV, formatoExportacaoXLSX, formatoExportacaoJSON},
+		cabecalho: []string{
+			"idSistemaContabilConfig", "idSeguradora", "idSistemaContabil", "sistemaContabil",
+			"idObjetoContabilizacao", "objetoContabilizacao", "idCodigoEvento", "eventoNumero",
+			"eventoDescricao", "contaDebito", "contaCredito", "ativo",
+		},
+		linha: func(c models.SistemaContabilConfig) []string {
+			return []string{
+				strconv.FormatInt(c.ID, 10),
+				strconv.FormatInt(c.IdSeguradora, 10),
+				strconv.FormatInt(c.IdSistemaContabil, 10),
+				c.SistemaContabilNome,
+				strconv.FormatInt(c.IdObjetoContabilizacao, 10),
+				c.ObjetoContabilizacaoNome,
+				strconv.FormatInt(c.IdCodigoEvento, 10),
+				strconv.Itoa(c.EventoNumero),
+				c.EventoDescricao,
+				c.ContaDebitoCodigo,
+				c.ContaCreditoCodigo,
+				strconv.FormatBool(c.Ativo),
+			}
+		},
+		export: h.tenantRepo(r).Export,
+	})
+}
+
 // getSistemaContabilConfigByID retorna uma configuração específica pelo ID
 func (h *SistemaContabilConfigHandler) getSistemaContabilConfigByID(w http.ResponseWriter, r *http.Request, id int64) {
-	config, err := h.repo.GetByID(id)
+	config, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar configuração")
 		return
 	}
 
@@ -145,9 +238,19 @@ func (h *SistemaContabilConfigHandler) getSistemaContabilConfigByID(w http.Respo
 
 // getSistemasContabeisConfigBySeguradora retorna configurações de uma seguradora específica
 func (h *SistemaContabilConfigHandler) getSistemasContabeisConfigBySeguradora(w http.ResponseWriter, r *http.Request, idSeguradora int64) {
-	configs, err := h.repo.GetBySeguradora(idSeguradora)
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	configs, err := h.tenantRepo(r).GetBySeguradora(r.Context(), idSeguradora, opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar configurações por seguradora: %v", err), http.StatusInternalServerError)
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL_CONFIG", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao buscar configurações por seguradora")
 		return
 	}
 
@@ -158,7 +261,7 @@ func (h *SistemaContabilConfigHandler) getSistemasContabeisConfigBySeguradora(w
 		"LIST",
 		"SISTEMAS_CONTABEIS_CONFIG",
 		fmt.Sprintf("seguradora/%d", idSeguradora),
-		fmt.Sprintf("Listadas %d configurações da seguradora %d", len(configs), idSeguradora),
+		fmt.Sprintf("Listadas %d configurações da seguradora %d", len(configs.Items), idSeguradora),
 	)
 
 	json.NewEncoder(w).Encode(configs)
@@ -166,9 +269,15 @@ func (h *SistemaContabilConfigHandler) getSistemasContabeisConfigBySeguradora(w
 
 // getSistemasContabeisConfigBySistemaContabil retorna configurações de um sistema contábil específico
 func (h *SistemaContabilConfigHandler) getSistemasContabeisConfigBySistemaContabil(w http.ResponseWriter, r *http.Request, idSistemaContabil int64) {
-	configs, err := h.repo.GetBySistemaContabil(idSistemaContabil)
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar configurações por sistema contábil: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	configs, err := h.tenantRepo(r).GetBySistemaContabil(r.Context(), idSistemaContabil, opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar configurações por sistema contábil")
 		return
 	}
 
@@ -179,7 +288,7 @@ func (h *SistemaContabilConfigHandler) getSistemasContabeisConfigBySistemaContab
 		"LIST",
 		"SISTEMAS_CONTABEIS_CONFIG",
 		fmt.Sprintf("sistema/%d", idSistemaContabil),
-		fmt.Sprintf("Listadas %d configurações do sistema contábil %d", len(configs), idSistemaContabil),
+		fmt.Sprintf("Listadas %d configurações do sistema contábil %d", len(configs.Items), idSistemaContabil),
 	)
 
 	json.NewEncoder(w).Encode(configs)
@@ -189,13 +298,13 @@ func (h *SistemaContabilConfigHandler) getSistemasContabeisConfigBySistemaContab
 func (h *SistemaContabilConfigHandler) createSistemaContabilConfig(w http.ResponseWriter, r *http.Request) {
 	var config models.SistemaContabilConfig
 	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if config.IdSistemaContabil <= 0 || config.IdObjetoContabilizacao <= 0 || config.IdCodigoEvento <= 0 || config.IdSeguradora <= 0 {
-		http.Error(w, "ID do sistema contábil, ID do objeto de contabilização, ID do evento e ID da seguradora são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "ID do sistema contábil, ID do objeto de contabilização, ID do evento e ID da seguradora são obrigatórios")
 		return
 	}
 
@@ -204,42 +313,25 @@ func (h *SistemaContabilConfigHandler) createSistemaContabilConfig(w http.Respon
 		config.Ativo = true
 	}
 
-	if err := h.repo.Create(&config); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar configuração: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &config); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL_CONFIG", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar configuração")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"CREATE",
-		"SISTEMA_CONTABIL_CONFIG",
-		fmt.Sprintf("%d", config.ID),
-		fmt.Sprintf("Criada configuração para sistema contábil %d, objeto %d e evento %d", config.IdSistemaContabil, config.IdObjetoContabilizacao, config.IdCodigoEvento),
-	)
-
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(config)
 }
 
 // updateSistemaContabilConfig atualiza uma configuração existente
 func (h *SistemaContabilConfigHandler) updateSistemaContabilConfig(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se a configuração existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var config models.SistemaContabilConfig
 	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -247,25 +339,19 @@ func (h *SistemaContabilConfigHandler) updateSistemaContabilConfig(w http.Respon
 	config.ID = id
 
 	// Atualizar a configuração
-	if err := h.repo.Update(&config); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar configuração: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Update(r.Context(), &config); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL_CONFIG", fmt.Sprintf("%d", config.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar configuração")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"UPDATE",
-		"SISTEMA_CONTABIL_CONFIG",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Atualizada configuração para sistema contábil %d, objeto %d e evento %d", config.IdSistemaContabil, config.IdObjetoContabilizacao, config.IdCodigoEvento),
-	)
-
 	// Buscar a configuração atualizada
-	updatedConfig, err := h.repo.GetByID(id)
+	updatedConfig, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar configuração atualizada: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar configuração atualizada")
 		return
 	}
 
@@ -274,33 +360,27 @@ func (h *SistemaContabilConfigHandler) updateSistemaContabilConfig(w http.Respon
 
 // deleteSistemaContabilConfig remove uma configuração
 func (h *SistemaContabilConfigHandler) deleteSistemaContabilConfig(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se a configuração existe
-	config, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrada") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Excluir a configuração
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL_CONFIG", fmt.Sprintf("%d", id))
+			return
 		}
+		problem.Error(w, r, err, "Erro ao excluir configuração")
 		return
 	}
 
-	// Excluir a configuração
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir configuração: %v", err), http.StatusInternalServerError)
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getSistemaContabilConfigHistorico retorna o histórico de alterações de uma configuração de sistema contábil
+func (h *SistemaContabilConfigHandler) getSistemaContabilConfigHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar configuração")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"DELETE",
-		"SISTEMA_CONTABIL_CONFIG",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Desativada configuração para sistema contábil %d, objeto %d e evento %d", config.IdSistemaContabil, config.IdObjetoContabilizacao, config.IdCodigoEvento),
-	)
-
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	writeHistorico(w, r, h.auditService, models.EntidadeSistemaContabilConfig, id)
 }
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+)
+
+func TestSistemaContabilConfigContaInvalida(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewSistemaContabilConfigHandler(env.stores.SistemasContabeisConfig, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	f := env.createContabil(t, env.seguradoraA)
+	ctx := context.Background()
+
+	// Conta inativa no mesmo plano e conta ativa no plano de outro sistema contábil da seguradora
+	inativa := &models.ContaContabil{IdSeguradora: env.seguradoraA, IdSistemaContabil: f.sistema.ID, Codigo: "3.1", Descricao: "Conta 3.1", Natureza: models.NaturezaCredito, Tipo: models.TipoContaAnalitica}
+	if err := env.stores.PlanoContas.Create(ctx, inativa); err != nil {
+		t.Fatalf("erro ao criar conta contábil: %v", err)
+	}
+	outroSistema := &models.SistemaContabil{SistemaContabil: "TOTVS", IdSeguradora: env.seguradoraA, Ativo: true}
+	if err := env.stores.SistemasContabeis.Create(ctx, outroSistema); err != nil {
+		t.Fatalf("erro ao criar sistema contábil: %v", err)
+	}
+	outroPlano := &models.ContaContabil{IdSeguradora: env.seguradoraA, IdSistemaContabil: outroSistema.ID, Codigo: "1.1", Descricao: "Conta 1.1", Natureza: models.NaturezaDebito, Tipo: models.TipoContaAnalitica, Ativo: true}
+	if err := env.stores.PlanoContas.Create(ctx, outroPlano); err != nil {
+		t.Fatalf("erro ao criar conta contábil: %v", err)
+	}
+
+	id := fmt.Sprintf("%d", f.config.ID)
+	for name, idContaCredito := range map[string]int64{"inativa": inativa.ID, "outro plano": outroPlano.ID} {
+		body := fmt.Sprintf(
+			`{"idSistemaContabil":%d,"idObjetoContabilizacao":%d,"idCodigoEvento":%d,"idSeguradora":%d,"idContaDebito":%d,"idContaCredito":%d,"ativo":true}`,
+			f.sistema.ID, f.objeto.ID, f.evento.ID, env.seguradoraA, *f.config.IdContaDebito, idContaCredito,
+		)
+		w := serve(h.HandleSistemaContabilConfig, newRequest(http.MethodPut, "/sistemas-contabeis-config/"+id, body, usuario.ID, env.seguradoraA))
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("conta %s: status %d, esperado 422, corpo %s", name, w.Code, w.Body.String())
+			continue
+		}
+		var p problem.Problem
+		decode(t, w, &p)
+		if p.Code != problem.CodeInvalidReference || len(p.Errors) != 1 || p.Errors[0].Field != "idContaCredito" {
+			t.Errorf("conta %s: problema %+v, esperado %s em idContaCredito", name, p, problem.CodeInvalidReference)
+		}
+	}
+
+	// A configuração mantém as contas originais
+	config, err := env.stores.SistemasContabeisConfig.GetByID(ctx, f.config.ID)
+	if err != nil {
+		t.Fatalf("erro ao buscar configuração: %v", err)
+	}
+	if *config.IdContaCredito != *f.config.IdContaCredito {
+		t.Errorf("conta de crédito alterada para %d", *config.IdContaCredito)
+	}
+}
+
+func TestExportSistemasContabeisConfigNeutralizaFormulas(t *testing.T) {
+	env := newTestEnv(t)
+	h := NewSistemaContabilConfigHandler(env.stores.SistemasContabeisConfig, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	f := env.createContabil(t, env.seguradoraA)
+	ctx := context.Background()
+
+	// Nomes cadastrados pelos usuários que uma planilha executaria como fórmula
+	f.sistema.SistemaContabil = "@SUM(1+1)"
+	if err := env.stores.SistemasContabeis.Update(ctx, f.sistema); err != nil {
+		t.Fatalf("erro ao alterar sistema contábil: %v", err)
+	}
+	f.evento.Descricao = `=HYPERLINK("http://exemplo.com","clique")`
+	if err := env.stores.Eventos.Update(ctx, f.evento); err != nil {
+		t.Fatalf("erro ao alterar evento: %v", err)
+	}
+
+	want := map[int]string{3: "'@SUM(1+1)", 8: `'=HYPERLINK("http://exemplo.com","clique")`}
+	for _, formato := range []string{"csv", "xlsx"} {
+		target := "/sistemas-contabeis-config/export?format=" + formato
+		w := serve(h.HandleSistemaContabilConfig, newRequest(http.MethodGet, target, "", usuario.ID, env.seguradoraA))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d, corpo %s", target, w.Code, w.Body.String())
+		}
+
+		var linhas [][]string
+		if formato == "csv" {
+			var err error
+			if linhas, err = csv.NewReader(w.Body).ReadAll(); err != nil {
+				t.Fatalf("erro ao ler CSV: %v", err)
+			}
+		} else {
+			arquivo, err := excelize.OpenReader(w.Body)
+			if err != nil {
+				t.Fatalf("erro ao abrir XLSX: %v", err)
+			}
+			linhas, err = arquivo.GetRows(arquivo.GetSheetName(0))
+			arquivo.Close()
+			if err != nil {
+				t.Fatalf("erro ao ler XLSX: %v", err)
+			}
+		}
+		if len(linhas) != 2 {
+			t.Fatalf("%s: %d linhas, esperadas 2 (cabeçalho e configuração)", formato, len(linhas))
+		}
+		for coluna, valor := range want {
+			if linhas[1][coluna] != valor {
+				t.Errorf("%s: coluna %s = %q, esperado %q", formato, linhas[0][coluna], linhas[1][coluna], valor)
+			}
+		}
+	}
+}
@@ -1,71 +1,90 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // SistemaContabilHandler gerencia requisições relacionadas a sistemas contábeis
 type SistemaContabilHandler struct {
-	repo         *models.SistemaContabilRepository
+	repo         models.SistemaContabilStore
 	auditService *services.AuditService
 }
 
 // NewSistemaContabilHandler cria um novo handler de sistemas contábeis
-func NewSistemaContabilHandler(db *sql.DB) *SistemaContabilHandler {
+func NewSistemaContabilHandler(repo models.SistemaContabilStore, auditService *services.AuditService) *SistemaContabilHandler {
 	return &SistemaContabilHandler{
-		repo:         models.NewSistemaContabilRepository(db),
-		auditService: services.NewAuditService(db),
+		repo:         repo,
+		auditService: auditService,
 	}
 }
 
+// tenantRepo retorna o repositório restrito à seguradora da requisição, que registra as alterações
+// no histórico em nome do usuário autenticado
+func (h *SistemaContabilHandler) tenantRepo(r *http.Request) models.SistemaContabilStore {
+	return h.repo.WithTenant(middleware.GetTenantScopeFromContext(r.Context())).WithActor(middleware.ActorFromRequest(r))
+}
+
 // HandleSistemaContabil gerencia todas as requisições relacionadas a sistemas contábeis
 func (h *SistemaContabilHandler) HandleSistemaContabil(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	// Verificar se há um ID na URL para operações específicas
 	parts := strings.Split(r.URL.Path, "/")
-	if len(parts) > 2 && parts[1] == "sistemas-contabeis" && parts[2] != "" {
-		id, err := strconv.ParseInt(parts[2], 10, 64)
+
+	// Verificar se há um parâmetro de seguradora na URL
+	if len(parts) > 3 && parts[1] == "sistemas-contabeis" && parts[2] == "seguradora" && parts[3] != "" {
+		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
 		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID de seguradora inválido")
 			return
 		}
 
-		switch r.Method {
-		case http.MethodGet:
-			h.getSistemaContabilByID(w, r, id)
-		case http.MethodPut:
-			h.updateSistemaContabil(w, r, id)
-		case http.MethodDelete:
-			h.deleteSistemaContabil(w, r, id)
-		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		if r.Method == http.MethodGet {
+			h.getSistemasContabeisBySeguradora(w, r, idSeguradora)
+			return
 		}
+
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		return
 	}
 
-	// Verificar se há um parâmetro de seguradora na URL
-	if len(parts) > 3 && parts[1] == "sistemas-contabeis" && parts[2] == "seguradora" && parts[3] != "" {
-		idSeguradora, err := strconv.ParseInt(parts[3], 10, 64)
+	// Verificar se há um ID na URL para operações específicas
+	if len(parts) > 2 && parts[1] == "sistemas-contabeis" && parts[2] != "" {
+		id, err := strconv.ParseInt(parts[2], 10, 64)
 		if err != nil {
-			http.Error(w, "ID de seguradora inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
 			return
 		}
 
-		if r.Method == http.MethodGet {
-			h.getSistemasContabeisBySeguradora(w, r, idSeguradora)
+		// Histórico de alterações: /sistemas-contabeis/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getSistemaContabilHistorico(w, r, id)
 			return
 		}
 
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		switch r.Method {
+		case http.MethodGet:
+			h.getSistemaContabilByID(w, r, id)
+		case http.MethodPut:
+			h.updateSistemaContabil(w, r, id)
+		case http.MethodDelete:
+			h.deleteSistemaContabil(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
 		return
 	}
 
@@ -76,15 +95,21 @@ func (h *SistemaContabilHandler) HandleSistemaContabil(w http.ResponseWriter, r
 	case http.MethodPost:
 		h.createSistemaContabil(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getSistemasContabeis retorna todos os sistemas contábeis
+// getSistemasContabeis retorna uma página de sistemas contábeis, com ordenação e filtros
 func (h *SistemaContabilHandler) getSistemasContabeis(w http.ResponseWriter, r *http.Request) {
-	sistemas, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar sistemas contábeis: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	sistemas, err := h.tenantRepo(r).GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar sistemas contábeis")
 		return
 	}
 
@@ -95,7 +120,7 @@ func (h *SistemaContabilHandler) getSistemasContabeis(w http.ResponseWriter, r *
 		"LIST",
 		"SISTEMAS_CONTABEIS",
 		"",
-		fmt.Sprintf("Listados %d sistemas contábeis", len(sistemas)),
+		fmt.Sprintf("Listados %d sistemas contábeis", len(sistemas.Items)),
 	)
 
 	json.NewEncoder(w).Encode(sistemas)
@@ -103,13 +128,9 @@ func (h *SistemaContabilHandler) getSistemasContabeis(w http.ResponseWriter, r *
 
 // getSistemaContabilByID retorna um sistema contábil específico pelo ID
 func (h *SistemaContabilHandler) getSistemaContabilByID(w http.ResponseWriter, r *http.Request, id int64) {
-	sistema, err := h.repo.GetByID(id)
+	sistema, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar sistema contábil")
 		return
 	}
 
@@ -128,9 +149,19 @@ func (h *SistemaContabilHandler) getSistemaContabilByID(w http.ResponseWriter, r
 
 // getSistemasContabeisBySeguradora retorna sistemas contábeis de uma seguradora específica
 func (h *SistemaContabilHandler) getSistemasContabeisBySeguradora(w http.ResponseWriter, r *http.Request, idSeguradora int64) {
-	sistemas, err := h.repo.GetBySeguradora(idSeguradora)
+	opts, err := models.ParseListOptions(r.URL.Query())
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar sistemas contábeis por seguradora: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	sistemas, err := h.tenantRepo(r).GetBySeguradora(r.Context(), idSeguradora, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL", fmt.Sprintf("seguradora/%d", idSeguradora))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao buscar sistemas contábeis por seguradora")
 		return
 	}
 
@@ -141,7 +172,7 @@ func (h *SistemaContabilHandler) getSistemasContabeisBySeguradora(w http.Respons
 		"LIST",
 		"SISTEMAS_CONTABEIS",
 		fmt.Sprintf("seguradora/%d", idSeguradora),
-		fmt.Sprintf("Listados %d sistemas contábeis da seguradora %d", len(sistemas), idSeguradora),
+		fmt.Sprintf("Listados %d sistemas contábeis da seguradora %d", len(sistemas.Items), idSeguradora),
 	)
 
 	json.NewEncoder(w).Encode(sistemas)
@@ -151,13 +182,13 @@ func (h *SistemaContabilHandler) getSistemasContabeisBySeguradora(w http.Respons
 func (h *SistemaContabilHandler) createSistemaContabil(w http.ResponseWriter, r *http.Request) {
 	var sistema models.SistemaContabil
 	if err := json.NewDecoder(r.Body).Decode(&sistema); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if sistema.SistemaContabil == "" || sistema.IdSeguradora <= 0 {
-		http.Error(w, "Nome do sistema contábil e ID da seguradora são obrigatórios", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Nome do sistema contábil e ID da seguradora são obrigatórios")
 		return
 	}
 
@@ -166,42 +197,25 @@ func (h *SistemaContabilHandler) createSistemaContabil(w http.ResponseWriter, r
 		sistema.Ativo = true
 	}
 
-	if err := h.repo.Create(&sistema); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar sistema contábil: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Create(r.Context(), &sistema); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL", "")
+			return
+		}
+		problem.Error(w, r, err, "Erro ao criar sistema contábil")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"CREATE",
-		"SISTEMA_CONTABIL",
-		fmt.Sprintf("%d", sistema.ID),
-		fmt.Sprintf("Criado sistema contábil: %s", sistema.SistemaContabil),
-	)
-
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(sistema)
 }
 
 // updateSistemaContabil atualiza um sistema contábil existente
 func (h *SistemaContabilHandler) updateSistemaContabil(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o sistema existe
-	_, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
-		return
-	}
-
 	// Decodificar os dados da requisição
 	var sistema models.SistemaContabil
 	if err := json.NewDecoder(r.Body).Decode(&sistema); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -209,25 +223,19 @@ func (h *SistemaContabilHandler) updateSistemaContabil(w http.ResponseWriter, r
 	sistema.ID = id
 
 	// Atualizar o sistema
-	if err := h.repo.Update(&sistema); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar sistema contábil: %v", err), http.StatusInternalServerError)
+	if err := h.tenantRepo(r).Update(r.Context(), &sistema); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL", fmt.Sprintf("%d", sistema.ID))
+			return
+		}
+		problem.Error(w, r, err, "Erro ao atualizar sistema contábil")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"UPDATE",
-		"SISTEMA_CONTABIL",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Atualizado sistema contábil: %s", sistema.SistemaContabil),
-	)
-
 	// Buscar o sistema atualizado
-	updatedSistema, err := h.repo.GetByID(id)
+	updatedSistema, err := h.tenantRepo(r).GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar sistema contábil atualizado: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar sistema contábil atualizado")
 		return
 	}
 
@@ -236,33 +244,27 @@ func (h *SistemaContabilHandler) updateSistemaContabil(w http.ResponseWriter, r
 
 // deleteSistemaContabil remove um sistema contábil
 func (h *SistemaContabilHandler) deleteSistemaContabil(w http.ResponseWriter, r *http.Request, id int64) {
-	// Verificar se o sistema existe
-	sistema, err := h.repo.GetByID(id)
-	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+	// Excluir o sistema
+	if err := h.tenantRepo(r).Delete(r.Context(), id); err != nil {
+		if errors.Is(err, models.ErrCrossTenant) {
+			denyCrossTenant(w, r, h.auditService, "SISTEMA_CONTABIL", fmt.Sprintf("%d", id))
+			return
 		}
+		problem.Error(w, r, err, "Erro ao excluir sistema contábil")
 		return
 	}
 
-	// Excluir o sistema
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir sistema contábil: %v", err), http.StatusInternalServerError)
+	// Responder com sucesso
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getSistemaContabilHistorico retorna o histórico de alterações de um sistema contábil
+func (h *SistemaContabilHandler) getSistemaContabilHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe e é visível para o usuário
+	if _, err := h.tenantRepo(r).GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar sistema contábil")
 		return
 	}
 
-	// Registrar na auditoria
-	_ = h.auditService.LogAction(
-		r.Context(),
-		r,
-		"DELETE",
-		"SISTEMA_CONTABIL",
-		fmt.Sprintf("%d", id),
-		fmt.Sprintf("Desativado sistema contábil: %s", sistema.SistemaContabil),
-	)
-
-	// Responder com sucesso
-	w.WriteHeader(http.StatusNoContent)
+	writeHistorico(w, r, h.auditService, models.EntidadeSistemaContabil, id)
 }
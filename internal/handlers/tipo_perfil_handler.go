@@ -1,25 +1,34 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
 
 // TipoPerfilHandler gerencia requisições relacionadas a tipos de perfil
 type TipoPerfilHandler struct {
-	repo *models.TipoPerfilRepository
+	repo          models.TipoPerfilStore
+	permissaoRepo models.PermissaoStore
+	authorizer    *middleware.Authorizer
+	auditService  *services.AuditService
 }
 
-// NewTipoPerfilHandler cria um novo handler de tipos de perfil
-func NewTipoPerfilHandler(db *sql.DB) *TipoPerfilHandler {
+// NewTipoPerfilHandler cria um novo handler de tipos de perfil.
+// O authorizer é notificado sempre que as permissões de um perfil são alteradas.
+func NewTipoPerfilHandler(repo models.TipoPerfilStore, permissaoRepo models.PermissaoStore, authorizer *middleware.Authorizer, auditService *services.AuditService) *TipoPerfilHandler {
 	return &TipoPerfilHandler{
-		repo: models.NewTipoPerfilRepository(db),
+		repo:          repo,
+		permissaoRepo: permissaoRepo,
+		authorizer:    authorizer,
+		auditService:  auditService,
 	}
 }
 
@@ -27,24 +36,63 @@ func NewTipoPerfilHandler(db *sql.DB) *TipoPerfilHandler {
 func (h *TipoPerfilHandler) HandleTipoPerfil(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	// Perfis e permissões valem para todas as seguradoras: somente administradores do ERP podem alterá-los
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		if adminERP, _ := middleware.GetAdminERPFromContext(r.Context()); !adminERP {
+			_ = h.auditService.LogAction(
+				r.Context(),
+				r,
+				"PERMISSION_DENIED",
+				"TIPO_PERFIL",
+				"",
+				fmt.Sprintf("Tentativa de %s em %s sem ser administrador", r.Method, r.URL.Path),
+			)
+			problem.Write(w, r, http.StatusForbidden, "Acesso restrito a administradores")
+			return
+		}
+	}
+
 	// Verificar se há um ID na URL para operações específicas
 	parts := strings.Split(r.URL.Path, "/")
+
+	// Catálogo de permissões: /tipos-perfil/permissoes[/{id}]
+	if len(parts) > 2 && parts[1] == "tipos-perfil" && parts[2] == "permissoes" {
+		h.handlePermissoes(w, r, parts)
+		return
+	}
+
 	if len(parts) > 2 && parts[1] == "tipos-perfil" && parts[2] != "" {
 		id, err := strconv.ParseInt(parts[2], 10, 64)
 		if err != nil {
-			http.Error(w, "ID inválido", http.StatusBadRequest)
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Permissões de um tipo de perfil: /tipos-perfil/{id}/permissoes[/{nome}]
+		if len(parts) > 3 && parts[3] == "permissoes" {
+			h.handleTipoPerfilPermissoes(w, r, id, parts)
+			return
+		}
+
+		// Histórico de alterações: /tipos-perfil/{id}/historico
+		if len(parts) > 3 && parts[3] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getTipoPerfilHistorico(w, r, id)
 			return
 		}
 
 		switch r.Method {
 		case http.MethodGet:
-			h.getTipoPerfilByID(w, id)
+			h.getTipoPerfilByID(w, r, id)
 		case http.MethodPut:
 			h.updateTipoPerfil(w, r, id)
 		case http.MethodDelete:
-			h.deleteTipoPerfil(w, id)
+			h.deleteTipoPerfil(w, r, id)
 		default:
-			http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 		}
 		return
 	}
@@ -56,15 +104,21 @@ func (h *TipoPerfilHandler) HandleTipoPerfil(w http.ResponseWriter, r *http.Requ
 	case http.MethodPost:
 		h.createTipoPerfil(w, r)
 	default:
-		http.Error(w, "Método não permitido", http.StatusMethodNotAllowed)
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
 	}
 }
 
-// getTiposPerfil retorna todos os tipos de perfil
+// getTiposPerfil retorna uma página de tipos de perfil, com ordenação e filtros
 func (h *TipoPerfilHandler) getTiposPerfil(w http.ResponseWriter, r *http.Request) {
-	tiposPerfil, err := h.repo.GetAll()
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	tiposPerfil, err := h.repo.GetAll(r.Context(), opts)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar tipos de perfil: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar tipos de perfil")
 		return
 	}
 
@@ -72,14 +126,10 @@ func (h *TipoPerfilHandler) getTiposPerfil(w http.ResponseWriter, r *http.Reques
 }
 
 // getTipoPerfilByID retorna um tipo de perfil específico pelo ID
-func (h *TipoPerfilHandler) getTipoPerfilByID(w http.ResponseWriter, id int64) {
-	tipoPerfil, err := h.repo.GetByID(id)
+func (h *TipoPerfilHandler) getTipoPerfilByID(w http.ResponseWriter, r *http.Request, id int64) {
+	tipoPerfil, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar tipo de perfil")
 		return
 	}
 
@@ -90,13 +140,13 @@ func (h *TipoPerfilHandler) getTipoPerfilByID(w http.ResponseWriter, id int64) {
 func (h *TipoPerfilHandler) createTipoPerfil(w http.ResponseWriter, r *http.Request) {
 	var tipoPerfil models.TipoPerfil
 	if err := json.NewDecoder(r.Body).Decode(&tipoPerfil); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
 	// Validação básica
 	if tipoPerfil.Perfil == "" {
-		http.Error(w, "Nome do perfil é obrigatório", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Nome do perfil é obrigatório")
 		return
 	}
 
@@ -105,8 +155,8 @@ func (h *TipoPerfilHandler) createTipoPerfil(w http.ResponseWriter, r *http.Requ
 		tipoPerfil.Ativo = true
 	}
 
-	if err := h.repo.Create(&tipoPerfil); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao criar tipo de perfil: %v", err), http.StatusInternalServerError)
+	if err := h.repo.WithActor(middleware.ActorFromRequest(r)).Create(r.Context(), &tipoPerfil); err != nil {
+		problem.Error(w, r, err, "Erro ao criar tipo de perfil")
 		return
 	}
 
@@ -117,20 +167,16 @@ func (h *TipoPerfilHandler) createTipoPerfil(w http.ResponseWriter, r *http.Requ
 // updateTipoPerfil atualiza um tipo de perfil existente
 func (h *TipoPerfilHandler) updateTipoPerfil(w http.ResponseWriter, r *http.Request, id int64) {
 	// Verificar se o tipo de perfil existe
-	_, err := h.repo.GetByID(id)
+	_, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar tipo de perfil")
 		return
 	}
 
 	// Decodificar os dados da requisição
 	var tipoPerfil models.TipoPerfil
 	if err := json.NewDecoder(r.Body).Decode(&tipoPerfil); err != nil {
-		http.Error(w, "Dados inválidos", http.StatusBadRequest)
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
 		return
 	}
 
@@ -138,15 +184,15 @@ func (h *TipoPerfilHandler) updateTipoPerfil(w http.ResponseWriter, r *http.Requ
 	tipoPerfil.ID = id
 
 	// Atualizar o tipo de perfil
-	if err := h.repo.Update(&tipoPerfil); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao atualizar tipo de perfil: %v", err), http.StatusInternalServerError)
+	if err := h.repo.WithActor(middleware.ActorFromRequest(r)).Update(r.Context(), &tipoPerfil); err != nil {
+		problem.Error(w, r, err, "Erro ao atualizar tipo de perfil")
 		return
 	}
 
 	// Buscar o tipo de perfil atualizado
-	updatedTipoPerfil, err := h.repo.GetByID(id)
+	updatedTipoPerfil, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao buscar tipo de perfil atualizado: %v", err), http.StatusInternalServerError)
+		problem.Error(w, r, err, "Erro ao buscar tipo de perfil atualizado")
 		return
 	}
 
@@ -154,24 +200,295 @@ func (h *TipoPerfilHandler) updateTipoPerfil(w http.ResponseWriter, r *http.Requ
 }
 
 // deleteTipoPerfil remove um tipo de perfil
-func (h *TipoPerfilHandler) deleteTipoPerfil(w http.ResponseWriter, id int64) {
+func (h *TipoPerfilHandler) deleteTipoPerfil(w http.ResponseWriter, r *http.Request, id int64) {
 	// Verificar se o tipo de perfil existe
-	_, err := h.repo.GetByID(id)
+	_, err := h.repo.GetByID(r.Context(), id)
 	if err != nil {
-		if strings.Contains(err.Error(), "não encontrado") {
-			http.Error(w, err.Error(), http.StatusNotFound)
-		} else {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-		}
+		problem.Error(w, r, err, "Erro ao buscar tipo de perfil")
 		return
 	}
 
 	// Excluir o tipo de perfil
-	if err := h.repo.Delete(id); err != nil {
-		http.Error(w, fmt.Sprintf("Erro ao excluir tipo de perfil: %v", err), http.StatusInternalServerError)
+	if err := h.repo.WithActor(middleware.ActorFromRequest(r)).Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao excluir tipo de perfil")
 		return
 	}
 
 	// Responder com sucesso
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// handlePermissoes gerencia o catálogo de permissões (/tipos-perfil/permissoes[/{id}])
+func (h *TipoPerfilHandler) handlePermissoes(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) > 3 && parts[3] != "" {
+		id, err := strconv.ParseInt(parts[3], 10, 64)
+		if err != nil {
+			problem.Write(w, r, http.StatusBadRequest, "ID inválido")
+			return
+		}
+
+		// Histórico de alterações: /tipos-perfil/permissoes/{id}/historico
+		if len(parts) > 4 && parts[4] == "historico" {
+			if r.Method != http.MethodGet {
+				problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+				return
+			}
+			h.getPermissaoHistorico(w, r, id)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			h.getPermissaoByID(w, r, id)
+		case http.MethodPut:
+			h.updatePermissao(w, r, id)
+		case http.MethodDelete:
+			h.deletePermissao(w, r, id)
+		default:
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+		}
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getPermissoes(w, r)
+	case http.MethodPost:
+		h.createPermissao(w, r)
+	default:
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+	}
+}
+
+// handleTipoPerfilPermissoes gerencia as permissões concedidas a um tipo de perfil
+func (h *TipoPerfilHandler) handleTipoPerfilPermissoes(w http.ResponseWriter, r *http.Request, id int64, parts []string) {
+	// Verificar se o tipo de perfil existe
+	if _, err := h.repo.GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar tipo de perfil")
+		return
+	}
+
+	// Revogação de uma permissão específica: DELETE /tipos-perfil/{id}/permissoes/{nome}
+	if len(parts) > 4 && parts[4] != "" {
+		if r.Method != http.MethodDelete {
+			problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+			return
+		}
+		h.revokePermissao(w, r, id, parts[4])
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		h.getPermissoesTipoPerfil(w, r, id)
+	case http.MethodPut:
+		h.replacePermissoesTipoPerfil(w, r, id)
+	case http.MethodPost:
+		h.grantPermissao(w, r, id)
+	default:
+		problem.Write(w, r, http.StatusMethodNotAllowed, "Método não permitido")
+	}
+}
+
+// getPermissoes retorna o catálogo de permissões
+func (h *TipoPerfilHandler) getPermissoes(w http.ResponseWriter, r *http.Request) {
+	opts, err := models.ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Error(w, r, err, "Parâmetros inválidos")
+		return
+	}
+
+	permissoes, err := h.permissaoRepo.GetAll(r.Context(), opts)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissões")
+		return
+	}
+
+	json.NewEncoder(w).Encode(permissoes)
+}
+
+// getPermissaoByID retorna uma permissão específica pelo ID
+func (h *TipoPerfilHandler) getPermissaoByID(w http.ResponseWriter, r *http.Request, id int64) {
+	permissao, err := h.permissaoRepo.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissão")
+		return
+	}
+
+	json.NewEncoder(w).Encode(permissao)
+}
+
+// createPermissao cria uma nova permissão no catálogo
+func (h *TipoPerfilHandler) createPermissao(w http.ResponseWriter, r *http.Request) {
+	var permissao models.Permissao
+	if err := json.NewDecoder(r.Body).Decode(&permissao); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	// Por padrão, se não for especificado, a permissão é ativa
+	if !permissao.Ativo {
+		permissao.Ativo = true
+	}
+
+	if err := h.permissaoRepo.WithActor(middleware.ActorFromRequest(r)).Create(r.Context(), &permissao); err != nil {
+		problem.Error(w, r, err, "Erro ao criar permissão")
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(permissao)
+}
+
+// updatePermissao atualiza uma permissão do catálogo
+func (h *TipoPerfilHandler) updatePermissao(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se a permissão existe
+	if _, err := h.permissaoRepo.GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissão")
+		return
+	}
+
+	// Decodificar os dados da requisição
+	var permissao models.Permissao
+	if err := json.NewDecoder(r.Body).Decode(&permissao); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	// Garantir que o ID seja o mesmo
+	permissao.ID = id
+
+	if err := h.permissaoRepo.WithActor(middleware.ActorFromRequest(r)).Update(r.Context(), &permissao); err != nil {
+		problem.Error(w, r, err, "Erro ao atualizar permissão")
+		return
+	}
+
+	// As permissões em cache deixam de ser válidas
+	h.authorizer.Invalidate()
+
+	// Buscar a permissão atualizada
+	updatedPermissao, err := h.permissaoRepo.GetByID(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissão atualizada")
+		return
+	}
+
+	json.NewEncoder(w).Encode(updatedPermissao)
+}
+
+// deletePermissao desativa uma permissão do catálogo
+func (h *TipoPerfilHandler) deletePermissao(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se a permissão existe
+	if _, err := h.permissaoRepo.GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissão")
+		return
+	}
+
+	if err := h.permissaoRepo.WithActor(middleware.ActorFromRequest(r)).Delete(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao excluir permissão")
+		return
+	}
+
+	// As permissões em cache deixam de ser válidas
+	h.authorizer.Invalidate()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getPermissoesTipoPerfil retorna as permissões concedidas a um tipo de perfil
+func (h *TipoPerfilHandler) getPermissoesTipoPerfil(w http.ResponseWriter, r *http.Request, id int64) {
+	permissoes, err := h.permissaoRepo.GetByTipoPerfil(r.Context(), id)
+	if err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissões do tipo de perfil")
+		return
+	}
+
+	json.NewEncoder(w).Encode(permissoes)
+}
+
+// replacePermissoesTipoPerfil substitui todas as permissões de um tipo de perfil
+func (h *TipoPerfilHandler) replacePermissoesTipoPerfil(w http.ResponseWriter, r *http.Request, id int64) {
+	var request struct {
+		Permissoes []string `json:"permissoes"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	if err := h.permissaoRepo.WithActor(middleware.ActorFromRequest(r)).ReplaceForTipoPerfil(r.Context(), id, request.Permissoes); err != nil {
+		problem.Error(w, r, err, "Erro ao atualizar permissões")
+		return
+	}
+
+	// As permissões em cache deixam de ser válidas
+	h.authorizer.Invalidate()
+
+	_ = h.auditService.LogAction(r.Context(), r, "UPDATE_PERMISSIONS", "TIPO_PERFIL", strconv.FormatInt(id, 10),
+		fmt.Sprintf("Permissões definidas: %s", strings.Join(request.Permissoes, ", ")))
+
+	h.getPermissoesTipoPerfil(w, r, id)
+}
+
+// grantPermissao concede uma permissão a um tipo de perfil
+func (h *TipoPerfilHandler) grantPermissao(w http.ResponseWriter, r *http.Request, id int64) {
+	var request struct {
+		Permissao string `json:"permissao"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Permissao == "" {
+		problem.Write(w, r, http.StatusBadRequest, "Dados inválidos")
+		return
+	}
+
+	if err := h.permissaoRepo.Grant(r.Context(), id, request.Permissao); err != nil {
+		problem.Error(w, r, err, "Erro ao conceder permissão")
+		return
+	}
+
+	// As permissões em cache deixam de ser válidas
+	h.authorizer.Invalidate()
+
+	_ = h.auditService.LogAction(r.Context(), r, "GRANT_PERMISSION", "TIPO_PERFIL", strconv.FormatInt(id, 10),
+		fmt.Sprintf("Permissão concedida: %s", request.Permissao))
+
+	w.WriteHeader(http.StatusCreated)
+	h.getPermissoesTipoPerfil(w, r, id)
+}
+
+// revokePermissao remove uma permissão de um tipo de perfil
+func (h *TipoPerfilHandler) revokePermissao(w http.ResponseWriter, r *http.Request, id int64, nome string) {
+	if err := h.permissaoRepo.Revoke(r.Context(), id, nome); err != nil {
+		problem.Error(w, r, err, "Erro ao revogar permissão")
+		return
+	}
+
+	// As permissões em cache deixam de ser válidas
+	h.authorizer.Invalidate()
+
+	_ = h.auditService.LogAction(r.Context(), r, "REVOKE_PERMISSION", "TIPO_PERFIL", strconv.FormatInt(id, 10),
+		fmt.Sprintf("Permissão revogada: %s", nome))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getTipoPerfilHistorico retorna o histórico de alterações de um tipo de perfil
+func (h *TipoPerfilHandler) getTipoPerfilHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe
+	if _, err := h.repo.GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar tipo de perfil")
+		return
+	}
+
+	writeHistorico(w, r, h.auditService, models.EntidadeTipoPerfil, id)
+}
+
+// getPermissaoHistorico retorna o histórico de alterações de uma permissão do catálogo
+func (h *TipoPerfilHandler) getPermissaoHistorico(w http.ResponseWriter, r *http.Request, id int64) {
+	// Verificar se o registro existe
+	if _, err := h.permissaoRepo.GetByID(r.Context(), id); err != nil {
+		problem.Error(w, r, err, "Erro ao buscar permissão")
+		return
+	}
+
+	writeHistorico(w, r, h.auditService, models.EntidadePermissao, id)
+}
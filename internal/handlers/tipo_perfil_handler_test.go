@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+)
+
+func TestTipoPerfilEscritaSomenteAdminERP(t *testing.T) {
+	env := newTestEnv(t)
+	authorizer := middleware.NewAuthorizer(env.stores.Permissoes, time.Minute, nil)
+	h := NewTipoPerfilHandler(env.stores.TiposPerfil, env.stores.Permissoes, authorizer, env.auditService)
+	usuario := env.createUsuario(t, "maria", env.seguradoraA)
+	id := fmt.Sprintf("%d", env.tipoPerfil)
+
+	// Um administrador de seguradora lê perfis, mas não altera perfis nem permissões
+	w := serve(h.HandleTipoPerfil, newRequest(http.MethodGet, "/tipos-perfil/"+id, "", usuario.ID, env.seguradoraA))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET /tipos-perfil/%s: status %d, corpo %s", id, w.Code, w.Body.String())
+	}
+	for _, tc := range []struct {
+		method string
+		target string
+		body   string
+	}{
+		{http.MethodPost, "/tipos-perfil", `{"perfil":"Gestor","ativo":true}`},
+		{http.MethodPut, "/tipos-perfil/" + id, `{"perfil":"Operador","ativo":true}`},
+		{http.MethodDelete, "/tipos-perfil/" + id, ""},
+		{http.MethodPut, "/tipos-perfil/" + id + "/permissoes", `{"permissoes":["usuarios:write"]}`},
+		{http.MethodPost, "/tipos-perfil/" + id + "/permissoes", `{"permissao":"usuarios:write"}`},
+		{http.MethodDelete, "/tipos-perfil/" + id + "/permissoes/eventos:read", ""},
+		{http.MethodPost, "/tipos-perfil/permissoes", `{"nome":"relatorios:read","ativo":true}`},
+		{http.MethodDelete, "/tipos-perfil/permissoes/1", ""},
+	} {
+		w := serve(h.HandleTipoPerfil, newRequest(tc.method, tc.target, tc.body, usuario.ID, env.seguradoraA))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status %d, esperado 403, corpo %s", tc.method, tc.target, w.Code, w.Body.String())
+		}
+	}
+	if n := env.countAudit(t, "PERMISSION_DENIED", "TIPO_PERFIL", ""); n != 8 {
+		t.Errorf("%d entradas PERMISSION_DENIED, esperadas 8", n)
+	}
+
+	// O administrador do ERP cria o perfil
+	r := newRequest(http.MethodPost, "/tipos-perfil", `{"perfil":"Gestor","ativo":true}`, usuario.ID, env.seguradoraA)
+	r = r.WithContext(context.WithValue(r.Context(), middleware.AdminERPKey, true))
+	w = serve(h.HandleTipoPerfil, r)
+	if w.Code != http.StatusCreated {
+		t.Errorf("POST /tipos-perfil como AdminERP: status %d, esperado 201, corpo %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Formatos de saída do log
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// requestIDKey é a chave do ID da requisição no contexto
+type requestIDKey struct{}
+
+// Setup configura o logger padrão (slog) com o nível e o formato informados. As mensagens do pacote
+// log também passam a ser gravadas pelo logger configurado, no nível INFO.
+func Setup(level, format string) error {
+	handler, err := NewHandler(os.Stdout, level, format)
+	if err != nil {
+		return err
+	}
+	
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// NewHandler cria o handler do log com o nível e o formato informados, incluindo em cada linha
+// o ID da requisição presente no contexto
+func NewHandler(w io.Writer, level, format string) (slog.Handler, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("nível de log inválido: %s (use debug, info, warn ou error)", level)
+	}
+	
+	opts := &slog.HandlerOptions{Level: lvl}
+	
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("formato de log inválido: %s (use %s ou %s)", format, FormatJSON, FormatText)
+	}
+	
+	return contextHandler{handler}, nil
+}
+
+// WithRequestID retorna um contexto com o ID da requisição
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext obtém o ID da requisição do contexto
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// contextHandler inclui nas linhas do log o ID da requisição presente no contexto
+type contextHandler struct {
+	slog.Handler
+}
+
+// Handle grava a linha do log com o ID da requisição
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs retorna um handler com os atributos informados, preservando o ID da requisição
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup retorna um handler com o grupo informado, preservando o ID da requisição
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
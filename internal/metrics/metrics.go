@@ -0,0 +1,182 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+)
+
+// Namespace das métricas da aplicação
+const namespace = "estudogo"
+
+// Resultados das tentativas de login
+const (
+	LoginSuccess          = "success"
+	LoginInvalid          = "invalid_credentials"
+	LoginLocked           = "locked"
+	LoginAttemptsExceeded = "attempts_exceeded"
+)
+
+// Resultados da gravação das entradas do log de auditoria
+const (
+	AuditWritten = "written"
+	AuditDropped = "dropped"
+	AuditSpilled = "spilled"
+	AuditFailed  = "failed"
+)
+
+// Registry reúne as métricas expostas em /metrics
+var Registry = prometheus.NewRegistry()
+
+var (
+	// HTTPRequests conta as requisições por rota, método e status
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Requisições HTTP atendidas, por rota, método e status.",
+	}, []string{"route", "method", "status"})
+
+	// HTTPDuration mede a latência das requisições por rota e método
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latência das requisições HTTP, por rota e método.",
+		Buckets:   []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}, []string{"route", "method"})
+
+	// HTTPInFlight mede as requisições em andamento
+	HTTPInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "http_requests_in_flight",
+		Help:      "Requisições HTTP em andamento.",
+	})
+
+	// LoginAttempts conta as tentativas de login por resultado
+	LoginAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "login_attempts_total",
+		Help:      "Tentativas de login, por resultado.",
+	}, []string{"result"})
+
+	// RateLimitRejections conta as requisições recusadas pela limitação de taxa, por regra
+	RateLimitRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_rejected_total",
+		Help:      "Requisições recusadas pela limitação de taxa, por regra.",
+	}, []string{"rule"})
+
+	// RateLimitBlocks conta os clientes bloqueados pela limitação de taxa, por regra
+	RateLimitBlocks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limit_blocks_total",
+		Help:      "Clientes bloqueados por exceder a limitação de taxa, por regra.",
+	}, []string{"rule"})
+
+	// CSRFRejections conta as requisições recusadas pela proteção CSRF, por motivo
+	CSRFRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "csrf_rejected_total",
+		Help:      "Requisições recusadas pela proteção CSRF, por motivo (missing ou invalid).",
+	}, []string{"reason"})
+
+	// AuditEntries conta as entradas do log de auditoria por resultado da gravação
+	AuditEntries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "audit_entries_total",
+		Help:      "Entradas do log de auditoria, por resultado da gravação (written, dropped, spilled ou failed).",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		HTTPInFlight,
+		LoginAttempts,
+		RateLimitRejections,
+		RateLimitBlocks,
+		CSRFRejections,
+		AuditEntries,
+	)
+}
+
+// RegisterDB expõe as estatísticas do pool de conexões com o banco de dados
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler retorna o handler HTTP que expõe as métricas no formato do Prometheus.
+// Com o token informado, exige o cabeçalho "Authorization: Bearer {token}".
+func Handler(token string) http.Handler {
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+	if token == "" {
+		return handler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+token {
+			problem.Write(w, r, http.StatusUnauthorized, "Não autorizado")
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// Middleware registra a quantidade, o status e a latência das requisições. A rota é o padrão
+// registrado no mux que atendeu a requisição (como "/eventos/"), mantendo a cardinalidade limitada.
+func Middleware(mux *http.ServeMux) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := mux.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+
+			HTTPInFlight.Inc()
+			defer HTTPInFlight.Dec()
+
+			start := time.Now()
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r)
+
+			HTTPRequests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+			HTTPDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// statusRecorder guarda o status da resposta
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader guarda o status e o envia ao cliente
+func (s *statusRecorder) WriteHeader(status int) {
+	if !s.wroteHeader {
+		s.status = status
+		s.wroteHeader = true
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// Write envia o corpo da resposta (com status 200, se ainda não definido)
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	s.wroteHeader = true
+	return s.ResponseWriter.Write(b)
+}
+
+// Unwrap permite ao http.ResponseController acessar a resposta original (flush e prazos de escrita)
+func (s *statusRecorder) Unwrap() http.ResponseWriter {
+	return s.ResponseWriter
+}
@@ -3,9 +3,13 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/clientip"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 )
 
 // Chaves para o contexto
@@ -15,78 +19,150 @@ const (
 	UserIDKey       contextKey = "user_id"
 	UsernameKey     contextKey = "username"
 	TipoPerfilIDKey contextKey = "tipo_perfil_id"
+	IdSeguradoraKey contextKey = "id_seguradora"
+	AdminERPKey     contextKey = "admin_erp"
+	TenantScopeKey  contextKey = "tenant_scope"
+	SessionIDKey    contextKey = "session_id"
 	
 	// Cabeçalhos para rotação de token
 	HeaderNewToken        = "X-New-Access-Token"
 	HeaderNewRefreshToken = "X-New-Refresh-Token"
+	
+	// Cabeçalho usado por administradores do ERP para escolher a seguradora ("*" para todas)
+	HeaderSeguradora = "X-Seguradora-ID"
 )
 
-// AuthMiddleware verifica se o usuário está autenticado
-func AuthMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Obter o token do cabeçalho Authorization
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Autorização necessária", http.StatusUnauthorized)
-			return
-		}
-		
-		// O token deve estar no formato "Bearer {token}"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			http.Error(w, "Formato de autorização inválido", http.StatusUnauthorized)
-			return
-		}
-		
-		tokenString := parts[1]
-		
-		// Validar o token
-		claims, err := auth.ValidateToken(tokenString)
-		if err != nil {
-			http.Error(w, "Token inválido: "+err.Error(), http.StatusUnauthorized)
-			return
-		}
-		
-		// Adicionar informações do usuário ao contexto da requisição
-		ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
-		ctx = context.WithValue(ctx, UsernameKey, claims.Username)
-		ctx = context.WithValue(ctx, TipoPerfilIDKey, claims.TipoPerfilID)
-		
-		// Verificar se o token precisa ser renovado
-		if auth.ShouldRefreshToken(claims) {
-			// Gerar um novo token
-			newToken, err := auth.GenerateToken(claims.UserID, claims.Username, claims.TipoPerfilID)
-			if err == nil {
-				// Adicionar o novo token ao cabeçalho da resposta
-				w.Header().Add(HeaderNewToken, newToken)
+// SessionValidator verifica se a sessão de um token de acesso ainda está ativa
+type SessionValidator interface {
+	IsSessionActive(ctx context.Context, sessionID string) (bool, error)
+}
+
+// AuthMiddleware verifica se o usuário está autenticado e se a sessão do token não foi revogada
+// (logout, reuso de refresh token, usuário desativado ou bloqueado)
+func AuthMiddleware(sessions SessionValidator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Obter o token do cabeçalho Authorization
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				problem.Write(w, r, http.StatusUnauthorized, "Autorização necessária")
+				return
+			}
+			
+			// O token deve estar no formato "Bearer {token}"
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				problem.Write(w, r, http.StatusUnauthorized, "Formato de autorização inválido")
+				return
+			}
+			
+			tokenString := parts[1]
+			
+			// Validar o token
+			claims, err := auth.ValidateToken(tokenString)
+			if err != nil {
+				problem.Write(w, r, http.StatusUnauthorized, "Token inválido: "+err.Error())
+				return
+			}
+			
+			// Verificar se a sessão do token não foi revogada
+			active, err := sessions.IsSessionActive(r.Context(), claims.SessionID)
+			if err != nil {
+				problem.Error(w, r, err, "Erro ao verificar sessão")
+				return
 			}
-		}
-		
-		// Chamar o próximo handler com o contexto atualizado
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+			if !active {
+				problem.Write(w, r, http.StatusUnauthorized, "Sessão encerrada ou revogada")
+				return
+			}
+			
+			// Adicionar informações do usuário ao contexto da requisição
+			ctx := context.WithValue(r.Context(), UserIDKey, claims.UserID)
+			ctx = context.WithValue(ctx, UsernameKey, claims.Username)
+			ctx = context.WithValue(ctx, TipoPerfilIDKey, claims.TipoPerfilID)
+			ctx = context.WithValue(ctx, IdSeguradoraKey, claims.IdSeguradora)
+			ctx = context.WithValue(ctx, AdminERPKey, claims.AdminERP)
+			ctx = context.WithValue(ctx, SessionIDKey, claims.SessionID)
+			
+			// Verificar se o token precisa ser renovado
+			if auth.ShouldRefreshToken(claims) {
+				// Gerar um novo token na mesma sessão
+				newToken, err := auth.GenerateToken(claims.Identity, claims.SessionID)
+				if err == nil {
+					// Adicionar o novo token ao cabeçalho da resposta
+					w.Header().Add(HeaderNewToken, newToken)
+				}
+			}
+			
+			// Chamar o próximo handler com o contexto atualizado
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
-// RequireAdmin verifica se o usuário tem perfil de administrador
-func RequireAdmin(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Obter o tipo de perfil do contexto
-		tipoPerfilID, ok := r.Context().Value(TipoPerfilIDKey).(int)
-		if !ok {
-			http.Error(w, "Erro ao obter tipo de perfil", http.StatusInternalServerError)
-			return
-		}
-		
-		// Verificar se é administrador (assumindo que o ID 1 é o perfil de administrador)
-		// Em uma implementação real, você pode ter uma lista de perfis com permissões
-		if tipoPerfilID != 1 {
-			http.Error(w, "Acesso negado: permissão de administrador necessária", http.StatusForbidden)
-			return
-		}
-		
-		// Chamar o próximo handler
-		next.ServeHTTP(w, r)
-	})
+// TenantMiddleware resolve o escopo de seguradora da requisição a partir do token.
+// Usuários comuns ficam restritos à própria seguradora; usuários AdminERP podem
+// escolher outra seguradora (ou todas) explicitamente pelo cabeçalho X-Seguradora-ID.
+// A função onDenied é chamada quando a requisição tenta acessar outra seguradora sem permissão.
+func TenantMiddleware(onDenied func(r *http.Request, requested string)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Obter a seguradora e o privilégio do usuário do contexto
+			idSeguradora, ok := GetIdSeguradoraFromContext(r.Context())
+			if !ok {
+				problem.Write(w, r, http.StatusInternalServerError, "Erro ao obter seguradora do usuário")
+				return
+			}
+			adminERP, _ := GetAdminERPFromContext(r.Context())
+			
+			// Por padrão, o escopo é a seguradora do próprio usuário
+			scope := models.TenantScope{
+				IdSeguradora: idSeguradora,
+				AdminERP:     adminERP,
+			}
+			
+			// Verificar se foi solicitada outra seguradora explicitamente
+			requested := strings.TrimSpace(r.Header.Get(HeaderSeguradora))
+			if requested != "" {
+				denied := false
+				
+				if requested == "*" {
+					// Acesso a todas as seguradoras
+					if adminERP {
+						scope.Todas = true
+					} else {
+						denied = true
+					}
+				} else {
+					requestedID, err := strconv.ParseInt(requested, 10, 64)
+					if err != nil || requestedID <= 0 {
+						problem.Write(w, r, http.StatusBadRequest, "Cabeçalho "+HeaderSeguradora+" inválido")
+						return
+					}
+					
+					if requestedID != idSeguradora {
+						if adminERP {
+							scope.IdSeguradora = requestedID
+						} else {
+							denied = true
+						}
+					}
+				}
+				
+				if denied {
+					if onDenied != nil {
+						onDenied(r, requested)
+					}
+					problem.WriteCode(w, r, http.StatusForbidden, problem.CodeCrossTenant, "Acesso negado: dados de outra seguradora")
+					return
+				}
+			}
+			
+			// Adicionar o escopo ao contexto da requisição
+			ctx := context.WithValue(r.Context(), TenantScopeKey, scope)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
 }
 
 // GetUserIDFromContext obtém o ID do usuário do contexto
@@ -107,15 +183,54 @@ func GetTipoPerfilIDFromContext(ctx context.Context) (int, bool) {
 	return tipoPerfilID, ok
 }
 
-// GetClientIP obtém o endereço IP do cliente
-func GetClientIP(r *http.Request) string {
-	// Tentar obter o IP real se estiver atrás de um proxy
-	ip := r.Header.Get("X-Real-IP")
-	if ip == "" {
-		ip = r.Header.Get("X-Forwarded-For")
+// GetIdSeguradoraFromContext obtém o ID da seguradora do usuário do contexto
+func GetIdSeguradoraFromContext(ctx context.Context) (int64, bool) {
+	idSeguradora, ok := ctx.Value(IdSeguradoraKey).(int64)
+	return idSeguradora, ok
+}
+
+// GetAdminERPFromContext indica se o usuário do contexto é administrador do ERP
+func GetAdminERPFromContext(ctx context.Context) (bool, bool) {
+	adminERP, ok := ctx.Value(AdminERPKey).(bool)
+	return adminERP, ok
+}
+
+// GetSessionIDFromContext obtém a sessão (família de refresh tokens) do token de acesso do contexto
+func GetSessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value(SessionIDKey).(string)
+	return sessionID, ok && sessionID != ""
+}
+
+// GetTenantScopeFromContext obtém o escopo de seguradora da requisição.
+// Se o escopo não estiver presente, retorna um escopo que não enxerga nenhuma seguradora.
+func GetTenantScopeFromContext(ctx context.Context) models.TenantScope {
+	scope, ok := ctx.Value(TenantScopeKey).(models.TenantScope)
+	if !ok {
+		return models.TenantScope{}
+	}
+	return scope
+}
+
+// ActorFromRequest identifica o usuário autenticado e a origem da requisição,
+// para o registro das alterações no histórico
+func ActorFromRequest(r *http.Request) models.Actor {
+	userID, _ := GetUserIDFromContext(r.Context())
+	username, _ := GetUsernameFromContext(r.Context())
+	requestID, _ := GetRequestIDFromContext(r.Context())
+	return models.Actor{
+		UserID:    userID,
+		Username:  username,
+		IPAddress: clientip.FromRequest(r),
+		RequestID: requestID,
 	}
-	if ip == "" {
-		ip = r.RemoteAddr
+}
+
+// RateLimitKeyByUser identifica o usuário autenticado para a limitação de taxa.
+// Retorna uma string vazia (limitação pelo IP) se a requisição não estiver autenticada.
+func RateLimitKeyByUser(r *http.Request) string {
+	userID, ok := GetUserIDFromContext(r.Context())
+	if !ok {
+		return ""
 	}
-	return ip
+	return "usuario:" + strconv.FormatInt(userID, 10)
 }
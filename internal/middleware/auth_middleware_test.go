@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	var negadas []string
+	var scope models.TenantScope
+	handler := TenantMiddleware(func(r *http.Request, requested string) {
+		negadas = append(negadas, requested)
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope = GetTenantScopeFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for _, tc := range []struct {
+		name     string
+		adminERP bool
+		header   string
+		status   int
+		scope    models.TenantScope
+	}{
+		{"usuário sem cabeçalho", false, "", http.StatusNoContent, models.TenantScope{IdSeguradora: 1}},
+		{"usuário na própria seguradora", false, "1", http.StatusNoContent, models.TenantScope{IdSeguradora: 1}},
+		{"usuário em outra seguradora", false, "2", http.StatusForbidden, models.TenantScope{}},
+		{"usuário em todas as seguradoras", false, "*", http.StatusForbidden, models.TenantScope{}},
+		{"cabeçalho inválido", false, "abc", http.StatusBadRequest, models.TenantScope{}},
+		{"AdminERP sem cabeçalho", true, "", http.StatusNoContent, models.TenantScope{IdSeguradora: 1, AdminERP: true}},
+		{"AdminERP em outra seguradora", true, "2", http.StatusNoContent, models.TenantScope{IdSeguradora: 2, AdminERP: true}},
+		{"AdminERP em todas as seguradoras", true, "*", http.StatusNoContent, models.TenantScope{IdSeguradora: 1, AdminERP: true, Todas: true}},
+	} {
+		scope = models.TenantScope{}
+		r := httptest.NewRequest(http.MethodGet, "/eventos", nil)
+		if tc.header != "" {
+			r.Header.Set(HeaderSeguradora, tc.header)
+		}
+		ctx := context.WithValue(r.Context(), IdSeguradoraKey, int64(1))
+		ctx = context.WithValue(ctx, AdminERPKey, tc.adminERP)
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r.WithContext(ctx))
+		if w.Code != tc.status {
+			t.Errorf("%s: status %d, esperado %d", tc.name, w.Code, tc.status)
+		}
+		if scope != tc.scope {
+			t.Errorf("%s: escopo %+v, esperado %+v", tc.name, scope, tc.scope)
+		}
+	}
+
+	// Somente as tentativas de acesso a outra seguradora são notificadas
+	if len(negadas) != 2 || negadas[0] != "2" || negadas[1] != "*" {
+		t.Errorf("acessos negados notificados = %v, esperado [2 *]", negadas)
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+)
+
+// PermissionLoader carrega os nomes das permissões de um tipo de perfil
+type PermissionLoader interface {
+	GetNomesByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]string, error)
+}
+
+// cachedPermissions armazena as permissões de um perfil por um tempo limitado
+type cachedPermissions struct {
+	permissions map[string]bool
+	expiresAt   time.Time
+}
+
+// Authorizer verifica as permissões do perfil do usuário para cada rota
+type Authorizer struct {
+	loader   PermissionLoader
+	cache    map[int]cachedPermissions
+	mu       sync.Mutex
+	cacheTTL time.Duration
+	onDenied func(r *http.Request, permission string)
+}
+
+// NewAuthorizer cria um novo verificador de permissões.
+// A função onDenied é chamada quando uma requisição é negada por falta de permissão.
+func NewAuthorizer(loader PermissionLoader, cacheTTL time.Duration, onDenied func(r *http.Request, permission string)) *Authorizer {
+	return &Authorizer{
+		loader:   loader,
+		cache:    make(map[int]cachedPermissions),
+		cacheTTL: cacheTTL,
+		onDenied: onDenied,
+	}
+}
+
+// HasPermission verifica se um tipo de perfil possui a permissão informada
+func (a *Authorizer) HasPermission(ctx context.Context, tipoPerfilID int, permission string) (bool, error) {
+	a.mu.Lock()
+	cached, ok := a.cache[tipoPerfilID]
+	a.mu.Unlock()
+	
+	// Recarregar as permissões se não estiverem em cache ou se tiverem expirado
+	if !ok || time.Now().After(cached.expiresAt) {
+		nomes, err := a.loader.GetNomesByTipoPerfil(ctx, int64(tipoPerfilID))
+		if err != nil {
+			return false, err
+		}
+		
+		cached = cachedPermissions{
+			permissions: make(map[string]bool, len(nomes)),
+			expiresAt:   time.Now().Add(a.cacheTTL),
+		}
+		for _, nome := range nomes {
+			cached.permissions[nome] = true
+		}
+		
+		a.mu.Lock()
+		a.cache[tipoPerfilID] = cached
+		a.mu.Unlock()
+	}
+	
+	return cached.permissions[permission], nil
+}
+
+// Invalidate descarta as permissões em cache (usado quando as permissões são alteradas)
+func (a *Authorizer) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	
+	a.cache = make(map[int]cachedPermissions)
+}
+
+// Require cria um middleware que exige uma permissão específica
+func (a *Authorizer) Require(permission string) func(http.Handler) http.Handler {
+	return a.require(func(r *http.Request) string {
+		return permission
+	})
+}
+
+// RequireResource cria um middleware que exige a permissão de leitura do recurso para
+// métodos seguros (GET, HEAD, OPTIONS) e a permissão de escrita para os demais métodos
+func (a *Authorizer) RequireResource(resource string) func(http.Handler) http.Handler {
+	return a.require(func(r *http.Request) string {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			return models.PermissaoLeitura(resource)
+		}
+		return models.PermissaoEscrita(resource)
+	})
+}
+
+// require cria o middleware de verificação a partir da permissão exigida pela requisição
+func (a *Authorizer) require(permissionFor func(r *http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Obter o tipo de perfil do contexto
+			tipoPerfilID, ok := GetTipoPerfilIDFromContext(r.Context())
+			if !ok {
+				problem.Write(w, r, http.StatusInternalServerError, "Erro ao obter tipo de perfil")
+				return
+			}
+			
+			// Verificar a permissão exigida pela rota e método
+			permission := permissionFor(r)
+			allowed, err := a.HasPermission(r.Context(), tipoPerfilID, permission)
+			if err != nil {
+				problem.Error(w, r, err, "Erro ao verificar permissões")
+				return
+			}
+			
+			if !allowed {
+				if a.onDenied != nil {
+					a.onDenied(r, permission)
+				}
+				problem.Write(w, r, http.StatusForbidden, "Acesso negado: permissão "+permission+" necessária")
+				return
+			}
+			
+			// Chamar o próximo handler
+			next.ServeHTTP(w, r)
+		})
+	}
+}
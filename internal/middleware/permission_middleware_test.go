@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stubPermissionLoader retorna as permissões fixas de cada tipo de perfil e conta as consultas
+type stubPermissionLoader struct {
+	permissoes map[int64][]string
+	consultas  int
+}
+
+func (l *stubPermissionLoader) GetNomesByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]string, error) {
+	l.consultas++
+	return l.permissoes[idTipoPerfil], nil
+}
+
+// withTipoPerfil cria uma requisição autenticada com o tipo de perfil informado
+func withTipoPerfil(method string, tipoPerfilID int) *http.Request {
+	r := httptest.NewRequest(method, "/eventos", nil)
+	return r.WithContext(context.WithValue(r.Context(), TipoPerfilIDKey, tipoPerfilID))
+}
+
+func TestRequireResource(t *testing.T) {
+	loader := &stubPermissionLoader{permissoes: map[int64][]string{
+		1: {"eventos:read", "eventos:write"},
+		2: {"eventos:read"},
+		3: {"usuarios:read", "usuarios:write"},
+	}}
+	var negadas []string
+	authorizer := NewAuthorizer(loader, time.Minute, func(r *http.Request, permission string) {
+		negadas = append(negadas, permission)
+	})
+	handler := authorizer.RequireResource("eventos")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// Leitura para métodos seguros e escrita para os demais, por perfil
+	for _, tc := range []struct {
+		method       string
+		tipoPerfilID int
+		status       int
+	}{
+		{http.MethodGet, 1, http.StatusNoContent},
+		{http.MethodPost, 1, http.StatusNoContent},
+		{http.MethodDelete, 1, http.StatusNoContent},
+		{http.MethodGet, 2, http.StatusNoContent},
+		{http.MethodHead, 2, http.StatusNoContent},
+		{http.MethodPut, 2, http.StatusForbidden},
+		{http.MethodGet, 3, http.StatusForbidden},
+		{http.MethodPost, 3, http.StatusForbidden},
+		{http.MethodGet, 4, http.StatusForbidden},
+	} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, withTipoPerfil(tc.method, tc.tipoPerfilID))
+		if w.Code != tc.status {
+			t.Errorf("%s com perfil %d: status %d, esperado %d", tc.method, tc.tipoPerfilID, w.Code, tc.status)
+		}
+	}
+
+	want := []string{"eventos:write", "eventos:read", "eventos:write", "eventos:read"}
+	if len(negadas) != len(want) {
+		t.Fatalf("permissões negadas = %v, esperado %v", negadas, want)
+	}
+	for i := range want {
+		if negadas[i] != want[i] {
+			t.Errorf("permissões negadas = %v, esperado %v", negadas, want)
+			break
+		}
+	}
+
+	// Sem tipo de perfil no contexto, a requisição não chega ao handler
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/eventos", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("sem tipo de perfil: status %d, esperado 500", w.Code)
+	}
+}
+
+func TestAuthorizerCache(t *testing.T) {
+	loader := &stubPermissionLoader{permissoes: map[int64][]string{1: {"eventos:read"}}}
+	authorizer := NewAuthorizer(loader, time.Minute, nil)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if ok, err := authorizer.HasPermission(ctx, 1, "eventos:read"); err != nil || !ok {
+			t.Fatalf("HasPermission = %v, %v, esperado true", ok, err)
+		}
+	}
+	if loader.consultas != 1 {
+		t.Errorf("%d consultas ao carregador, esperada 1 (permissões em cache)", loader.consultas)
+	}
+
+	// Alteradas as permissões, o cache descartado passa a refletir a alteração
+	loader.permissoes[1] = []string{"eventos:read", "eventos:write"}
+	if ok, _ := authorizer.HasPermission(ctx, 1, "eventos:write"); ok {
+		t.Error("permissão concedida antes de descartar o cache")
+	}
+	authorizer.Invalidate()
+	if ok, _ := authorizer.HasPermission(ctx, 1, "eventos:write"); !ok {
+		t.Error("permissão negada após descartar o cache")
+	}
+	if loader.consultas != 2 {
+		t.Errorf("%d consultas ao carregador, esperadas 2", loader.consultas)
+	}
+}
@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/clientip"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/logging"
+)
+
+// HeaderRequestID é o cabeçalho com o ID da requisição, recebido do cliente ou gerado pela aplicação
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDRegex valida os IDs recebidos do cliente (evita injeção no log e na auditoria)
+var requestIDRegex = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// Rotas consultadas com frequência pelo orquestrador e pelo Prometheus, registradas apenas no nível DEBUG
+var quietPaths = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+// RequestIDMiddleware atribui um ID a cada requisição, ou propaga o recebido no cabeçalho X-Request-ID,
+// devolvendo-o no cabeçalho da resposta. O ID é incluído no contexto (e, assim, nas linhas do log e
+// no log de auditoria). Ao final, registra a requisição no log com o status e a duração.
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := strings.TrimSpace(r.Header.Get(HeaderRequestID))
+		if !requestIDRegex.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+		
+		w.Header().Set(HeaderRequestID, requestID)
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		r = r.WithContext(ctx)
+		
+		start := time.Now()
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r)
+		
+		level := slog.LevelInfo
+		switch {
+		case recorder.status >= 500:
+			level = slog.LevelError
+		case quietPaths[r.URL.Path]:
+			level = slog.LevelDebug
+		}
+		slog.Log(ctx, level, "Requisição atendida",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", recorder.status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"ip", clientip.FromRequest(r),
+		)
+	})
+}
+
+// GetRequestIDFromContext obtém o ID da requisição do contexto
+func GetRequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID := logging.RequestIDFromContext(ctx)
+	return requestID, requestID != ""
+}
+
+// newRequestID gera um ID aleatório de requisição
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strings.ReplaceAll(time.Now().UTC().Format("20060102150405.000000000"), ".", "")
+	}
+	return hex.EncodeToString(b)
+}
+
+// responseRecorder guarda o status da resposta
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// WriteHeader guarda o status e o envia ao cliente
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status = status
+		rr.wroteHeader = true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+// Write envia o corpo da resposta (com status 200, se ainda não definido)
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	return rr.ResponseWriter.Write(b)
+}
+
+// Unwrap permite ao http.ResponseController acessar a resposta original (flush e prazos de escrita)
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
@@ -0,0 +1,343 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditChainHead representa a última entrada encadeada do log de auditoria
+type AuditChainHead struct {
+	LastID   int64
+	LastHash string
+}
+
+// AuditCheckpoint representa um ponto de verificação assinado da cadeia do log de auditoria
+type AuditCheckpoint struct {
+	ID          int64     `json:"id"`
+	LastAuditID int64     `json:"last_audit_id"`
+	LastHash    string    `json:"last_hash"`
+	Entries     int64     `json:"entries"`   // Entradas encadeadas até last_audit_id
+	KeyID       string    `json:"key_id"`    // kid da chave que assinou o ponto de verificação
+	Signature   string    `json:"signature"` // JWS compacto com last_audit_id, last_hash e entries
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// AuditChainVerification representa o resultado da verificação da cadeia do log de auditoria
+type AuditChainVerification struct {
+	Valid                 bool   `json:"valid"`
+	CheckedEntries        int64  `json:"checked_entries"`
+	LegacyEntries         int64  `json:"legacy_entries"` // Registros anteriores ao encadeamento (sem hash)
+	CheckedCheckpoints    int    `json:"checked_checkpoints"`
+	UnverifiedCheckpoints int    `json:"unverified_checkpoints"` // Assinados por chaves que não estão mais configuradas
+	LastID                int64  `json:"last_id"`
+	LastHash              string `json:"last_hash"`
+	BrokenAt              *int64 `json:"broken_at,omitempty"` // ID do primeiro registro com o encadeamento quebrado
+	Reason                string `json:"reason,omitempty"`
+}
+
+// fail marca a verificação como inválida no registro informado
+func (v *AuditChainVerification) fail(id int64, reason string) {
+	v.Valid = false
+	v.BrokenAt = &id
+	v.Reason = reason
+}
+
+// Campos de listagem dos pontos de verificação
+var auditCheckpointListSpec = ListSpec{
+	Key: "id",
+	Fields: map[string]ListField{
+		"id":            {Column: "id", Type: FieldInt},
+		"last_audit_id": {Column: "last_audit_id", Type: FieldInt},
+		"entries":       {Column: "entries", Type: FieldInt},
+		"key_id":        {Column: "key_id", Type: FieldString},
+		"created_at":    {Column: "created_at", Type: FieldTime},
+	},
+}
+
+// Consulta base dos pontos de verificação
+const auditCheckpointQuery = `
+	SELECT id, last_audit_id, last_hash, entries, key_id, signature, created_at
+	FROM audit_checkpoints
+	WHERE 1 = 1`
+
+// auditEntryHash calcula o hash de uma entrada do log de auditoria: SHA-256 do hash da entrada
+// anterior seguido do conteúdo da entrada serializado em JSON, com os campos em ordem fixa.
+// O ID não faz parte do conteúdo: remoções e inserções são detectadas pelo encadeamento.
+// O ID da requisição só entra no conteúdo quando presente, preservando o hash das entradas anteriores a ele.
+func auditEntryHash(l *AuditLog) string {
+	content, _ := json.Marshal(struct {
+		UserID     *int64 `json:"user_id"`
+		Username   string `json:"username"`
+		Action     string `json:"action"`
+		EntityType string `json:"entity_type"`
+		EntityID   string `json:"entity_id"`
+		Details    string `json:"details"`
+		IPAddress  string `json:"ip_address"`
+		RequestID  string `json:"request_id,omitempty"`
+		Changes    string `json:"changes"`
+		CreatedAt  string `json:"created_at"`
+	}{
+		UserID:     l.UserID,
+		Username:   l.Username,
+		Action:     l.Action,
+		EntityType: l.EntityType,
+		EntityID:   l.EntityID,
+		Details:    l.Details,
+		IPAddress:  l.IPAddress,
+		RequestID:  l.RequestID,
+		Changes:    string(l.Changes),
+		CreatedAt:  l.CreatedAt.Format("2006-01-02 15:04:05"),
+	})
+	
+	sum := sha256.Sum256(append([]byte(l.PrevHash+"\n"), content...))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChainHead retorna a última entrada encadeada do log de auditoria
+func (r *AuditLogRepository) ChainHead(ctx context.Context) (*AuditChainHead, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	var head AuditChainHead
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, "SELECT last_id, last_hash FROM audit_chain_head WHERE id = 1").Scan(&head.LastID, &head.LastHash)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao obter o topo da cadeia de auditoria: %w", err)
+	}
+	
+	return &head, nil
+}
+
+// CountChained retorna a quantidade de entradas encadeadas até o registro informado
+func (r *AuditLogRepository) CountChained(ctx context.Context, lastID int64) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	var count int64
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, "SELECT COUNT(*) FROM audit_log WHERE hash IS NOT NULL AND id <= ?", lastID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao contar registros de auditoria: %w", err)
+	}
+	
+	return count, nil
+}
+
+// CreateCheckpoint grava um ponto de verificação assinado
+func (r *AuditLogRepository) CreateCheckpoint(ctx context.Context, cp *AuditCheckpoint) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	INSERT INTO audit_checkpoints
+	(last_audit_id, last_hash, entries, key_id, signature)
+	VALUES (?, ?, ?, ?, ?)`
+	
+	result, err := execInsert(ctx, connFor(ctx, r.DB), "id", query, cp.LastAuditID, cp.LastHash, cp.Entries, cp.KeyID, cp.Signature)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar ponto de verificação: %w", err)
+	}
+	
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("erro ao obter ID do ponto de verificação: %w", err)
+	}
+	
+	cp.ID = id
+	return nil
+}
+
+// GetLastCheckpoint retorna o ponto de verificação mais recente, ou nil se não houver nenhum
+func (r *AuditLogRepository) GetLastCheckpoint(ctx context.Context) (*AuditCheckpoint, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	cp, err := scanAuditCheckpointRow(connFor(ctx, r.DB).QueryRowContext(ctx, auditCheckpointQuery + " ORDER BY id DESC LIMIT 1"))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar ponto de verificação: %w", err)
+	}
+	
+	return &cp, nil
+}
+
+// GetCheckpoints retorna uma página dos pontos de verificação, aplicando filtros e ordenação
+func (r *AuditLogRepository) GetCheckpoints(ctx context.Context, opts ListOptions) (*Page[AuditCheckpoint], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	return listPage(ctx, r.DB, &auditCheckpointListSpec, opts, auditCheckpointQuery, nil, func(rows *sql.Rows) ([]AuditCheckpoint, error) {
+		var checkpoints []AuditCheckpoint
+		for rows.Next() {
+			cp, err := scanAuditCheckpointRow(rows)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao ler ponto de verificação: %w", err)
+			}
+			checkpoints = append(checkpoints, cp)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao iterar sobre pontos de verificação: %w", err)
+		}
+		return checkpoints, nil
+	})
+}
+
+// AllCheckpoints retorna todos os pontos de verificação, do mais antigo para o mais recente
+func (r *AuditLogRepository) AllCheckpoints(ctx context.Context) ([]AuditCheckpoint, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, auditCheckpointQuery + " ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar pontos de verificação: %w", err)
+	}
+	defer rows.Close()
+	
+	var checkpoints []AuditCheckpoint
+	for rows.Next() {
+		cp, err := scanAuditCheckpointRow(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler ponto de verificação: %w", err)
+		}
+		checkpoints = append(checkpoints, cp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre pontos de verificação: %w", err)
+	}
+	
+	return checkpoints, nil
+}
+
+// VerifyChain percorre a cadeia do log de auditoria, recalculando o hash de cada entrada, e
+// retorna o primeiro encadeamento quebrado. As entradas também são comparadas com os pontos de
+// verificação informados (cujas assinaturas devem ser verificadas antes) e com o topo da cadeia.
+// Como as exportações, a verificação lê todo o log e não tem tempo limite: é interrompida apenas pelo
+// cancelamento do contexto.
+func (r *AuditLogRepository) VerifyChain(ctx context.Context, checkpoints []AuditCheckpoint) (*AuditChainVerification, error) {
+	// Entradas gravadas depois da leitura do topo da cadeia não são verificadas
+	head, err := r.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, auditLogQuery+" AND id <= ? ORDER BY id", head.LastID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar registros de auditoria: %w", err)
+	}
+	defer rows.Close()
+	
+	verifier := newChainVerifier(checkpoints)
+	for rows.Next() {
+		l, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		if !verifier.check(&l) {
+			return verifier.result, nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre registros de auditoria: %w", err)
+	}
+	
+	return verifier.finish(checkpoints, head), nil
+}
+
+// chainVerifier verifica o encadeamento das entradas do log de auditoria, informadas em ordem de ID
+type chainVerifier struct {
+	result    *AuditChainVerification
+	pendentes map[int64][]AuditCheckpoint // Pontos de verificação por registro
+	prevHash  string
+	encadeado bool
+}
+
+// newChainVerifier cria a verificação da cadeia com os pontos de verificação informados
+func newChainVerifier(checkpoints []AuditCheckpoint) *chainVerifier {
+	v := &chainVerifier{
+		result:    &AuditChainVerification{Valid: true},
+		pendentes: make(map[int64][]AuditCheckpoint),
+	}
+	for _, cp := range checkpoints {
+		v.pendentes[cp.LastAuditID] = append(v.pendentes[cp.LastAuditID], cp)
+	}
+	return v
+}
+
+// check verifica a próxima entrada da cadeia e retorna false no primeiro encadeamento quebrado
+func (v *chainVerifier) check(l *AuditLog) bool {
+	result := v.result
+	
+	// Registros anteriores ao encadeamento não possuem hash
+	if l.Hash == "" && !v.encadeado {
+		result.LegacyEntries++
+		return true
+	}
+	v.encadeado = true
+	
+	switch {
+	case l.Hash == "":
+		result.fail(l.ID, "registro sem hash após o topo da cadeia")
+	case l.PrevHash != v.prevHash:
+		result.fail(l.ID, "hash anterior não corresponde ao registro anterior (registros removidos ou inseridos)")
+	case auditEntryHash(l) != l.Hash:
+		result.fail(l.ID, "conteúdo do registro alterado")
+	}
+	if !result.Valid {
+		return false
+	}
+	
+	for _, cp := range v.pendentes[l.ID] {
+		if cp.LastHash != l.Hash || cp.Entries != result.CheckedEntries+1 {
+			result.fail(l.ID, fmt.Sprintf("registro diverge do ponto de verificação %d", cp.ID))
+			return false
+		}
+		result.CheckedCheckpoints++
+	}
+	delete(v.pendentes, l.ID)
+	
+	result.CheckedEntries++
+	result.LastID = l.ID
+	result.LastHash = l.Hash
+	v.prevHash = l.Hash
+	return true
+}
+
+// finish conclui a verificação após a última entrada, comparando-a com o topo da cadeia
+func (v *chainVerifier) finish(checkpoints []AuditCheckpoint, head *AuditChainHead) *AuditChainVerification {
+	result := v.result
+	
+	// Pontos de verificação cujo registro não foi encontrado indicam registros removidos
+	for _, cp := range checkpoints {
+		if _, ok := v.pendentes[cp.LastAuditID]; ok {
+			result.fail(cp.LastAuditID, fmt.Sprintf("registro do ponto de verificação %d não encontrado (registros removidos)", cp.ID))
+			return result
+		}
+	}
+	
+	// O último registro deve ser o registrado no topo da cadeia
+	if result.LastID != head.LastID || result.LastHash != head.LastHash {
+		result.fail(head.LastID, "último registro da cadeia removido ou alterado")
+	}
+	
+	return result
+}
+
+// scanAuditCheckpointRow lê um ponto de verificação de uma consulta de várias linhas ou de uma única linha
+func scanAuditCheckpointRow(row interface{ Scan(dest ...interface{}) error }) (AuditCheckpoint, error) {
+	var cp AuditCheckpoint
+	err := row.Scan(
+		&cp.ID,
+		&cp.LastAuditID,
+		&cp.LastHash,
+		&cp.Entries,
+		&cp.KeyID,
+		&cp.Signature,
+		&cp.CreatedAt,
+	)
+	return cp, err
+}
@@ -0,0 +1,514 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// AuditLog representa um registro do log de auditoria
+type AuditLog struct {
+	ID         int64     `json:"id"`
+	UserID     *int64    `json:"user_id"`
+	Username   string    `json:"username"`
+	Action     string    `json:"action"`
+	EntityType string    `json:"entity_type"`
+	EntityID   string    `json:"entity_id"`
+	Details    string    `json:"details"`
+	IPAddress  string          `json:"ip_address"`
+	RequestID  string          `json:"request_id,omitempty"` // ID da requisição (X-Request-ID) que gerou a entrada
+	Changes    json.RawMessage `json:"changes,omitempty"` // Alterações estruturadas: {"campo": {"old": ..., "new": ...}}
+	PrevHash   string          `json:"prev_hash,omitempty"` // Hash da entrada anterior na cadeia
+	Hash       string          `json:"hash,omitempty"`      // Hash desta entrada (conteúdo + hash anterior)
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// LoginAttempt representa uma tentativa de login registrada
+type LoginAttempt struct {
+	ID          int64     `json:"id"`
+	Login       string    `json:"login"`
+	IPAddress   string    `json:"ip_address"`
+	Success     bool      `json:"success"`
+	AttemptTime time.Time `json:"attempt_time"`
+}
+
+// Campos de listagem do log de auditoria
+var auditLogListSpec = ListSpec{
+	Key: "id",
+	Fields: map[string]ListField{
+		"id":          {Column: "id", Type: FieldInt},
+		"user_id":     {Column: "user_id", Type: FieldInt, Nullable: true},
+		"username":    {Column: "COALESCE(username, '')", Type: FieldString},
+		"action":      {Column: "action", Type: FieldString},
+		"entity_type": {Column: "entity_type", Type: FieldString},
+		"entity_id":   {Column: "COALESCE(entity_id, '')", Type: FieldString},
+		"details":     {Column: "COALESCE(details, '')", Type: FieldString},
+		"ip_address":  {Column: "ip_address", Type: FieldString},
+		"request_id":  {Column: "COALESCE(request_id, '')", Type: FieldString},
+		"created_at":  {Column: "created_at", Type: FieldTime},
+	},
+}
+
+// Campos de listagem das tentativas de login
+var loginAttemptListSpec = ListSpec{
+	Key: "id",
+	Fields: map[string]ListField{
+		"id":           {Column: "id", Type: FieldInt},
+		"login":        {Column: "login", Type: FieldString},
+		"ip_address":   {Column: "ip_address", Type: FieldString},
+		"success":      {Column: "success", Type: FieldBool},
+		"attempt_time": {Column: "attempt_time", Type: FieldTime},
+	},
+}
+
+// Consultas base do log de auditoria e das tentativas de login
+const (
+	auditLogQuery = `
+	SELECT id, user_id, COALESCE(username, ''), action, entity_type,
+		COALESCE(entity_id, ''), COALESCE(details, ''), ip_address, COALESCE(request_id, ''), changes,
+		COALESCE(prev_hash, ''), COALESCE(hash, ''), created_at
+	FROM audit_log
+	WHERE 1 = 1`
+
+	loginAttemptQuery = `
+	SELECT id, login, ip_address, success, attempt_time
+	FROM login_attempts
+	WHERE 1 = 1`
+)
+
+// AuditLogRepository gerencia as consultas ao log de auditoria e às tentativas de login.
+// Os registros não pertencem a uma seguradora: o acesso é restrito a administradores.
+type AuditLogRepository struct {
+	DB *sql.DB
+}
+
+// NewAuditLogRepository cria um novo repositório do log de auditoria
+func NewAuditLogRepository(db *sql.DB) *AuditLogRepository {
+	return &AuditLogRepository{DB: db}
+}
+
+// Create registra uma entrada no log de auditoria, encadeada à entrada anterior. Dentro de uma unidade
+// de trabalho, a entrada fica pendente e é encadeada após a confirmação da transação.
+func (r *AuditLogRepository) Create(ctx context.Context, entry *AuditLog) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	if InTransaction(ctx) {
+		return insertAuditLog(ctx, connFor(ctx, r.DB), entry)
+	}
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		return chainAuditLogs(ctx, tx, []*AuditLog{entry})
+	})
+}
+
+// GetHistorico retorna o histórico de alterações estruturadas de um registro, com paginação e filtros
+func (r *AuditLogRepository) GetHistorico(ctx context.Context, entityType string, entityID int64, opts ListOptions) (*Page[AuditLog], error) {
+	query := auditLogQuery + " AND changes IS NOT NULL"
+	opts = opts.WithFilter("entity_type", entityType).WithFilter("entity_id", entityID)
+	return r.list(ctx, query, opts)
+}
+
+// GetAll retorna uma página do log de auditoria, aplicando filtros e ordenação
+func (r *AuditLogRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[AuditLog], error) {
+	return r.list(ctx, auditLogQuery, opts)
+}
+
+// list retorna uma página do log de auditoria a partir da consulta base informada
+func (r *AuditLogRepository) list(ctx context.Context, query string, opts ListOptions) (*Page[AuditLog], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	return listPage(ctx, r.DB, &auditLogListSpec, opts, query, nil, func(rows *sql.Rows) ([]AuditLog, error) {
+		var logs []AuditLog
+		for rows.Next() {
+			l, err := scanAuditLog(rows)
+			if err != nil {
+				return nil, err
+			}
+			logs = append(logs, l)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao iterar sobre registros de auditoria: %w", err)
+		}
+		return logs, nil
+	})
+}
+
+// Export percorre todos os registros do log de auditoria que atendem aos filtros, na ordem solicitada
+func (r *AuditLogRepository) Export(ctx context.Context, opts ListOptions, fn func(AuditLog) error) error {
+	return streamList(ctx, r.DB, &auditLogListSpec, opts, auditLogQuery, nil, scanAuditLog, fn)
+}
+
+// GetLoginAttempts retorna uma página das tentativas de login, aplicando filtros e ordenação
+func (r *AuditLogRepository) GetLoginAttempts(ctx context.Context, opts ListOptions) (*Page[LoginAttempt], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	return listPage(ctx, r.DB, &loginAttemptListSpec, opts, loginAttemptQuery, nil, func(rows *sql.Rows) ([]LoginAttempt, error) {
+		var attempts []LoginAttempt
+		for rows.Next() {
+			a, err := scanLoginAttempt(rows)
+			if err != nil {
+				return nil, err
+			}
+			attempts = append(attempts, a)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao iterar sobre tentativas de login: %w", err)
+		}
+		return attempts, nil
+	})
+}
+
+// ExportLoginAttempts percorre todas as tentativas de login que atendem aos filtros, na ordem solicitada
+func (r *AuditLogRepository) ExportLoginAttempts(ctx context.Context, opts ListOptions, fn func(LoginAttempt) error) error {
+	return streamList(ctx, r.DB, &loginAttemptListSpec, opts, loginAttemptQuery, nil, scanLoginAttempt, fn)
+}
+
+// scanAuditLog lê um registro de auditoria
+func scanAuditLog(rows *sql.Rows) (AuditLog, error) {
+	return scanAuditLogRow(rows)
+}
+
+// scanAuditLogRow lê um registro de auditoria de uma consulta de várias linhas ou de uma única linha
+func scanAuditLogRow(row interface{ Scan(dest ...interface{}) error }) (AuditLog, error) {
+	var l AuditLog
+	var userID sql.NullInt64
+	var changes sql.NullString
+	err := row.Scan(
+		&l.ID,
+		&userID,
+		&l.Username,
+		&l.Action,
+		&l.EntityType,
+		&l.EntityID,
+		&l.Details,
+		&l.IPAddress,
+		&l.RequestID,
+		&changes,
+		&l.PrevHash,
+		&l.Hash,
+		&l.CreatedAt,
+	)
+	if err != nil {
+		return l, fmt.Errorf("erro ao ler registro de auditoria: %w", err)
+	}
+	if userID.Valid {
+		l.UserID = &userID.Int64
+	}
+	if changes.Valid {
+		l.Changes = json.RawMessage(changes.String)
+	}
+	return l, nil
+}
+
+// CreateBatch registra várias entradas no log de auditoria em uma única transação, encadeadas na ordem informada
+// após as entradas pendentes
+func (r *AuditLogRepository) CreateBatch(ctx context.Context, entries []*AuditLog) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	if len(entries) == 0 {
+		return nil
+	}
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		return chainAuditLogs(ctx, tx, entries)
+	})
+}
+
+// insertAuditLog grava uma entrada pendente do log de auditoria na transação de negócio informada.
+// A entrada não é encadeada nela, para que a transação não bloqueie o topo da cadeia: é encadeada
+// por sealAuditLog após a confirmação.
+func insertAuditLog(ctx context.Context, tx dbConn, entry *AuditLog) error {
+	placeholders, args := auditLogValues([]*AuditLog{entry})
+	query := "INSERT INTO audit_log_pendente " + auditLogColumns + " VALUES " + placeholders
+	if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("erro ao registrar ação de auditoria: %w", err)
+	}
+	
+	return nil
+}
+
+// Entradas pendentes encadeadas por vez
+const lotePendentes = 500
+
+// Consulta das entradas pendentes, com as colunas de auditLogQuery (sem hashes)
+const auditLogPendenteQuery = `
+	SELECT id, user_id, COALESCE(username, ''), action, entity_type,
+		COALESCE(entity_id, ''), COALESCE(details, ''), ip_address, COALESCE(request_id, ''), changes,
+		'', '', created_at
+	FROM audit_log_pendente
+	ORDER BY id
+	LIMIT ?`
+
+// sealAuditLog encadeia as entradas pendentes em uma transação própria, curta, após a confirmação da
+// transação que as gravou. Com erro, as entradas continuam pendentes e são encadeadas na próxima vez.
+func sealAuditLog(ctx context.Context, db *sql.DB) {
+	var pendente int
+	err := db.QueryRowContext(ctx, "SELECT 1 FROM audit_log_pendente LIMIT 1").Scan(&pendente)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err == nil {
+		err = sealPending(ctx, db)
+	}
+	if err != nil {
+		slog.Error("Erro ao encadear registros de auditoria pendentes", "error", err)
+	}
+}
+
+// sealPending encadeia as entradas pendentes em uma transação própria
+func sealPending(ctx context.Context, db *sql.DB) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	if err := chainAuditLogs(ctx, tx, nil); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return nil
+}
+
+// chainAuditLogs encadeia as entradas pendentes, na ordem em que foram gravadas, e em seguida as entradas
+// informadas. O topo da cadeia é bloqueado antes da leitura das pendentes, de forma que cada uma é
+// encadeada uma única vez.
+func chainAuditLogs(ctx context.Context, tx *sql.Tx, entries []*AuditLog) error {
+	if _, err := lockChainHead(ctx, tx); err != nil {
+		return err
+	}
+	
+	for {
+		pendentes, ids, err := readPending(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if len(pendentes) == 0 {
+			break
+		}
+		
+		if err := insertAuditLogs(ctx, tx, pendentes); err != nil {
+			return err
+		}
+		
+		// Remover as pendentes encadeadas pelo ID: pendentes gravadas por transações ainda não confirmadas
+		// podem ter IDs menores e ficam para a próxima vez
+		query := "DELETE FROM audit_log_pendente WHERE id IN (?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		if _, err := tx.ExecContext(ctx, query, ids...); err != nil {
+			return fmt.Errorf("erro ao remover registros de auditoria pendentes: %w", err)
+		}
+		
+		if len(pendentes) < lotePendentes {
+			break
+		}
+	}
+	
+	if len(entries) == 0 {
+		return nil
+	}
+	return insertAuditLogs(ctx, tx, entries)
+}
+
+// readPending lê o próximo lote de entradas pendentes, na ordem em que foram gravadas, com os seus IDs
+func readPending(ctx context.Context, tx *sql.Tx) ([]*AuditLog, []interface{}, error) {
+	rows, err := tx.QueryContext(ctx, auditLogPendenteQuery, lotePendentes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao buscar registros de auditoria pendentes: %w", err)
+	}
+	defer rows.Close()
+	
+	var pendentes []*AuditLog
+	var ids []interface{}
+	for rows.Next() {
+		l, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, l.ID)
+		pendentes = append(pendentes, &l)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("erro ao iterar sobre registros de auditoria pendentes: %w", err)
+	}
+	
+	return pendentes, ids, nil
+}
+
+// lockChainHead bloqueia o topo da cadeia até o fim da transação e retorna o hash da última entrada
+func lockChainHead(ctx context.Context, tx *sql.Tx) (string, error) {
+	var lastHash string
+	if err := tx.QueryRowContext(ctx, "SELECT last_hash FROM audit_chain_head WHERE id = 1" + dialect.Current().ForUpdate()).Scan(&lastHash); err != nil {
+		return "", fmt.Errorf("erro ao obter o topo da cadeia de auditoria: %w", err)
+	}
+	return lastHash, nil
+}
+
+// Colunas gravadas de cada entrada do log de auditoria, no log e nas entradas pendentes
+const auditLogColumns = "(user_id, username, action, entity_type, entity_id, details, ip_address, request_id, changes, created_at)"
+
+// insertAuditLogs insere as entradas no log de auditoria na transação informada, com um único INSERT,
+// encadeando-as à entrada anterior. A linha de topo da cadeia fica bloqueada até o fim da transação,
+// de forma que as entradas são encadeadas um lote de cada vez, na ordem dos seus IDs.
+func insertAuditLogs(ctx context.Context, tx *sql.Tx, entries []*AuditLog) error {
+	// Bloquear o topo da cadeia e obter o hash da última entrada
+	prevHash, err := lockChainHead(ctx, tx)
+	if err != nil {
+		return err
+	}
+	
+	placeholders, args := auditLogValues(entries)
+	query := "INSERT INTO audit_log " + auditLogColumns + " VALUES " + placeholders
+	
+	firstID, err := insertAuditLogRows(ctx, tx, query, args, len(entries))
+	if err != nil {
+		return err
+	}
+	
+	// Calcular os hashes sobre os registros como foram gravados (valores truncados e datas do banco).
+	// Com o topo da cadeia bloqueado, os registros a partir do primeiro ID são os deste lote.
+	rows, err := tx.QueryContext(ctx, auditLogQuery+" AND id >= ? ORDER BY id LIMIT ?", firstID, len(entries))
+	if err != nil {
+		return fmt.Errorf("erro ao buscar registros de auditoria: %w", err)
+	}
+	var gravados []AuditLog
+	for rows.Next() {
+		l, err := scanAuditLog(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		gravados = append(gravados, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre registros de auditoria: %w", err)
+	}
+	if len(gravados) != len(entries) {
+		return fmt.Errorf("erro ao registrar ação de auditoria: %d de %d registros encontrados", len(gravados), len(entries))
+	}
+	
+	for i := range gravados {
+		gravado := &gravados[i]
+		gravado.PrevHash = prevHash
+		gravado.Hash = auditEntryHash(gravado)
+		
+		if _, err := tx.ExecContext(ctx, "UPDATE audit_log SET prev_hash = ?, hash = ? WHERE id = ?", gravado.PrevHash, gravado.Hash, gravado.ID); err != nil {
+			return fmt.Errorf("erro ao registrar hash de auditoria: %w", err)
+		}
+		
+		*entries[i] = *gravado
+		prevHash = gravado.Hash
+	}
+	
+	last := gravados[len(gravados)-1]
+	if _, err := tx.ExecContext(ctx, "UPDATE audit_chain_head SET last_id = ?, last_hash = ? WHERE id = 1", last.ID, last.Hash); err != nil {
+		return fmt.Errorf("erro ao atualizar o topo da cadeia de auditoria: %w", err)
+	}
+	
+	return nil
+}
+
+// auditLogValues monta os valores do INSERT das entradas do log de auditoria, nas colunas de auditLogColumns.
+// Entradas sem data são registradas com a data atual.
+func auditLogValues(entries []*AuditLog) (string, []interface{}) {
+	placeholders := make([]string, 0, len(entries))
+	args := make([]interface{}, 0, len(entries)*10)
+	now := time.Now()
+	for _, entry := range entries {
+		var userID sql.NullInt64
+		if entry.UserID != nil && *entry.UserID > 0 {
+			userID = sql.NullInt64{Int64: *entry.UserID, Valid: true}
+		}
+		
+		var changes sql.NullString
+		if len(entry.Changes) > 0 {
+			changes = sql.NullString{String: string(entry.Changes), Valid: true}
+		}
+		
+		createdAt := entry.CreatedAt
+		if createdAt.IsZero() {
+			createdAt = now
+		}
+		
+		placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		args = append(args,
+			userID,
+			nullString(truncate(entry.Username, 50)),
+			truncate(entry.Action, 100),
+			truncate(entry.EntityType, 50),
+			nullString(truncate(entry.EntityID, 50)),
+			nullString(entry.Details),
+			truncate(entry.IPAddress, 45),
+			nullString(truncate(entry.RequestID, 64)),
+			changes,
+			createdAt,
+		)
+	}
+	
+	return strings.Join(placeholders, ", "), args
+}
+
+// insertAuditLogRows executa o INSERT das entradas do log de auditoria e retorna o ID da primeira.
+// O MySQL informa o ID da primeira linha de um INSERT com várias linhas e o SQLite, o da última
+// (os IDs de um mesmo INSERT são consecutivos); no PostgreSQL, os IDs são devolvidos pelo RETURNING.
+func insertAuditLogRows(ctx context.Context, tx *sql.Tx, query string, args []interface{}, count int) (int64, error) {
+	var firstID int64
+	
+	switch dialect.Current() {
+	case dialect.Postgres:
+		err := tx.QueryRowContext(ctx, "WITH gravados AS ("+query+" RETURNING id) SELECT MIN(id) FROM gravados", args...).Scan(&firstID)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao registrar ação de auditoria: %w", err)
+		}
+	default:
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, fmt.Errorf("erro ao registrar ação de auditoria: %w", err)
+		}
+		
+		firstID, err = result.LastInsertId()
+		if err != nil {
+			return 0, fmt.Errorf("erro ao obter ID do registro de auditoria: %w", err)
+		}
+		if dialect.Current() == dialect.SQLite {
+			firstID -= int64(count) - 1
+		}
+	}
+	
+	return firstID, nil
+}
+
+// nullString converte textos vazios em NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// scanLoginAttempt lê uma tentativa de login
+func scanLoginAttempt(rows *sql.Rows) (LoginAttempt, error) {
+	var a LoginAttempt
+	err := rows.Scan(
+		&a.ID,
+		&a.Login,
+		&a.IPAddress,
+		&a.Success,
+		&a.AttemptTime,
+	)
+	if err != nil {
+		return a, fmt.Errorf("erro ao ler tentativa de login: %w", err)
+	}
+	return a, nil
+}
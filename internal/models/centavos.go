@@ -0,0 +1,112 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Quantidade máxima de dígitos da parte inteira de um valor (colunas DECIMAL(18,2))
+const maxDigitosCentavos = 16
+
+// Centavos representa um valor monetário em centavos. O valor é lido e gravado, em JSON e no banco
+// de dados, como um número decimal com duas casas (ex.: 150.75), sem passar por ponto flutuante,
+// para que as somas de débitos e créditos sejam exatas.
+type Centavos int64
+
+// ParseCentavos converte um número decimal com no máximo duas casas decimais (ex.: "150.75") em centavos
+func ParseCentavos(s string) (Centavos, error) {
+	texto := strings.TrimSpace(s)
+	
+	negativo := strings.HasPrefix(texto, "-")
+	texto = strings.TrimPrefix(texto, "-")
+	
+	inteiro, decimal, _ := strings.Cut(texto, ".")
+	if inteiro == "" || len(inteiro) > maxDigitosCentavos || len(decimal) > 2 || !apenasDigitos(inteiro) || !apenasDigitos(decimal) {
+		return 0, fmt.Errorf("valor inválido: %q (use um número com no máximo duas casas decimais)", s)
+	}
+	
+	unidades, err := strconv.ParseInt(inteiro, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor inválido: %q", s)
+	}
+	decimal = (decimal + "00")[:2]
+	centavos, _ := strconv.ParseInt(decimal, 10, 64)
+	
+	valor := Centavos(unidades*100 + centavos)
+	if negativo {
+		valor = -valor
+	}
+	return valor, nil
+}
+
+// apenasDigitos informa se o texto contém somente dígitos decimais
+func apenasDigitos(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formata o valor como número decimal com duas casas
+func (c Centavos) String() string {
+	sinal := ""
+	valor := int64(c)
+	if valor < 0 {
+		sinal = "-"
+		valor = -valor
+	}
+	return fmt.Sprintf("%s%d.%02d", sinal, valor/100, valor%100)
+}
+
+// MarshalJSON grava o valor como número decimal com duas casas
+func (c Centavos) MarshalJSON() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalJSON lê o valor de um número ou de um texto com no máximo duas casas decimais
+func (c *Centavos) UnmarshalJSON(data []byte) error {
+	texto := string(bytes.Trim(data, `"`))
+	if texto == "null" {
+		return nil
+	}
+	
+	return c.set(texto)
+}
+
+// Scan lê o valor de uma coluna DECIMAL: texto no MySQL e no PostgreSQL, número no SQLite
+func (c *Centavos) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return c.set(string(v))
+	case string:
+		return c.set(v)
+	case int64:
+		*c = Centavos(v * 100)
+	case float64:
+		*c = Centavos(math.Round(v * 100))
+	default:
+		return fmt.Errorf("tipo de valor não suportado: %T", src)
+	}
+	return nil
+}
+
+// set atribui o valor lido de um texto decimal
+func (c *Centavos) set(texto string) error {
+	valor, err := ParseCentavos(texto)
+	if err != nil {
+		return err
+	}
+	*c = valor
+	return nil
+}
+
+// Value grava o valor como texto decimal, convertido para DECIMAL pelo banco de dados
+func (c Centavos) Value() (driver.Value, error) {
+	return c.String(), nil
+}
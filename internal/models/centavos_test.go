@@ -0,0 +1,76 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseCentavos(t *testing.T) {
+	casos := []struct {
+		texto string
+		valor Centavos
+	}{
+		{"150.75", 15075},
+		{"150.7", 15070},
+		{"150", 15000},
+		{"0.01", 1},
+		{"-3.5", -350},
+		{"9999999999999999.99", 999999999999999999},
+	}
+	for _, caso := range casos {
+		valor, err := ParseCentavos(caso.texto)
+		if err != nil || valor != caso.valor {
+			t.Errorf("ParseCentavos(%q) = %d, %v; esperado %d", caso.texto, valor, err, caso.valor)
+		}
+	}
+
+	for _, texto := range []string{"", "1.234", "1e2", "abc", ".5", "1.2.3", "12345678901234567"} {
+		if _, err := ParseCentavos(texto); err == nil {
+			t.Errorf("ParseCentavos(%q) sem erro", texto)
+		}
+	}
+}
+
+func TestCentavosJSON(t *testing.T) {
+	var transacao TransacaoNegocio
+	if err := json.Unmarshal([]byte(`{"valor": 0.1}`), &transacao); err != nil {
+		t.Fatal(err)
+	}
+
+	// 0.1 + 0.2 em ponto flutuante não é 0.3; em centavos, a soma é exata
+	soma := transacao.Valor + Centavos(20)
+	dados, err := json.Marshal(struct {
+		Valor Centavos `json:"valor"`
+	}{soma})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(dados) != `{"valor":0.30}` {
+		t.Errorf("JSON %s, esperado {\"valor\":0.30}", dados)
+	}
+
+	if err := json.Unmarshal([]byte(`{"valor": 10.005}`), &transacao); err == nil {
+		t.Errorf("valor com três casas decimais aceito")
+	}
+	if err := json.Unmarshal([]byte(`{"valor": "10.50"}`), &transacao); err != nil || transacao.Valor != 1050 {
+		t.Errorf("valor em texto: %d, %v", transacao.Valor, err)
+	}
+}
+
+func TestCentavosScan(t *testing.T) {
+	casos := []struct {
+		src   interface{}
+		valor Centavos
+	}{
+		{[]byte("150.75"), 15075},
+		{"0.30", 30},
+		{int64(12), 1200},
+		{0.1 + 0.2, 30},
+	}
+	for _, caso := range casos {
+		var valor Centavos
+		if err := valor.Scan(caso.src); err != nil || valor != caso.valor {
+			t.Errorf("Scan(%v) = %d, %v; esperado %d", caso.src, valor, err, caso.valor)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// Erros de domínio, identificados com errors.Is e convertidos nas respostas HTTP de erro
+var (
+	ErrNotFound    = errors.New("registro não encontrado")
+	ErrConflict    = errors.New("registro em conflito com os dados existentes")
+	ErrForeignKey  = errors.New("registro relacionado inválido")
+	ErrReference   = errors.New("registro referenciado inválido para a operação")
+	ErrUnavailable = errors.New("banco de dados indisponível")
+)
+
+// ValidationError indica dados inválidos em um campo
+type ValidationError = utils.ValidationError
+
+// NotFoundError indica que o registro não existe ou não é visível para a seguradora da requisição
+type NotFoundError struct {
+	Message string
+}
+
+// Error implementa a interface error
+func (e NotFoundError) Error() string {
+	return e.Message
+}
+
+// Is permite identificar o erro com errors.Is(err, ErrNotFound)
+func (e NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ConflictError indica que a operação viola uma restrição de unicidade ou o estado atual do registro
+type ConflictError struct {
+	Message string
+	Key     string // Índice único violado, quando informado pelo banco
+	Err     error
+}
+
+// Error implementa a interface error
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
+// Is permite identificar o erro com errors.Is(err, ErrConflict)
+func (e ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Unwrap retorna o erro original do banco
+func (e ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ForeignKeyError indica que a operação viola uma chave estrangeira: o registro referenciado não
+// existe ou o registro excluído ainda é referenciado por outros
+type ForeignKeyError struct {
+	Message string
+	Field   string // Coluna da chave estrangeira, quando informada pelo banco
+	Err     error
+}
+
+// Error implementa a interface error
+func (e ForeignKeyError) Error() string {
+	return e.Message
+}
+
+// Is permite identificar o erro com errors.Is(err, ErrForeignKey)
+func (e ForeignKeyError) Is(target error) bool {
+	return target == ErrForeignKey
+}
+
+// Unwrap retorna o erro original do banco
+func (e ForeignKeyError) Unwrap() error {
+	return e.Err
+}
+
+// ReferenceError indica que um registro referenciado existe, mas viola uma regra de negócio: pertence
+// a outra seguradora, está inativo ou não está relacionado aos demais registros referenciados
+type ReferenceError struct {
+	Message string
+	Field   string // Campo da referência inválida
+}
+
+// Error implementa a interface error
+func (e ReferenceError) Error() string {
+	return e.Message
+}
+
+// Is permite identificar o erro com errors.Is(err, ErrReference)
+func (e ReferenceError) Is(target error) bool {
+	return target == ErrReference
+}
+
+// Códigos de erro do MySQL convertidos em erros de domínio ou de indisponibilidade, ou tratados nas transações
+const (
+	mysqlErrTooManyConns     = 1040
+	mysqlErrDupEntry         = 1062
+	mysqlErrLockDeadlock     = 1213
+	mysqlErrNoReferencedRow  = 1216
+	mysqlErrRowIsReferenced  = 1217
+	mysqlErrRowIsReferenced2 = 1451
+	mysqlErrNoReferencedRow2 = 1452
+)
+
+// Códigos de erro do PostgreSQL (SQLSTATE) convertidos em erros de domínio ou tratados nas transações
+const (
+	postgresErrUniqueViolation     = "23505"
+	postgresErrForeignKeyViolation = "23503"
+	postgresErrDeadlockDetected    = "40P01"
+)
+
+var (
+	dupEntryKeyRegex   = regexp.MustCompile("for key '([^']+)'")
+	foreignKeyColRegex = regexp.MustCompile("FOREIGN KEY \\(`([^`]+)`\\)")
+	postgresKeyRegex   = regexp.MustCompile(`Key \(([^)]+)\)`)
+)
+
+// ClassifyDBError converte as violações de unicidade e de chave estrangeira do MySQL, do PostgreSQL e
+// do SQLite em ConflictError e ForeignKeyError, e as falhas de conexão com o banco em ErrUnavailable.
+// O cancelamento e o tempo esgotado da consulta (context.Canceled e context.DeadlineExceeded) e os
+// demais erros são retornados sem alteração.
+func ClassifyDBError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrUnavailable) {
+		return err
+	}
+	
+	var netErr *net.OpError
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, mysql.ErrInvalidConn) || errors.As(err, &netErr) {
+		return unavailable(err)
+	}
+	
+	var mysqlErr *mysql.MySQLError
+	var postgresErr *pq.Error
+	var sqliteErr sqlite3.Error
+	
+	switch {
+	case errors.As(err, &mysqlErr):
+		return classifyMySQLError(err, mysqlErr)
+	case errors.As(err, &postgresErr):
+		return classifyPostgresError(err, postgresErr)
+	case errors.As(err, &sqliteErr):
+		return classifySQLiteError(err, sqliteErr)
+	}
+	
+	return err
+}
+
+// classifyMySQLError converte os erros de unicidade e de chave estrangeira do MySQL
+func classifyMySQLError(err error, mysqlErr *mysql.MySQLError) error {
+	switch mysqlErr.Number {
+	case mysqlErrTooManyConns:
+		return unavailable(err)
+	case mysqlErrDupEntry:
+		conflict := ConflictError{Message: "já existe um registro com os mesmos dados", Err: err}
+		if m := dupEntryKeyRegex.FindStringSubmatch(mysqlErr.Message); m != nil {
+			conflict.Key = m[1]
+			conflict.Message = fmt.Sprintf("já existe um registro com os mesmos dados (%s)", m[1])
+		}
+		return conflict
+	case mysqlErrRowIsReferenced, mysqlErrRowIsReferenced2:
+		fk := ForeignKeyError{Message: "registro utilizado por outros registros", Err: err}
+		if m := foreignKeyColRegex.FindStringSubmatch(mysqlErr.Message); m != nil {
+			fk.Field = m[1]
+		}
+		return fk
+	case mysqlErrNoReferencedRow, mysqlErrNoReferencedRow2:
+		fk := ForeignKeyError{Message: "registro relacionado não encontrado", Err: err}
+		if m := foreignKeyColRegex.FindStringSubmatch(mysqlErr.Message); m != nil {
+			fk.Field = m[1]
+			fk.Message = fmt.Sprintf("registro relacionado não encontrado (%s)", m[1])
+		}
+		return fk
+	}
+	
+	return err
+}
+
+// classifyPostgresError converte os erros de unicidade e de chave estrangeira do PostgreSQL
+func classifyPostgresError(err error, postgresErr *pq.Error) error {
+	switch string(postgresErr.Code) {
+	case postgresErrUniqueViolation:
+		conflict := ConflictError{Message: "já existe um registro com os mesmos dados", Err: err}
+		if postgresErr.Constraint != "" {
+			conflict.Key = postgresErr.Constraint
+			conflict.Message = fmt.Sprintf("já existe um registro com os mesmos dados (%s)", postgresErr.Constraint)
+		}
+		return conflict
+	case postgresErrForeignKeyViolation:
+		// A exclusão ou alteração de um registro referenciado informa a tabela que o referencia
+		fk := ForeignKeyError{Message: "registro relacionado não encontrado", Err: err}
+		if strings.Contains(postgresErr.Detail, "is still referenced") {
+			fk.Message = "registro utilizado por outros registros"
+		}
+		if m := postgresKeyRegex.FindStringSubmatch(postgresErr.Detail); m != nil {
+			fk.Field = m[1]
+			if fk.Message == "registro relacionado não encontrado" {
+				fk.Message = fmt.Sprintf("registro relacionado não encontrado (%s)", m[1])
+			}
+		}
+		return fk
+	}
+	
+	return err
+}
+
+// classifySQLiteError converte os erros de unicidade e de chave estrangeira do SQLite, que não
+// informa a coluna da chave estrangeira violada
+func classifySQLiteError(err error, sqliteErr sqlite3.Error) error {
+	if sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked {
+		return unavailable(err)
+	}
+	
+	switch sqliteErr.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		conflict := ConflictError{Message: "já existe um registro com os mesmos dados", Err: err}
+		if _, key, ok := strings.Cut(sqliteErr.Error(), "constraint failed: "); ok {
+			conflict.Key = key
+			conflict.Message = fmt.Sprintf("já existe um registro com os mesmos dados (%s)", key)
+		}
+		return conflict
+	case sqlite3.ErrConstraintForeignKey:
+		return ForeignKeyError{Message: "registro relacionado inválido ou utilizado por outros registros", Err: err}
+	}
+	
+	return err
+}
+
+// isDeadlock informa se a transação foi interrompida por deadlock (erro 1213 do MySQL ou 40P01 do PostgreSQL)
+func isDeadlock(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrLockDeadlock
+	}
+	
+	var postgresErr *pq.Error
+	if errors.As(err, &postgresErr) {
+		return string(postgresErr.Code) == postgresErrDeadlockDetected
+	}
+	
+	return false
+}
+
+// unavailable marca a falha de conexão com o banco como ErrUnavailable, preservando o erro original
+func unavailable(err error) error {
+	return fmt.Errorf("%w: %w", ErrUnavailable, err)
+}
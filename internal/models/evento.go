@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -21,7 +22,9 @@ type Evento struct {
 
 // EventoRepository gerencia operações de banco de dados para eventos
 type EventoRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewEventoRepository cria um novo repositório de eventos
@@ -29,13 +32,31 @@ func NewEventoRepository(db *sql.DB) *EventoRepository {
 	return &EventoRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *EventoRepository) WithTenant(scope TenantScope) EventoStore {
+	return &EventoRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *EventoRepository) WithActor(actor Actor) EventoStore {
+	return &EventoRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
 // Create insere um novo evento no banco de dados
-func (r *EventoRepository) Create(evento *Evento) error {
+func (r *EventoRepository) Create(ctx context.Context, evento *Evento) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do evento
 	if err := validateEvento(evento); err != nil {
 		return err
 	}
 	
+	// Verificar se o evento pertence à seguradora do usuário
+	if !r.scope.Allows(evento.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	evento.Descricao = utils.SanitizeString(evento.Descricao)
 	
@@ -44,41 +65,61 @@ func (r *EventoRepository) Create(evento *Evento) error {
 	(Evento, Descricao, idSeguradora, ativo) 
 	VALUES (?, ?, ?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		evento.Evento, 
-		evento.Descricao, 
-		evento.IdSeguradora, 
-		evento.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar evento: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID do evento: %v", err)
-	}
-	
-	evento.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "idCodigoEvento",
+			query, 
+			evento.Evento, 
+			evento.Descricao, 
+			evento.IdSeguradora, 
+			evento.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar evento: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID do evento: %w", err)
+		}
+		
+		evento.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeEvento, id, nil, evento)
+	})
 }
 
-// GetAll retorna todos os eventos do banco de dados
-func (r *EventoRepository) GetAll() ([]Evento, error) {
+// eventoListSpec define os campos de ordenação e filtro da listagem de eventos
+var eventoListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idCodigoEvento": {Column: "idCodigoEvento", Type: FieldInt},
+		"evento":         {Column: "Evento", Type: FieldInt},
+		"descricao":      {Column: "Descricao", Type: FieldString},
+		"idSeguradora":   {Column: "idSeguradora", Type: FieldInt},
+		"created_at":     {Column: "created_at", Type: FieldTime},
+		"updated_at":     {Column: "updated_at", Type: FieldTime},
+		"ativo":          {Column: "ativo", Type: FieldBool},
+	},
+	Key: "idCodigoEvento",
+}
+
+// GetAll retorna os eventos com paginação, ordenação e filtros
+func (r *EventoRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[Evento], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		idCodigoEvento, Evento, Descricao, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM eventos 
-	ORDER BY idCodigoEvento DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar eventos: %v", err)
-	}
-	defer rows.Close()
+	WHERE ` + r.scope.condition("idSeguradora")
 	
+	return listPage(ctx, r.DB, &eventoListSpec, opts, query, r.scope.args(), scanEventos)
+}
+
+// scanEventos lê os eventos retornados por uma consulta
+func scanEventos(rows *sql.Rows) ([]Evento, error) {
 	var eventos []Evento
 	
 	for rows.Next() {
@@ -92,29 +133,32 @@ func (r *EventoRepository) GetAll() ([]Evento, error) {
 			&e.UpdatedAt, 
 			&e.Ativo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler evento: %v", err)
+			return nil, fmt.Errorf("erro ao ler evento: %w", err)
 		}
 		eventos = append(eventos, e)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre eventos: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre eventos: %w", err)
 	}
 	
 	return eventos, nil
 }
 
 // GetByID busca um evento pelo ID
-func (r *EventoRepository) GetByID(id int64) (*Evento, error) {
+func (r *EventoRepository) GetByID(ctx context.Context, id int64) (*Evento, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		idCodigoEvento, Evento, Descricao, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM eventos 
-	WHERE idCodigoEvento = ?`
+	WHERE idCodigoEvento = ? AND ` + r.scope.condition("idSeguradora")
 	
 	var e Evento
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&e.ID, 
 		&e.Evento, 
 		&e.Descricao, 
@@ -125,99 +169,256 @@ func (r *EventoRepository) GetByID(id int64) (*Evento, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("evento não encontrado")
+			return nil, NotFoundError{Message: "evento não encontrado"}
 		}
-		return nil, fmt.Errorf("erro ao buscar evento: %v", err)
+		return nil, fmt.Errorf("erro ao buscar evento: %w", err)
 	}
 	
 	return &e, nil
 }
 
-// GetBySeguradora busca eventos por seguradora
-func (r *EventoRepository) GetBySeguradora(idSeguradora int64) ([]Evento, error) {
+// GetByNumero busca um evento pelo número dentro de uma seguradora (ativo ou não).
+// Retorna nil se o evento não existir.
+func (r *EventoRepository) GetByNumero(ctx context.Context, idSeguradora int64, numero int) (*Evento, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
 	query := `
 	SELECT 
 		idCodigoEvento, Evento, Descricao, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM eventos 
-	WHERE idSeguradora = ? 
-	ORDER BY idCodigoEvento DESC`
+	WHERE idSeguradora = ? AND Evento = ? 
+	ORDER BY ativo DESC, idCodigoEvento DESC 
+	LIMIT 1`
 	
-	rows, err := r.DB.Query(query, idSeguradora)
+	var e Evento
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, idSeguradora, numero).Scan(
+		&e.ID, 
+		&e.Evento, 
+		&e.Descricao, 
+		&e.IdSeguradora, 
+		&e.CreatedAt, 
+		&e.UpdatedAt, 
+		&e.Ativo,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar eventos por seguradora: %v", err)
-	}
-	defer rows.Close()
-	
-	var eventos []Evento
-	
-	for rows.Next() {
-		var e Evento
-		if err := rows.Scan(
-			&e.ID, 
-			&e.Evento, 
-			&e.Descricao, 
-			&e.IdSeguradora, 
-			&e.CreatedAt, 
-			&e.UpdatedAt, 
-			&e.Ativo,
-		); err != nil {
-			return nil, fmt.Errorf("erro ao ler evento: %v", err)
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
-		eventos = append(eventos, e)
+		return nil, fmt.Errorf("erro ao buscar evento: %w", err)
 	}
 	
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre eventos: %v", err)
+	return &e, nil
+}
+
+// GetBySeguradora busca eventos por seguradora, com paginação, ordenação e filtros
+func (r *EventoRepository) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[Evento], error) {
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
 	}
 	
-	return eventos, nil
+	return r.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
 }
 
 // Update atualiza os dados de um evento existente
-func (r *EventoRepository) Update(evento *Evento) error {
+func (r *EventoRepository) Update(ctx context.Context, evento *Evento) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do evento
 	if err := validateEvento(evento); err != nil {
 		return err
 	}
 	
+	// Verificar se o evento pertence à seguradora do usuário
+	if !r.scope.Allows(evento.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	evento.Descricao = utils.SanitizeString(evento.Descricao)
 	
 	query := `
 	UPDATE eventos 
 	SET Evento = ?, Descricao = ?, idSeguradora = ?, ativo = ? 
-	WHERE idCodigoEvento = ?`
-	
-	_, err := r.DB.Exec(
-		query, 
-		evento.Evento, 
-		evento.Descricao, 
-		evento.IdSeguradora, 
-		evento.Ativo, 
-		evento.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar evento: %v", err)
-	}
+	WHERE idCodigoEvento = ? AND ` + r.scope.condition("idSeguradora")
 	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "eventos", "idCodigoEvento", evento.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				evento.Evento, 
+				evento.Descricao, 
+				evento.IdSeguradora, 
+				evento.Ativo, 
+				evento.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar evento: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeEvento, evento.ID, anterior, evento)
+	})
 }
 
 // Delete remove um evento do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *EventoRepository) Delete(id int64) error {
+func (r *EventoRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM eventos WHERE idCodigoEvento = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE eventos SET ativo = false WHERE idCodigoEvento = ?`
+	query := `UPDATE eventos SET ativo = false WHERE idCodigoEvento = ? AND ` + r.scope.condition("idSeguradora")
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "eventos", "idCodigoEvento", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir evento: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeEvento, id, anterior, &excluido)
+	})
+}
+
+// Import cria em lote os eventos de uma seguradora lidos de um arquivo (colunas evento, descricao e
+// ativo, opcional). Todas as linhas são validadas antes da gravação: com qualquer erro, nenhum evento
+// é gravado e os erros de cada linha são retornados. Na simulação, as linhas são apenas validadas.
+func (r *EventoRepository) Import(ctx context.Context, idSeguradora int64, linhas []LinhaArquivo, simular bool) (*ResultadoImportacao, error) {
+	// Verificar se os eventos pertencem à seguradora do usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	// Eventos ativos já cadastrados na seguradora
+	existentes := make(map[int]bool)
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, "SELECT Evento FROM eventos WHERE idSeguradora = ? AND ativo = true", idSeguradora)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar eventos: %w", err)
+	}
+	for rows.Next() {
+		var numero int
+		if err := rows.Scan(&numero); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("erro ao ler evento: %w", err)
+		}
+		existentes[numero] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre eventos: %w", err)
+	}
+	
+	resultado := &ResultadoImportacao{Simulacao: simular, Linhas: len(linhas)}
+	eventos := validarImportacaoEventos(idSeguradora, linhas, existentes, resultado)
+	
+	// Qualquer erro cancela toda a importação
+	if len(resultado.Erros) > 0 || simular {
+		return resultado, nil
+	}
+	
+	query := `
+	INSERT INTO eventos 
+	(Evento, Descricao, idSeguradora, ativo) 
+	VALUES (?, ?, ?, ?)`
 	
-	_, err := r.DB.Exec(query, id)
+	err = inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		for _, evento := range eventos {
+			evento.Descricao = utils.SanitizeString(evento.Descricao)
+			
+			result, err := execInsert(ctx, tx, "idCodigoEvento", query, evento.Evento, evento.Descricao, evento.IdSeguradora, evento.Ativo)
+			if err != nil {
+				return fmt.Errorf("erro ao criar evento %d: %w", evento.Evento, err)
+			}
+			
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("erro ao obter ID do evento: %w", err)
+			}
+			evento.ID = id
+			
+			// Registrar a criação no histórico
+			if err := recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeEvento, id, nil, evento); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("erro ao excluir evento: %v", err)
+		return nil, err
 	}
 	
-	return nil
+	resultado.Criados = len(eventos)
+	return resultado, nil
+}
+
+// validarImportacaoEventos valida os dados de cada linha de um arquivo de eventos, dados os números
+// dos eventos ativos já cadastrados na seguradora, e retorna os eventos das linhas válidas.
+// Os erros de cada linha são registrados no resultado.
+func validarImportacaoEventos(idSeguradora int64, linhas []LinhaArquivo, existentes map[int]bool, resultado *ResultadoImportacao) []*Evento {
+	var eventos []*Evento
+	numeros := make(map[int]int)
+	for _, linha := range linhas {
+		codigo := linha.Valor("evento")
+		erro := func(mensagem string) {
+			resultado.Erros = append(resultado.Erros, ErroImportacao{Linha: linha.Linha, Codigo: codigo, Mensagem: mensagem})
+		}
+		
+		numero, err := parseInteiro(codigo)
+		if err != nil {
+			erro("evento: " + err.Error())
+			continue
+		}
+		ativo, err := parseAtivo(linha.Valor("ativo"))
+		if err != nil {
+			erro(err.Error())
+			continue
+		}
+		
+		evento := &Evento{Evento: numero, Descricao: linha.Valor("descricao"), IdSeguradora: idSeguradora, Ativo: ativo}
+		if err := validateEvento(evento); err != nil {
+			erro(err.Error())
+			continue
+		}
+		if anterior, ok := numeros[numero]; ok {
+			erro(fmt.Sprintf("evento duplicado no arquivo (linha %d)", anterior))
+			continue
+		}
+		numeros[numero] = linha.Linha
+		if ativo && existentes[numero] {
+			erro("evento já cadastrado na seguradora")
+			continue
+		}
+		
+		eventos = append(eventos, evento)
+	}
+	resultado.Validas = len(eventos)
+	
+	return eventos
 }
 
 // validateEvento valida os dados de um evento
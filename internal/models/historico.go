@@ -0,0 +1,252 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// Ações registradas no histórico de alterações
+const (
+	AcaoCriacao   = "CREATE"
+	AcaoAlteracao = "UPDATE"
+	AcaoExclusao  = "DELETE"
+)
+
+// Tipos de entidade registrados no histórico de alterações
+const (
+	EntidadeUsuario                    = "USUARIO"
+	EntidadeSeguradora                 = "SEGURADORA"
+	EntidadeTipoPerfil                 = "TIPO_PERFIL"
+	EntidadePermissao                  = "PERMISSAO"
+	EntidadeEvento                     = "EVENTO"
+	EntidadeObjetoContabilizacao       = "OBJETO_CONTABILIZACAO"
+	EntidadeObjetoContabilizacaoEvento = "OBJETO_CONTABILIZACAO_EVENTO"
+	EntidadeSistemaContabil            = "SISTEMA_CONTABIL"
+	EntidadeSistemaContabilConfig      = "SISTEMA_CONTABIL_CONFIG"
+	EntidadeContaContabil              = "CONTA_CONTABIL"
+)
+
+// valorOculto substitui o valor de campos sensíveis no histórico
+const valorOculto = "***"
+
+// camposSensiveis são registrados no histórico sem o valor (apenas indicando a alteração)
+var camposSensiveis = map[string]bool{
+	"senha":    true,
+	"password": true,
+	"token":    true,
+	"secret":   true,
+}
+
+// camposIgnorados não são registrados no histórico (controlados pelo banco)
+var camposIgnorados = map[string]bool{
+	"created_at": true,
+	"updated_at": true,
+}
+
+// Actor identifica o usuário e a origem das alterações registradas no histórico
+type Actor struct {
+	UserID    int64
+	Username  string
+	IPAddress string
+	RequestID string
+}
+
+// FieldChange representa a alteração de um campo: valor anterior e novo valor
+type FieldChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// diffChanges compara dois estados de um registro pelos seus campos JSON e retorna os campos alterados.
+// before nulo indica criação. Na alteração, campos omitidos do novo estado (campos de exibição
+// e campos opcionais vazios) são considerados inalterados. Campos sensíveis têm o valor ocultado.
+func diffChanges(before, after interface{}) (map[string]FieldChange, error) {
+	anterior, err := toFieldMap(before)
+	if err != nil {
+		return nil, err
+	}
+	atual, err := toFieldMap(after)
+	if err != nil {
+		return nil, err
+	}
+	
+	changes := make(map[string]FieldChange)
+	for field, newValue := range atual {
+		if camposIgnorados[field] {
+			continue
+		}
+		
+		oldValue, existed := anterior[field]
+		if existed && reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		
+		change := FieldChange{Old: oldValue, New: newValue}
+		if camposSensiveis[strings.ToLower(field)] {
+			change = FieldChange{New: valorOculto}
+			if existed {
+				change.Old = valorOculto
+			}
+		}
+		changes[field] = change
+	}
+	
+	return changes, nil
+}
+
+// toFieldMap converte um registro nos seus campos JSON
+func toFieldMap(v interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if v == nil {
+		return fields, nil
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return fields, nil
+	}
+	
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar registro do histórico: %w", err)
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("erro ao serializar registro do histórico: %w", err)
+	}
+	
+	return fields, nil
+}
+
+// recordChange registra no log de auditoria a alteração de um registro, com a diferença estruturada
+// entre o estado anterior e o novo. Deve ser chamado na mesma transação da alteração; a entrada é
+// encadeada após a confirmação dela.
+// Alterações sem campos modificados não são registradas.
+func recordChange(ctx context.Context, tx *sql.Tx, actor *Actor, action, entityType string, entityID int64, before, after interface{}) error {
+	entry, err := changeEntry(actor, action, entityType, entityID, before, after)
+	if err != nil || entry == nil {
+		return err
+	}
+	
+	return insertAuditLog(ctx, tx, entry)
+}
+
+// lockedBefore bloqueia o registro na transação e lê o seu estado anterior com get, para o histórico
+// de alterações: uma alteração concorrente do mesmo registro aguarda o fim da transação, de forma que o
+// estado anterior registrado é o que foi de fato alterado. O registro não encontrado no escopo do
+// repositório é tratado por crossTenant.
+func lockedBefore[T any](ctx context.Context, tx *sql.Tx, scope *TenantScope, table, idColumn string, id int64, get func(context.Context, int64) (*T, error)) (*T, error) {
+	var bloqueado int64
+	err := tx.QueryRowContext(ctx, "SELECT "+idColumn+" FROM "+table+" WHERE "+idColumn+" = ?"+dialect.Current().ForUpdate(), id).Scan(&bloqueado)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("erro ao bloquear o registro: %w", err)
+	}
+	
+	anterior, err := get(withTx(ctx, tx), id)
+	if err != nil {
+		return nil, scope.crossTenant(ctx, tx, table, idColumn, id, err)
+	}
+	return anterior, nil
+}
+
+// changeEntry monta a entrada do log de auditoria com a alteração de um registro, ou retorna nil
+// se nenhum campo foi modificado
+func changeEntry(actor *Actor, action, entityType string, entityID int64, before, after interface{}) (*AuditLog, error) {
+	changes, err := diffChanges(before, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar alterações: %w", err)
+	}
+	
+	// Resumo legível dos campos alterados
+	fields := make([]string, 0, len(changes))
+	for field := range changes {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	
+	entry := &AuditLog{
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   fmt.Sprintf("%d", entityID),
+		Details:    "Campos alterados: " + strings.Join(fields, ", "),
+		Changes:    data,
+	}
+	if actor != nil {
+		entry.Username = actor.Username
+		entry.IPAddress = actor.IPAddress
+		entry.RequestID = actor.RequestID
+		if actor.UserID > 0 {
+			userID := actor.UserID
+			entry.UserID = &userID
+		}
+	}
+	
+	return entry, nil
+}
+
+// inTx executa a função em uma transação, confirmando-a se não houver erro, ou na transação da
+// unidade de trabalho do contexto. Violações de unicidade e de chave estrangeira são retornadas
+// como ConflictError e ForeignKeyError.
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := beginTx(ctx, db)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	if err := fn(tx.Tx); err != nil {
+		return ClassifyDBError(err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return nil
+}
+
+// inserter executa instruções em uma conexão ou em uma transação
+type inserter interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// execInsert executa o INSERT de uma linha. No PostgreSQL, cujo driver não informa o último ID
+// inserido, o ID gerado na coluna informada é devolvido pela cláusula RETURNING.
+func execInsert(ctx context.Context, q inserter, column, query string, args ...interface{}) (sql.Result, error) {
+	returning := dialect.Current().Returning(column)
+	if returning == "" {
+		return q.ExecContext(ctx, query, args...)
+	}
+	
+	var id int64
+	if err := q.QueryRowContext(ctx, query+returning, args...).Scan(&id); err != nil {
+		return nil, err
+	}
+	return insertResult(id), nil
+}
+
+// insertResult é o resultado do INSERT de uma linha cujo ID foi devolvido pela cláusula RETURNING
+type insertResult int64
+
+// LastInsertId retorna o ID gerado pelo INSERT
+func (r insertResult) LastInsertId() (int64, error) {
+	return int64(r), nil
+}
+
+// RowsAffected retorna o número de linhas inseridas
+func (r insertResult) RowsAffected() (int64, error) {
+	return 1, nil
+}
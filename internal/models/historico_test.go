@@ -0,0 +1,70 @@
+package models_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+func TestHistoricoEncadeado(t *testing.T) {
+	for name, newStores := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stores := newStores(t)
+			seguradoraA, _ := createSeguradoras(t, stores)
+
+			evento := &models.Evento{Evento: 101, Descricao: "Emissão de apólice", IdSeguradora: seguradoraA, Ativo: true}
+			if err := stores.Eventos.Create(ctx, evento); err != nil {
+				t.Fatalf("erro ao criar evento: %v", err)
+			}
+
+			// Alteração fora e dentro de uma unidade de trabalho, com uma entrada avulsa na mesma transação
+			alterado := *evento
+			alterado.Descricao = "Emissão"
+			if err := stores.Eventos.Update(ctx, &alterado); err != nil {
+				t.Fatalf("erro ao alterar evento: %v", err)
+			}
+			err := stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+				if err := stores.Eventos.Delete(ctx, evento.ID); err != nil {
+					return err
+				}
+				return stores.AuditLog.Create(ctx, &models.AuditLog{Action: "TESTE", EntityType: "EVENTO", IPAddress: "127.0.0.1"})
+			})
+			if err != nil {
+				t.Fatalf("erro na unidade de trabalho: %v", err)
+			}
+
+			// O histórico registra o estado anterior de cada alteração, já encadeado após a confirmação
+			page, err := stores.AuditLog.GetHistorico(ctx, models.EntidadeEvento, evento.ID, models.ListOptions{Page: 1, PageSize: 10, Sort: []models.SortField{{Field: "id"}}})
+			if err != nil {
+				t.Fatalf("erro ao consultar histórico: %v", err)
+			}
+			if len(page.Items) != 3 {
+				t.Fatalf("histórico com %d entradas, esperado 3 (inclusão, alteração e exclusão)", len(page.Items))
+			}
+			var changes map[string]models.FieldChange
+			if err := json.Unmarshal(page.Items[1].Changes, &changes); err != nil {
+				t.Fatalf("erro ao ler alterações: %v", err)
+			}
+			if changes["descricao"].Old != "Emissão de apólice" || changes["descricao"].New != "Emissão" {
+				t.Errorf("alteração da descrição = %+v", changes["descricao"])
+			}
+			for _, l := range page.Items {
+				if l.Hash == "" {
+					t.Errorf("entrada %d (%s) sem hash", l.ID, l.Action)
+				}
+			}
+
+			// Inclusão das duas seguradoras, histórico do evento e entrada avulsa
+			verification, err := stores.AuditLog.VerifyChain(ctx, nil)
+			if err != nil {
+				t.Fatalf("erro ao verificar a cadeia: %v", err)
+			}
+			if !verification.Valid || verification.CheckedEntries != 6 {
+				t.Errorf("verificação da cadeia = %+v, esperadas 6 entradas válidas", verification)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Formatos dos arquivos de importação
+const (
+	FormatoImportacaoCSV  = "csv"
+	FormatoImportacaoXLSX = "xlsx"
+)
+
+// Quantidade máxima de linhas de dados aceitas em um arquivo de importação
+const MaxLinhasImportacao = 1000
+
+// Limites de descompactação dos arquivos XLSX: total descompactado e tamanho máximo de cada XML
+// mantido em memória (acima dele, a planilha é lida de um arquivo temporário)
+const (
+	limiteDescompactadoXLSX = 64 << 20
+	limiteXMLEmMemoriaXLSX  = 16 << 20
+)
+
+// ErrImportacaoExcedida indica um arquivo de importação com mais linhas que o permitido
+var ErrImportacaoExcedida = fmt.Errorf("quantidade máxima de %d linhas por arquivo de importação excedida", MaxLinhasImportacao)
+
+// LinhaArquivo representa uma linha de dados de um arquivo importado, com os valores indexados
+// pelo nome da coluna no cabeçalho (em minúsculas)
+type LinhaArquivo struct {
+	Linha   int
+	Valores map[string]string
+}
+
+// Valor retorna o valor da coluna informada (vazio se ausente)
+func (l LinhaArquivo) Valor(coluna string) string {
+	return l.Valores[coluna]
+}
+
+// ResultadoImportacao representa o resultado da importação em lote de eventos ou objetos de contabilização.
+// Na simulação, as linhas são apenas validadas e nenhum registro é gravado.
+type ResultadoImportacao struct {
+	Simulacao bool             `json:"simulacao"`
+	Linhas    int              `json:"linhas"`
+	Validas   int              `json:"validas"`
+	Criados   int              `json:"criados"`
+	Erros     []ErroImportacao `json:"erros,omitempty"`
+}
+
+// ParseArquivoImportacao lê um arquivo de importação em CSV (separado por vírgula ou ponto e vírgula)
+// ou XLSX (primeira planilha). A primeira linha deve ser o cabeçalho, com as colunas obrigatórias
+// informadas. Linhas em branco são ignoradas. Arquivos com mais de MaxLinhasImportacao linhas de dados
+// são rejeitados com ErrImportacaoExcedida.
+func ParseArquivoImportacao(reader io.Reader, formato string, obrigatorias []string) ([]LinhaArquivo, error) {
+	var registros [][]string
+	var numeros []int
+	var err error
+	
+	switch formato {
+	case FormatoImportacaoCSV:
+		registros, numeros, err = lerRegistrosCSV(reader)
+	case FormatoImportacaoXLSX:
+		registros, numeros, err = lerRegistrosXLSX(reader)
+	default:
+		return nil, fmt.Errorf("formato de importação inválido: %s (use %s ou %s)", formato, FormatoImportacaoCSV, FormatoImportacaoXLSX)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(registros) == 0 {
+		return nil, fmt.Errorf("arquivo vazio")
+	}
+	
+	// Mapear as colunas pelo cabeçalho
+	cabecalho := registros[0]
+	for i, nome := range cabecalho {
+		cabecalho[i] = strings.ToLower(strings.TrimSpace(nome))
+	}
+	for _, obrigatoria := range obrigatorias {
+		encontrada := false
+		for _, nome := range cabecalho {
+			if nome == obrigatoria {
+				encontrada = true
+				break
+			}
+		}
+		if !encontrada {
+			return nil, fmt.Errorf("coluna obrigatória ausente no cabeçalho: %s", obrigatoria)
+		}
+	}
+	
+	var linhas []LinhaArquivo
+	for i, registro := range registros[1:] {
+		valores := make(map[string]string, len(cabecalho))
+		vazia := true
+		for j, nome := range cabecalho {
+			if nome == "" || j >= len(registro) {
+				continue
+			}
+			valores[nome] = strings.TrimSpace(registro[j])
+			if valores[nome] != "" {
+				vazia = false
+			}
+		}
+		if vazia {
+			continue
+		}
+		if len(linhas) == MaxLinhasImportacao {
+			return nil, ErrImportacaoExcedida
+		}
+		linhas = append(linhas, LinhaArquivo{Linha: numeros[i+1], Valores: valores})
+	}
+	
+	return linhas, nil
+}
+
+// lerRegistrosCSV lê os registros de um arquivo CSV, com o número da linha de cada registro
+func lerRegistrosCSV(reader io.Reader) ([][]string, []int, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	
+	// Detectar o separador pela primeira linha
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	primeiraLinha := strings.SplitN(text, "\n", 2)[0]
+	
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	if strings.Count(primeiraLinha, ";") > strings.Count(primeiraLinha, ",") {
+		csvReader.Comma = ';'
+	}
+	
+	var registros [][]string
+	var numeros []int
+	for {
+		registro, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("arquivo CSV inválido: %w", err)
+		}
+		
+		// Número da linha no arquivo (linhas em branco são ignoradas pelo leitor)
+		numero, _ := csvReader.FieldPos(0)
+		registros = append(registros, registro)
+		numeros = append(numeros, numero)
+	}
+	
+	return registros, numeros, nil
+}
+
+// lerRegistrosXLSX lê os registros da primeira planilha de um arquivo XLSX, com o número da linha de cada registro
+func lerRegistrosXLSX(reader io.Reader) ([][]string, []int, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	
+	arquivo, err := excelize.OpenReader(bytes.NewReader(content), excelize.Options{
+		UnzipSizeLimit:    limiteDescompactadoXLSX,
+		UnzipXMLSizeLimit: limiteXMLEmMemoriaXLSX,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("arquivo XLSX inválido: %w", err)
+	}
+	defer arquivo.Close()
+	
+	planilhas := arquivo.GetSheetList()
+	if len(planilhas) == 0 {
+		return nil, nil, fmt.Errorf("arquivo XLSX sem planilhas")
+	}
+	
+	// GetRows retorna também as linhas em branco entre as linhas preenchidas
+	rows, err := arquivo.GetRows(planilhas[0])
+	if err != nil {
+		return nil, nil, fmt.Errorf("arquivo XLSX inválido: %w", err)
+	}
+	
+	var registros [][]string
+	var numeros []int
+	for i, row := range rows {
+		if len(registros) == 0 && strings.TrimSpace(strings.Join(row, "")) == "" {
+			continue
+		}
+		registros = append(registros, row)
+		numeros = append(numeros, i+1)
+	}
+	
+	return registros, numeros, nil
+}
+
+// parseAtivo interpreta a coluna opcional "ativo" de um arquivo importado (padrão: ativo)
+func parseAtivo(valor string) (bool, error) {
+	switch strings.ToLower(valor) {
+	case "", "1", "s", "sim", "true":
+		return true, nil
+	case "0", "n", "nao", "não", "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("valor inválido na coluna ativo: %s", valor)
+}
+
+// parseInteiro interpreta uma coluna numérica de um arquivo importado (planilhas podem formatar inteiros como "101.0")
+func parseInteiro(valor string) (int, error) {
+	if n, err := strconv.Atoi(valor); err == nil {
+		return n, nil
+	}
+	f, err := strconv.ParseFloat(strings.Replace(valor, ",", ".", 1), 64)
+	if err != nil || f != float64(int(f)) {
+		return 0, fmt.Errorf("valor numérico inválido: %s", valor)
+	}
+	return int(f), nil
+}
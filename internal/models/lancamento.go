@@ -0,0 +1,413 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Naturezas das partidas de um lançamento
+const (
+	NaturezaDebito  = "D"
+	NaturezaCredito = "C"
+)
+
+// TransacaoNegocio representa uma transação de negócio a ser contabilizada
+type TransacaoNegocio struct {
+	IdSeguradora         int64    `json:"idSeguradora"`
+	Evento               int      `json:"evento"`
+	ObjetoContabilizacao string   `json:"objetoContabilizacao"`
+	Valor                Centavos `json:"valor"`
+	DataMovimento        string   `json:"dataMovimento"` // Formato AAAA-MM-DD
+	DocumentoReferencia  string   `json:"documentoReferencia"`
+}
+
+// TransacaoRejeitada representa uma transação que não gerou lançamentos, com os motivos da rejeição
+type TransacaoRejeitada struct {
+	Indice    int              `json:"indice"` // Posição da transação na requisição
+	Transacao TransacaoNegocio `json:"transacao"`
+	Motivos   []string         `json:"motivos"`
+}
+
+// LoteLancamento representa um lote de lançamentos gerados em uma mesma requisição.
+// Todos os lançamentos de um lote pertencem à seguradora do lote.
+type LoteLancamento struct {
+	ID              int64     `json:"idLote"`
+	IdSeguradora    int64     `json:"idSeguradora"`
+	IdUsuario       int64     `json:"idUsuario,omitempty"`
+	TotalTransacoes int       `json:"totalTransacoes"`
+	TotalAceitas    int       `json:"totalAceitas"`
+	TotalRejeitadas int       `json:"totalRejeitadas"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// Lancamento representa um lançamento contábil gerado para um sistema contábil de destino
+type Lancamento struct {
+	ID                      int64               `json:"idLancamento"`
+	IdLote                  int64               `json:"idLote"`
+	IdSeguradora            int64               `json:"idSeguradora"`
+	IdSistemaContabil       int64               `json:"idSistemaContabil"`
+	IdSistemaContabilConfig int64               `json:"idSistemaContabilConfig"`
+	IdCodigoEvento          int64               `json:"idCodigoEvento"`
+	IdObjetoContabilizacao  int64               `json:"idObjetoContabilizacao"`
+	DataMovimento           time.Time           `json:"dataMovimento"`
+	Valor                   Centavos            `json:"valor"`
+	DocumentoReferencia     string              `json:"documentoReferencia"`
+	CreatedAt               time.Time           `json:"created_at"`
+	Partidas                []PartidaLancamento `json:"partidas"`
+}
+
+// PartidaLancamento representa uma partida (débito ou crédito) de um lançamento
+type PartidaLancamento struct {
+	ID           int64    `json:"idPartida"`
+	IdLancamento int64    `json:"idLancamento"`
+	Natureza     string   `json:"natureza"` // "D" (débito) ou "C" (crédito)
+	Valor        Centavos `json:"valor"`
+	IdConta      *int64   `json:"idConta"` // Conta do plano de contas movimentada
+}
+
+// LancamentoRepository gerencia operações de banco de dados para lançamentos
+type LancamentoRepository struct {
+	DB    *sql.DB
+	scope *TenantScope
+}
+
+// NewLancamentoRepository cria um novo repositório de lançamentos
+func NewLancamentoRepository(db *sql.DB) *LancamentoRepository {
+	return &LancamentoRepository{DB: db}
+}
+
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *LancamentoRepository) WithTenant(scope TenantScope) LancamentoStore {
+	return &LancamentoRepository{DB: r.DB, scope: scopeCopy(scope)}
+}
+
+// CreateLote grava o lote e todos os seus lançamentos em uma única transação
+func (r *LancamentoRepository) CreateLote(ctx context.Context, lote *LoteLancamento, lancamentos []Lancamento) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Verificar se o lote e todos os seus lançamentos pertencem à seguradora do usuário
+	if err := checkLote(r.scope, lote, lancamentos); err != nil {
+		return err
+	}
+	
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Gravar o lote
+	result, err := execInsert(ctx, tx, "id_lote",
+		"INSERT INTO lotes_lancamento (idSeguradora, id_usuario, total_transacoes, total_aceitas, total_rejeitadas) VALUES (?, ?, ?, ?, ?)",
+		lote.IdSeguradora,
+		sql.NullInt64{Int64: lote.IdUsuario, Valid: lote.IdUsuario > 0},
+		lote.TotalTransacoes,
+		lote.TotalAceitas,
+		lote.TotalRejeitadas,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao criar lote de lançamentos: %w", err)
+	}
+	
+	lote.ID, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("erro ao obter ID do lote: %w", err)
+	}
+	
+	// Gravar os lançamentos e suas partidas
+	for i := range lancamentos {
+		l := &lancamentos[i]
+		l.IdLote = lote.ID
+		
+		result, err := execInsert(ctx, tx, "id_lancamento", `
+		INSERT INTO lancamentos 
+		(id_lote, idSeguradora, idSistemaContabil, idSistemaContabilConfig, idCodigoEvento, 
+		idObjetoContabilizacao, data_movimento, valor, documento_referencia) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			l.IdLote,
+			l.IdSeguradora,
+			l.IdSistemaContabil,
+			l.IdSistemaContabilConfig,
+			l.IdCodigoEvento,
+			l.IdObjetoContabilizacao,
+			l.DataMovimento,
+			l.Valor,
+			l.DocumentoReferencia,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar lançamento: %w", err)
+		}
+		
+		l.ID, err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID do lançamento: %w", err)
+		}
+		
+		for j := range l.Partidas {
+			p := &l.Partidas[j]
+			p.IdLancamento = l.ID
+			
+			result, err := execInsert(ctx, tx, "id_partida",
+				"INSERT INTO lancamento_partidas (id_lancamento, natureza, valor, id_conta) VALUES (?, ?, ?, ?)",
+				p.IdLancamento,
+				p.Natureza,
+				p.Valor,
+				p.IdConta,
+			)
+			if err != nil {
+				return fmt.Errorf("erro ao criar partida do lançamento: %w", err)
+			}
+			
+			p.ID, err = result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("erro ao obter ID da partida: %w", err)
+			}
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return nil
+}
+
+// checkLote verifica se o lote pertence a uma seguradora visível no escopo e se os lançamentos são da seguradora do lote
+func checkLote(scope *TenantScope, lote *LoteLancamento, lancamentos []Lancamento) error {
+	if !scope.Allows(lote.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	for _, l := range lancamentos {
+		if l.IdSeguradora != lote.IdSeguradora {
+			return ErrCrossTenant
+		}
+	}
+	
+	return nil
+}
+
+// GetLoteByID busca um lote de lançamentos pelo ID
+func (r *LancamentoRepository) GetLoteByID(ctx context.Context, id int64) (*LoteLancamento, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT 
+		id_lote, idSeguradora, id_usuario, total_transacoes, total_aceitas, total_rejeitadas, created_at 
+	FROM lotes_lancamento 
+	WHERE id_lote = ? AND ` + r.scope.condition("idSeguradora")
+	
+	var lote LoteLancamento
+	var idUsuario sql.NullInt64
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
+		&lote.ID,
+		&lote.IdSeguradora,
+		&idUsuario,
+		&lote.TotalTransacoes,
+		&lote.TotalAceitas,
+		&lote.TotalRejeitadas,
+		&lote.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFoundError{Message: "lote de lançamentos não encontrado"}
+		}
+		return nil, fmt.Errorf("erro ao buscar lote de lançamentos: %w", err)
+	}
+	
+	lote.IdUsuario = idUsuario.Int64
+	return &lote, nil
+}
+
+// GetByLote busca os lançamentos de um lote, com suas partidas
+func (r *LancamentoRepository) GetByLote(ctx context.Context, idLote int64) ([]Lancamento, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT 
+		id_lancamento, id_lote, idSeguradora, idSistemaContabil, idSistemaContabilConfig, 
+		idCodigoEvento, idObjetoContabilizacao, data_movimento, valor, documento_referencia, created_at 
+	FROM lancamentos 
+	WHERE id_lote = ? AND ` + r.scope.condition("idSeguradora") + ` 
+	ORDER BY id_lancamento`
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, query, r.scope.args(idLote)...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar lançamentos do lote: %w", err)
+	}
+	defer rows.Close()
+	
+	lancamentos, err := scanLancamentos(rows)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Carregar as partidas de cada lançamento
+	if err := r.loadPartidas(ctx, lancamentos); err != nil {
+		return nil, err
+	}
+	
+	return lancamentos, nil
+}
+
+// lancamentoListSpec define os campos de ordenação e filtro da listagem de lançamentos
+var lancamentoListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idLancamento":            {Column: "id_lancamento", Type: FieldInt},
+		"idLote":                  {Column: "id_lote", Type: FieldInt},
+		"idSeguradora":            {Column: "idSeguradora", Type: FieldInt},
+		"idSistemaContabil":       {Column: "idSistemaContabil", Type: FieldInt},
+		"idSistemaContabilConfig": {Column: "idSistemaContabilConfig", Type: FieldInt},
+		"idCodigoEvento":          {Column: "idCodigoEvento", Type: FieldInt},
+		"idObjetoContabilizacao":  {Column: "idObjetoContabilizacao", Type: FieldInt},
+		"dataMovimento":           {Column: "data_movimento", Type: FieldTime},
+		"documentoReferencia":     {Column: "documento_referencia", Type: FieldString},
+		"created_at":              {Column: "created_at", Type: FieldTime},
+	},
+	Key: "idLancamento",
+}
+
+// GetAll retorna os lançamentos, com suas partidas, com paginação, ordenação e filtros
+func (r *LancamentoRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[Lancamento], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT 
+		id_lancamento, id_lote, idSeguradora, idSistemaContabil, idSistemaContabilConfig, 
+		idCodigoEvento, idObjetoContabilizacao, data_movimento, valor, documento_referencia, created_at 
+	FROM lancamentos 
+	WHERE ` + r.scope.condition("idSeguradora")
+	
+	page, err := listPage(ctx, r.DB, &lancamentoListSpec, opts, query, r.scope.args(), scanLancamentos)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Carregar as partidas dos lançamentos da página
+	if err := r.loadPartidas(ctx, page.Items); err != nil {
+		return nil, err
+	}
+	
+	return page, nil
+}
+
+// scanLancamentos lê os lançamentos retornados por uma consulta, sem as partidas
+func scanLancamentos(rows *sql.Rows) ([]Lancamento, error) {
+	var lancamentos []Lancamento
+	
+	for rows.Next() {
+		var l Lancamento
+		if err := rows.Scan(
+			&l.ID,
+			&l.IdLote,
+			&l.IdSeguradora,
+			&l.IdSistemaContabil,
+			&l.IdSistemaContabilConfig,
+			&l.IdCodigoEvento,
+			&l.IdObjetoContabilizacao,
+			&l.DataMovimento,
+			&l.Valor,
+			&l.DocumentoReferencia,
+			&l.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao ler lançamento: %w", err)
+		}
+		lancamentos = append(lancamentos, l)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre lançamentos: %w", err)
+	}
+	
+	return lancamentos, nil
+}
+
+// loadPartidas carrega as partidas de cada lançamento informado
+func (r *LancamentoRepository) loadPartidas(ctx context.Context, lancamentos []Lancamento) error {
+	for i := range lancamentos {
+		partidas, err := r.getPartidas(ctx, lancamentos[i].ID)
+		if err != nil {
+			return err
+		}
+		lancamentos[i].Partidas = partidas
+	}
+	return nil
+}
+
+// GetByID busca um lançamento pelo ID, com suas partidas
+func (r *LancamentoRepository) GetByID(ctx context.Context, id int64) (*Lancamento, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT 
+		id_lancamento, id_lote, idSeguradora, idSistemaContabil, idSistemaContabilConfig, 
+		idCodigoEvento, idObjetoContabilizacao, data_movimento, valor, documento_referencia, created_at 
+	FROM lancamentos 
+	WHERE id_lancamento = ? AND ` + r.scope.condition("idSeguradora")
+	
+	var l Lancamento
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
+		&l.ID,
+		&l.IdLote,
+		&l.IdSeguradora,
+		&l.IdSistemaContabil,
+		&l.IdSistemaContabilConfig,
+		&l.IdCodigoEvento,
+		&l.IdObjetoContabilizacao,
+		&l.DataMovimento,
+		&l.Valor,
+		&l.DocumentoReferencia,
+		&l.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFoundError{Message: "lançamento não encontrado"}
+		}
+		return nil, fmt.Errorf("erro ao buscar lançamento: %w", err)
+	}
+	
+	l.Partidas, err = r.getPartidas(ctx, l.ID)
+	if err != nil {
+		return nil, err
+	}
+	
+	return &l, nil
+}
+
+// getPartidas busca as partidas de um lançamento
+func (r *LancamentoRepository) getPartidas(ctx context.Context, idLancamento int64) ([]PartidaLancamento, error) {
+	query := `
+	SELECT id_partida, id_lancamento, natureza, valor, id_conta 
+	FROM lancamento_partidas 
+	WHERE id_lancamento = ? 
+	ORDER BY id_partida`
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, query, idLancamento)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar partidas do lançamento: %w", err)
+	}
+	defer rows.Close()
+	
+	var partidas []PartidaLancamento
+	
+	for rows.Next() {
+		var p PartidaLancamento
+		if err := rows.Scan(&p.ID, &p.IdLancamento, &p.Natureza, &p.Valor, &p.IdConta); err != nil {
+			return nil, fmt.Errorf("erro ao ler partida: %w", err)
+		}
+		partidas = append(partidas, p)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre partidas: %w", err)
+	}
+	
+	return partidas, nil
+}
@@ -0,0 +1,115 @@
+package models_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// createLote grava na seguradora informada um lote com um lançamento por data de movimento,
+// sobre um sistema contábil, evento, objeto e configuração criados para o lote
+func createLote(t *testing.T, stores *models.Stores, idSeguradora int64, datas ...string) {
+	t.Helper()
+
+	ctx := context.Background()
+	sistema := &models.SistemaContabil{SistemaContabil: "SAP", IdSeguradora: idSeguradora, Ativo: true}
+	if err := stores.SistemasContabeis.Create(ctx, sistema); err != nil {
+		t.Fatalf("erro ao criar sistema contábil: %v", err)
+	}
+	evento := &models.Evento{Evento: 101, Descricao: "Emissão de apólice", IdSeguradora: idSeguradora, Ativo: true}
+	if err := stores.Eventos.Create(ctx, evento); err != nil {
+		t.Fatalf("erro ao criar evento: %v", err)
+	}
+	objeto := &models.ObjetoContabilizacao{ObjetoContabilizacao: "PREMIO", Descricao: "Prêmio emitido", IdSeguradora: idSeguradora, Ativo: true}
+	if err := stores.ObjetosContabilizacao.Create(ctx, objeto); err != nil {
+		t.Fatalf("erro ao criar objeto de contabilização: %v", err)
+	}
+	relacao := &models.ObjetoContabilizacaoEvento{IdObjetoContabilizacao: objeto.ID, IdCodigoEvento: evento.ID, IdSeguradora: idSeguradora, Ativo: true}
+	if err := stores.ObjetosContabilizacaoEvento.Create(ctx, relacao); err != nil {
+		t.Fatalf("erro ao relacionar objeto e evento: %v", err)
+	}
+	config := &models.SistemaContabilConfig{IdSistemaContabil: sistema.ID, IdObjetoContabilizacao: objeto.ID, IdCodigoEvento: evento.ID, IdSeguradora: idSeguradora, Ativo: true}
+	if err := stores.SistemasContabeisConfig.Create(ctx, config); err != nil {
+		t.Fatalf("erro ao criar configuração de sistema contábil: %v", err)
+	}
+
+	var lancamentos []models.Lancamento
+	for _, data := range datas {
+		dataMovimento, err := time.Parse("2006-01-02", data)
+		if err != nil {
+			t.Fatalf("data inválida: %v", err)
+		}
+		lancamentos = append(lancamentos, models.Lancamento{
+			IdSeguradora:            idSeguradora,
+			IdSistemaContabil:       sistema.ID,
+			IdSistemaContabilConfig: config.ID,
+			IdCodigoEvento:          evento.ID,
+			IdObjetoContabilizacao:  objeto.ID,
+			DataMovimento:           dataMovimento,
+			Valor:                   15075,
+			DocumentoReferencia:     "APOLICE-" + data,
+			Partidas: []models.PartidaLancamento{
+				{Natureza: models.NaturezaDebito, Valor: 15075},
+				{Natureza: models.NaturezaCredito, Valor: 15075},
+			},
+		})
+	}
+	lote := &models.LoteLancamento{IdSeguradora: idSeguradora, TotalTransacoes: len(datas), TotalAceitas: len(datas)}
+	if err := stores.Lancamentos.CreateLote(ctx, lote, lancamentos); err != nil {
+		t.Fatalf("erro ao criar lote de lançamentos: %v", err)
+	}
+}
+
+func TestListagemLancamentos(t *testing.T) {
+	for name, newStores := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stores := newStores(t)
+			seguradoraA, seguradoraB := createSeguradoras(t, stores)
+			createLote(t, stores, seguradoraA, "2024-01-10", "2024-02-10", "2024-03-10", "2024-04-10")
+			createLote(t, stores, seguradoraB, "2024-03-10")
+			repo := stores.Lancamentos.WithTenant(models.TenantScope{IdSeguradora: seguradoraA})
+
+			// Filtro por data de movimento, em ordem crescente, duas entradas por página
+			opts := models.ListOptions{
+				Page:     1,
+				PageSize: 2,
+				Sort:     []models.SortField{{Field: "dataMovimento"}},
+				Filters:  []models.Filter{{Field: "dataMovimento", Operator: models.FilterGreaterOrEq, Value: "2024-02-01"}},
+			}
+			page, err := repo.GetAll(ctx, opts)
+			if err != nil {
+				t.Fatalf("erro ao listar lançamentos: %v", err)
+			}
+			if page.Total != 3 || len(page.Items) != 2 || page.NextCursor == "" {
+				t.Fatalf("página com total %d, %d itens e cursor %q, esperado total 3, 2 itens e cursor", page.Total, len(page.Items), page.NextCursor)
+			}
+			if page.Items[0].DocumentoReferencia != "APOLICE-2024-02-10" || page.Items[1].DocumentoReferencia != "APOLICE-2024-03-10" {
+				t.Errorf("itens da primeira página: %s, %s", page.Items[0].DocumentoReferencia, page.Items[1].DocumentoReferencia)
+			}
+			for _, l := range page.Items {
+				if l.IdSeguradora != seguradoraA || len(l.Partidas) != 2 {
+					t.Errorf("lançamento %d da seguradora %d com %d partidas", l.ID, l.IdSeguradora, len(l.Partidas))
+				}
+			}
+
+			// Próxima página pelo cursor
+			opts.Page, opts.After = 0, page.NextCursor
+			page, err = repo.GetAll(ctx, opts)
+			if err != nil {
+				t.Fatalf("erro ao listar lançamentos: %v", err)
+			}
+			if len(page.Items) != 1 || page.Items[0].DocumentoReferencia != "APOLICE-2024-04-10" || page.NextCursor != "" {
+				t.Errorf("segunda página com %d itens e cursor %q", len(page.Items), page.NextCursor)
+			}
+
+			// Campo fora da lista de permitidos
+			_, err = repo.GetAll(ctx, models.ListOptions{Sort: []models.SortField{{Field: "valor"}}})
+			if err == nil {
+				t.Error("ordenação por campo não permitido aceita")
+			}
+		})
+	}
+}
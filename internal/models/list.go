@@ -0,0 +1,580 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// Limites de paginação das listagens
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// Parâmetros reservados da query string das listagens (os demais são filtros)
+const (
+	paramPage     = "page"
+	paramPageSize = "page_size"
+	paramAfter    = "after"
+	paramSort     = "sort"
+)
+
+// FieldType define o tipo de um campo de listagem, usado para converter os valores dos filtros
+type FieldType int
+
+const (
+	FieldInt FieldType = iota
+	FieldString
+	FieldBool
+	FieldTime
+)
+
+// Operadores de filtro aceitos nas listagens
+const (
+	FilterEqual       = "="
+	FilterNotEqual    = "!="
+	FilterContains    = "~="
+	FilterGreaterOrEq = ">="
+	FilterLessOrEq    = "<="
+)
+
+// ListField descreve um campo que pode ser usado para ordenar e filtrar uma listagem
+type ListField struct {
+	Column   string    // Coluna (ou expressão) SQL correspondente
+	Type     FieldType // Tipo do campo
+	Nullable bool      // A coluna aceita NULL (ordenado antes dos demais valores)
+}
+
+// ListSpec define os campos permitidos na listagem de uma entidade.
+// Os nomes dos campos são os mesmos nomes JSON da entidade.
+type ListSpec struct {
+	Fields map[string]ListField
+	Key    string // Campo único usado como desempate da ordenação e no cursor (ordem padrão: decrescente)
+}
+
+// SortField representa um campo de ordenação
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Filter representa um filtro de campo (ex.: descricao~=prem)
+type Filter struct {
+	Field    string
+	Operator string
+	Value    string
+}
+
+// ListOptions representa os parâmetros de paginação, ordenação e filtro de uma listagem
+type ListOptions struct {
+	Page     int
+	PageSize int
+	After    string // Cursor retornado em next_cursor pela página anterior
+	Sort     []SortField
+	Filters  []Filter
+}
+
+// Page representa uma página de resultados de uma listagem
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	PageSize   int    `json:"page_size"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// ParseListOptions lê os parâmetros de paginação, ordenação e filtro da query string.
+// Os nomes dos campos são validados pelo repositório de cada entidade.
+func ParseListOptions(values url.Values) (ListOptions, error) {
+	opts := ListOptions{Page: 1, PageSize: DefaultPageSize}
+	
+	// Paginação por número de página
+	if v := values.Get(paramPage); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return opts, utils.ValidationError{Field: paramPage, Message: "deve ser um número inteiro maior que zero"}
+		}
+		opts.Page = page
+	}
+	
+	if v := values.Get(paramPageSize); v != "" {
+		pageSize, err := strconv.Atoi(v)
+		if err != nil || pageSize < 1 || pageSize > MaxPageSize {
+			return opts, utils.ValidationError{Field: paramPageSize, Message: fmt.Sprintf("deve ser um número entre 1 e %d", MaxPageSize)}
+		}
+		opts.PageSize = pageSize
+	}
+	
+	// Paginação por cursor
+	if v := values.Get(paramAfter); v != "" {
+		if values.Get(paramPage) != "" {
+			return opts, utils.ValidationError{Field: paramAfter, Message: "não pode ser usado junto com page"}
+		}
+		opts.After = v
+	}
+	
+	// Ordenação: sort=campo,-campo
+	if v := values.Get(paramSort); v != "" {
+		for _, field := range strings.Split(v, ",") {
+			field = strings.TrimSpace(field)
+			desc := strings.HasPrefix(field, "-")
+			field = strings.TrimPrefix(field, "-")
+			if field == "" {
+				return opts, utils.ValidationError{Field: paramSort, Message: "campo de ordenação vazio"}
+			}
+			opts.Sort = append(opts.Sort, SortField{Field: field, Desc: desc})
+		}
+	}
+	
+	// Filtros: os demais parâmetros (campo=valor, campo~=valor, campo!=valor, campo>=valor, campo<=valor)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	
+	for _, key := range keys {
+		if key == paramPage || key == paramPageSize || key == paramAfter || key == paramSort {
+			continue
+		}
+		
+		field, operator := key, FilterEqual
+		for _, suffix := range []string{"~", "!", ">", "<"} {
+			if strings.HasSuffix(key, suffix) {
+				field, operator = strings.TrimSuffix(key, suffix), suffix+"="
+				break
+			}
+		}
+		
+		for _, value := range values[key] {
+			opts.Filters = append(opts.Filters, Filter{Field: field, Operator: operator, Value: value})
+		}
+	}
+	
+	return opts, nil
+}
+
+// WithFilter retorna uma cópia das opções com um filtro de igualdade adicional
+func (o ListOptions) WithFilter(field string, value interface{}) ListOptions {
+	filters := make([]Filter, len(o.Filters), len(o.Filters)+1)
+	copy(filters, o.Filters)
+	o.Filters = append(filters, Filter{Field: field, Operator: FilterEqual, Value: fmt.Sprint(value)})
+	return o
+}
+
+// fieldNames retorna os nomes dos campos permitidos, para as mensagens de erro
+func (s *ListSpec) fieldNames() string {
+	names := make([]string, 0, len(s.Fields))
+	for name := range s.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// sortFields retorna a ordenação validada, sempre terminando pelo campo único da entidade
+func (s *ListSpec) sortFields(opts ListOptions) ([]SortField, error) {
+	var fields []SortField
+	hasKey := false
+	
+	for _, sf := range opts.Sort {
+		if _, ok := s.Fields[sf.Field]; !ok {
+			return nil, utils.ValidationError{
+				Field:   paramSort,
+				Message: fmt.Sprintf("campo de ordenação inválido: %s (permitidos: %s)", sf.Field, s.fieldNames()),
+			}
+		}
+		fields = append(fields, sf)
+		if sf.Field == s.Key {
+			hasKey = true
+			break // Campos após o campo único não alteram a ordem
+		}
+	}
+	
+	if !hasKey {
+		fields = append(fields, SortField{Field: s.Key, Desc: true})
+	}
+	
+	return fields, nil
+}
+
+// filterConditions converte os filtros em condições SQL
+func (s *ListSpec) filterConditions(filters []Filter) (string, []interface{}, error) {
+	var conditions []string
+	var args []interface{}
+	
+	for _, f := range filters {
+		field, value, err := s.checkFilter(f)
+		if err != nil {
+			return "", nil, err
+		}
+		
+		switch f.Operator {
+		case FilterEqual, FilterNotEqual:
+			sqlOperator := "="
+			if f.Operator == FilterNotEqual {
+				sqlOperator = "<>"
+			}
+			conditions = append(conditions, field.Column+" "+sqlOperator+" ?")
+			args = append(args, value)
+		case FilterContains:
+			conditions = append(conditions, dialect.Current().Contains(field.Column))
+			args = append(args, "%"+escapeLike(f.Value)+"%")
+		default:
+			conditions = append(conditions, field.Column+" "+f.Operator+" ?")
+			args = append(args, value)
+		}
+	}
+	
+	return strings.Join(conditions, " AND "), args, nil
+}
+
+// checkFilter valida o campo e o operador de um filtro e converte o valor para o tipo do campo
+func (s *ListSpec) checkFilter(f Filter) (ListField, interface{}, error) {
+	field, ok := s.Fields[f.Field]
+	if !ok {
+		return field, nil, utils.ValidationError{
+			Field:   f.Field,
+			Message: fmt.Sprintf("campo de filtro inválido (permitidos: %s)", s.fieldNames()),
+		}
+	}
+	
+	value, err := convertListValue(field.Type, f.Value)
+	if err != nil {
+		return field, nil, utils.ValidationError{Field: f.Field, Message: err.Error()}
+	}
+	
+	switch f.Operator {
+	case FilterEqual, FilterNotEqual:
+	case FilterContains:
+		if field.Type != FieldString {
+			return field, nil, utils.ValidationError{Field: f.Field, Message: "o operador ~= só pode ser usado em campos de texto"}
+		}
+	case FilterGreaterOrEq, FilterLessOrEq:
+		if field.Type != FieldInt && field.Type != FieldTime {
+			return field, nil, utils.ValidationError{Field: f.Field, Message: "os operadores >= e <= só podem ser usados em campos numéricos e datas"}
+		}
+	default:
+		return field, nil, utils.ValidationError{Field: f.Field, Message: "operador de filtro inválido: " + f.Operator}
+	}
+	
+	return field, value, nil
+}
+
+// cursorCondition converte o cursor na condição que seleciona os registros posteriores ao último da página anterior
+func (s *ListSpec) cursorCondition(cursor string, sortFields []SortField) (string, []interface{}, error) {
+	values, err := s.decodeCursor(cursor, sortFields)
+	if err != nil {
+		return "", nil, err
+	}
+	
+	// (a > v1) OR (a = v1 AND b > v2) OR ... respeitando o sentido de cada campo.
+	// Os valores nulos precedem os demais: vêm antes na ordem crescente e depois na decrescente.
+	var alternatives []string
+	var args []interface{}
+	for i, sf := range sortFields {
+		column := s.Fields[sf.Field].Column
+		
+		var parts []string
+		var partArgs []interface{}
+		for j := 0; j < i; j++ {
+			previous := s.Fields[sortFields[j].Field].Column
+			if values[j] == nil {
+				parts = append(parts, previous+" IS NULL")
+				continue
+			}
+			parts = append(parts, previous+" = ?")
+			partArgs = append(partArgs, values[j])
+		}
+		
+		switch {
+		case values[i] == nil && sf.Desc:
+			continue // Nenhum valor sucede o nulo na ordem decrescente
+		case values[i] == nil:
+			parts = append(parts, column+" IS NOT NULL")
+		default:
+			operator := ">"
+			if sf.Desc {
+				operator = "<"
+			}
+			condition := column + " " + operator + " ?"
+			if sf.Desc && s.Fields[sf.Field].Nullable {
+				condition = "(" + condition + " OR " + column + " IS NULL)"
+			}
+			parts = append(parts, condition)
+			partArgs = append(partArgs, values[i])
+		}
+		alternatives = append(alternatives, "("+strings.Join(parts, " AND ")+")")
+		args = append(args, partArgs...)
+	}
+	
+	return "(" + strings.Join(alternatives, " OR ") + ")", args, nil
+}
+
+// decodeCursor converte o cursor nos valores dos campos de ordenação do último item da página anterior
+func (s *ListSpec) decodeCursor(cursor string, sortFields []SortField) ([]interface{}, error) {
+	invalid := utils.ValidationError{Field: paramAfter, Message: "cursor inválido"}
+	
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, invalid
+	}
+	
+	var raw []*string
+	if err := json.Unmarshal(data, &raw); err != nil || len(raw) != len(sortFields) {
+		return nil, invalid
+	}
+	
+	// Valores nulos só são aceitos nos campos que admitem NULL
+	values := make([]interface{}, len(raw))
+	for i, v := range raw {
+		field := s.Fields[sortFields[i].Field]
+		if v == nil {
+			if !field.Nullable {
+				return nil, invalid
+			}
+			continue
+		}
+		values[i], err = convertListValue(field.Type, *v)
+		if err != nil {
+			return nil, invalid
+		}
+	}
+	
+	return values, nil
+}
+
+// encodeCursor gera o cursor a partir dos valores dos campos de ordenação do último item da página
+func (s *ListSpec) encodeCursor(item interface{}, sortFields []SortField) (string, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar cursor: %w", err)
+	}
+	
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("erro ao gerar cursor: %w", err)
+	}
+	
+	// Os valores nulos são gravados como null, para que o cursor os distinga do texto vazio
+	values := make([]*string, len(sortFields))
+	for i, sf := range sortFields {
+		var value string
+		switch v := fields[sf.Field].(type) {
+		case nil:
+			continue
+		case string:
+			value = v
+		case float64:
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			value = fmt.Sprint(v)
+		}
+		values[i] = &value
+	}
+	
+	data, err = json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar cursor: %w", err)
+	}
+	
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// listPage executa a consulta base (que deve terminar em uma cláusula WHERE) aplicando
+// filtros, ordenação e paginação, e retorna a página com o total de registros
+func listPage[T any](ctx context.Context, db *sql.DB, spec *ListSpec, opts ListOptions, query string, args []interface{}, scan func(*sql.Rows) ([]T, error)) (*Page[T], error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	
+	sortFields, err := spec.sortFields(opts)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Aplicar os filtros
+	filters, filterArgs, err := spec.filterConditions(opts.Filters)
+	if err != nil {
+		return nil, err
+	}
+	if filters != "" {
+		query += " AND " + filters
+		args = append(args, filterArgs...)
+	}
+	
+	// Contar o total de registros que atendem aos filtros
+	var total int64
+	if err := connFor(ctx, db).QueryRowContext(ctx, "SELECT COUNT(*) FROM ("+query+") AS total", args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("erro ao contar registros: %w", err)
+	}
+	
+	// Aplicar o cursor
+	if opts.After != "" {
+		cursor, cursorArgs, err := spec.cursorCondition(opts.After, sortFields)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND " + cursor
+		args = append(args, cursorArgs...)
+	}
+	
+	// Aplicar a ordenação
+	query += " ORDER BY " + spec.orderBy(sortFields)
+	
+	// Buscar um registro a mais para saber se existe próxima página
+	query += " LIMIT ?"
+	args = append(args, opts.PageSize+1)
+	if opts.After == "" {
+		query += " OFFSET ?"
+		args = append(args, (opts.Page-1)*opts.PageSize)
+	}
+	
+	rows, err := connFor(ctx, db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar registros: %w", err)
+	}
+	defer rows.Close()
+	
+	items, err := scan(rows)
+	if err != nil {
+		return nil, err
+	}
+	
+	return newPage(spec, opts, sortFields, items, total)
+}
+
+// newPage monta a página a partir dos registros lidos (até um a mais que o tamanho da página,
+// para indicar a existência da próxima) e do total de registros que atendem aos filtros
+func newPage[T any](spec *ListSpec, opts ListOptions, sortFields []SortField, items []T, total int64) (*Page[T], error) {
+	page := &Page[T]{
+		Items:    items,
+		Total:    total,
+		PageSize: opts.PageSize,
+	}
+	if opts.After == "" {
+		page.Page = opts.Page
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	
+	// Gerar o cursor da próxima página
+	if len(page.Items) > opts.PageSize {
+		page.Items = page.Items[:opts.PageSize]
+		cursor, err := spec.encodeCursor(page.Items[len(page.Items)-1], sortFields)
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = cursor
+	}
+	
+	return page, nil
+}
+
+// streamList executa a consulta base (que deve terminar em uma cláusula WHERE) aplicando
+// filtros e ordenação, sem paginação, e chama fn para cada registro lido. Usado nas
+// exportações, para não carregar o resultado inteiro em memória.
+func streamList[T any](ctx context.Context, db *sql.DB, spec *ListSpec, opts ListOptions, query string, args []interface{}, scan func(*sql.Rows) (T, error), fn func(T) error) error {
+	sortFields, err := spec.sortFields(opts)
+	if err != nil {
+		return err
+	}
+	
+	// Aplicar os filtros
+	filters, filterArgs, err := spec.filterConditions(opts.Filters)
+	if err != nil {
+		return err
+	}
+	if filters != "" {
+		query += " AND " + filters
+		args = append(args, filterArgs...)
+	}
+	
+	// Aplicar a ordenação
+	query += " ORDER BY " + spec.orderBy(sortFields)
+	
+	rows, err := connFor(ctx, db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar registros: %w", err)
+	}
+	defer rows.Close()
+	
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre registros: %w", err)
+	}
+	
+	return nil
+}
+
+// orderBy monta a cláusula ORDER BY a partir dos campos de ordenação validados
+func (s *ListSpec) orderBy(sortFields []SortField) string {
+	orderBy := make([]string, len(sortFields))
+	for i, sf := range sortFields {
+		orderBy[i] = s.Fields[sf.Field].Column
+		if sf.Desc {
+			orderBy[i] += " DESC"
+		}
+		if s.Fields[sf.Field].Nullable {
+			orderBy[i] += dialect.Current().NullsFirst(sf.Desc)
+		}
+	}
+	return strings.Join(orderBy, ", ")
+}
+
+// convertListValue converte o valor de um filtro ou cursor para o tipo do campo
+func convertListValue(fieldType FieldType, value string) (interface{}, error) {
+	switch fieldType {
+	case FieldInt:
+		v, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("deve ser um número inteiro")
+		}
+		return v, nil
+	case FieldBool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("deve ser true ou false")
+		}
+		return v, nil
+	case FieldTime:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+			if v, err := time.Parse(layout, value); err == nil {
+				return v, nil
+			}
+		}
+		return nil, fmt.Errorf("deve ser uma data no formato AAAA-MM-DD ou RFC 3339")
+	default:
+		return value, nil
+	}
+}
+
+// escapeLike escapa os caracteres especiais do operador LIKE
+func escapeLike(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return replacer.Replace(value)
+}
@@ -0,0 +1,66 @@
+package models_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+func TestCursorComValoresNulos(t *testing.T) {
+	for name, newStores := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stores := newStores(t)
+
+			// Entradas sem usuário (ações do sistema) e de dois usuários
+			var entries []*models.AuditLog
+			for _, userID := range []int64{0, 2, 0, 1, 0, 2} {
+				entry := &models.AuditLog{Action: "TESTE", EntityType: "EVENTO", IPAddress: "127.0.0.1"}
+				if userID != 0 {
+					id := userID
+					entry.UserID = &id
+				}
+				entries = append(entries, entry)
+			}
+			if err := stores.AuditLog.CreateBatch(ctx, entries); err != nil {
+				t.Fatalf("erro ao registrar entradas: %v", err)
+			}
+
+			// Os nulos precedem os demais valores na ordem crescente e os sucedem na decrescente
+			for _, tc := range []struct {
+				sort models.SortField
+				want string
+			}{
+				{models.SortField{Field: "user_id"}, "[- - - 1 2 2]"},
+				{models.SortField{Field: "user_id", Desc: true}, "[2 2 1 - - -]"},
+			} {
+				opts := models.ListOptions{PageSize: 2, Sort: []models.SortField{tc.sort}}
+				var got []string
+				ids := map[int64]bool{}
+				for pages := 0; pages < 10; pages++ {
+					page, err := stores.AuditLog.GetAll(ctx, opts)
+					if err != nil {
+						t.Fatalf("sort %+v: erro ao listar a página %d: %v", tc.sort, pages+1, err)
+					}
+					for _, entry := range page.Items {
+						ids[entry.ID] = true
+						if entry.UserID == nil {
+							got = append(got, "-")
+						} else {
+							got = append(got, fmt.Sprint(*entry.UserID))
+						}
+					}
+					if page.NextCursor == "" {
+						break
+					}
+					opts.After = page.NextCursor
+				}
+				if fmt.Sprint(got) != tc.want || len(ids) != len(entries) {
+					t.Errorf("sort %+v: usuários %v em %d entradas distintas, esperado %s em %d", tc.sort, got, len(ids), tc.want, len(entries))
+				}
+			}
+		})
+	}
+}
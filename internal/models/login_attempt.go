@@ -0,0 +1,132 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// LoginAttemptRepository gerencia o registro das tentativas de login e o bloqueio temporário das contas
+// que excedem o limite de tentativas. A consulta das tentativas fica no AuditLogRepository.
+type LoginAttemptRepository struct {
+	DB *sql.DB
+}
+
+// NewLoginAttemptRepository cria um novo repositório de tentativas de login
+func NewLoginAttemptRepository(db *sql.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+// Create registra uma tentativa de login
+func (r *LoginAttemptRepository) Create(ctx context.Context, attempt *LoginAttempt) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	INSERT INTO login_attempts
+	(login, ip_address, success)
+	VALUES (?, ?, ?)`
+	
+	_, err := connFor(ctx, r.DB).ExecContext(ctx, query, attempt.Login, attempt.IPAddress, attempt.Success)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar tentativa de login: %w", err)
+	}
+	
+	return nil
+}
+
+// CountRecentFailures conta as tentativas de login malsucedidas do login ou do IP nos últimos minutos
+func (r *LoginAttemptRepository) CountRecentFailures(ctx context.Context, login, ipAddress string, minutes int) (int, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT COUNT(*)
+	FROM login_attempts
+	WHERE (login = ? OR ip_address = ?)
+	AND success = false
+	AND attempt_time > ` + dialect.Current().MinutesAgo(minutes)
+	
+	var count int
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, login, ipAddress).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar tentativas de login: %w", err)
+	}
+	
+	return count, nil
+}
+
+// LockAccount bloqueia a conta do login informado até o instante indicado e revoga as suas sessões,
+// invalidando também os tokens de acesso já emitidos
+func (r *LoginAttemptRepository) LockAccount(ctx context.Context, login string, until time.Time) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		query := `
+		UPDATE usuarios
+		SET bloqueado = true, bloqueado_ate = ?
+		WHERE login = ?`
+		
+		if _, err := tx.ExecContext(ctx, query, until, login); err != nil {
+			return fmt.Errorf("erro ao bloquear conta: %w", err)
+		}
+		
+		query = `
+		UPDATE refresh_tokens
+		SET revoked_at = CURRENT_TIMESTAMP, motivo_revogacao = ?
+		WHERE revoked_at IS NULL
+		AND id_usuario IN (SELECT id FROM usuarios WHERE login = ?)`
+		
+		if _, err := tx.ExecContext(ctx, query, MotivoRevogacaoBloqueio, login); err != nil {
+			return fmt.Errorf("erro ao revogar sessões da conta bloqueada: %w", err)
+		}
+		
+		return nil
+	})
+}
+
+// GetAccountLock retorna o status de bloqueio da conta do login informado (não bloqueada se o login não existir)
+func (r *LoginAttemptRepository) GetAccountLock(ctx context.Context, login string) (bool, time.Time, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT bloqueado, bloqueado_ate
+	FROM usuarios
+	WHERE login = ?`
+	
+	var bloqueado bool
+	var bloqueadoAte sql.NullTime
+	
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, login).Scan(&bloqueado, &bloqueadoAte)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, time.Time{}, nil
+		}
+		return false, time.Time{}, fmt.Errorf("erro ao verificar status de bloqueio: %w", err)
+	}
+	
+	return bloqueado, bloqueadoAte.Time, nil
+}
+
+// UnlockAccount desbloqueia a conta do login informado
+func (r *LoginAttemptRepository) UnlockAccount(ctx context.Context, login string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	UPDATE usuarios
+	SET bloqueado = false, bloqueado_ate = NULL
+	WHERE login = ?`
+	
+	_, err := connFor(ctx, r.DB).ExecContext(ctx, query, login)
+	if err != nil {
+		return fmt.Errorf("erro ao desbloquear conta: %w", err)
+	}
+	
+	return nil
+}
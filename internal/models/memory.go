@@ -0,0 +1,527 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryDB armazena em memória os dados compartilhados pelos armazenamentos em memória (MemoryXStore).
+// Reproduz as regras do banco de dados usadas pela aplicação: IDs sequenciais, chaves únicas e
+// estrangeiras, datas de criação e alteração e o encadeamento do log de auditoria. Cada operação é
+// executada com o banco bloqueado, o que equivale a uma transação.
+type MemoryDB struct {
+	mu sync.Mutex
+	memoryTables
+}
+
+// memoryTables contém as tabelas do banco de dados em memória
+type memoryTables struct {
+	seq map[string]int64 // Último ID gerado por tabela
+
+	usuarios             map[int64]Usuario // Com o hash da senha
+	tiposPerfil          map[int64]TipoPerfil
+	permissoes           map[int64]Permissao
+	tipoPerfilPermissoes map[int64]map[int64]bool // Permissões concedidas por tipo de perfil
+	seguradoras          map[int64]Seguradora
+	eventos              map[int64]Evento
+	objetos              map[int64]ObjetoContabilizacao
+	relacoes             map[int64]ObjetoContabilizacaoEvento
+	sistemas             map[int64]SistemaContabil
+	configs              map[int64]SistemaContabilConfig
+	contas               map[int64]ContaContabil
+	lotes                map[int64]LoteLancamento
+	lancamentos          map[int64]Lancamento // Com as partidas
+	refreshTokens        map[int64]RefreshToken
+	auditLogs            []AuditLog // Em ordem de ID
+	checkpoints          []AuditCheckpoint
+	loginAttempts        []LoginAttempt
+	chainHead            AuditChainHead
+}
+
+// NewMemoryDB cria um banco de dados em memória vazio
+func NewMemoryDB() *MemoryDB {
+	return &MemoryDB{memoryTables: memoryTables{
+		seq:                  make(map[string]int64),
+		usuarios:             make(map[int64]Usuario),
+		tiposPerfil:          make(map[int64]TipoPerfil),
+		permissoes:           make(map[int64]Permissao),
+		tipoPerfilPermissoes: make(map[int64]map[int64]bool),
+		seguradoras:          make(map[int64]Seguradora),
+		eventos:              make(map[int64]Evento),
+		objetos:              make(map[int64]ObjetoContabilizacao),
+		relacoes:             make(map[int64]ObjetoContabilizacaoEvento),
+		sistemas:             make(map[int64]SistemaContabil),
+		configs:              make(map[int64]SistemaContabilConfig),
+		contas:               make(map[int64]ContaContabil),
+		lotes:                make(map[int64]LoteLancamento),
+		lancamentos:          make(map[int64]Lancamento),
+		refreshTokens:        make(map[int64]RefreshToken),
+	}}
+}
+
+// clone copia as tabelas, para que possam ser restauradas ao desfazer uma unidade de trabalho
+func (t *memoryTables) clone() memoryTables {
+	c := *t
+	c.seq = maps.Clone(t.seq)
+	c.usuarios = maps.Clone(t.usuarios)
+	c.tiposPerfil = maps.Clone(t.tiposPerfil)
+	c.permissoes = maps.Clone(t.permissoes)
+	c.tipoPerfilPermissoes = make(map[int64]map[int64]bool, len(t.tipoPerfilPermissoes))
+	for id, concedidas := range t.tipoPerfilPermissoes {
+		c.tipoPerfilPermissoes[id] = maps.Clone(concedidas)
+	}
+	c.seguradoras = maps.Clone(t.seguradoras)
+	c.eventos = maps.Clone(t.eventos)
+	c.objetos = maps.Clone(t.objetos)
+	c.relacoes = maps.Clone(t.relacoes)
+	c.sistemas = maps.Clone(t.sistemas)
+	c.configs = maps.Clone(t.configs)
+	c.contas = maps.Clone(t.contas)
+	c.lotes = maps.Clone(t.lotes)
+	c.lancamentos = maps.Clone(t.lancamentos)
+	c.refreshTokens = maps.Clone(t.refreshTokens)
+	c.auditLogs = slices.Clone(t.auditLogs)
+	c.checkpoints = slices.Clone(t.checkpoints)
+	c.loginAttempts = slices.Clone(t.loginAttempts)
+	return c
+}
+
+// lock bloqueia o banco para uma operação e retorna a função que o libera. Dentro de uma unidade de
+// trabalho deste banco, que o mantém bloqueado até o final, não tem efeito.
+func (db *MemoryDB) lock(ctx context.Context) (unlock func()) {
+	if ctx.Value(txKey{}) == db {
+		return func() {}
+	}
+	
+	db.mu.Lock()
+	return db.mu.Unlock
+}
+
+// MemoryUnitOfWork executa unidades de trabalho sobre o banco de dados em memória
+type MemoryUnitOfWork struct {
+	db *MemoryDB
+}
+
+// NewMemoryUnitOfWork cria as unidades de trabalho sobre o banco de dados em memória
+func NewMemoryUnitOfWork(db *MemoryDB) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{db: db}
+}
+
+// Do executa fn com o banco bloqueado, restaurando as tabelas em caso de erro ou pânico, como a
+// transação de SQLUnitOfWork. Dentro de outra unidade de trabalho, fn participa da existente.
+func (u *MemoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if InTransaction(ctx) {
+		return fn(ctx)
+	}
+	
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+	
+	copia := u.db.memoryTables.clone()
+	defer func() {
+		if p := recover(); p != nil {
+			u.db.memoryTables = copia
+			panic(p)
+		}
+		if err != nil {
+			u.db.memoryTables = copia
+		}
+	}()
+	
+	return fn(context.WithValue(ctx, txKey{}, u.db))
+}
+
+// nextID gera o próximo ID da tabela informada (IDs descartados não são reutilizados, como no AUTO_INCREMENT)
+func (db *MemoryDB) nextID(table string) int64 {
+	db.seq[table]++
+	return db.seq[table]
+}
+
+// memoryNow retorna a data atual com a precisão das colunas de data do banco (segundos)
+func memoryNow() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// conflictError retorna o erro de violação da chave única informada, como classificado a partir do banco
+func conflictError(key string) error {
+	return ConflictError{Message: fmt.Sprintf("já existe um registro com os mesmos dados (%s)", key), Key: key}
+}
+
+// foreignKeyError retorna o erro de referência a um registro inexistente, como classificado a partir do banco
+func foreignKeyError(field string) error {
+	return ForeignKeyError{Message: fmt.Sprintf("registro relacionado não encontrado (%s)", field), Field: field}
+}
+
+// change registra a alteração de um registro no histórico, aplicando-a com apply somente se a
+// entrada do histórico puder ser montada (como na transação que grava o registro e o histórico)
+func (db *MemoryDB) change(actor *Actor, action, entityType string, entityID int64, before, after interface{}, apply func()) error {
+	entry, err := changeEntry(actor, action, entityType, entityID, before, after)
+	if err != nil {
+		return err
+	}
+	
+	apply()
+	if entry != nil {
+		db.appendAuditLogs([]*AuditLog{entry})
+	}
+	return nil
+}
+
+// appendAuditLogs grava as entradas no log de auditoria, encadeadas na ordem informada, preenchendo
+// os IDs, as datas e os hashes. Os textos são truncados ao tamanho das colunas do banco.
+func (db *MemoryDB) appendAuditLogs(entries []*AuditLog) {
+	now := memoryNow()
+	for _, entry := range entries {
+		gravado := *entry
+		gravado.ID = db.nextID("audit_log")
+		if gravado.UserID != nil && *gravado.UserID <= 0 {
+			gravado.UserID = nil
+		}
+		gravado.Username = truncate(gravado.Username, 50)
+		gravado.Action = truncate(gravado.Action, 100)
+		gravado.EntityType = truncate(gravado.EntityType, 50)
+		gravado.EntityID = truncate(gravado.EntityID, 50)
+		gravado.IPAddress = truncate(gravado.IPAddress, 45)
+		gravado.RequestID = truncate(gravado.RequestID, 64)
+		if gravado.CreatedAt.IsZero() {
+			gravado.CreatedAt = now
+		}
+		gravado.CreatedAt = gravado.CreatedAt.Truncate(time.Second)
+		gravado.PrevHash = db.chainHead.LastHash
+		gravado.Hash = auditEntryHash(&gravado)
+		
+		db.auditLogs = append(db.auditLogs, gravado)
+		db.chainHead = AuditChainHead{LastID: gravado.ID, LastHash: gravado.Hash}
+		*entry = gravado
+	}
+}
+
+// seguradoraDoSistema implementa contaLookup sobre os dados em memória (com o banco bloqueado)
+func (db *MemoryDB) seguradoraDoSistema(idSistemaContabil int64) (int64, error) {
+	sistema, ok := db.sistemas[idSistemaContabil]
+	if !ok {
+		return 0, sql.ErrNoRows
+	}
+	return sistema.IdSeguradora, nil
+}
+
+// conta implementa contaLookup sobre os dados em memória (com o banco bloqueado)
+func (db *MemoryDB) conta(idConta int64) (*ContaContabil, error) {
+	c, ok := db.contas[idConta]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &c, nil
+}
+
+// sistemaDaConfig implementa configLookup sobre os dados em memória (com o banco bloqueado)
+func (db *MemoryDB) sistemaDaConfig(idSistemaContabil int64) (referenciaConfig, error) {
+	sistema, ok := db.sistemas[idSistemaContabil]
+	if !ok {
+		return referenciaConfig{}, sql.ErrNoRows
+	}
+	return referenciaConfig{idSeguradora: sistema.IdSeguradora, ativo: sistema.Ativo}, nil
+}
+
+// objetoDaConfig implementa configLookup sobre os dados em memória (com o banco bloqueado)
+func (db *MemoryDB) objetoDaConfig(idObjetoContabilizacao int64) (referenciaConfig, error) {
+	objeto, ok := db.objetos[idObjetoContabilizacao]
+	if !ok {
+		return referenciaConfig{}, sql.ErrNoRows
+	}
+	return referenciaConfig{idSeguradora: objeto.IdSeguradora, ativo: objeto.Ativo}, nil
+}
+
+// eventoDaConfig implementa configLookup sobre os dados em memória (com o banco bloqueado)
+func (db *MemoryDB) eventoDaConfig(idCodigoEvento int64) (referenciaConfig, error) {
+	evento, ok := db.eventos[idCodigoEvento]
+	if !ok {
+		return referenciaConfig{}, sql.ErrNoRows
+	}
+	return referenciaConfig{idSeguradora: evento.IdSeguradora, ativo: evento.Ativo}, nil
+}
+
+// relacaoDaConfig implementa configLookup sobre os dados em memória (com o banco bloqueado)
+func (db *MemoryDB) relacaoDaConfig(idObjetoContabilizacao, idCodigoEvento int64) (referenciaConfig, error) {
+	encontrada := false
+	var ref referenciaConfig
+	for _, relacao := range db.relacoes {
+		if relacao.IdObjetoContabilizacao != idObjetoContabilizacao || relacao.IdCodigoEvento != idCodigoEvento {
+			continue
+		}
+		if !encontrada || relacao.Ativo {
+			ref = referenciaConfig{idSeguradora: relacao.IdSeguradora, ativo: relacao.Ativo}
+			encontrada = true
+		}
+	}
+	if !encontrada {
+		return referenciaConfig{}, sql.ErrNoRows
+	}
+	return ref, nil
+}
+
+// memoryRow associa um registro aos valores dos seus campos de listagem
+type memoryRow[T any] struct {
+	item   T
+	values map[string]interface{}
+}
+
+// memoryList aplica os filtros e a ordenação de uma listagem aos registros em memória
+func memoryList[T any](spec *ListSpec, opts ListOptions, items []T) ([]memoryRow[T], []SortField, error) {
+	sortFields, err := spec.sortFields(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	// Validar os filtros e converter os valores
+	filterValues := make([]interface{}, len(opts.Filters))
+	for i, f := range opts.Filters {
+		_, value, err := spec.checkFilter(f)
+		if err != nil {
+			return nil, nil, err
+		}
+		filterValues[i] = value
+	}
+	
+	var rows []memoryRow[T]
+	for _, item := range items {
+		values, err := spec.listValues(item)
+		if err != nil {
+			return nil, nil, err
+		}
+		
+		match := true
+		for i, f := range opts.Filters {
+			if !matchListFilter(f, values[f.Field], filterValues[i]) {
+				match = false
+				break
+			}
+		}
+		if match {
+			rows = append(rows, memoryRow[T]{item: item, values: values})
+		}
+	}
+	
+	sort.SliceStable(rows, func(i, j int) bool {
+		return compareListRows(sortFields, rows[i].values, func(field string) interface{} { return rows[j].values[field] }) < 0
+	})
+	
+	return rows, sortFields, nil
+}
+
+// memoryPage retorna uma página dos registros em memória, com a mesma semântica de listPage
+func memoryPage[T any](spec *ListSpec, opts ListOptions, items []T) (*Page[T], error) {
+	if opts.PageSize <= 0 {
+		opts.PageSize = DefaultPageSize
+	}
+	if opts.Page <= 0 {
+		opts.Page = 1
+	}
+	
+	rows, sortFields, err := memoryList(spec, opts, items)
+	if err != nil {
+		return nil, err
+	}
+	total := int64(len(rows))
+	
+	// Aplicar o cursor ou o deslocamento da página
+	if opts.After != "" {
+		cursor, err := spec.decodeCursor(opts.After, sortFields)
+		if err != nil {
+			return nil, err
+		}
+		inicio := len(rows)
+		for i, row := range rows {
+			if compareListRows(sortFields, row.values, func(field string) interface{} { return cursorValue(sortFields, cursor, field) }) > 0 {
+				inicio = i
+				break
+			}
+		}
+		rows = rows[inicio:]
+	} else {
+		offset := (opts.Page - 1) * opts.PageSize
+		if offset > len(rows) {
+			offset = len(rows)
+		}
+		rows = rows[offset:]
+	}
+	
+	// Um registro a mais indica a existência da próxima página
+	if len(rows) > opts.PageSize+1 {
+		rows = rows[:opts.PageSize+1]
+	}
+	pageItems := make([]T, len(rows))
+	for i, row := range rows {
+		pageItems[i] = row.item
+	}
+	
+	return newPage(spec, opts, sortFields, pageItems, total)
+}
+
+// memoryStream chama fn para cada registro em memória que atende aos filtros, na ordem solicitada,
+// com a mesma semântica de streamList
+func memoryStream[T any](spec *ListSpec, opts ListOptions, items []T, fn func(T) error) error {
+	rows, _, err := memoryList(spec, opts, items)
+	if err != nil {
+		return err
+	}
+	
+	for _, row := range rows {
+		if err := fn(row.item); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// listValues converte os campos JSON de um registro nos valores dos campos de listagem. Campos
+// omitidos valem zero (como as colunas com COALESCE) e campos nulos não atendem a nenhum filtro.
+func (s *ListSpec) listValues(item interface{}) (map[string]interface{}, error) {
+	fields, err := toFieldMap(item)
+	if err != nil {
+		return nil, err
+	}
+	
+	values := make(map[string]interface{}, len(s.Fields))
+	for name, field := range s.Fields {
+		raw, ok := fields[name]
+		switch {
+		case !ok && field.Type == FieldInt:
+			values[name] = int64(0)
+		case !ok && field.Type == FieldString:
+			values[name] = ""
+		case !ok && field.Type == FieldBool:
+			values[name] = false
+		case raw == nil:
+			values[name] = nil
+		case field.Type == FieldInt:
+			n, _ := raw.(float64)
+			values[name] = int64(n)
+		case field.Type == FieldTime:
+			text, _ := raw.(string)
+			t, err := time.Parse(time.RFC3339Nano, text)
+			if err != nil {
+				return nil, fmt.Errorf("erro ao ler data do campo %s: %w", name, err)
+			}
+			values[name] = t
+		default:
+			values[name] = raw
+		}
+	}
+	
+	return values, nil
+}
+
+// cursorValue retorna o valor do cursor correspondente ao campo de ordenação informado
+func cursorValue(sortFields []SortField, cursor []interface{}, field string) interface{} {
+	for i, sf := range sortFields {
+		if sf.Field == field {
+			return cursor[i]
+		}
+	}
+	return nil
+}
+
+// compareListRows compara os valores de dois registros pelos campos de ordenação, respeitando o
+// sentido de cada campo
+func compareListRows(sortFields []SortField, a map[string]interface{}, b func(field string) interface{}) int {
+	for _, sf := range sortFields {
+		c := compareListValues(a[sf.Field], b(sf.Field))
+		if sf.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareListValues compara dois valores de um campo de listagem. Valores nulos precedem os demais
+// e textos são comparados sem diferenciar maiúsculas de minúsculas, como no MySQL.
+func compareListValues(a, b interface{}) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	
+	switch va := a.(type) {
+	case int64:
+		vb, _ := b.(int64)
+		switch {
+		case va < vb:
+			return -1
+		case va > vb:
+			return 1
+		}
+	case string:
+		vb, _ := b.(string)
+		return strings.Compare(strings.ToLower(va), strings.ToLower(vb))
+	case bool:
+		vb, _ := b.(bool)
+		switch {
+		case !va && vb:
+			return -1
+		case va && !vb:
+			return 1
+		}
+	case time.Time:
+		vb, _ := b.(time.Time)
+		return va.Compare(vb)
+	}
+	return 0
+}
+
+// matchListFilter verifica se o valor de um campo atende ao filtro (já validado e convertido)
+func matchListFilter(f Filter, value, filterValue interface{}) bool {
+	if value == nil {
+		return false
+	}
+	
+	switch f.Operator {
+	case FilterContains:
+		text, _ := value.(string)
+		return strings.Contains(strings.ToLower(text), strings.ToLower(f.Value))
+	case FilterNotEqual:
+		return compareListValues(value, filterValue) != 0
+	case FilterGreaterOrEq:
+		return compareListValues(value, filterValue) >= 0
+	case FilterLessOrEq:
+		return compareListValues(value, filterValue) <= 0
+	default:
+		return compareListValues(value, filterValue) == 0
+	}
+}
+
+// mapValues retorna os registros de um mapa, para as listagens em memória
+func mapValues[T any](m map[int64]T, keep func(T) bool) []T {
+	items := make([]T, 0, len(m))
+	for _, item := range m {
+		if keep == nil || keep(item) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// memoryCrossTenant trata o registro não encontrado no escopo em uma alteração ou exclusão: se ele
+// existir (em outra seguradora), retorna ErrCrossTenant; caso contrário, o erro original
+func memoryCrossTenant[T any](m map[int64]T, id int64, err error) error {
+	if _, ok := m[id]; ok && errors.Is(err, ErrNotFound) {
+		return ErrCrossTenant
+	}
+	return err
+}
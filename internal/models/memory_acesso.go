@@ -0,0 +1,1015 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MemoryUsuarioStore implementa UsuarioStore sobre o MemoryDB
+type MemoryUsuarioStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemoryUsuarioStore cria um armazenamento de usuários em memória
+func NewMemoryUsuarioStore(db *MemoryDB) *MemoryUsuarioStore {
+	return &MemoryUsuarioStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemoryUsuarioStore) WithTenant(scope TenantScope) UsuarioStore {
+	return &MemoryUsuarioStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryUsuarioStore) WithActor(actor Actor) UsuarioStore {
+	return &MemoryUsuarioStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// checkTenant verifica se o usuário pode ser gravado dentro do escopo do armazenamento
+func (s *MemoryUsuarioStore) checkTenant(usuario *Usuario) error {
+	if !s.scope.Allows(int64(usuario.IdSeguradora)) {
+		return ErrCrossTenant
+	}
+	if usuario.AdminERP && s.scope != nil && !s.scope.AdminERP {
+		return ErrCrossTenant
+	}
+	return nil
+}
+
+// checkKeys verifica as chaves únicas e estrangeiras do usuário
+func (s *MemoryUsuarioStore) checkKeys(usuario *Usuario) error {
+	for _, u := range s.db.usuarios {
+		if u.ID == usuario.ID {
+			continue
+		}
+		if strings.EqualFold(u.Email, usuario.Email) {
+			return conflictError("usuarios.email")
+		}
+		if strings.EqualFold(u.Login, usuario.Login) {
+			return conflictError("usuarios.login")
+		}
+	}
+	if _, ok := s.db.tiposPerfil[int64(usuario.IdTipoPerfil)]; !ok {
+		return foreignKeyError("idTipoPerfil")
+	}
+	if _, ok := s.db.seguradoras[int64(usuario.IdSeguradora)]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	return nil
+}
+
+// get busca o usuário visível no escopo, sem o hash da senha
+func (s *MemoryUsuarioStore) get(id int64) (*Usuario, error) {
+	u, ok := s.db.usuarios[id]
+	if !ok || !s.scope.Allows(int64(u.IdSeguradora)) {
+		return nil, NotFoundError{Message: "usuário não encontrado"}
+	}
+	u.Senha = ""
+	return &u, nil
+}
+
+// Create insere um novo usuário
+func (s *MemoryUsuarioStore) Create(ctx context.Context, usuario *Usuario) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateUsuario(usuario); err != nil {
+		return err
+	}
+	if err := s.checkTenant(usuario); err != nil {
+		return err
+	}
+	
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(usuario.Senha), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if err := s.checkKeys(usuario); err != nil {
+		return err
+	}
+	
+	gravado := *usuario
+	gravado.Senha = string(hashedPassword)
+	gravado.BloqueadoAte = nil
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	usuario.ID = s.db.nextID("usuarios")
+	gravado.ID = usuario.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeUsuario, usuario.ID, nil, usuario, func() {
+		s.db.usuarios[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna os usuários com paginação, ordenação e filtros
+func (s *MemoryUsuarioStore) GetAll(ctx context.Context, opts ListOptions) (*Page[Usuario], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	usuarios := mapValues(s.db.usuarios, func(u Usuario) bool { return s.scope.Allows(int64(u.IdSeguradora)) })
+	unlock()
+	
+	for i := range usuarios {
+		usuarios[i].Senha = ""
+	}
+	
+	return memoryPage(&usuarioListSpec, opts, usuarios)
+}
+
+// GetByID busca um usuário pelo ID
+func (s *MemoryUsuarioStore) GetByID(ctx context.Context, id int64) (*Usuario, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetByLogin busca um usuário pelo login, com o hash da senha
+func (s *MemoryUsuarioStore) GetByLogin(ctx context.Context, login string) (*Usuario, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	for _, u := range s.db.usuarios {
+		if strings.EqualFold(u.Login, login) {
+			return &u, nil
+		}
+	}
+	return nil, NotFoundError{Message: "usuário não encontrado"}
+}
+
+// Update atualiza os dados de um usuário existente, revogando as sessões se ele ficar inativo ou bloqueado
+func (s *MemoryUsuarioStore) Update(ctx context.Context, usuario *Usuario) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateUsuarioUpdate(usuario); err != nil {
+		return err
+	}
+	if err := s.checkTenant(usuario); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(usuario.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.usuarios, usuario.ID, err)
+	}
+	if err := s.checkKeys(usuario); err != nil {
+		return err
+	}
+	
+	gravado := s.db.usuarios[usuario.ID]
+	gravado.Nome = usuario.Nome
+	gravado.Email = usuario.Email
+	gravado.Login = usuario.Login
+	gravado.IdTipoPerfil = usuario.IdTipoPerfil
+	gravado.IdSeguradora = usuario.IdSeguradora
+	gravado.AdminERP = usuario.AdminERP
+	gravado.Bloqueado = usuario.Bloqueado
+	gravado.Ativo = usuario.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	atual := *usuario
+	atual.Senha = ""
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeUsuario, usuario.ID, anterior, &atual, func() {
+		s.db.usuarios[gravado.ID] = gravado
+		s.db.revokeSessoesUsuarioInativo(gravado.ID)
+	})
+}
+
+// UpdatePassword atualiza apenas a senha do usuário
+func (s *MemoryUsuarioStore) UpdatePassword(ctx context.Context, id int64, novaSenha string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := utils.ValidatePassword(novaSenha); err != nil {
+		return err
+	}
+	
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(novaSenha), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	u, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.usuarios, id, err)
+	}
+	
+	anterior := map[string]string{"senha": ""}
+	atual := map[string]string{"senha": string(hashedPassword)}
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeUsuario, id, anterior, atual, func() {
+		u.Senha = string(hashedPassword)
+		u.UpdatedAt = memoryNow()
+		s.db.usuarios[id] = *u
+	})
+}
+
+// Delete desativa um usuário (exclusão lógica) e revoga as suas sessões
+func (s *MemoryUsuarioStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.usuarios, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeUsuario, id, anterior, &excluido, func() {
+		u := s.db.usuarios[id]
+		u.Ativo = false
+		u.UpdatedAt = memoryNow()
+		s.db.usuarios[id] = u
+		s.db.revokeSessoesUsuarioInativo(id)
+	})
+}
+
+// VerifyPassword verifica se a senha fornecida corresponde à senha armazenada
+func (s *MemoryUsuarioStore) VerifyPassword(ctx context.Context, login, senha string) (*Usuario, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	usuario, err := s.GetByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	
+	if !usuario.Ativo {
+		return nil, fmt.Errorf("usuário inativo")
+	}
+	
+	if usuario.Bloqueado {
+		if usuario.BloqueadoAte != nil && usuario.BloqueadoAte.After(time.Now()) {
+			return nil, fmt.Errorf("usuário bloqueado temporariamente")
+		}
+		
+		// O tempo de bloqueio já passou: desbloquear o usuário
+		usuario.Bloqueado = false
+		usuario.BloqueadoAte = nil
+		unlock := s.db.lock(ctx)
+		s.db.unlockUsuario(usuario.ID)
+		unlock()
+	}
+	
+	if err := bcrypt.CompareHashAndPassword([]byte(usuario.Senha), []byte(senha)); err != nil {
+		return nil, fmt.Errorf("senha incorreta")
+	}
+	
+	usuario.Senha = ""
+	
+	return usuario, nil
+}
+
+// unlockUsuario remove o bloqueio temporário do usuário (com o banco bloqueado)
+func (db *MemoryDB) unlockUsuario(id int64) {
+	if u, ok := db.usuarios[id]; ok {
+		u.Bloqueado = false
+		u.BloqueadoAte = nil
+		u.UpdatedAt = memoryNow()
+		db.usuarios[id] = u
+	}
+}
+
+// MemoryTipoPerfilStore implementa TipoPerfilStore sobre o MemoryDB
+type MemoryTipoPerfilStore struct {
+	db    *MemoryDB
+	actor *Actor
+}
+
+// NewMemoryTipoPerfilStore cria um armazenamento de tipos de perfil em memória
+func NewMemoryTipoPerfilStore(db *MemoryDB) *MemoryTipoPerfilStore {
+	return &MemoryTipoPerfilStore{db: db}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryTipoPerfilStore) WithActor(actor Actor) TipoPerfilStore {
+	return &MemoryTipoPerfilStore{db: s.db, actor: &actor}
+}
+
+// get busca o tipo de perfil pelo ID
+func (s *MemoryTipoPerfilStore) get(id int64) (*TipoPerfil, error) {
+	tp, ok := s.db.tiposPerfil[id]
+	if !ok {
+		return nil, NotFoundError{Message: "tipo de perfil não encontrado"}
+	}
+	return &tp, nil
+}
+
+// Create insere um novo tipo de perfil
+func (s *MemoryTipoPerfilStore) Create(ctx context.Context, tipoPerfil *TipoPerfil) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateTipoPerfil(tipoPerfil); err != nil {
+		return err
+	}
+	tipoPerfil.Perfil = utils.SanitizeString(tipoPerfil.Perfil)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	gravado := *tipoPerfil
+	gravado.ID = s.db.nextID("tipo_perfil")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	tipoPerfil.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeTipoPerfil, gravado.ID, nil, tipoPerfil, func() {
+		s.db.tiposPerfil[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna os tipos de perfil com paginação, ordenação e filtros
+func (s *MemoryTipoPerfilStore) GetAll(ctx context.Context, opts ListOptions) (*Page[TipoPerfil], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	tiposPerfil := mapValues(s.db.tiposPerfil, nil)
+	unlock()
+	
+	return memoryPage(&tipoPerfilListSpec, opts, tiposPerfil)
+}
+
+// GetByID busca um tipo de perfil pelo ID
+func (s *MemoryTipoPerfilStore) GetByID(ctx context.Context, id int64) (*TipoPerfil, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// Update atualiza os dados de um tipo de perfil existente
+func (s *MemoryTipoPerfilStore) Update(ctx context.Context, tipoPerfil *TipoPerfil) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateTipoPerfil(tipoPerfil); err != nil {
+		return err
+	}
+	tipoPerfil.Perfil = utils.SanitizeString(tipoPerfil.Perfil)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(tipoPerfil.ID)
+	if err != nil {
+		return err
+	}
+	
+	gravado := *anterior
+	gravado.Perfil = tipoPerfil.Perfil
+	gravado.Ativo = tipoPerfil.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeTipoPerfil, tipoPerfil.ID, anterior, tipoPerfil, func() {
+		s.db.tiposPerfil[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa um tipo de perfil (exclusão lógica)
+func (s *MemoryTipoPerfilStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	excluido.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeTipoPerfil, id, anterior, &excluido, func() {
+		s.db.tiposPerfil[id] = excluido
+	})
+}
+
+// MemoryPermissaoStore implementa PermissaoStore sobre o MemoryDB
+type MemoryPermissaoStore struct {
+	db    *MemoryDB
+	actor *Actor
+}
+
+// NewMemoryPermissaoStore cria um armazenamento de permissões em memória
+func NewMemoryPermissaoStore(db *MemoryDB) *MemoryPermissaoStore {
+	return &MemoryPermissaoStore{db: db}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryPermissaoStore) WithActor(actor Actor) PermissaoStore {
+	return &MemoryPermissaoStore{db: s.db, actor: &actor}
+}
+
+// get busca a permissão pelo ID
+func (s *MemoryPermissaoStore) get(id int64) (*Permissao, error) {
+	p, ok := s.db.permissoes[id]
+	if !ok {
+		return nil, NotFoundError{Message: "permissão não encontrada"}
+	}
+	return &p, nil
+}
+
+// byNome busca a permissão pelo nome
+func (s *MemoryPermissaoStore) byNome(nome string) (Permissao, bool) {
+	for _, p := range s.db.permissoes {
+		if strings.EqualFold(p.Nome, nome) {
+			return p, true
+		}
+	}
+	return Permissao{}, false
+}
+
+// Create insere uma nova permissão
+func (s *MemoryPermissaoStore) Create(ctx context.Context, permissao *Permissao) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validatePermissao(permissao); err != nil {
+		return err
+	}
+	permissao.Descricao = utils.SanitizeString(permissao.Descricao)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if _, ok := s.byNome(permissao.Nome); ok {
+		return conflictError("permissoes.nome")
+	}
+	
+	gravado := *permissao
+	gravado.ID = s.db.nextID("permissoes")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	permissao.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadePermissao, gravado.ID, nil, permissao, func() {
+		s.db.permissoes[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna as permissões com paginação, ordenação e filtros (por padrão, ordenadas pelo nome)
+func (s *MemoryPermissaoStore) GetAll(ctx context.Context, opts ListOptions) (*Page[Permissao], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	permissoes := mapValues(s.db.permissoes, nil)
+	unlock()
+	
+	if len(opts.Sort) == 0 {
+		opts.Sort = []SortField{{Field: "nome"}}
+	}
+	
+	return memoryPage(&permissaoListSpec, opts, permissoes)
+}
+
+// GetByID busca uma permissão pelo ID
+func (s *MemoryPermissaoStore) GetByID(ctx context.Context, id int64) (*Permissao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetByTipoPerfil retorna as permissões associadas a um tipo de perfil, ordenadas pelo nome
+func (s *MemoryPermissaoStore) GetByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]Permissao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	var permissoes []Permissao
+	for idPermissao := range s.db.tipoPerfilPermissoes[idTipoPerfil] {
+		permissoes = append(permissoes, s.db.permissoes[idPermissao])
+	}
+	sort.Slice(permissoes, func(i, j int) bool { return permissoes[i].Nome < permissoes[j].Nome })
+	
+	return permissoes, nil
+}
+
+// GetNomesByTipoPerfil retorna os nomes das permissões ativas de um tipo de perfil ativo
+func (s *MemoryPermissaoStore) GetNomesByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if tp, ok := s.db.tiposPerfil[idTipoPerfil]; !ok || !tp.Ativo {
+		return nil, nil
+	}
+	
+	var nomes []string
+	for idPermissao := range s.db.tipoPerfilPermissoes[idTipoPerfil] {
+		if p := s.db.permissoes[idPermissao]; p.Ativo {
+			nomes = append(nomes, p.Nome)
+		}
+	}
+	
+	return nomes, nil
+}
+
+// Update atualiza os dados de uma permissão existente
+func (s *MemoryPermissaoStore) Update(ctx context.Context, permissao *Permissao) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validatePermissao(permissao); err != nil {
+		return err
+	}
+	permissao.Descricao = utils.SanitizeString(permissao.Descricao)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(permissao.ID)
+	if err != nil {
+		return err
+	}
+	if p, ok := s.byNome(permissao.Nome); ok && p.ID != permissao.ID {
+		return conflictError("permissoes.nome")
+	}
+	
+	gravado := *anterior
+	gravado.Nome = permissao.Nome
+	gravado.Descricao = permissao.Descricao
+	gravado.Ativo = permissao.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadePermissao, permissao.ID, anterior, permissao, func() {
+		s.db.permissoes[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa uma permissão (exclusão lógica)
+func (s *MemoryPermissaoStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	excluido.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadePermissao, id, anterior, &excluido, func() {
+		s.db.permissoes[id] = excluido
+	})
+}
+
+// Grant concede uma permissão (pelo nome) a um tipo de perfil
+func (s *MemoryPermissaoStore) Grant(ctx context.Context, idTipoPerfil int64, nome string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	p, ok := s.byNome(nome)
+	if !ok {
+		return utils.ValidationError{Field: "permissao", Message: "permissão não encontrada: " + nome}
+	}
+	
+	// Como no INSERT ... SELECT, nada é concedido a um tipo de perfil inexistente
+	if _, ok := s.db.tiposPerfil[idTipoPerfil]; ok {
+		s.db.grant(idTipoPerfil, p.ID)
+	}
+	
+	return nil
+}
+
+// Revoke remove uma permissão (pelo nome) de um tipo de perfil
+func (s *MemoryPermissaoStore) Revoke(ctx context.Context, idTipoPerfil int64, nome string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if p, ok := s.byNome(nome); ok {
+		delete(s.db.tipoPerfilPermissoes[idTipoPerfil], p.ID)
+	}
+	
+	return nil
+}
+
+// ReplaceForTipoPerfil substitui todas as permissões de um tipo de perfil pelas informadas
+func (s *MemoryPermissaoStore) ReplaceForTipoPerfil(ctx context.Context, idTipoPerfil int64, nomes []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	// Validar todas as permissões antes de alterar as concedidas
+	ids := make([]int64, 0, len(nomes))
+	for _, nome := range nomes {
+		p, ok := s.byNome(nome)
+		if !ok {
+			return utils.ValidationError{Field: "permissoes", Message: "permissão não encontrada: " + nome}
+		}
+		ids = append(ids, p.ID)
+	}
+	if _, ok := s.db.tiposPerfil[idTipoPerfil]; !ok && len(ids) > 0 {
+		return foreignKeyError("id_tipo_perfil")
+	}
+	
+	delete(s.db.tipoPerfilPermissoes, idTipoPerfil)
+	for _, id := range ids {
+		s.db.grant(idTipoPerfil, id)
+	}
+	
+	return nil
+}
+
+// grant concede a permissão ao tipo de perfil (com o banco bloqueado)
+func (db *MemoryDB) grant(idTipoPerfil, idPermissao int64) {
+	if db.tipoPerfilPermissoes[idTipoPerfil] == nil {
+		db.tipoPerfilPermissoes[idTipoPerfil] = make(map[int64]bool)
+	}
+	db.tipoPerfilPermissoes[idTipoPerfil][idPermissao] = true
+}
+
+// MemoryRefreshTokenStore implementa RefreshTokenStore sobre o MemoryDB
+type MemoryRefreshTokenStore struct {
+	db *MemoryDB
+}
+
+// NewMemoryRefreshTokenStore cria um armazenamento de refresh tokens em memória
+func NewMemoryRefreshTokenStore(db *MemoryDB) *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{db: db}
+}
+
+// Create persiste um refresh token recém-emitido
+func (s *MemoryRefreshTokenStore) Create(ctx context.Context, token *RefreshToken) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.db.insertRefreshToken(token)
+}
+
+// Rotate consome o refresh token informado (pelo jti) e persiste o novo token da mesma família,
+// emitido por emitir com os dados atuais do usuário. O reuso de um token já rotacionado revoga toda
+// a família e retorna ErrRefreshTokenReutilizado.
+func (s *MemoryRefreshTokenStore) Rotate(ctx context.Context, jti string, emitir func(usuario *Usuario) (*RefreshToken, error)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	var atual *RefreshToken
+	for _, t := range s.db.refreshTokens {
+		if t.JTI == jti {
+			atual = &t
+			break
+		}
+	}
+	if atual == nil {
+		return ErrRefreshTokenInvalido
+	}
+	usuario, ok := s.db.usuarios[atual.IdUsuario]
+	if !ok {
+		return ErrRefreshTokenInvalido
+	}
+	
+	// Tokens revogados ou expirados não podem ser renovados
+	if atual.RevokedAt != nil || !atual.ExpiresAt.After(time.Now()) {
+		return ErrRefreshTokenInvalido
+	}
+	
+	// Reuso de um token já rotacionado: revogar toda a família
+	if atual.RotatedAt != nil {
+		s.db.revokeTokens(func(t RefreshToken) bool { return t.Familia == atual.Familia }, MotivoRevogacaoReutilizacao)
+		return ErrRefreshTokenReutilizado
+	}
+	
+	// Usuários inativos ou bloqueados não renovam sessões
+	if !usuario.Ativo || usuario.Bloqueado {
+		s.db.revokeTokens(func(t RefreshToken) bool { return t.Familia == atual.Familia }, MotivoRevogacaoUsuario)
+		return ErrRefreshTokenInvalido
+	}
+	
+	// Emitir o novo token com os dados atuais do usuário
+	atualizado := usuario
+	atualizado.Senha = ""
+	novo, err := emitir(&atualizado)
+	if err != nil {
+		return err
+	}
+	novo.Familia = atual.Familia
+	novo.IdUsuario = atual.IdUsuario
+	if err := s.db.insertRefreshToken(novo); err != nil {
+		return err
+	}
+	
+	agora := memoryNow()
+	atual.RotatedAt = &agora
+	s.db.refreshTokens[atual.ID] = *atual
+	
+	return nil
+}
+
+// IsFamiliaAtiva verifica se a sessão ainda possui um refresh token não revogado e não expirado
+func (s *MemoryRefreshTokenStore) IsFamiliaAtiva(ctx context.Context, familia string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	agora := time.Now()
+	for _, t := range s.db.refreshTokens {
+		if t.Familia == familia && t.RevokedAt == nil && t.ExpiresAt.After(agora) {
+			return true, nil
+		}
+	}
+	
+	return false, nil
+}
+
+// GetAtivosByUsuario retorna o refresh token vigente de cada sessão ativa de um usuário
+func (s *MemoryRefreshTokenStore) GetAtivosByUsuario(ctx context.Context, idUsuario int64) ([]RefreshToken, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	agora := time.Now()
+	tokens := mapValues(s.db.refreshTokens, func(t RefreshToken) bool {
+		return t.IdUsuario == idUsuario && t.RotatedAt == nil && t.RevokedAt == nil && t.ExpiresAt.After(agora)
+	})
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID > tokens[j].ID
+	})
+	
+	return tokens, nil
+}
+
+// RevokeFamilia revoga todos os refresh tokens de uma sessão
+func (s *MemoryRefreshTokenStore) RevokeFamilia(ctx context.Context, familia, motivo string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	s.db.revokeTokens(func(t RefreshToken) bool { return t.Familia == familia }, motivo)
+	
+	return nil
+}
+
+// RevokeByUsuario revoga todas as sessões de um usuário e retorna quantas sessões ativas foram revogadas
+func (s *MemoryRefreshTokenStore) RevokeByUsuario(ctx context.Context, idUsuario int64, motivo string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	agora := time.Now()
+	familias := make(map[string]bool)
+	for _, t := range s.db.refreshTokens {
+		if t.IdUsuario == idUsuario && t.RevokedAt == nil && t.ExpiresAt.After(agora) {
+			familias[t.Familia] = true
+		}
+	}
+	
+	s.db.revokeTokens(func(t RefreshToken) bool { return t.IdUsuario == idUsuario }, motivo)
+	
+	return int64(len(familias)), nil
+}
+
+// insertRefreshToken grava um refresh token (com o banco bloqueado)
+func (db *MemoryDB) insertRefreshToken(token *RefreshToken) error {
+	for _, t := range db.refreshTokens {
+		if t.JTI == token.JTI {
+			return conflictError("refresh_tokens.jti")
+		}
+	}
+	if _, ok := db.usuarios[token.IdUsuario]; !ok {
+		return foreignKeyError("id_usuario")
+	}
+	
+	gravado := *token
+	gravado.ID = db.nextID("refresh_tokens")
+	gravado.RotatedAt = nil
+	gravado.RevokedAt = nil
+	gravado.MotivoRevogacao = ""
+	gravado.IPAddress = truncate(gravado.IPAddress, 45)
+	gravado.UserAgent = truncate(gravado.UserAgent, 255)
+	gravado.CreatedAt = memoryNow()
+	db.refreshTokens[gravado.ID] = gravado
+	
+	token.ID = gravado.ID
+	return nil
+}
+
+// revokeTokens revoga os refresh tokens ainda não revogados selecionados (com o banco bloqueado)
+func (db *MemoryDB) revokeTokens(selecionar func(RefreshToken) bool, motivo string) {
+	agora := memoryNow()
+	for id, t := range db.refreshTokens {
+		if t.RevokedAt == nil && selecionar(t) {
+			t.RevokedAt = &agora
+			t.MotivoRevogacao = motivo
+			db.refreshTokens[id] = t
+		}
+	}
+}
+
+// revokeSessoesUsuarioInativo revoga as sessões de um usuário caso ele esteja inativo ou bloqueado
+// (com o banco bloqueado)
+func (db *MemoryDB) revokeSessoesUsuarioInativo(idUsuario int64) {
+	if u, ok := db.usuarios[idUsuario]; ok && (!u.Ativo || u.Bloqueado) {
+		db.revokeTokens(func(t RefreshToken) bool { return t.IdUsuario == idUsuario }, MotivoRevogacaoUsuario)
+	}
+}
+
+// MemoryLoginAttemptStore implementa LoginAttemptStore sobre o MemoryDB
+type MemoryLoginAttemptStore struct {
+	db *MemoryDB
+}
+
+// NewMemoryLoginAttemptStore cria um armazenamento de tentativas de login em memória
+func NewMemoryLoginAttemptStore(db *MemoryDB) *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{db: db}
+}
+
+// Create registra uma tentativa de login
+func (s *MemoryLoginAttemptStore) Create(ctx context.Context, attempt *LoginAttempt) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	gravado := *attempt
+	gravado.ID = s.db.nextID("login_attempts")
+	gravado.AttemptTime = memoryNow()
+	s.db.loginAttempts = append(s.db.loginAttempts, gravado)
+	
+	return nil
+}
+
+// CountRecentFailures conta as tentativas de login malsucedidas do login ou do IP nos últimos minutos
+func (s *MemoryLoginAttemptStore) CountRecentFailures(ctx context.Context, login, ipAddress string, minutes int) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	inicio := time.Now().Add(-time.Duration(minutes) * time.Minute)
+	count := 0
+	for _, a := range s.db.loginAttempts {
+		if (a.Login == login || a.IPAddress == ipAddress) && !a.Success && a.AttemptTime.After(inicio) {
+			count++
+		}
+	}
+	
+	return count, nil
+}
+
+// LockAccount bloqueia a conta do login informado até o instante indicado e revoga as suas sessões
+func (s *MemoryLoginAttemptStore) LockAccount(ctx context.Context, login string, until time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	for id, u := range s.db.usuarios {
+		if strings.EqualFold(u.Login, login) {
+			u.Bloqueado = true
+			u.BloqueadoAte = &until
+			u.UpdatedAt = memoryNow()
+			s.db.usuarios[id] = u
+			s.db.revokeTokens(func(t RefreshToken) bool { return t.IdUsuario == id }, MotivoRevogacaoBloqueio)
+		}
+	}
+	
+	return nil
+}
+
+// GetAccountLock retorna o status de bloqueio da conta do login informado (não bloqueada se o login não existir)
+func (s *MemoryLoginAttemptStore) GetAccountLock(ctx context.Context, login string) (bool, time.Time, error) {
+	if err := ctx.Err(); err != nil {
+		return false, time.Time{}, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	for _, u := range s.db.usuarios {
+		if strings.EqualFold(u.Login, login) {
+			if u.BloqueadoAte == nil {
+				return u.Bloqueado, time.Time{}, nil
+			}
+			return u.Bloqueado, *u.BloqueadoAte, nil
+		}
+	}
+	
+	return false, time.Time{}, nil
+}
+
+// UnlockAccount desbloqueia a conta do login informado
+func (s *MemoryLoginAttemptStore) UnlockAccount(ctx context.Context, login string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	for id, u := range s.db.usuarios {
+		if strings.EqualFold(u.Login, login) {
+			s.db.unlockUsuario(id)
+		}
+	}
+	
+	return nil
+}
@@ -0,0 +1,212 @@
+package models
+
+import "context"
+
+// MemoryAuditLogStore implementa AuditLogStore sobre o MemoryDB
+type MemoryAuditLogStore struct {
+	db *MemoryDB
+}
+
+// NewMemoryAuditLogStore cria um armazenamento do log de auditoria em memória
+func NewMemoryAuditLogStore(db *MemoryDB) *MemoryAuditLogStore {
+	return &MemoryAuditLogStore{db: db}
+}
+
+// Create registra uma entrada no log de auditoria, encadeada à entrada anterior
+func (s *MemoryAuditLogStore) Create(ctx context.Context, entry *AuditLog) error {
+	return s.CreateBatch(ctx, []*AuditLog{entry})
+}
+
+// CreateBatch registra várias entradas no log de auditoria, encadeadas na ordem informada
+func (s *MemoryAuditLogStore) CreateBatch(ctx context.Context, entries []*AuditLog) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	s.db.appendAuditLogs(entries)
+	
+	return nil
+}
+
+// GetHistorico retorna o histórico de alterações de um registro, com paginação, ordenação e filtros
+func (s *MemoryAuditLogStore) GetHistorico(ctx context.Context, entityType string, entityID int64, opts ListOptions) (*Page[AuditLog], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	var logs []AuditLog
+	for _, l := range s.db.auditLogs {
+		if len(l.Changes) > 0 {
+			logs = append(logs, l)
+		}
+	}
+	unlock()
+	
+	opts = opts.WithFilter("entity_type", entityType).WithFilter("entity_id", entityID)
+	return memoryPage(&auditLogListSpec, opts, logs)
+}
+
+// GetAll retorna o log de auditoria com paginação, ordenação e filtros
+func (s *MemoryAuditLogStore) GetAll(ctx context.Context, opts ListOptions) (*Page[AuditLog], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	return memoryPage(&auditLogListSpec, opts, s.logs(ctx))
+}
+
+// Export percorre todas as entradas do log de auditoria que atendem aos filtros, na ordem solicitada
+func (s *MemoryAuditLogStore) Export(ctx context.Context, opts ListOptions, fn func(AuditLog) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	return memoryStream(&auditLogListSpec, opts, s.logs(ctx), fn)
+}
+
+// logs retorna uma cópia das entradas do log de auditoria
+func (s *MemoryAuditLogStore) logs(ctx context.Context) []AuditLog {
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return append([]AuditLog(nil), s.db.auditLogs...)
+}
+
+// GetLoginAttempts retorna as tentativas de login com paginação, ordenação e filtros
+func (s *MemoryAuditLogStore) GetLoginAttempts(ctx context.Context, opts ListOptions) (*Page[LoginAttempt], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	return memoryPage(&loginAttemptListSpec, opts, s.loginAttempts(ctx))
+}
+
+// ExportLoginAttempts percorre todas as tentativas de login que atendem aos filtros, na ordem solicitada
+func (s *MemoryAuditLogStore) ExportLoginAttempts(ctx context.Context, opts ListOptions, fn func(LoginAttempt) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	return memoryStream(&loginAttemptListSpec, opts, s.loginAttempts(ctx), fn)
+}
+
+// loginAttempts retorna uma cópia das tentativas de login
+func (s *MemoryAuditLogStore) loginAttempts(ctx context.Context) []LoginAttempt {
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return append([]LoginAttempt(nil), s.db.loginAttempts...)
+}
+
+// ChainHead retorna a última entrada encadeada do log de auditoria
+func (s *MemoryAuditLogStore) ChainHead(ctx context.Context) (*AuditChainHead, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	head := s.db.chainHead
+	return &head, nil
+}
+
+// CountChained retorna a quantidade de entradas encadeadas até o registro informado
+func (s *MemoryAuditLogStore) CountChained(ctx context.Context, lastID int64) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	var count int64
+	for _, l := range s.db.auditLogs {
+		if l.Hash != "" && l.ID <= lastID {
+			count++
+		}
+	}
+	
+	return count, nil
+}
+
+// CreateCheckpoint grava um ponto de verificação assinado
+func (s *MemoryAuditLogStore) CreateCheckpoint(ctx context.Context, cp *AuditCheckpoint) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	cp.ID = s.db.nextID("audit_checkpoints")
+	gravado := *cp
+	gravado.CreatedAt = memoryNow()
+	s.db.checkpoints = append(s.db.checkpoints, gravado)
+	
+	return nil
+}
+
+// GetLastCheckpoint retorna o ponto de verificação mais recente, ou nil se não houver nenhum
+func (s *MemoryAuditLogStore) GetLastCheckpoint(ctx context.Context) (*AuditCheckpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if len(s.db.checkpoints) == 0 {
+		return nil, nil
+	}
+	
+	cp := s.db.checkpoints[len(s.db.checkpoints)-1]
+	return &cp, nil
+}
+
+// GetCheckpoints retorna uma página dos pontos de verificação, aplicando filtros e ordenação
+func (s *MemoryAuditLogStore) GetCheckpoints(ctx context.Context, opts ListOptions) (*Page[AuditCheckpoint], error) {
+	checkpoints, _ := s.AllCheckpoints(ctx)
+	return memoryPage(&auditCheckpointListSpec, opts, checkpoints)
+}
+
+// AllCheckpoints retorna todos os pontos de verificação, do mais antigo para o mais recente
+func (s *MemoryAuditLogStore) AllCheckpoints(ctx context.Context) ([]AuditCheckpoint, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return append([]AuditCheckpoint(nil), s.db.checkpoints...), nil
+}
+
+// VerifyChain percorre a cadeia do log de auditoria, recalculando o hash de cada entrada, e
+// retorna o primeiro encadeamento quebrado
+func (s *MemoryAuditLogStore) VerifyChain(ctx context.Context, checkpoints []AuditCheckpoint) (*AuditChainVerification, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	head := s.db.chainHead
+	logs := append([]AuditLog(nil), s.db.auditLogs...)
+	unlock()
+	
+	verifier := newChainVerifier(checkpoints)
+	for i := range logs {
+		if logs[i].ID > head.LastID {
+			break
+		}
+		if !verifier.check(&logs[i]) {
+			return verifier.result, nil
+		}
+	}
+	
+	return verifier.finish(checkpoints, &head), nil
+}
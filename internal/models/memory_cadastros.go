@@ -0,0 +1,953 @@
+package models
+
+import (
+	"context"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// MemorySeguradoraStore implementa SeguradoraStore sobre o MemoryDB
+type MemorySeguradoraStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemorySeguradoraStore cria um armazenamento de seguradoras em memória
+func NewMemorySeguradoraStore(db *MemoryDB) *MemorySeguradoraStore {
+	return &MemorySeguradoraStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemorySeguradoraStore) WithTenant(scope TenantScope) SeguradoraStore {
+	return &MemorySeguradoraStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemorySeguradoraStore) WithActor(actor Actor) SeguradoraStore {
+	return &MemorySeguradoraStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// get busca a seguradora visível no escopo
+func (s *MemorySeguradoraStore) get(id int64) (*Seguradora, error) {
+	seguradora, ok := s.db.seguradoras[id]
+	if !ok || !s.scope.Allows(id) {
+		return nil, NotFoundError{Message: "seguradora não encontrada"}
+	}
+	return &seguradora, nil
+}
+
+// Create insere uma nova seguradora
+func (s *MemorySeguradoraStore) Create(ctx context.Context, seguradora *Seguradora) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateSeguradora(seguradora); err != nil {
+		return err
+	}
+	if s.scope != nil && !s.scope.Todas {
+		return ErrCrossTenant
+	}
+	seguradora.Nome = utils.SanitizeString(seguradora.Nome)
+	seguradora.NomeAbreviado = utils.SanitizeString(seguradora.NomeAbreviado)
+	seguradora.CodigoSusep = utils.SanitizeString(seguradora.CodigoSusep)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	gravado := *seguradora
+	gravado.ID = s.db.nextID("seguradoras")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	seguradora.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeSeguradora, gravado.ID, nil, seguradora, func() {
+		s.db.seguradoras[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna as seguradoras com paginação, ordenação e filtros
+func (s *MemorySeguradoraStore) GetAll(ctx context.Context, opts ListOptions) (*Page[Seguradora], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	seguradoras := mapValues(s.db.seguradoras, func(seg Seguradora) bool { return s.scope.Allows(seg.ID) })
+	unlock()
+	
+	return memoryPage(&seguradoraListSpec, opts, seguradoras)
+}
+
+// GetByID busca uma seguradora pelo ID
+func (s *MemorySeguradoraStore) GetByID(ctx context.Context, id int64) (*Seguradora, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// Update atualiza os dados de uma seguradora existente
+func (s *MemorySeguradoraStore) Update(ctx context.Context, seguradora *Seguradora) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateSeguradora(seguradora); err != nil {
+		return err
+	}
+	if !s.scope.Allows(seguradora.ID) {
+		return ErrCrossTenant
+	}
+	seguradora.Nome = utils.SanitizeString(seguradora.Nome)
+	seguradora.NomeAbreviado = utils.SanitizeString(seguradora.NomeAbreviado)
+	seguradora.CodigoSusep = utils.SanitizeString(seguradora.CodigoSusep)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(seguradora.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.seguradoras, seguradora.ID, err)
+	}
+	
+	gravado := *anterior
+	gravado.Nome = seguradora.Nome
+	gravado.NomeAbreviado = seguradora.NomeAbreviado
+	gravado.CodigoSusep = seguradora.CodigoSusep
+	gravado.Ativo = seguradora.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeSeguradora, seguradora.ID, anterior, seguradora, func() {
+		s.db.seguradoras[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa uma seguradora (exclusão lógica)
+func (s *MemorySeguradoraStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.seguradoras, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	excluido.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeSeguradora, id, anterior, &excluido, func() {
+		s.db.seguradoras[id] = excluido
+	})
+}
+
+// MemoryEventoStore implementa EventoStore sobre o MemoryDB
+type MemoryEventoStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemoryEventoStore cria um armazenamento de eventos em memória
+func NewMemoryEventoStore(db *MemoryDB) *MemoryEventoStore {
+	return &MemoryEventoStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemoryEventoStore) WithTenant(scope TenantScope) EventoStore {
+	return &MemoryEventoStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryEventoStore) WithActor(actor Actor) EventoStore {
+	return &MemoryEventoStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// get busca o evento visível no escopo
+func (s *MemoryEventoStore) get(id int64) (*Evento, error) {
+	evento, ok := s.db.eventos[id]
+	if !ok || !s.scope.Allows(evento.IdSeguradora) {
+		return nil, NotFoundError{Message: "evento não encontrado"}
+	}
+	return &evento, nil
+}
+
+// insert grava um novo evento e registra a criação no histórico (com o banco bloqueado)
+func (s *MemoryEventoStore) insert(evento *Evento) error {
+	if _, ok := s.db.seguradoras[evento.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	gravado := *evento
+	gravado.ID = s.db.nextID("eventos")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	evento.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeEvento, gravado.ID, nil, evento, func() {
+		s.db.eventos[gravado.ID] = gravado
+	})
+}
+
+// Create insere um novo evento
+func (s *MemoryEventoStore) Create(ctx context.Context, evento *Evento) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateEvento(evento); err != nil {
+		return err
+	}
+	if !s.scope.Allows(evento.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	evento.Descricao = utils.SanitizeString(evento.Descricao)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.insert(evento)
+}
+
+// GetAll retorna os eventos com paginação, ordenação e filtros
+func (s *MemoryEventoStore) GetAll(ctx context.Context, opts ListOptions) (*Page[Evento], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	eventos := mapValues(s.db.eventos, func(e Evento) bool { return s.scope.Allows(e.IdSeguradora) })
+	unlock()
+	
+	return memoryPage(&eventoListSpec, opts, eventos)
+}
+
+// GetByID busca um evento pelo ID
+func (s *MemoryEventoStore) GetByID(ctx context.Context, id int64) (*Evento, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetByNumero busca um evento pelo número dentro de uma seguradora (ativo ou não).
+// Retorna nil se o evento não existir.
+func (s *MemoryEventoStore) GetByNumero(ctx context.Context, idSeguradora int64, numero int) (*Evento, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	// Preferir o evento ativo e, entre eles, o mais recente
+	var encontrado *Evento
+	for _, e := range s.db.eventos {
+		if e.IdSeguradora != idSeguradora || e.Evento != numero {
+			continue
+		}
+		if encontrado == nil || (e.Ativo && !encontrado.Ativo) || (e.Ativo == encontrado.Ativo && e.ID > encontrado.ID) {
+			e := e
+			encontrado = &e
+		}
+	}
+	
+	return encontrado, nil
+}
+
+// GetBySeguradora busca eventos por seguradora, com paginação, ordenação e filtros
+func (s *MemoryEventoStore) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[Evento], error) {
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	return s.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
+}
+
+// Update atualiza os dados de um evento existente
+func (s *MemoryEventoStore) Update(ctx context.Context, evento *Evento) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateEvento(evento); err != nil {
+		return err
+	}
+	if !s.scope.Allows(evento.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	evento.Descricao = utils.SanitizeString(evento.Descricao)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(evento.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.eventos, evento.ID, err)
+	}
+	if _, ok := s.db.seguradoras[evento.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	gravado := *anterior
+	gravado.Evento = evento.Evento
+	gravado.Descricao = evento.Descricao
+	gravado.IdSeguradora = evento.IdSeguradora
+	gravado.Ativo = evento.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeEvento, evento.ID, anterior, evento, func() {
+		s.db.eventos[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa um evento (exclusão lógica)
+func (s *MemoryEventoStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.eventos, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	excluido.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeEvento, id, anterior, &excluido, func() {
+		s.db.eventos[id] = excluido
+	})
+}
+
+// Import cria em lote os eventos de uma seguradora lidos de um arquivo. Com qualquer erro, nenhum
+// evento é gravado. Na simulação, as linhas são apenas validadas.
+func (s *MemoryEventoStore) Import(ctx context.Context, idSeguradora int64, linhas []LinhaArquivo, simular bool) (*ResultadoImportacao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	existentes := make(map[int]bool)
+	for _, e := range s.db.eventos {
+		if e.IdSeguradora == idSeguradora && e.Ativo {
+			existentes[e.Evento] = true
+		}
+	}
+	
+	resultado := &ResultadoImportacao{Simulacao: simular, Linhas: len(linhas)}
+	eventos := validarImportacaoEventos(idSeguradora, linhas, existentes, resultado)
+	if len(resultado.Erros) > 0 || simular {
+		return resultado, nil
+	}
+	if _, ok := s.db.seguradoras[idSeguradora]; !ok && len(eventos) > 0 {
+		return nil, foreignKeyError("idSeguradora")
+	}
+	
+	for _, evento := range eventos {
+		evento.Descricao = utils.SanitizeString(evento.Descricao)
+		if err := s.insert(evento); err != nil {
+			return nil, err
+		}
+	}
+	
+	resultado.Criados = len(eventos)
+	return resultado, nil
+}
+
+// MemoryObjetoContabilizacaoStore implementa ObjetoContabilizacaoStore sobre o MemoryDB
+type MemoryObjetoContabilizacaoStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemoryObjetoContabilizacaoStore cria um armazenamento de objetos de contabilização em memória
+func NewMemoryObjetoContabilizacaoStore(db *MemoryDB) *MemoryObjetoContabilizacaoStore {
+	return &MemoryObjetoContabilizacaoStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemoryObjetoContabilizacaoStore) WithTenant(scope TenantScope) ObjetoContabilizacaoStore {
+	return &MemoryObjetoContabilizacaoStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryObjetoContabilizacaoStore) WithActor(actor Actor) ObjetoContabilizacaoStore {
+	return &MemoryObjetoContabilizacaoStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// get busca o objeto de contabilização visível no escopo
+func (s *MemoryObjetoContabilizacaoStore) get(id int64) (*ObjetoContabilizacao, error) {
+	objeto, ok := s.db.objetos[id]
+	if !ok || !s.scope.Allows(objeto.IdSeguradora) {
+		return nil, NotFoundError{Message: "objeto de contabilização não encontrado"}
+	}
+	return &objeto, nil
+}
+
+// insert grava um novo objeto de contabilização e registra a criação no histórico (com o banco bloqueado)
+func (s *MemoryObjetoContabilizacaoStore) insert(objeto *ObjetoContabilizacao) error {
+	if _, ok := s.db.seguradoras[objeto.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	gravado := *objeto
+	gravado.ID = s.db.nextID("objeto_contabilizacao")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	objeto.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeObjetoContabilizacao, gravado.ID, nil, objeto, func() {
+		s.db.objetos[gravado.ID] = gravado
+	})
+}
+
+// Create insere um novo objeto de contabilização
+func (s *MemoryObjetoContabilizacaoStore) Create(ctx context.Context, objeto *ObjetoContabilizacao) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateObjetoContabilizacao(objeto); err != nil {
+		return err
+	}
+	if !s.scope.Allows(objeto.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	objeto.ObjetoContabilizacao = utils.SanitizeString(objeto.ObjetoContabilizacao)
+	objeto.Descricao = utils.SanitizeString(objeto.Descricao)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.insert(objeto)
+}
+
+// GetAll retorna os objetos de contabilização com paginação, ordenação e filtros
+func (s *MemoryObjetoContabilizacaoStore) GetAll(ctx context.Context, opts ListOptions) (*Page[ObjetoContabilizacao], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	objetos := mapValues(s.db.objetos, func(o ObjetoContabilizacao) bool { return s.scope.Allows(o.IdSeguradora) })
+	unlock()
+	
+	return memoryPage(&objetoContabilizacaoListSpec, opts, objetos)
+}
+
+// GetByID busca um objeto de contabilização pelo ID
+func (s *MemoryObjetoContabilizacaoStore) GetByID(ctx context.Context, id int64) (*ObjetoContabilizacao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetByCodigo busca um objeto de contabilização pelo código dentro de uma seguradora (ativo ou não).
+// Retorna nil se o objeto não existir.
+func (s *MemoryObjetoContabilizacaoStore) GetByCodigo(ctx context.Context, idSeguradora int64, codigo string) (*ObjetoContabilizacao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	// Preferir o objeto ativo e, entre eles, o mais recente
+	var encontrado *ObjetoContabilizacao
+	for _, o := range s.db.objetos {
+		if o.IdSeguradora != idSeguradora || o.ObjetoContabilizacao != codigo {
+			continue
+		}
+		if encontrado == nil || (o.Ativo && !encontrado.Ativo) || (o.Ativo == encontrado.Ativo && o.ID > encontrado.ID) {
+			o := o
+			encontrado = &o
+		}
+	}
+	
+	return encontrado, nil
+}
+
+// GetBySeguradora busca objetos de contabilização por seguradora, com paginação, ordenação e filtros
+func (s *MemoryObjetoContabilizacaoStore) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[ObjetoContabilizacao], error) {
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	return s.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
+}
+
+// Update atualiza os dados de um objeto de contabilização existente
+func (s *MemoryObjetoContabilizacaoStore) Update(ctx context.Context, objeto *ObjetoContabilizacao) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateObjetoContabilizacao(objeto); err != nil {
+		return err
+	}
+	if !s.scope.Allows(objeto.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	objeto.ObjetoContabilizacao = utils.SanitizeString(objeto.ObjetoContabilizacao)
+	objeto.Descricao = utils.SanitizeString(objeto.Descricao)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(objeto.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.objetos, objeto.ID, err)
+	}
+	if _, ok := s.db.seguradoras[objeto.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	gravado := *anterior
+	gravado.ObjetoContabilizacao = objeto.ObjetoContabilizacao
+	gravado.Descricao = objeto.Descricao
+	gravado.IdSeguradora = objeto.IdSeguradora
+	gravado.Ativo = objeto.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeObjetoContabilizacao, objeto.ID, anterior, objeto, func() {
+		s.db.objetos[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa um objeto de contabilização (exclusão lógica)
+func (s *MemoryObjetoContabilizacaoStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.objetos, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	excluido.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeObjetoContabilizacao, id, anterior, &excluido, func() {
+		s.db.objetos[id] = excluido
+	})
+}
+
+// Import cria em lote os objetos de contabilização de uma seguradora lidos de um arquivo. Com qualquer
+// erro, nenhum objeto é gravado. Na simulação, as linhas são apenas validadas.
+func (s *MemoryObjetoContabilizacaoStore) Import(ctx context.Context, idSeguradora int64, linhas []LinhaArquivo, simular bool) (*ResultadoImportacao, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	existentes := make(map[string]bool)
+	for _, o := range s.db.objetos {
+		if o.IdSeguradora == idSeguradora && o.Ativo {
+			existentes[o.ObjetoContabilizacao] = true
+		}
+	}
+	
+	resultado := &ResultadoImportacao{Simulacao: simular, Linhas: len(linhas)}
+	objetos := validarImportacaoObjetos(idSeguradora, linhas, existentes, resultado)
+	if len(resultado.Erros) > 0 || simular {
+		return resultado, nil
+	}
+	if _, ok := s.db.seguradoras[idSeguradora]; !ok && len(objetos) > 0 {
+		return nil, foreignKeyError("idSeguradora")
+	}
+	
+	for _, objeto := range objetos {
+		if err := s.insert(objeto); err != nil {
+			return nil, err
+		}
+	}
+	
+	resultado.Criados = len(objetos)
+	return resultado, nil
+}
+
+// MemoryObjetoContabilizacaoEventoStore implementa ObjetoContabilizacaoEventoStore sobre o MemoryDB
+type MemoryObjetoContabilizacaoEventoStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemoryObjetoContabilizacaoEventoStore cria um armazenamento de relações entre objetos de contabilização e eventos em memória
+func NewMemoryObjetoContabilizacaoEventoStore(db *MemoryDB) *MemoryObjetoContabilizacaoEventoStore {
+	return &MemoryObjetoContabilizacaoEventoStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemoryObjetoContabilizacaoEventoStore) WithTenant(scope TenantScope) ObjetoContabilizacaoEventoStore {
+	return &MemoryObjetoContabilizacaoEventoStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryObjetoContabilizacaoEventoStore) WithActor(actor Actor) ObjetoContabilizacaoEventoStore {
+	return &MemoryObjetoContabilizacaoEventoStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// view preenche os campos de exibição da relação com o objeto e o evento relacionados
+func (s *MemoryObjetoContabilizacaoEventoStore) view(rel ObjetoContabilizacaoEvento) ObjetoContabilizacaoEvento {
+	rel.ObjetoContabilizacaoNome = s.db.objetos[rel.IdObjetoContabilizacao].ObjetoContabilizacao
+	rel.EventoNumero = s.db.eventos[rel.IdCodigoEvento].Evento
+	rel.EventoDescricao = s.db.eventos[rel.IdCodigoEvento].Descricao
+	return rel
+}
+
+// get busca a relação visível no escopo
+func (s *MemoryObjetoContabilizacaoEventoStore) get(id int64) (*ObjetoContabilizacaoEvento, error) {
+	rel, ok := s.db.relacoes[id]
+	if !ok || !s.scope.Allows(rel.IdSeguradora) {
+		return nil, NotFoundError{Message: "relação não encontrada"}
+	}
+	rel = s.view(rel)
+	return &rel, nil
+}
+
+// checkKeys verifica as chaves estrangeiras da relação
+func (s *MemoryObjetoContabilizacaoEventoStore) checkKeys(relacao *ObjetoContabilizacaoEvento) error {
+	if _, ok := s.db.objetos[relacao.IdObjetoContabilizacao]; !ok {
+		return foreignKeyError("idObjetoContabilizacao")
+	}
+	if _, ok := s.db.eventos[relacao.IdCodigoEvento]; !ok {
+		return foreignKeyError("idCodigoEvento")
+	}
+	if _, ok := s.db.seguradoras[relacao.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	return nil
+}
+
+// Create insere uma nova relação entre objeto de contabilização e evento
+func (s *MemoryObjetoContabilizacaoEventoStore) Create(ctx context.Context, relacao *ObjetoContabilizacaoEvento) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateObjetoContabilizacaoEvento(relacao); err != nil {
+		return err
+	}
+	if !s.scope.Allows(relacao.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if err := s.checkKeys(relacao); err != nil {
+		return err
+	}
+	
+	gravado := *relacao
+	gravado.ID = s.db.nextID("objeto_contabilizacao_evento")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	gravado.ObjetoContabilizacaoNome = ""
+	gravado.EventoNumero = 0
+	gravado.EventoDescricao = ""
+	
+	relacao.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeObjetoContabilizacaoEvento, gravado.ID, nil, relacao, func() {
+		s.db.relacoes[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna as relações entre objetos de contabilização e eventos com paginação, ordenação e filtros
+func (s *MemoryObjetoContabilizacaoEventoStore) GetAll(ctx context.Context, opts ListOptions) (*Page[ObjetoContabilizacaoEvento], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	relacoes := mapValues(s.db.relacoes, func(rel ObjetoContabilizacaoEvento) bool { return s.scope.Allows(rel.IdSeguradora) })
+	for i := range relacoes {
+		relacoes[i] = s.view(relacoes[i])
+	}
+	unlock()
+	
+	return memoryPage(&objetoContabilizacaoEventoListSpec, opts, relacoes)
+}
+
+// GetByID busca uma relação pelo ID
+func (s *MemoryObjetoContabilizacaoEventoStore) GetByID(ctx context.Context, id int64) (*ObjetoContabilizacaoEvento, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetBySeguradora busca relações por seguradora, com paginação, ordenação e filtros
+func (s *MemoryObjetoContabilizacaoEventoStore) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[ObjetoContabilizacaoEvento], error) {
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	return s.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
+}
+
+// Update atualiza os dados de uma relação existente
+func (s *MemoryObjetoContabilizacaoEventoStore) Update(ctx context.Context, relacao *ObjetoContabilizacaoEvento) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateObjetoContabilizacaoEvento(relacao); err != nil {
+		return err
+	}
+	if !s.scope.Allows(relacao.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(relacao.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.relacoes, relacao.ID, err)
+	}
+	if err := s.checkKeys(relacao); err != nil {
+		return err
+	}
+	
+	gravado := s.db.relacoes[relacao.ID]
+	gravado.IdObjetoContabilizacao = relacao.IdObjetoContabilizacao
+	gravado.IdCodigoEvento = relacao.IdCodigoEvento
+	gravado.IdSeguradora = relacao.IdSeguradora
+	gravado.Ativo = relacao.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeObjetoContabilizacaoEvento, relacao.ID, anterior, relacao, func() {
+		s.db.relacoes[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa uma relação (exclusão lógica)
+func (s *MemoryObjetoContabilizacaoEventoStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.relacoes, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeObjetoContabilizacaoEvento, id, anterior, &excluido, func() {
+		rel := s.db.relacoes[id]
+		rel.Ativo = false
+		rel.UpdatedAt = memoryNow()
+		s.db.relacoes[id] = rel
+	})
+}
+
+// MemorySistemaContabilStore implementa SistemaContabilStore sobre o MemoryDB
+type MemorySistemaContabilStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemorySistemaContabilStore cria um armazenamento de sistemas contábeis em memória
+func NewMemorySistemaContabilStore(db *MemoryDB) *MemorySistemaContabilStore {
+	return &MemorySistemaContabilStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemorySistemaContabilStore) WithTenant(scope TenantScope) SistemaContabilStore {
+	return &MemorySistemaContabilStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemorySistemaContabilStore) WithActor(actor Actor) SistemaContabilStore {
+	return &MemorySistemaContabilStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// get busca o sistema contábil visível no escopo
+func (s *MemorySistemaContabilStore) get(id int64) (*SistemaContabil, error) {
+	sistema, ok := s.db.sistemas[id]
+	if !ok || !s.scope.Allows(sistema.IdSeguradora) {
+		return nil, NotFoundError{Message: "sistema contábil não encontrado"}
+	}
+	return &sistema, nil
+}
+
+// Create insere um novo sistema contábil
+func (s *MemorySistemaContabilStore) Create(ctx context.Context, sistema *SistemaContabil) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateSistemaContabil(sistema); err != nil {
+		return err
+	}
+	if !s.scope.Allows(sistema.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	sistema.SistemaContabil = utils.SanitizeString(sistema.SistemaContabil)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if _, ok := s.db.seguradoras[sistema.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	gravado := *sistema
+	gravado.ID = s.db.nextID("sistema_contabil")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	sistema.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeSistemaContabil, gravado.ID, nil, sistema, func() {
+		s.db.sistemas[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna os sistemas contábeis com paginação, ordenação e filtros
+func (s *MemorySistemaContabilStore) GetAll(ctx context.Context, opts ListOptions) (*Page[SistemaContabil], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	sistemas := mapValues(s.db.sistemas, func(sis SistemaContabil) bool { return s.scope.Allows(sis.IdSeguradora) })
+	unlock()
+	
+	return memoryPage(&sistemaContabilListSpec, opts, sistemas)
+}
+
+// GetByID busca um sistema contábil pelo ID
+func (s *MemorySistemaContabilStore) GetByID(ctx context.Context, id int64) (*SistemaContabil, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetBySeguradora busca sistemas contábeis por seguradora, com paginação, ordenação e filtros
+func (s *MemorySistemaContabilStore) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[SistemaContabil], error) {
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	return s.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
+}
+
+// Update atualiza os dados de um sistema contábil existente
+func (s *MemorySistemaContabilStore) Update(ctx context.Context, sistema *SistemaContabil) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateSistemaContabil(sistema); err != nil {
+		return err
+	}
+	if !s.scope.Allows(sistema.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	sistema.SistemaContabil = utils.SanitizeString(sistema.SistemaContabil)
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(sistema.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.sistemas, sistema.ID, err)
+	}
+	if _, ok := s.db.seguradoras[sistema.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	gravado := *anterior
+	gravado.SistemaContabil = sistema.SistemaContabil
+	gravado.IdSeguradora = sistema.IdSeguradora
+	gravado.Ativo = sistema.Ativo
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeSistemaContabil, sistema.ID, anterior, sistema, func() {
+		s.db.sistemas[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa um sistema contábil (exclusão lógica)
+func (s *MemorySistemaContabilStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.sistemas, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	excluido.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeSistemaContabil, id, anterior, &excluido, func() {
+		s.db.sistemas[id] = excluido
+	})
+}
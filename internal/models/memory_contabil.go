@@ -0,0 +1,794 @@
+package models
+
+import (
+	"context"
+	"sort"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// MemorySistemaContabilConfigStore implementa SistemaContabilConfigStore sobre o MemoryDB
+type MemorySistemaContabilConfigStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemorySistemaContabilConfigStore cria um armazenamento de configurações de sistema contábil em memória
+func NewMemorySistemaContabilConfigStore(db *MemoryDB) *MemorySistemaContabilConfigStore {
+	return &MemorySistemaContabilConfigStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemorySistemaContabilConfigStore) WithTenant(scope TenantScope) SistemaContabilConfigStore {
+	return &MemorySistemaContabilConfigStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemorySistemaContabilConfigStore) WithActor(actor Actor) SistemaContabilConfigStore {
+	return &MemorySistemaContabilConfigStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// view preenche os campos de exibição da configuração com os registros relacionados
+func (s *MemorySistemaContabilConfigStore) view(c SistemaContabilConfig) SistemaContabilConfig {
+	c.SistemaContabilNome = s.db.sistemas[c.IdSistemaContabil].SistemaContabil
+	c.ObjetoContabilizacaoNome = s.db.objetos[c.IdObjetoContabilizacao].ObjetoContabilizacao
+	c.EventoNumero = s.db.eventos[c.IdCodigoEvento].Evento
+	c.EventoDescricao = s.db.eventos[c.IdCodigoEvento].Descricao
+	c.ContaDebitoCodigo = ""
+	if c.IdContaDebito != nil {
+		c.ContaDebitoCodigo = s.db.contas[*c.IdContaDebito].Codigo
+	}
+	c.ContaCreditoCodigo = ""
+	if c.IdContaCredito != nil {
+		c.ContaCreditoCodigo = s.db.contas[*c.IdContaCredito].Codigo
+	}
+	return c
+}
+
+// list retorna as configurações visíveis no escopo, com os campos de exibição
+func (s *MemorySistemaContabilConfigStore) list(ctx context.Context) []SistemaContabilConfig {
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	configs := mapValues(s.db.configs, func(c SistemaContabilConfig) bool { return s.scope.Allows(c.IdSeguradora) })
+	for i := range configs {
+		configs[i] = s.view(configs[i])
+	}
+	return configs
+}
+
+// get busca a configuração visível no escopo
+func (s *MemorySistemaContabilConfigStore) get(id int64) (*SistemaContabilConfig, error) {
+	c, ok := s.db.configs[id]
+	if !ok || !s.scope.Allows(c.IdSeguradora) {
+		return nil, NotFoundError{Message: "configuração não encontrada"}
+	}
+	c = s.view(c)
+	return &c, nil
+}
+
+// checkKeys verifica as chaves estrangeiras e a chave única das configurações ativas
+func (s *MemorySistemaContabilConfigStore) checkKeys(config *SistemaContabilConfig) error {
+	if _, ok := s.db.sistemas[config.IdSistemaContabil]; !ok {
+		return foreignKeyError("idSistemaContabil")
+	}
+	if _, ok := s.db.objetos[config.IdObjetoContabilizacao]; !ok {
+		return foreignKeyError("idObjetoContabilizacao")
+	}
+	if _, ok := s.db.eventos[config.IdCodigoEvento]; !ok {
+		return foreignKeyError("idCodigoEvento")
+	}
+	if _, ok := s.db.seguradoras[config.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	
+	// Chave única das configurações ativas (uk_sistema_contabil_config_ativa)
+	if !config.Ativo {
+		return nil
+	}
+	for _, c := range s.db.configs {
+		if c.ID != config.ID && c.Ativo &&
+			c.IdSistemaContabil == config.IdSistemaContabil &&
+			c.IdObjetoContabilizacao == config.IdObjetoContabilizacao &&
+			c.IdCodigoEvento == config.IdCodigoEvento {
+			return conflictError("sistema_contabil_config.uk_sistema_contabil_config_ativa")
+		}
+	}
+	return nil
+}
+
+// record retorna a configuração como gravada, sem os campos de exibição
+func (s *MemorySistemaContabilConfigStore) record(config *SistemaContabilConfig) SistemaContabilConfig {
+	return SistemaContabilConfig{
+		ID:                     config.ID,
+		IdSistemaContabil:      config.IdSistemaContabil,
+		IdObjetoContabilizacao: config.IdObjetoContabilizacao,
+		IdCodigoEvento:         config.IdCodigoEvento,
+		IdSeguradora:           config.IdSeguradora,
+		CreatedAt:              config.CreatedAt,
+		UpdatedAt:              config.UpdatedAt,
+		Ativo:                  config.Ativo,
+		IdContaDebito:          config.IdContaDebito,
+		IdContaCredito:         config.IdContaCredito,
+	}
+}
+
+// Create insere uma nova configuração de sistema contábil
+func (s *MemorySistemaContabilConfigStore) Create(ctx context.Context, config *SistemaContabilConfig) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateSistemaContabilConfig(config); err != nil {
+		return err
+	}
+	if !s.scope.Allows(config.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if err := validateReferenciasConfig(s.db, config); err != nil {
+		return err
+	}
+	if err := validateContasConfig(s.db, config); err != nil {
+		return err
+	}
+	if err := s.checkKeys(config); err != nil {
+		return err
+	}
+	
+	gravado := s.record(config)
+	gravado.ID = s.db.nextID("sistema_contabil_config")
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	config.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeSistemaContabilConfig, gravado.ID, nil, config, func() {
+		s.db.configs[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna as configurações de sistema contábil com paginação, ordenação e filtros
+func (s *MemorySistemaContabilConfigStore) GetAll(ctx context.Context, opts ListOptions) (*Page[SistemaContabilConfig], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	return memoryPage(&sistemaContabilConfigListSpec, opts, s.list(ctx))
+}
+
+// Export percorre todas as configurações que atendem aos filtros, na ordem solicitada
+func (s *MemorySistemaContabilConfigStore) Export(ctx context.Context, opts ListOptions, fn func(SistemaContabilConfig) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	return memoryStream(&sistemaContabilConfigListSpec, opts, s.list(ctx), fn)
+}
+
+// GetByID busca uma configuração pelo ID
+func (s *MemorySistemaContabilConfigStore) GetByID(ctx context.Context, id int64) (*SistemaContabilConfig, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// GetBySeguradora busca configurações por seguradora, com paginação, ordenação e filtros
+func (s *MemorySistemaContabilConfigStore) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[SistemaContabilConfig], error) {
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	return s.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
+}
+
+// GetBySistemaContabil busca configurações por sistema contábil, com paginação, ordenação e filtros
+func (s *MemorySistemaContabilConfigStore) GetBySistemaContabil(ctx context.Context, idSistemaContabil int64, opts ListOptions) (*Page[SistemaContabilConfig], error) {
+	return s.GetAll(ctx, opts.WithFilter("idSistemaContabil", idSistemaContabil))
+}
+
+// GetAtivasByEventoObjeto busca as configurações ativas, de sistemas contábeis ativos,
+// para um par evento e objeto de contabilização de uma seguradora
+func (s *MemorySistemaContabilConfigStore) GetAtivasByEventoObjeto(ctx context.Context, idSeguradora, idCodigoEvento, idObjetoContabilizacao int64) ([]SistemaContabilConfig, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	configs := mapValues(s.db.configs, func(c SistemaContabilConfig) bool {
+		return c.IdSeguradora == idSeguradora && c.IdCodigoEvento == idCodigoEvento &&
+			c.IdObjetoContabilizacao == idObjetoContabilizacao && c.Ativo && s.db.sistemas[c.IdSistemaContabil].Ativo
+	})
+	for i := range configs {
+		configs[i] = s.view(configs[i])
+	}
+	sort.Slice(configs, func(i, j int) bool {
+		if configs[i].IdSistemaContabil != configs[j].IdSistemaContabil {
+			return configs[i].IdSistemaContabil < configs[j].IdSistemaContabil
+		}
+		return configs[i].ID < configs[j].ID
+	})
+	
+	return configs, nil
+}
+
+// Update atualiza os dados de uma configuração existente
+func (s *MemorySistemaContabilConfigStore) Update(ctx context.Context, config *SistemaContabilConfig) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateSistemaContabilConfig(config); err != nil {
+		return err
+	}
+	if !s.scope.Allows(config.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(config.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.configs, config.ID, err)
+	}
+	if err := validateReferenciasConfig(s.db, config); err != nil {
+		return err
+	}
+	if err := validateContasConfig(s.db, config); err != nil {
+		return err
+	}
+	if err := s.checkKeys(config); err != nil {
+		return err
+	}
+	
+	gravado := s.record(config)
+	gravado.CreatedAt = anterior.CreatedAt
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeSistemaContabilConfig, config.ID, anterior, config, func() {
+		s.db.configs[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa uma configuração (exclusão lógica)
+func (s *MemorySistemaContabilConfigStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.configs, id, err)
+	}
+	excluido := *anterior
+	excluido.Ativo = false
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeSistemaContabilConfig, id, anterior, &excluido, func() {
+		c := s.db.configs[id]
+		c.Ativo = false
+		c.UpdatedAt = memoryNow()
+		s.db.configs[id] = c
+	})
+}
+
+// MemoryPlanoContasStore implementa PlanoContasStore sobre o MemoryDB
+type MemoryPlanoContasStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewMemoryPlanoContasStore cria um armazenamento do plano de contas em memória
+func NewMemoryPlanoContasStore(db *MemoryDB) *MemoryPlanoContasStore {
+	return &MemoryPlanoContasStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemoryPlanoContasStore) WithTenant(scope TenantScope) PlanoContasStore {
+	return &MemoryPlanoContasStore{db: s.db, scope: scopeCopy(scope), actor: s.actor}
+}
+
+// WithActor retorna uma cópia do armazenamento que registra as alterações no histórico em nome do usuário informado
+func (s *MemoryPlanoContasStore) WithActor(actor Actor) PlanoContasStore {
+	return &MemoryPlanoContasStore{db: s.db, scope: s.scope, actor: &actor}
+}
+
+// view preenche o código da conta pai
+func (s *MemoryPlanoContasStore) view(c ContaContabil) ContaContabil {
+	c.CodigoContaPai = ""
+	if c.IdContaPai != nil {
+		c.CodigoContaPai = s.db.contas[*c.IdContaPai].Codigo
+	}
+	return c
+}
+
+// get busca a conta visível no escopo
+func (s *MemoryPlanoContasStore) get(id int64) (*ContaContabil, error) {
+	c, ok := s.db.contas[id]
+	if !ok || !s.scope.Allows(c.IdSeguradora) {
+		return nil, NotFoundError{Message: "conta não encontrada"}
+	}
+	c = s.view(c)
+	return &c, nil
+}
+
+// checkKeys verifica a chave única e as chaves estrangeiras da conta
+func (s *MemoryPlanoContasStore) checkKeys(conta *ContaContabil) error {
+	for _, c := range s.db.contas {
+		if c.ID != conta.ID && c.IdSeguradora == conta.IdSeguradora &&
+			c.IdSistemaContabil == conta.IdSistemaContabil && c.Codigo == conta.Codigo {
+			return conflictError("plano_contas.uk_plano_contas_codigo")
+		}
+	}
+	if _, ok := s.db.seguradoras[conta.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	return nil
+}
+
+// filhasAtivas verifica se a conta possui contas filhas ativas
+func (s *MemoryPlanoContasStore) filhasAtivas(id int64) bool {
+	for _, c := range s.db.contas {
+		if c.IdContaPai != nil && *c.IdContaPai == id && c.Ativo {
+			return true
+		}
+	}
+	return false
+}
+
+// Create insere uma nova conta no plano de contas
+func (s *MemoryPlanoContasStore) Create(ctx context.Context, conta *ContaContabil) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateContaContabil(conta); err != nil {
+		return err
+	}
+	if !s.scope.Allows(conta.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if err := validateHierarquiaConta(s.db, conta); err != nil {
+		return err
+	}
+	conta.Descricao = utils.SanitizeString(conta.Descricao)
+	if err := s.checkKeys(conta); err != nil {
+		return err
+	}
+	
+	gravado := *conta
+	gravado.ID = s.db.nextID("plano_contas")
+	gravado.CodigoContaPai = ""
+	gravado.CreatedAt = memoryNow()
+	gravado.UpdatedAt = gravado.CreatedAt
+	
+	conta.ID = gravado.ID
+	return s.db.change(s.actor, AcaoCriacao, EntidadeContaContabil, gravado.ID, nil, conta, func() {
+		s.db.contas[gravado.ID] = gravado
+	})
+}
+
+// GetAll retorna as contas do plano de contas com paginação, ordenação e filtros
+// (por padrão, ordenadas por seguradora, sistema contábil e código)
+func (s *MemoryPlanoContasStore) GetAll(ctx context.Context, opts ListOptions) (*Page[ContaContabil], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	contas := mapValues(s.db.contas, func(c ContaContabil) bool { return s.scope.Allows(c.IdSeguradora) })
+	for i := range contas {
+		contas[i] = s.view(contas[i])
+	}
+	unlock()
+	
+	if len(opts.Sort) == 0 {
+		opts.Sort = []SortField{{Field: "idSeguradora"}, {Field: "idSistemaContabil"}, {Field: "codigo"}}
+	}
+	
+	return memoryPage(&planoContasListSpec, opts, contas)
+}
+
+// GetBySistemaContabil retorna o plano de contas de um sistema contábil, com paginação, ordenação e filtros
+// (por padrão, ordenado pelo código)
+func (s *MemoryPlanoContasStore) GetBySistemaContabil(ctx context.Context, idSistemaContabil int64, opts ListOptions) (*Page[ContaContabil], error) {
+	if len(opts.Sort) == 0 {
+		opts.Sort = []SortField{{Field: "codigo"}}
+	}
+	
+	return s.GetAll(ctx, opts.WithFilter("idSistemaContabil", idSistemaContabil))
+}
+
+// GetByID busca uma conta pelo ID
+func (s *MemoryPlanoContasStore) GetByID(ctx context.Context, id int64) (*ContaContabil, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	return s.get(id)
+}
+
+// Update atualiza os dados de uma conta existente
+func (s *MemoryPlanoContasStore) Update(ctx context.Context, conta *ContaContabil) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := validateContaContabil(conta); err != nil {
+		return err
+	}
+	if !s.scope.Allows(conta.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(conta.ID)
+	if err != nil {
+		return memoryCrossTenant(s.db.contas, conta.ID, err)
+	}
+	if err := validateHierarquiaConta(s.db, conta); err != nil {
+		return err
+	}
+	
+	// Uma conta com contas filhas ativas precisa continuar sintética
+	if conta.Tipo == TipoContaAnalitica && s.filhasAtivas(conta.ID) {
+		return utils.ValidationError{
+			Field:   "tipo",
+			Message: "conta com contas filhas ativas deve ser sintética",
+		}
+	}
+	conta.Descricao = utils.SanitizeString(conta.Descricao)
+	
+	if err := s.checkKeys(conta); err != nil {
+		return err
+	}
+	
+	gravado := *conta
+	gravado.CodigoContaPai = ""
+	gravado.CreatedAt = anterior.CreatedAt
+	gravado.UpdatedAt = memoryNow()
+	
+	return s.db.change(s.actor, AcaoAlteracao, EntidadeContaContabil, conta.ID, anterior, conta, func() {
+		s.db.contas[gravado.ID] = gravado
+	})
+}
+
+// Delete desativa uma conta do plano de contas (exclusão lógica)
+func (s *MemoryPlanoContasStore) Delete(ctx context.Context, id int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	anterior, err := s.get(id)
+	if err != nil {
+		return memoryCrossTenant(s.db.contas, id, err)
+	}
+	excluida := *anterior
+	excluida.Ativo = false
+	
+	// Não permitir desativar contas que ainda possuem contas filhas ativas
+	if s.filhasAtivas(id) {
+		return ConflictError{Message: "conta possui contas filhas ativas"}
+	}
+	
+	// Não permitir desativar contas usadas por configurações ativas
+	for _, c := range s.db.configs {
+		usada := (c.IdContaDebito != nil && *c.IdContaDebito == id) || (c.IdContaCredito != nil && *c.IdContaCredito == id)
+		if usada && c.Ativo {
+			return ConflictError{Message: "conta utilizada por configurações de sistema contábil ativas"}
+		}
+	}
+	
+	return s.db.change(s.actor, AcaoExclusao, EntidadeContaContabil, id, anterior, &excluida, func() {
+		c := s.db.contas[id]
+		c.Ativo = false
+		c.UpdatedAt = memoryNow()
+		s.db.contas[id] = c
+	})
+}
+
+// Import importa (cria ou atualiza pelo código) as contas de um plano de contas. Se qualquer linha
+// for inválida, nenhuma alteração é gravada e os erros de cada linha são retornados.
+func (s *MemoryPlanoContasStore) Import(ctx context.Context, idSeguradora, idSistemaContabil int64, linhas []LinhaPlanoContas) (*ResultadoImportacaoPlanoContas, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	if !s.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if err := validateSistemaDaConta(s.db, idSeguradora, idSistemaContabil); err != nil {
+		return nil, err
+	}
+	
+	// Carregar as contas existentes do plano
+	existentes := make(map[string]*contaImportada)
+	for _, c := range s.db.contas {
+		if c.IdSeguradora != idSeguradora || c.IdSistemaContabil != idSistemaContabil {
+			continue
+		}
+		var idPai int64
+		if c.IdContaPai != nil {
+			idPai = *c.IdContaPai
+		}
+		existentes[c.Codigo] = &contaImportada{id: c.ID, tipo: c.Tipo, idPai: idPai, anterior: &ContaContabil{
+			ID:                c.ID,
+			IdSeguradora:      c.IdSeguradora,
+			IdSistemaContabil: c.IdSistemaContabil,
+			Codigo:            c.Codigo,
+			Descricao:         c.Descricao,
+			Natureza:          c.Natureza,
+			Tipo:              c.Tipo,
+			IdContaPai:        c.IdContaPai,
+			Ativo:             c.Ativo,
+		}}
+	}
+	
+	gravador := &memoryGravadorPlanoContas{db: s.db, actor: s.actor}
+	resultado, err := importarPlanoContas(idSeguradora, idSistemaContabil, linhas, existentes, gravador)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Qualquer erro cancela toda a importação
+	if len(resultado.Erros) > 0 {
+		resultado.Criadas = 0
+		resultado.Atualizadas = 0
+		return resultado, nil
+	}
+	
+	gravador.gravar()
+	return resultado, nil
+}
+
+// memoryGravadorPlanoContas acumula as contas de uma importação do plano de contas, que só são
+// gravadas, com o histórico, se a importação não tiver erros
+type memoryGravadorPlanoContas struct {
+	db       *MemoryDB
+	actor    *Actor
+	contas   []ContaContabil
+	entradas []*AuditLog
+}
+
+func (g *memoryGravadorPlanoContas) criar(conta *ContaContabil) error {
+	conta.ID = g.db.nextID("plano_contas")
+	
+	entry, err := changeEntry(g.actor, AcaoCriacao, EntidadeContaContabil, conta.ID, nil, conta)
+	if err != nil {
+		return err
+	}
+	
+	gravada := *conta
+	gravada.Ativo = true
+	gravada.CreatedAt = memoryNow()
+	gravada.UpdatedAt = gravada.CreatedAt
+	g.stage(gravada, entry)
+	return nil
+}
+
+func (g *memoryGravadorPlanoContas) atualizar(anterior, atualizada *ContaContabil) error {
+	entry, err := changeEntry(g.actor, AcaoAlteracao, EntidadeContaContabil, atualizada.ID, anterior, atualizada)
+	if err != nil {
+		return err
+	}
+	
+	gravada := g.db.contas[atualizada.ID]
+	gravada.Descricao = atualizada.Descricao
+	gravada.Natureza = atualizada.Natureza
+	gravada.Tipo = atualizada.Tipo
+	gravada.IdContaPai = atualizada.IdContaPai
+	gravada.Ativo = true
+	gravada.UpdatedAt = memoryNow()
+	g.stage(gravada, entry)
+	return nil
+}
+
+// stage acumula a conta e a entrada do histórico até o fim da importação
+func (g *memoryGravadorPlanoContas) stage(conta ContaContabil, entry *AuditLog) {
+	g.contas = append(g.contas, conta)
+	if entry != nil {
+		g.entradas = append(g.entradas, entry)
+	}
+}
+
+// gravar grava as contas acumuladas e registra o histórico (com o banco bloqueado)
+func (g *memoryGravadorPlanoContas) gravar() {
+	for _, conta := range g.contas {
+		g.db.contas[conta.ID] = conta
+	}
+	g.db.appendAuditLogs(g.entradas)
+}
+
+// MemoryLancamentoStore implementa LancamentoStore sobre o MemoryDB
+type MemoryLancamentoStore struct {
+	db    *MemoryDB
+	scope *TenantScope
+}
+
+// NewMemoryLancamentoStore cria um armazenamento de lançamentos em memória
+func NewMemoryLancamentoStore(db *MemoryDB) *MemoryLancamentoStore {
+	return &MemoryLancamentoStore{db: db}
+}
+
+// WithTenant retorna uma cópia do armazenamento restrita ao escopo de seguradora informado
+func (s *MemoryLancamentoStore) WithTenant(scope TenantScope) LancamentoStore {
+	return &MemoryLancamentoStore{db: s.db, scope: scopeCopy(scope)}
+}
+
+// checkKeys verifica as chaves estrangeiras do lançamento e das suas partidas
+func (s *MemoryLancamentoStore) checkKeys(l *Lancamento) error {
+	if _, ok := s.db.seguradoras[l.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	if _, ok := s.db.sistemas[l.IdSistemaContabil]; !ok {
+		return foreignKeyError("idSistemaContabil")
+	}
+	if _, ok := s.db.configs[l.IdSistemaContabilConfig]; !ok {
+		return foreignKeyError("idSistemaContabilConfig")
+	}
+	if _, ok := s.db.eventos[l.IdCodigoEvento]; !ok {
+		return foreignKeyError("idCodigoEvento")
+	}
+	if _, ok := s.db.objetos[l.IdObjetoContabilizacao]; !ok {
+		return foreignKeyError("idObjetoContabilizacao")
+	}
+	for _, p := range l.Partidas {
+		if p.IdConta == nil {
+			continue
+		}
+		if _, ok := s.db.contas[*p.IdConta]; !ok {
+			return foreignKeyError("id_conta")
+		}
+	}
+	return nil
+}
+
+// CreateLote grava o lote e todos os seus lançamentos em uma única operação
+func (s *MemoryLancamentoStore) CreateLote(ctx context.Context, lote *LoteLancamento, lancamentos []Lancamento) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	
+	if err := checkLote(s.scope, lote, lancamentos); err != nil {
+		return err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	if _, ok := s.db.seguradoras[lote.IdSeguradora]; !ok {
+		return foreignKeyError("idSeguradora")
+	}
+	for i := range lancamentos {
+		if err := s.checkKeys(&lancamentos[i]); err != nil {
+			return err
+		}
+	}
+	
+	agora := memoryNow()
+	lote.ID = s.db.nextID("lotes_lancamento")
+	gravado := *lote
+	gravado.CreatedAt = agora
+	s.db.lotes[lote.ID] = gravado
+	
+	for i := range lancamentos {
+		l := &lancamentos[i]
+		l.IdLote = lote.ID
+		l.ID = s.db.nextID("lancamentos")
+		for j := range l.Partidas {
+			p := &l.Partidas[j]
+			p.IdLancamento = l.ID
+			p.ID = s.db.nextID("lancamento_partidas")
+		}
+		
+		gravado := *l
+		gravado.CreatedAt = agora
+		gravado.Partidas = append([]PartidaLancamento(nil), l.Partidas...)
+		s.db.lancamentos[l.ID] = gravado
+	}
+	
+	return nil
+}
+
+// GetLoteByID busca um lote de lançamentos pelo ID
+func (s *MemoryLancamentoStore) GetLoteByID(ctx context.Context, id int64) (*LoteLancamento, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	lote, ok := s.db.lotes[id]
+	if !ok || !s.scope.Allows(lote.IdSeguradora) {
+		return nil, NotFoundError{Message: "lote de lançamentos não encontrado"}
+	}
+	return &lote, nil
+}
+
+// GetByLote busca os lançamentos de um lote, com suas partidas
+func (s *MemoryLancamentoStore) GetByLote(ctx context.Context, idLote int64) ([]Lancamento, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	lancamentos := mapValues(s.db.lancamentos, func(l Lancamento) bool {
+		return l.IdLote == idLote && s.scope.Allows(l.IdSeguradora)
+	})
+	sort.Slice(lancamentos, func(i, j int) bool { return lancamentos[i].ID < lancamentos[j].ID })
+	for i := range lancamentos {
+		lancamentos[i].Partidas = append([]PartidaLancamento(nil), lancamentos[i].Partidas...)
+	}
+	
+	return lancamentos, nil
+}
+
+// GetAll retorna os lançamentos, com suas partidas, com paginação, ordenação e filtros
+func (s *MemoryLancamentoStore) GetAll(ctx context.Context, opts ListOptions) (*Page[Lancamento], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	lancamentos := mapValues(s.db.lancamentos, func(l Lancamento) bool { return s.scope.Allows(l.IdSeguradora) })
+	unlock()
+	for i := range lancamentos {
+		lancamentos[i].Partidas = append([]PartidaLancamento(nil), lancamentos[i].Partidas...)
+	}
+	
+	return memoryPage(&lancamentoListSpec, opts, lancamentos)
+}
+
+// GetByID busca um lançamento pelo ID, com suas partidas
+func (s *MemoryLancamentoStore) GetByID(ctx context.Context, id int64) (*Lancamento, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	
+	unlock := s.db.lock(ctx)
+	defer unlock()
+	
+	l, ok := s.db.lancamentos[id]
+	if !ok || !s.scope.Allows(l.IdSeguradora) {
+		return nil, NotFoundError{Message: "lançamento não encontrado"}
+	}
+	l.Partidas = append([]PartidaLancamento(nil), l.Partidas...)
+	return &l, nil
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -21,7 +22,9 @@ type ObjetoContabilizacao struct {
 
 // ObjetoContabilizacaoRepository gerencia operações de banco de dados para objetos de contabilização
 type ObjetoContabilizacaoRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewObjetoContabilizacaoRepository cria um novo repositório de objetos de contabilização
@@ -29,13 +32,31 @@ func NewObjetoContabilizacaoRepository(db *sql.DB) *ObjetoContabilizacaoReposito
 	return &ObjetoContabilizacaoRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *ObjetoContabilizacaoRepository) WithTenant(scope TenantScope) ObjetoContabilizacaoStore {
+	return &ObjetoContabilizacaoRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *ObjetoContabilizacaoRepository) WithActor(actor Actor) ObjetoContabilizacaoStore {
+	return &ObjetoContabilizacaoRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
 // Create insere um novo objeto de contabilização no banco de dados
-func (r *ObjetoContabilizacaoRepository) Create(objeto *ObjetoContabilizacao) error {
+func (r *ObjetoContabilizacaoRepository) Create(ctx context.Context, objeto *ObjetoContabilizacao) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do objeto
 	if err := validateObjetoContabilizacao(objeto); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(objeto.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	objeto.ObjetoContabilizacao = utils.SanitizeString(objeto.ObjetoContabilizacao)
 	objeto.Descricao = utils.SanitizeString(objeto.Descricao)
@@ -45,41 +66,61 @@ func (r *ObjetoContabilizacaoRepository) Create(objeto *ObjetoContabilizacao) er
 	(ObjetoContabilizacao, Descricao, idSeguradora, ativo) 
 	VALUES (?, ?, ?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		objeto.ObjetoContabilizacao, 
-		objeto.Descricao, 
-		objeto.IdSeguradora, 
-		objeto.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar objeto de contabilização: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID do objeto de contabilização: %v", err)
-	}
-	
-	objeto.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "idObjetoContabilizacao",
+			query, 
+			objeto.ObjetoContabilizacao, 
+			objeto.Descricao, 
+			objeto.IdSeguradora, 
+			objeto.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar objeto de contabilização: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID do objeto de contabilização: %w", err)
+		}
+		
+		objeto.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeObjetoContabilizacao, id, nil, objeto)
+	})
 }
 
-// GetAll retorna todos os objetos de contabilização do banco de dados
-func (r *ObjetoContabilizacaoRepository) GetAll() ([]ObjetoContabilizacao, error) {
+// objetoContabilizacaoListSpec define os campos de ordenação e filtro da listagem de objetos de contabilização
+var objetoContabilizacaoListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idObjetoContabilizacao": {Column: "idObjetoContabilizacao", Type: FieldInt},
+		"objetoContabilizacao":   {Column: "ObjetoContabilizacao", Type: FieldString},
+		"descricao":              {Column: "Descricao", Type: FieldString},
+		"idSeguradora":           {Column: "idSeguradora", Type: FieldInt},
+		"created_at":             {Column: "created_at", Type: FieldTime},
+		"updated_at":             {Column: "updated_at", Type: FieldTime},
+		"ativo":                  {Column: "ativo", Type: FieldBool},
+	},
+	Key: "idObjetoContabilizacao",
+}
+
+// GetAll retorna os objetos de contabilização com paginação, ordenação e filtros
+func (r *ObjetoContabilizacaoRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[ObjetoContabilizacao], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		idObjetoContabilizacao, ObjetoContabilizacao, Descricao, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM objeto_contabilizacao 
-	ORDER BY idObjetoContabilizacao DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar objetos de contabilização: %v", err)
-	}
-	defer rows.Close()
+	WHERE ` + r.scope.condition("idSeguradora")
 	
+	return listPage(ctx, r.DB, &objetoContabilizacaoListSpec, opts, query, r.scope.args(), scanObjetosContabilizacao)
+}
+
+// scanObjetosContabilizacao lê os objetos de contabilização retornados por uma consulta
+func scanObjetosContabilizacao(rows *sql.Rows) ([]ObjetoContabilizacao, error) {
 	var objetos []ObjetoContabilizacao
 	
 	for rows.Next() {
@@ -93,29 +134,32 @@ func (r *ObjetoContabilizacaoRepository) GetAll() ([]ObjetoContabilizacao, error
 			&o.UpdatedAt, 
 			&o.Ativo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler objeto de contabilização: %v", err)
+			return nil, fmt.Errorf("erro ao ler objeto de contabilização: %w", err)
 		}
 		objetos = append(objetos, o)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre objetos de contabilização: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre objetos de contabilização: %w", err)
 	}
 	
 	return objetos, nil
 }
 
 // GetByID busca um objeto de contabilização pelo ID
-func (r *ObjetoContabilizacaoRepository) GetByID(id int64) (*ObjetoContabilizacao, error) {
+func (r *ObjetoContabilizacaoRepository) GetByID(ctx context.Context, id int64) (*ObjetoContabilizacao, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		idObjetoContabilizacao, ObjetoContabilizacao, Descricao, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM objeto_contabilizacao 
-	WHERE idObjetoContabilizacao = ?`
+	WHERE idObjetoContabilizacao = ? AND ` + r.scope.condition("idSeguradora")
 	
 	var o ObjetoContabilizacao
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&o.ID, 
 		&o.ObjetoContabilizacao, 
 		&o.Descricao, 
@@ -126,62 +170,79 @@ func (r *ObjetoContabilizacaoRepository) GetByID(id int64) (*ObjetoContabilizaca
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("objeto de contabilização não encontrado")
+			return nil, NotFoundError{Message: "objeto de contabilização não encontrado"}
 		}
-		return nil, fmt.Errorf("erro ao buscar objeto de contabilização: %v", err)
+		return nil, fmt.Errorf("erro ao buscar objeto de contabilização: %w", err)
 	}
 	
 	return &o, nil
 }
 
-// GetBySeguradora busca objetos de contabilização por seguradora
-func (r *ObjetoContabilizacaoRepository) GetBySeguradora(idSeguradora int64) ([]ObjetoContabilizacao, error) {
+// GetByCodigo busca um objeto de contabilização pelo código dentro de uma seguradora (ativo ou não).
+// Retorna nil se o objeto não existir.
+func (r *ObjetoContabilizacaoRepository) GetByCodigo(ctx context.Context, idSeguradora int64, codigo string) (*ObjetoContabilizacao, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
 	query := `
 	SELECT 
 		idObjetoContabilizacao, ObjetoContabilizacao, Descricao, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM objeto_contabilizacao 
-	WHERE idSeguradora = ? 
-	ORDER BY idObjetoContabilizacao DESC`
+	WHERE idSeguradora = ? AND ObjetoContabilizacao = ? 
+	ORDER BY ativo DESC, idObjetoContabilizacao DESC 
+	LIMIT 1`
 	
-	rows, err := r.DB.Query(query, idSeguradora)
+	var o ObjetoContabilizacao
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, idSeguradora, codigo).Scan(
+		&o.ID, 
+		&o.ObjetoContabilizacao, 
+		&o.Descricao, 
+		&o.IdSeguradora, 
+		&o.CreatedAt, 
+		&o.UpdatedAt, 
+		&o.Ativo,
+	)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar objetos de contabilização por seguradora: %v", err)
-	}
-	defer rows.Close()
-	
-	var objetos []ObjetoContabilizacao
-	
-	for rows.Next() {
-		var o ObjetoContabilizacao
-		if err := rows.Scan(
-			&o.ID, 
-			&o.ObjetoContabilizacao, 
-			&o.Descricao, 
-			&o.IdSeguradora, 
-			&o.CreatedAt, 
-			&o.UpdatedAt, 
-			&o.Ativo,
-		); err != nil {
-			return nil, fmt.Errorf("erro ao ler objeto de contabilização: %v", err)
+		if err == sql.ErrNoRows {
+			return nil, nil
 		}
-		objetos = append(objetos, o)
+		return nil, fmt.Errorf("erro ao buscar objeto de contabilização: %w", err)
 	}
 	
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre objetos de contabilização: %v", err)
+	return &o, nil
+}
+
+// GetBySeguradora busca objetos de contabilização por seguradora, com paginação, ordenação e filtros
+func (r *ObjetoContabilizacaoRepository) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[ObjetoContabilizacao], error) {
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
 	}
 	
-	return objetos, nil
+	return r.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
 }
 
 // Update atualiza os dados de um objeto de contabilização existente
-func (r *ObjetoContabilizacaoRepository) Update(objeto *ObjetoContabilizacao) error {
+func (r *ObjetoContabilizacaoRepository) Update(ctx context.Context, objeto *ObjetoContabilizacao) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do objeto
 	if err := validateObjetoContabilizacao(objeto); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(objeto.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	objeto.ObjetoContabilizacao = utils.SanitizeString(objeto.ObjetoContabilizacao)
 	objeto.Descricao = utils.SanitizeString(objeto.Descricao)
@@ -189,37 +250,176 @@ func (r *ObjetoContabilizacaoRepository) Update(objeto *ObjetoContabilizacao) er
 	query := `
 	UPDATE objeto_contabilizacao 
 	SET ObjetoContabilizacao = ?, Descricao = ?, idSeguradora = ?, ativo = ? 
-	WHERE idObjetoContabilizacao = ?`
-	
-	_, err := r.DB.Exec(
-		query, 
-		objeto.ObjetoContabilizacao, 
-		objeto.Descricao, 
-		objeto.IdSeguradora, 
-		objeto.Ativo, 
-		objeto.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar objeto de contabilização: %v", err)
-	}
+	WHERE idObjetoContabilizacao = ? AND ` + r.scope.condition("idSeguradora")
 	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "objeto_contabilizacao", "idObjetoContabilizacao", objeto.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				objeto.ObjetoContabilizacao, 
+				objeto.Descricao, 
+				objeto.IdSeguradora, 
+				objeto.Ativo, 
+				objeto.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar objeto de contabilização: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeObjetoContabilizacao, objeto.ID, anterior, objeto)
+	})
 }
 
 // Delete remove um objeto de contabilização do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *ObjetoContabilizacaoRepository) Delete(id int64) error {
+func (r *ObjetoContabilizacaoRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM objeto_contabilizacao WHERE idObjetoContabilizacao = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE objeto_contabilizacao SET ativo = false WHERE idObjetoContabilizacao = ?`
+	query := `UPDATE objeto_contabilizacao SET ativo = false WHERE idObjetoContabilizacao = ? AND ` + r.scope.condition("idSeguradora")
 	
-	_, err := r.DB.Exec(query, id)
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "objeto_contabilizacao", "idObjetoContabilizacao", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir objeto de contabilização: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeObjetoContabilizacao, id, anterior, &excluido)
+	})
+}
+
+// Import cria em lote os objetos de contabilização de uma seguradora lidos de um arquivo (colunas
+// objeto_contabilizacao, descricao e ativo, opcional). Todas as linhas são validadas antes da gravação:
+// com qualquer erro, nenhum objeto é gravado e os erros de cada linha são retornados. Na simulação,
+// as linhas são apenas validadas.
+func (r *ObjetoContabilizacaoRepository) Import(ctx context.Context, idSeguradora int64, linhas []LinhaArquivo, simular bool) (*ResultadoImportacao, error) {
+	// Verificar se os objetos pertencem à seguradora do usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	// Objetos ativos já cadastrados na seguradora
+	existentes := make(map[string]bool)
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, "SELECT ObjetoContabilizacao FROM objeto_contabilizacao WHERE idSeguradora = ? AND ativo = true", idSeguradora)
 	if err != nil {
-		return fmt.Errorf("erro ao excluir objeto de contabilização: %v", err)
+		return nil, fmt.Errorf("erro ao buscar objetos de contabilização: %w", err)
+	}
+	for rows.Next() {
+		var codigo string
+		if err := rows.Scan(&codigo); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("erro ao ler objeto de contabilização: %w", err)
+		}
+		existentes[codigo] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre objetos de contabilização: %w", err)
 	}
 	
-	return nil
+	resultado := &ResultadoImportacao{Simulacao: simular, Linhas: len(linhas)}
+	objetos := validarImportacaoObjetos(idSeguradora, linhas, existentes, resultado)
+	
+	// Qualquer erro cancela toda a importação
+	if len(resultado.Erros) > 0 || simular {
+		return resultado, nil
+	}
+	
+	query := `
+	INSERT INTO objeto_contabilizacao 
+	(ObjetoContabilizacao, Descricao, idSeguradora, ativo) 
+	VALUES (?, ?, ?, ?)`
+	
+	err = inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		for _, objeto := range objetos {
+			result, err := execInsert(ctx, tx, "idObjetoContabilizacao", query, objeto.ObjetoContabilizacao, objeto.Descricao, objeto.IdSeguradora, objeto.Ativo)
+			if err != nil {
+				return fmt.Errorf("erro ao criar objeto de contabilização %s: %w", objeto.ObjetoContabilizacao, err)
+			}
+			
+			id, err := result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("erro ao obter ID do objeto de contabilização: %w", err)
+			}
+			objeto.ID = id
+			
+			// Registrar a criação no histórico
+			if err := recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeObjetoContabilizacao, id, nil, objeto); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	resultado.Criados = len(objetos)
+	return resultado, nil
+}
+
+// validarImportacaoObjetos valida os dados de cada linha de um arquivo de objetos de contabilização,
+// dados os códigos dos objetos ativos já cadastrados na seguradora, e retorna os objetos das linhas
+// válidas. Os erros de cada linha são registrados no resultado.
+func validarImportacaoObjetos(idSeguradora int64, linhas []LinhaArquivo, existentes map[string]bool, resultado *ResultadoImportacao) []*ObjetoContabilizacao {
+	var objetos []*ObjetoContabilizacao
+	codigos := make(map[string]int)
+	for _, linha := range linhas {
+		codigo := linha.Valor("objeto_contabilizacao")
+		erro := func(mensagem string) {
+			resultado.Erros = append(resultado.Erros, ErroImportacao{Linha: linha.Linha, Codigo: codigo, Mensagem: mensagem})
+		}
+		
+		ativo, err := parseAtivo(linha.Valor("ativo"))
+		if err != nil {
+			erro(err.Error())
+			continue
+		}
+		
+		objeto := &ObjetoContabilizacao{ObjetoContabilizacao: codigo, Descricao: linha.Valor("descricao"), IdSeguradora: idSeguradora, Ativo: ativo}
+		if err := validateObjetoContabilizacao(objeto); err != nil {
+			erro(err.Error())
+			continue
+		}
+		
+		// Os códigos são gravados sanitizados
+		objeto.ObjetoContabilizacao = utils.SanitizeString(objeto.ObjetoContabilizacao)
+		objeto.Descricao = utils.SanitizeString(objeto.Descricao)
+		
+		if anterior, ok := codigos[objeto.ObjetoContabilizacao]; ok {
+			erro(fmt.Sprintf("objeto de contabilização duplicado no arquivo (linha %d)", anterior))
+			continue
+		}
+		codigos[objeto.ObjetoContabilizacao] = linha.Linha
+		if ativo && existentes[objeto.ObjetoContabilizacao] {
+			erro("objeto de contabilização já cadastrado na seguradora")
+			continue
+		}
+		
+		objetos = append(objetos, objeto)
+	}
+	resultado.Validas = len(objetos)
+	
+	return objetos
 }
 
 // validateObjetoContabilizacao valida os dados de um objeto de contabilização
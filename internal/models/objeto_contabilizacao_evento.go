@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -25,7 +26,9 @@ type ObjetoContabilizacaoEvento struct {
 
 // ObjetoContabilizacaoEventoRepository gerencia operações de banco de dados para relações entre objetos de contabilização e eventos
 type ObjetoContabilizacaoEventoRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewObjetoContabilizacaoEventoRepository cria um novo repositório de relações entre objetos de contabilização e eventos
@@ -33,40 +36,82 @@ func NewObjetoContabilizacaoEventoRepository(db *sql.DB) *ObjetoContabilizacaoEv
 	return &ObjetoContabilizacaoEventoRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *ObjetoContabilizacaoEventoRepository) WithTenant(scope TenantScope) ObjetoContabilizacaoEventoStore {
+	return &ObjetoContabilizacaoEventoRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *ObjetoContabilizacaoEventoRepository) WithActor(actor Actor) ObjetoContabilizacaoEventoStore {
+	return &ObjetoContabilizacaoEventoRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
 // Create insere uma nova relação entre objeto de contabilização e evento no banco de dados
-func (r *ObjetoContabilizacaoEventoRepository) Create(relacao *ObjetoContabilizacaoEvento) error {
+func (r *ObjetoContabilizacaoEventoRepository) Create(ctx context.Context, relacao *ObjetoContabilizacaoEvento) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados da relação
 	if err := validateObjetoContabilizacaoEvento(relacao); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(relacao.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	query := `
 	INSERT INTO objeto_contabilizacao_evento 
 	(idObjetoContabilizacao, idCodigoEvento, idSeguradora, ativo) 
 	VALUES (?, ?, ?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		relacao.IdObjetoContabilizacao, 
-		relacao.IdCodigoEvento, 
-		relacao.IdSeguradora, 
-		relacao.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar relação entre objeto de contabilização e evento: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID da relação: %v", err)
-	}
-	
-	relacao.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "idObjetoContabilizacaoEvento",
+			query, 
+			relacao.IdObjetoContabilizacao, 
+			relacao.IdCodigoEvento, 
+			relacao.IdSeguradora, 
+			relacao.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar relação entre objeto de contabilização e evento: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID da relação: %w", err)
+		}
+		
+		relacao.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeObjetoContabilizacaoEvento, id, nil, relacao)
+	})
 }
 
-// GetAll retorna todas as relações entre objetos de contabilização e eventos do banco de dados
-func (r *ObjetoContabilizacaoEventoRepository) GetAll() ([]ObjetoContabilizacaoEvento, error) {
+// objetoContabilizacaoEventoListSpec define os campos de ordenação e filtro da listagem de relações
+var objetoContabilizacaoEventoListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idObjetoContabilizacaoEvento": {Column: "oce.idObjetoContabilizacaoEvento", Type: FieldInt},
+		"idObjetoContabilizacao":       {Column: "oce.idObjetoContabilizacao", Type: FieldInt},
+		"idCodigoEvento":               {Column: "oce.idCodigoEvento", Type: FieldInt},
+		"idSeguradora":                 {Column: "oce.idSeguradora", Type: FieldInt},
+		"created_at":                   {Column: "oce.created_at", Type: FieldTime},
+		"updated_at":                   {Column: "oce.updated_at", Type: FieldTime},
+		"ativo":                        {Column: "oce.ativo", Type: FieldBool},
+		"objetoContabilizacaoNome":     {Column: "oc.ObjetoContabilizacao", Type: FieldString},
+		"eventoNumero":                 {Column: "e.Evento", Type: FieldInt},
+		"eventoDescricao":              {Column: "e.Descricao", Type: FieldString},
+	},
+	Key: "idObjetoContabilizacaoEvento",
+}
+
+// GetAll retorna as relações entre objetos de contabilização e eventos com paginação, ordenação e filtros
+func (r *ObjetoContabilizacaoEventoRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[ObjetoContabilizacaoEvento], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		oce.idObjetoContabilizacaoEvento, oce.idObjetoContabilizacao, oce.idCodigoEvento, 
@@ -75,14 +120,13 @@ func (r *ObjetoContabilizacaoEventoRepository) GetAll() ([]ObjetoContabilizacaoE
 	FROM objeto_contabilizacao_evento oce
 	JOIN objeto_contabilizacao oc ON oce.idObjetoContabilizacao = oc.idObjetoContabilizacao
 	JOIN eventos e ON oce.idCodigoEvento = e.idCodigoEvento
-	ORDER BY oce.idObjetoContabilizacaoEvento DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar relações: %v", err)
-	}
-	defer rows.Close()
+	WHERE ` + r.scope.condition("oce.idSeguradora")
 	
+	return listPage(ctx, r.DB, &objetoContabilizacaoEventoListSpec, opts, query, r.scope.args(), scanObjetosContabilizacaoEvento)
+}
+
+// scanObjetosContabilizacaoEvento lê as relações retornadas por uma consulta
+func scanObjetosContabilizacaoEvento(rows *sql.Rows) ([]ObjetoContabilizacaoEvento, error) {
 	var relacoes []ObjetoContabilizacaoEvento
 	
 	for rows.Next() {
@@ -99,20 +143,23 @@ func (r *ObjetoContabilizacaoEventoRepository) GetAll() ([]ObjetoContabilizacaoE
 			&r.EventoNumero,
 			&r.EventoDescricao,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler relação: %v", err)
+			return nil, fmt.Errorf("erro ao ler relação: %w", err)
 		}
 		relacoes = append(relacoes, r)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre relações: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre relações: %w", err)
 	}
 	
 	return relacoes, nil
 }
 
 // GetByID busca uma relação pelo ID
-func (r *ObjetoContabilizacaoEventoRepository) GetByID(id int64) (*ObjetoContabilizacaoEvento, error) {
+func (r *ObjetoContabilizacaoEventoRepository) GetByID(ctx context.Context, id int64) (*ObjetoContabilizacaoEvento, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		oce.idObjetoContabilizacaoEvento, oce.idObjetoContabilizacao, oce.idCodigoEvento, 
@@ -121,10 +168,10 @@ func (r *ObjetoContabilizacaoEventoRepository) GetByID(id int64) (*ObjetoContabi
 	FROM objeto_contabilizacao_evento oce
 	JOIN objeto_contabilizacao oc ON oce.idObjetoContabilizacao = oc.idObjetoContabilizacao
 	JOIN eventos e ON oce.idCodigoEvento = e.idCodigoEvento
-	WHERE oce.idObjetoContabilizacaoEvento = ?`
+	WHERE oce.idObjetoContabilizacaoEvento = ? AND ` + r.scope.condition("oce.idSeguradora")
 	
 	var rel ObjetoContabilizacaoEvento
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&rel.ID, 
 		&rel.IdObjetoContabilizacao, 
 		&rel.IdCodigoEvento, 
@@ -138,102 +185,97 @@ func (r *ObjetoContabilizacaoEventoRepository) GetByID(id int64) (*ObjetoContabi
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("relação não encontrada")
+			return nil, NotFoundError{Message: "relação não encontrada"}
 		}
-		return nil, fmt.Errorf("erro ao buscar relação: %v", err)
+		return nil, fmt.Errorf("erro ao buscar relação: %w", err)
 	}
 	
 	return &rel, nil
 }
 
-// GetBySeguradora busca relações por seguradora
-func (r *ObjetoContabilizacaoEventoRepository) GetBySeguradora(idSeguradora int64) ([]ObjetoContabilizacaoEvento, error) {
-	query := `
-	SELECT 
-		oce.idObjetoContabilizacaoEvento, oce.idObjetoContabilizacao, oce.idCodigoEvento, 
-		oce.idSeguradora, oce.created_at, oce.updated_at, oce.ativo,
-		oc.ObjetoContabilizacao, e.Evento, e.Descricao
-	FROM objeto_contabilizacao_evento oce
-	JOIN objeto_contabilizacao oc ON oce.idObjetoContabilizacao = oc.idObjetoContabilizacao
-	JOIN eventos e ON oce.idCodigoEvento = e.idCodigoEvento
-	WHERE oce.idSeguradora = ? 
-	ORDER BY oce.idObjetoContabilizacaoEvento DESC`
-	
-	rows, err := r.DB.Query(query, idSeguradora)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar relações por seguradora: %v", err)
+// GetBySeguradora busca relações por seguradora, com paginação, ordenação e filtros
+func (r *ObjetoContabilizacaoEventoRepository) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[ObjetoContabilizacaoEvento], error) {
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
 	}
-	defer rows.Close()
-	
-	var relacoes []ObjetoContabilizacaoEvento
 	
-	for rows.Next() {
-		var r ObjetoContabilizacaoEvento
-		if err := rows.Scan(
-			&r.ID, 
-			&r.IdObjetoContabilizacao, 
-			&r.IdCodigoEvento, 
-			&r.IdSeguradora, 
-			&r.CreatedAt, 
-			&r.UpdatedAt, 
-			&r.Ativo,
-			&r.ObjetoContabilizacaoNome,
-			&r.EventoNumero,
-			&r.EventoDescricao,
-		); err != nil {
-			return nil, fmt.Errorf("erro ao ler relação: %v", err)
-		}
-		relacoes = append(relacoes, r)
-	}
-	
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre relações: %v", err)
-	}
-	
-	return relacoes, nil
+	return r.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
 }
 
 // Update atualiza os dados de uma relação existente
-func (r *ObjetoContabilizacaoEventoRepository) Update(relacao *ObjetoContabilizacaoEvento) error {
+func (r *ObjetoContabilizacaoEventoRepository) Update(ctx context.Context, relacao *ObjetoContabilizacaoEvento) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados da relação
 	if err := validateObjetoContabilizacaoEvento(relacao); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(relacao.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	query := `
 	UPDATE objeto_contabilizacao_evento 
 	SET idObjetoContabilizacao = ?, idCodigoEvento = ?, idSeguradora = ?, ativo = ? 
-	WHERE idObjetoContabilizacaoEvento = ?`
+	WHERE idObjetoContabilizacaoEvento = ? AND ` + r.scope.condition("idSeguradora")
 	
-	_, err := r.DB.Exec(
-		query, 
-		relacao.IdObjetoContabilizacao, 
-		relacao.IdCodigoEvento, 
-		relacao.IdSeguradora, 
-		relacao.Ativo, 
-		relacao.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar relação: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "objeto_contabilizacao_evento", "idObjetoContabilizacaoEvento", relacao.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				relacao.IdObjetoContabilizacao, 
+				relacao.IdCodigoEvento, 
+				relacao.IdSeguradora, 
+				relacao.Ativo, 
+				relacao.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar relação: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeObjetoContabilizacaoEvento, relacao.ID, anterior, relacao)
+	})
 }
 
 // Delete remove uma relação do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *ObjetoContabilizacaoEventoRepository) Delete(id int64) error {
+func (r *ObjetoContabilizacaoEventoRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM objeto_contabilizacao_evento WHERE idObjetoContabilizacaoEvento = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE objeto_contabilizacao_evento SET ativo = false WHERE idObjetoContabilizacaoEvento = ?`
+	query := `UPDATE objeto_contabilizacao_evento SET ativo = false WHERE idObjetoContabilizacaoEvento = ? AND ` + r.scope.condition("idSeguradora")
 	
-	_, err := r.DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("erro ao excluir relação: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "objeto_contabilizacao_evento", "idObjetoContabilizacaoEvento", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir relação: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeObjetoContabilizacaoEvento, id, anterior, &excluido)
+	})
 }
 
 // validateObjetoContabilizacaoEvento valida os dados de uma relação
@@ -0,0 +1,435 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// Permissao representa uma permissão nomeada no sistema (ex.: "eventos:read")
+type Permissao struct {
+	ID        int64     `json:"id"`
+	Nome      string    `json:"nome"`
+	Descricao string    `json:"descricao"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Ativo     bool      `json:"ativo"`
+}
+
+// Recursos protegidos por permissões de leitura e escrita
+var RecursosProtegidos = []string{
+	"usuarios",
+	"tipos-perfil",
+	"seguradoras",
+	"eventos",
+	"objetos-contabilizacao",
+	"objetos-contabilizacao-eventos",
+	"sistemas-contabeis",
+	"sistemas-contabeis-config",
+	"plano-contas",
+	"lancamentos",
+	"auditoria",
+	"status",
+}
+
+// PermissaoLeitura retorna o nome da permissão de leitura de um recurso
+func PermissaoLeitura(recurso string) string {
+	return recurso + ":read"
+}
+
+// PermissaoEscrita retorna o nome da permissão de escrita de um recurso
+func PermissaoEscrita(recurso string) string {
+	return recurso + ":write"
+}
+
+// Formato aceito para nomes de permissões: "recurso:acao"
+var permissaoNomeRegex = regexp.MustCompile(`^[a-z0-9-]+:[a-z0-9-]+$`)
+
+// PermissaoRepository gerencia operações de banco de dados para permissões
+type PermissaoRepository struct {
+	DB    *sql.DB
+	actor *Actor
+}
+
+// NewPermissaoRepository cria um novo repositório de permissões
+func NewPermissaoRepository(db *sql.DB) *PermissaoRepository {
+	return &PermissaoRepository{DB: db}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *PermissaoRepository) WithActor(actor Actor) PermissaoStore {
+	return &PermissaoRepository{DB: r.DB, actor: &actor}
+}
+
+// Create insere uma nova permissão no banco de dados
+func (r *PermissaoRepository) Create(ctx context.Context, permissao *Permissao) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Validar dados da permissão
+	if err := validatePermissao(permissao); err != nil {
+		return err
+	}
+	
+	// Sanitizar dados
+	permissao.Descricao = utils.SanitizeString(permissao.Descricao)
+	
+	query := `
+	INSERT INTO permissoes
+	(nome, descricao, ativo)
+	VALUES (?, ?, ?)`
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "id_permissao",
+			query,
+			permissao.Nome,
+			permissao.Descricao,
+			permissao.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar permissão: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID da permissão: %w", err)
+		}
+		
+		permissao.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadePermissao, id, nil, permissao)
+	})
+}
+
+// permissaoListSpec define os campos de ordenação e filtro da listagem de permissões
+var permissaoListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"id":         {Column: "id_permissao", Type: FieldInt},
+		"nome":       {Column: "nome", Type: FieldString},
+		"descricao":  {Column: "descricao", Type: FieldString},
+		"created_at": {Column: "created_at", Type: FieldTime},
+		"updated_at": {Column: "updated_at", Type: FieldTime},
+		"ativo":      {Column: "ativo", Type: FieldBool},
+	},
+	Key: "id",
+}
+
+// GetAll retorna as permissões com paginação, ordenação e filtros (por padrão, ordenadas pelo nome)
+func (r *PermissaoRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[Permissao], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT
+		id_permissao, nome, descricao, created_at, updated_at, ativo
+	FROM permissoes
+	WHERE 1 = 1`
+	
+	if len(opts.Sort) == 0 {
+		opts.Sort = []SortField{{Field: "nome"}}
+	}
+	
+	return listPage(ctx, r.DB, &permissaoListSpec, opts, query, nil, scanPermissoes)
+}
+
+// GetByID busca uma permissão pelo ID
+func (r *PermissaoRepository) GetByID(ctx context.Context, id int64) (*Permissao, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT
+		id_permissao, nome, descricao, created_at, updated_at, ativo
+	FROM permissoes
+	WHERE id_permissao = ?`
+	
+	var p Permissao
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, id).Scan(
+		&p.ID,
+		&p.Nome,
+		&p.Descricao,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+		&p.Ativo,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFoundError{Message: "permissão não encontrada"}
+		}
+		return nil, fmt.Errorf("erro ao buscar permissão: %w", err)
+	}
+	
+	return &p, nil
+}
+
+// GetByTipoPerfil retorna as permissões associadas a um tipo de perfil
+func (r *PermissaoRepository) GetByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]Permissao, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT
+		p.id_permissao, p.nome, p.descricao, p.created_at, p.updated_at, p.ativo
+	FROM permissoes p
+	JOIN tipo_perfil_permissao tpp ON tpp.id_permissao = p.id_permissao
+	WHERE tpp.id_tipo_perfil = ?
+	ORDER BY p.nome`
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, query, idTipoPerfil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar permissões do tipo de perfil: %w", err)
+	}
+	defer rows.Close()
+	
+	return scanPermissoes(rows)
+}
+
+// GetNomesByTipoPerfil retorna os nomes das permissões ativas de um tipo de perfil ativo
+func (r *PermissaoRepository) GetNomesByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]string, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT p.nome
+	FROM permissoes p
+	JOIN tipo_perfil_permissao tpp ON tpp.id_permissao = p.id_permissao
+	JOIN tipo_perfil tp ON tp.id_tipo_perfil = tpp.id_tipo_perfil
+	WHERE tpp.id_tipo_perfil = ? AND p.ativo = true AND tp.ativo = true`
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, query, idTipoPerfil)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar permissões do tipo de perfil: %w", err)
+	}
+	defer rows.Close()
+	
+	var nomes []string
+	
+	for rows.Next() {
+		var nome string
+		if err := rows.Scan(&nome); err != nil {
+			return nil, fmt.Errorf("erro ao ler permissão: %w", err)
+		}
+		nomes = append(nomes, nome)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre permissões: %w", err)
+	}
+	
+	return nomes, nil
+}
+
+// Update atualiza os dados de uma permissão existente
+func (r *PermissaoRepository) Update(ctx context.Context, permissao *Permissao) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Validar dados da permissão
+	if err := validatePermissao(permissao); err != nil {
+		return err
+	}
+	
+	// Sanitizar dados
+	permissao.Descricao = utils.SanitizeString(permissao.Descricao)
+	
+	query := `
+	UPDATE permissoes
+	SET nome = ?, descricao = ?, ativo = ?
+	WHERE id_permissao = ?`
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, nil, "permissoes", "id_permissao", permissao.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query,
+			permissao.Nome,
+			permissao.Descricao,
+			permissao.Ativo,
+			permissao.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar permissão: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadePermissao, permissao.ID, anterior, permissao)
+	})
+}
+
+// Delete desativa uma permissão (exclusão lógica)
+func (r *PermissaoRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `UPDATE permissoes SET ativo = false WHERE id_permissao = ?`
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, nil, "permissoes", "id_permissao", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("erro ao excluir permissão: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadePermissao, id, anterior, &excluido)
+	})
+}
+
+// Grant concede uma permissão (pelo nome) a um tipo de perfil
+func (r *PermissaoRepository) Grant(ctx context.Context, idTipoPerfil int64, nome string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := dialect.Current().InsertIgnore(`
+	INSERT INTO tipo_perfil_permissao (id_tipo_perfil, id_permissao)
+	SELECT tp.id_tipo_perfil, p.id_permissao FROM tipo_perfil tp, permissoes p
+	WHERE tp.id_tipo_perfil = ? AND p.nome = ?`)
+	
+	result, err := connFor(ctx, r.DB).ExecContext(ctx, query, idTipoPerfil, nome)
+	if err != nil {
+		return fmt.Errorf("erro ao conceder permissão: %w", err)
+	}
+	
+	// Verificar se a permissão existe (nenhuma linha afetada pode indicar que já estava concedida)
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		var count int
+		if err := connFor(ctx, r.DB).QueryRowContext(ctx, "SELECT COUNT(*) FROM permissoes WHERE nome = ?", nome).Scan(&count); err != nil {
+			return fmt.Errorf("erro ao verificar permissão: %w", err)
+		}
+		if count == 0 {
+			return utils.ValidationError{Field: "permissao", Message: "permissão não encontrada: " + nome}
+		}
+	}
+	
+	return nil
+}
+
+// Revoke remove uma permissão (pelo nome) de um tipo de perfil
+func (r *PermissaoRepository) Revoke(ctx context.Context, idTipoPerfil int64, nome string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	DELETE FROM tipo_perfil_permissao
+	WHERE id_tipo_perfil = ? AND id_permissao IN (SELECT id_permissao FROM permissoes WHERE nome = ?)`
+	
+	_, err := connFor(ctx, r.DB).ExecContext(ctx, query, idTipoPerfil, nome)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar permissão: %w", err)
+	}
+	
+	return nil
+}
+
+// ReplaceForTipoPerfil substitui todas as permissões de um tipo de perfil pelas informadas
+func (r *PermissaoRepository) ReplaceForTipoPerfil(ctx context.Context, idTipoPerfil int64, nomes []string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Remover as permissões atuais
+	if _, err := tx.ExecContext(ctx, "DELETE FROM tipo_perfil_permissao WHERE id_tipo_perfil = ?", idTipoPerfil); err != nil {
+		return fmt.Errorf("erro ao remover permissões: %w", err)
+	}
+	
+	// Conceder as novas permissões
+	for _, nome := range nomes {
+		var idPermissao int64
+		err := tx.QueryRowContext(ctx, "SELECT id_permissao FROM permissoes WHERE nome = ?", nome).Scan(&idPermissao)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return utils.ValidationError{Field: "permissoes", Message: "permissão não encontrada: " + nome}
+			}
+			return fmt.Errorf("erro ao buscar permissão: %w", err)
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			dialect.Current().InsertIgnore("INSERT INTO tipo_perfil_permissao (id_tipo_perfil, id_permissao) VALUES (?, ?)"),
+			idTipoPerfil, idPermissao,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao conceder permissão: %w", err)
+		}
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return nil
+}
+
+// scanPermissoes lê as permissões retornadas por uma consulta
+func scanPermissoes(rows *sql.Rows) ([]Permissao, error) {
+	var permissoes []Permissao
+	
+	for rows.Next() {
+		var p Permissao
+		if err := rows.Scan(
+			&p.ID,
+			&p.Nome,
+			&p.Descricao,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&p.Ativo,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao ler permissão: %w", err)
+		}
+		permissoes = append(permissoes, p)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre permissões: %w", err)
+	}
+	
+	return permissoes, nil
+}
+
+// validatePermissao valida os dados de uma permissão
+func validatePermissao(p *Permissao) error {
+	// Validar nome da permissão
+	p.Nome = strings.ToLower(strings.TrimSpace(p.Nome))
+	if err := utils.ValidateRequired("nome", p.Nome); err != nil {
+		return err
+	}
+	if err := utils.ValidateLength("nome", p.Nome, 3, 100); err != nil {
+		return err
+	}
+	if !permissaoNomeRegex.MatchString(p.Nome) {
+		return utils.ValidationError{
+			Field:   "nome",
+			Message: "deve estar no formato recurso:acao (ex.: eventos:read)",
+		}
+	}
+	
+	// Validar descrição (opcional)
+	if p.Descricao != "" {
+		if err := utils.ValidateLength("descricao", p.Descricao, 3, 255); err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
@@ -0,0 +1,900 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// Tipos de conta do plano de contas
+const (
+	TipoContaSintetica = "S" // Agrupa outras contas, não recebe lançamentos
+	TipoContaAnalitica = "A" // Recebe lançamentos
+)
+
+// ContaContabil representa uma conta do plano de contas de uma seguradora e sistema contábil
+type ContaContabil struct {
+	ID                int64     `json:"idConta"`
+	IdSeguradora      int64     `json:"idSeguradora"`
+	IdSistemaContabil int64     `json:"idSistemaContabil"`
+	Codigo            string    `json:"codigo"`
+	Descricao         string    `json:"descricao"`
+	Natureza          string    `json:"natureza"` // "D" (devedora) ou "C" (credora)
+	Tipo              string    `json:"tipo"`     // "S" (sintética) ou "A" (analítica)
+	IdContaPai        *int64    `json:"idContaPai"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+	Ativo             bool      `json:"ativo"`
+	// Campo para exibição de informações relacionadas
+	CodigoContaPai string `json:"codigoContaPai,omitempty"`
+}
+
+// ErroImportacao representa um erro em uma linha de um arquivo importado
+type ErroImportacao struct {
+	Linha    int    `json:"linha"`
+	Codigo   string `json:"codigo,omitempty"`
+	Mensagem string `json:"mensagem"`
+}
+
+// ResultadoImportacaoPlanoContas representa o resultado da importação de um plano de contas
+type ResultadoImportacaoPlanoContas struct {
+	Criadas     int              `json:"criadas"`
+	Atualizadas int              `json:"atualizadas"`
+	Erros       []ErroImportacao `json:"erros,omitempty"`
+}
+
+// LinhaPlanoContas representa uma linha de um arquivo de importação do plano de contas
+type LinhaPlanoContas struct {
+	Linha     int
+	Conta     ContaContabil
+	CodigoPai string
+}
+
+// rowQuerier representa um banco ou transação capaz de consultar uma única linha
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// contaLookup consulta os sistemas contábeis e as contas usados nas validações do plano de contas
+// e das configurações de sistema contábil
+type contaLookup interface {
+	// seguradoraDoSistema retorna a seguradora do sistema contábil, ou sql.ErrNoRows se ele não existir
+	seguradoraDoSistema(idSistemaContabil int64) (int64, error)
+	// conta retorna o plano, o tipo, a conta pai e a situação de uma conta, ou sql.ErrNoRows se ela não existir
+	conta(idConta int64) (*ContaContabil, error)
+}
+
+// sqlContaLookup consulta os sistemas contábeis e as contas no banco de dados ou em uma transação
+type sqlContaLookup struct {
+	ctx context.Context
+	q   rowQuerier
+}
+
+func (l sqlContaLookup) seguradoraDoSistema(idSistemaContabil int64) (int64, error) {
+	var idSeguradora int64
+	err := l.q.QueryRowContext(l.ctx, "SELECT idSeguradora FROM sistema_contabil WHERE idSistemaContabil = ?", idSistemaContabil).Scan(&idSeguradora)
+	return idSeguradora, err
+}
+
+func (l sqlContaLookup) conta(idConta int64) (*ContaContabil, error) {
+	c := &ContaContabil{ID: idConta}
+	var idPai sql.NullInt64
+	err := l.q.QueryRowContext(l.ctx, 
+		"SELECT idSeguradora, idSistemaContabil, Tipo, idContaPai, ativo FROM plano_contas WHERE idConta = ?",
+		idConta,
+	).Scan(&c.IdSeguradora, &c.IdSistemaContabil, &c.Tipo, &idPai, &c.Ativo)
+	if err != nil {
+		return nil, err
+	}
+	if idPai.Valid {
+		c.IdContaPai = &idPai.Int64
+	}
+	return c, nil
+}
+
+// PlanoContasRepository gerencia operações de banco de dados para o plano de contas
+type PlanoContasRepository struct {
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
+}
+
+// NewPlanoContasRepository cria um novo repositório do plano de contas
+func NewPlanoContasRepository(db *sql.DB) *PlanoContasRepository {
+	return &PlanoContasRepository{DB: db}
+}
+
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *PlanoContasRepository) WithTenant(scope TenantScope) PlanoContasStore {
+	return &PlanoContasRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *PlanoContasRepository) WithActor(actor Actor) PlanoContasStore {
+	return &PlanoContasRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
+// Create insere uma nova conta no plano de contas
+func (r *PlanoContasRepository) Create(ctx context.Context, conta *ContaContabil) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Validar dados da conta
+	if err := validateContaContabil(conta); err != nil {
+		return err
+	}
+	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(conta.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	// Validar o sistema contábil e a conta pai
+	if err := validateHierarquiaConta(sqlContaLookup{ctx, connFor(ctx, r.DB)}, conta); err != nil {
+		return err
+	}
+	
+	// Sanitizar dados
+	conta.Descricao = utils.SanitizeString(conta.Descricao)
+	
+	query := `
+	INSERT INTO plano_contas 
+	(idSeguradora, idSistemaContabil, Codigo, Descricao, Natureza, Tipo, idContaPai, ativo) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "idConta",
+			query, 
+			conta.IdSeguradora, 
+			conta.IdSistemaContabil, 
+			conta.Codigo, 
+			conta.Descricao, 
+			conta.Natureza, 
+			conta.Tipo, 
+			conta.IdContaPai, 
+			conta.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar conta: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID da conta: %w", err)
+		}
+		
+		conta.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeContaContabil, id, nil, conta)
+	})
+}
+
+// Colunas consultadas do plano de contas (com o código da conta pai)
+const planoContasColumns = `
+		pc.idConta, pc.idSeguradora, pc.idSistemaContabil, pc.Codigo, pc.Descricao, 
+		pc.Natureza, pc.Tipo, pc.idContaPai, pc.created_at, pc.updated_at, pc.ativo, 
+		COALESCE(pai.Codigo, '')`
+
+// planoContasListSpec define os campos de ordenação e filtro da listagem do plano de contas
+var planoContasListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idConta":           {Column: "pc.idConta", Type: FieldInt},
+		"idSeguradora":      {Column: "pc.idSeguradora", Type: FieldInt},
+		"idSistemaContabil": {Column: "pc.idSistemaContabil", Type: FieldInt},
+		"codigo":            {Column: "pc.Codigo", Type: FieldString},
+		"descricao":         {Column: "pc.Descricao", Type: FieldString},
+		"natureza":          {Column: "pc.Natureza", Type: FieldString},
+		"tipo":              {Column: "pc.Tipo", Type: FieldString},
+		"created_at":        {Column: "pc.created_at", Type: FieldTime},
+		"updated_at":        {Column: "pc.updated_at", Type: FieldTime},
+		"ativo":             {Column: "pc.ativo", Type: FieldBool},
+		"codigoContaPai":    {Column: "COALESCE(pai.Codigo, '')", Type: FieldString},
+	},
+	Key: "idConta",
+}
+
+// GetAll retorna as contas do plano de contas com paginação, ordenação e filtros
+// (por padrão, ordenadas por seguradora, sistema contábil e código)
+func (r *PlanoContasRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[ContaContabil], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT ` + planoContasColumns + `
+	FROM plano_contas pc 
+	LEFT JOIN plano_contas pai ON pc.idContaPai = pai.idConta 
+	WHERE ` + r.scope.condition("pc.idSeguradora")
+	
+	if len(opts.Sort) == 0 {
+		opts.Sort = []SortField{{Field: "idSeguradora"}, {Field: "idSistemaContabil"}, {Field: "codigo"}}
+	}
+	
+	return listPage(ctx, r.DB, &planoContasListSpec, opts, query, r.scope.args(), scanContasContabeis)
+}
+
+// GetBySistemaContabil retorna o plano de contas de um sistema contábil, com paginação, ordenação e filtros
+// (por padrão, ordenado pelo código)
+func (r *PlanoContasRepository) GetBySistemaContabil(ctx context.Context, idSistemaContabil int64, opts ListOptions) (*Page[ContaContabil], error) {
+	if len(opts.Sort) == 0 {
+		opts.Sort = []SortField{{Field: "codigo"}}
+	}
+	
+	return r.GetAll(ctx, opts.WithFilter("idSistemaContabil", idSistemaContabil))
+}
+
+// GetByID busca uma conta pelo ID
+func (r *PlanoContasRepository) GetByID(ctx context.Context, id int64) (*ContaContabil, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT ` + planoContasColumns + `
+	FROM plano_contas pc 
+	LEFT JOIN plano_contas pai ON pc.idContaPai = pai.idConta 
+	WHERE pc.idConta = ? AND ` + r.scope.condition("pc.idSeguradora")
+	
+	var c ContaContabil
+	var idContaPai sql.NullInt64
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
+		&c.ID, 
+		&c.IdSeguradora, 
+		&c.IdSistemaContabil, 
+		&c.Codigo, 
+		&c.Descricao, 
+		&c.Natureza, 
+		&c.Tipo, 
+		&idContaPai, 
+		&c.CreatedAt, 
+		&c.UpdatedAt, 
+		&c.Ativo,
+		&c.CodigoContaPai,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, NotFoundError{Message: "conta não encontrada"}
+		}
+		return nil, fmt.Errorf("erro ao buscar conta: %w", err)
+	}
+	
+	if idContaPai.Valid {
+		c.IdContaPai = &idContaPai.Int64
+	}
+	
+	return &c, nil
+}
+
+// Update atualiza os dados de uma conta existente
+func (r *PlanoContasRepository) Update(ctx context.Context, conta *ContaContabil) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	// Validar dados da conta
+	if err := validateContaContabil(conta); err != nil {
+		return err
+	}
+	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(conta.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
+	// Sanitizar dados
+	conta.Descricao = utils.SanitizeString(conta.Descricao)
+	
+	query := `
+	UPDATE plano_contas 
+	SET idSeguradora = ?, idSistemaContabil = ?, Codigo = ?, Descricao = ?, Natureza = ?, 
+	Tipo = ?, idContaPai = ?, ativo = ? 
+	WHERE idConta = ? AND ` + r.scope.condition("idSeguradora")
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações (o registro deve
+		// ser visível antes das demais verificações)
+		anterior, err := lockedBefore(ctx, tx, r.scope, "plano_contas", "idConta", conta.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		// Validar o sistema contábil e a conta pai (incluindo referências circulares)
+		if err := validateHierarquiaConta(sqlContaLookup{ctx, tx}, conta); err != nil {
+			return err
+		}
+		
+		// Uma conta com contas filhas ativas precisa continuar sintética
+		if conta.Tipo == TipoContaAnalitica {
+			var filhas int
+			err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM plano_contas WHERE idContaPai = ? AND ativo = true", conta.ID).Scan(&filhas)
+			if err != nil {
+				return fmt.Errorf("erro ao verificar contas filhas: %w", err)
+			}
+			if filhas > 0 {
+				return utils.ValidationError{
+					Field:   "tipo",
+					Message: "conta com contas filhas ativas deve ser sintética",
+				}
+			}
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				conta.IdSeguradora, 
+				conta.IdSistemaContabil, 
+				conta.Codigo, 
+				conta.Descricao, 
+				conta.Natureza, 
+				conta.Tipo, 
+				conta.IdContaPai, 
+				conta.Ativo, 
+				conta.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar conta: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeContaContabil, conta.ID, anterior, conta)
+	})
+}
+
+// Delete desativa uma conta do plano de contas (exclusão lógica)
+func (r *PlanoContasRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `UPDATE plano_contas SET ativo = false WHERE idConta = ? AND ` + r.scope.condition("idSeguradora")
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com a conta bloqueada, para o histórico de alterações (a conta deve ser
+		// visível antes das demais verificações)
+		anterior, err := lockedBefore(ctx, tx, r.scope, "plano_contas", "idConta", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluida := *anterior
+		excluida.Ativo = false
+		
+		// Não permitir desativar contas que ainda possuem contas filhas ativas
+		var filhas int
+		err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM plano_contas WHERE idContaPai = ? AND ativo = true", id).Scan(&filhas)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar contas filhas: %w", err)
+		}
+		if filhas > 0 {
+			return ConflictError{Message: "conta possui contas filhas ativas"}
+		}
+		
+		// Não permitir desativar contas usadas por configurações ativas
+		var configs int
+		err = tx.QueryRowContext(ctx, 
+			"SELECT COUNT(*) FROM sistema_contabil_config WHERE (idContaDebito = ? OR idContaCredito = ?) AND ativo = true",
+			id, id,
+		).Scan(&configs)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar configurações da conta: %w", err)
+		}
+		if configs > 0 {
+			return ConflictError{Message: "conta utilizada por configurações de sistema contábil ativas"}
+		}
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir conta: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeContaContabil, id, anterior, &excluida)
+	})
+}
+
+// contaImportada representa uma conta já existente ou gravada durante a importação
+type contaImportada struct {
+	id       int64
+	tipo     string
+	idPai    int64
+	anterior *ContaContabil // Estado gravado antes da importação, para o histórico de alterações
+}
+
+// Import importa (cria ou atualiza pelo código) as contas de um plano de contas em uma única transação.
+// Se qualquer linha for inválida, nenhuma alteração é gravada e os erros de cada linha são retornados.
+func (r *PlanoContasRepository) Import(ctx context.Context, idSeguradora, idSistemaContabil int64, linhas []LinhaPlanoContas) (*ResultadoImportacaoPlanoContas, error) {
+	// Verificar se o plano pertence à seguradora do usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
+	}
+	
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Validar o sistema contábil de destino
+	if err := validateSistemaDaConta(sqlContaLookup{ctx, tx}, idSeguradora, idSistemaContabil); err != nil {
+		return nil, err
+	}
+	
+	// Carregar as contas existentes do plano
+	existentes := make(map[string]*contaImportada)
+	rows, err := tx.QueryContext(ctx, 
+		"SELECT idConta, Codigo, Descricao, Natureza, Tipo, idContaPai, ativo FROM plano_contas WHERE idSeguradora = ? AND idSistemaContabil = ?",
+		idSeguradora, idSistemaContabil,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar plano de contas: %w", err)
+	}
+	for rows.Next() {
+		conta := &ContaContabil{IdSeguradora: idSeguradora, IdSistemaContabil: idSistemaContabil}
+		var idContaPai sql.NullInt64
+		if err := rows.Scan(&conta.ID, &conta.Codigo, &conta.Descricao, &conta.Natureza, &conta.Tipo, &idContaPai, &conta.Ativo); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("erro ao ler conta: %w", err)
+		}
+		if idContaPai.Valid {
+			conta.IdContaPai = &idContaPai.Int64
+		}
+		existentes[conta.Codigo] = &contaImportada{id: conta.ID, tipo: conta.Tipo, idPai: idContaPai.Int64, anterior: conta}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre contas: %w", err)
+	}
+	
+	resultado, err := importarPlanoContas(idSeguradora, idSistemaContabil, linhas, existentes, &sqlGravadorPlanoContas{ctx: ctx, tx: tx.Tx, actor: r.actor})
+	if err != nil {
+		return nil, err
+	}
+	
+	// Qualquer erro cancela toda a importação
+	if len(resultado.Erros) > 0 {
+		resultado.Criadas = 0
+		resultado.Atualizadas = 0
+		return resultado, nil
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return resultado, nil
+}
+
+// gravadorPlanoContas grava as contas de uma importação do plano de contas
+type gravadorPlanoContas interface {
+	criar(conta *ContaContabil) error                    // Insere a conta (ativa), preenchendo o ID
+	atualizar(anterior, atualizada *ContaContabil) error // Atualiza uma conta existente do plano
+}
+
+// importarPlanoContas valida as linhas de um arquivo do plano de contas e grava as contas válidas,
+// criando-as ou atualizando as existentes pelo código. Com qualquer erro nas linhas, as gravações
+// devem ser descartadas por quem chamou.
+func importarPlanoContas(idSeguradora, idSistemaContabil int64, linhas []LinhaPlanoContas, existentes map[string]*contaImportada, gravador gravadorPlanoContas) (*ResultadoImportacaoPlanoContas, error) {
+	resultado := &ResultadoImportacaoPlanoContas{}
+	
+	// Validar os dados de cada linha
+	var pendentes []LinhaPlanoContas
+	codigos := make(map[string]int)
+	for _, linha := range linhas {
+		linha.Conta.IdSeguradora = idSeguradora
+		linha.Conta.IdSistemaContabil = idSistemaContabil
+		linha.Conta.Ativo = true
+		
+		if err := validateContaContabil(&linha.Conta); err != nil {
+			resultado.Erros = append(resultado.Erros, ErroImportacao{Linha: linha.Linha, Codigo: linha.Conta.Codigo, Mensagem: err.Error()})
+			continue
+		}
+		if anterior, ok := codigos[linha.Conta.Codigo]; ok {
+			resultado.Erros = append(resultado.Erros, ErroImportacao{
+				Linha:    linha.Linha,
+				Codigo:   linha.Conta.Codigo,
+				Mensagem: fmt.Sprintf("código duplicado no arquivo (linha %d)", anterior),
+			})
+			continue
+		}
+		codigos[linha.Conta.Codigo] = linha.Linha
+		pendentes = append(pendentes, linha)
+	}
+	
+	// Gravar as contas cujas contas pai já estão disponíveis, repetindo até não haver progresso,
+	// para que a ordem das linhas no arquivo não importe
+	for len(pendentes) > 0 {
+		var restantes []LinhaPlanoContas
+		
+		for _, linha := range pendentes {
+			conta := linha.Conta
+			conta.IdContaPai = nil
+			
+			if linha.CodigoPai != "" {
+				pai, ok := existentes[linha.CodigoPai]
+				if !ok {
+					// A conta pai pode ser criada por uma linha posterior do arquivo
+					if _, noArquivo := codigos[linha.CodigoPai]; noArquivo {
+						restantes = append(restantes, linha)
+						continue
+					}
+					resultado.Erros = append(resultado.Erros, ErroImportacao{
+						Linha:    linha.Linha,
+						Codigo:   conta.Codigo,
+						Mensagem: fmt.Sprintf("conta pai %s não encontrada", linha.CodigoPai),
+					})
+					continue
+				}
+				if pai.tipo != TipoContaSintetica {
+					resultado.Erros = append(resultado.Erros, ErroImportacao{
+						Linha:    linha.Linha,
+						Codigo:   conta.Codigo,
+						Mensagem: fmt.Sprintf("conta pai %s deve ser sintética", linha.CodigoPai),
+					})
+					continue
+				}
+				idPai := pai.id
+				conta.IdContaPai = &idPai
+			}
+			
+			conta.Descricao = utils.SanitizeString(conta.Descricao)
+			
+			if existente, ok := existentes[conta.Codigo]; ok {
+				// Impedir referências circulares ao mover uma conta existente
+				if conta.IdContaPai != nil && contaDescendeDe(existentes, *conta.IdContaPai, existente.id) {
+					resultado.Erros = append(resultado.Erros, ErroImportacao{
+						Linha:    linha.Linha,
+						Codigo:   conta.Codigo,
+						Mensagem: "conta pai não pode ser a própria conta ou uma de suas filhas",
+					})
+					continue
+				}
+				
+				atualizada := *existente.anterior
+				atualizada.Descricao = conta.Descricao
+				atualizada.Natureza = conta.Natureza
+				atualizada.Tipo = conta.Tipo
+				atualizada.IdContaPai = conta.IdContaPai
+				atualizada.Ativo = true
+				if err := gravador.atualizar(existente.anterior, &atualizada); err != nil {
+					return nil, err
+				}
+				
+				existente.tipo = conta.Tipo
+				existente.idPai = 0
+				if conta.IdContaPai != nil {
+					existente.idPai = *conta.IdContaPai
+				}
+				resultado.Atualizadas++
+				continue
+			}
+			
+			if err := gravador.criar(&conta); err != nil {
+				return nil, err
+			}
+			
+			nova := &contaImportada{id: conta.ID, tipo: conta.Tipo}
+			if conta.IdContaPai != nil {
+				nova.idPai = *conta.IdContaPai
+			}
+			existentes[conta.Codigo] = nova
+			resultado.Criadas++
+		}
+		
+		// Sem progresso: as contas restantes referenciam contas pai que nunca serão criadas (ciclo no arquivo)
+		if len(restantes) == len(pendentes) {
+			for _, linha := range restantes {
+				resultado.Erros = append(resultado.Erros, ErroImportacao{
+					Linha:    linha.Linha,
+					Codigo:   linha.Conta.Codigo,
+					Mensagem: fmt.Sprintf("conta pai %s não pôde ser importada", linha.CodigoPai),
+				})
+			}
+			break
+		}
+		pendentes = restantes
+	}
+	
+	return resultado, nil
+}
+
+// sqlGravadorPlanoContas grava as contas importadas na transação da importação, registrando o histórico
+type sqlGravadorPlanoContas struct {
+	ctx   context.Context
+	tx    *sql.Tx
+	actor *Actor
+}
+
+func (g *sqlGravadorPlanoContas) criar(conta *ContaContabil) error {
+	result, err := execInsert(g.ctx, g.tx, "idConta", `
+	INSERT INTO plano_contas 
+	(idSeguradora, idSistemaContabil, Codigo, Descricao, Natureza, Tipo, idContaPai, ativo) 
+	VALUES (?, ?, ?, ?, ?, ?, ?, true)`,
+		conta.IdSeguradora, conta.IdSistemaContabil, conta.Codigo, conta.Descricao,
+		conta.Natureza, conta.Tipo, conta.IdContaPai,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao criar conta %s: %w", conta.Codigo, err)
+	}
+	
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("erro ao obter ID da conta: %w", err)
+	}
+	
+	// Registrar a criação no histórico
+	conta.ID = id
+	return recordChange(g.ctx, g.tx, g.actor, AcaoCriacao, EntidadeContaContabil, id, nil, conta)
+}
+
+func (g *sqlGravadorPlanoContas) atualizar(anterior, atualizada *ContaContabil) error {
+	_, err := g.tx.ExecContext(g.ctx, `
+	UPDATE plano_contas 
+	SET Descricao = ?, Natureza = ?, Tipo = ?, idContaPai = ?, ativo = true 
+	WHERE idConta = ?`,
+		atualizada.Descricao, atualizada.Natureza, atualizada.Tipo, atualizada.IdContaPai, atualizada.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar conta %s: %w", atualizada.Codigo, err)
+	}
+	
+	// Registrar a alteração no histórico
+	return recordChange(g.ctx, g.tx, g.actor, AcaoAlteracao, EntidadeContaContabil, atualizada.ID, anterior, atualizada)
+}
+
+// ParsePlanoContasCSV lê um plano de contas em CSV (separado por vírgula ou ponto e vírgula).
+// O arquivo deve ter cabeçalho com as colunas: codigo, descricao, natureza, tipo e codigo_pai (opcional).
+func ParsePlanoContasCSV(reader io.Reader) ([]LinhaPlanoContas, []ErroImportacao, error) {
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler arquivo: %w", err)
+	}
+	
+	// Detectar o separador pela primeira linha
+	text := strings.TrimPrefix(string(content), "\ufeff")
+	primeiraLinha := strings.SplitN(text, "\n", 2)[0]
+	
+	csvReader := csv.NewReader(strings.NewReader(text))
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	if strings.Count(primeiraLinha, ";") > strings.Count(primeiraLinha, ",") {
+		csvReader.Comma = ';'
+	}
+	
+	cabecalho, err := csvReader.Read()
+	if err == io.EOF {
+		return nil, nil, fmt.Errorf("arquivo CSV vazio")
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("arquivo CSV inválido: %w", err)
+	}
+	
+	// Mapear as colunas pelo cabeçalho
+	colunas := make(map[string]int)
+	for i, nome := range cabecalho {
+		colunas[strings.ToLower(strings.TrimSpace(nome))] = i
+	}
+	for _, obrigatoria := range []string{"codigo", "descricao", "natureza", "tipo"} {
+		if _, ok := colunas[obrigatoria]; !ok {
+			return nil, nil, fmt.Errorf("coluna obrigatória ausente no cabeçalho: %s", obrigatoria)
+		}
+	}
+	
+	valor := func(record []string, coluna string) string {
+		i, ok := colunas[coluna]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+	
+	var linhas []LinhaPlanoContas
+	var erros []ErroImportacao
+	
+	for {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("arquivo CSV inválido: %w", err)
+		}
+		
+		// Número da linha no arquivo (linhas em branco são ignoradas pelo leitor)
+		numero, _ := csvReader.FieldPos(0)
+		
+		if len(record) < len(cabecalho) && valor(record, "tipo") == "" {
+			erros = append(erros, ErroImportacao{Linha: numero, Codigo: valor(record, "codigo"), Mensagem: "quantidade de colunas inválida"})
+			continue
+		}
+		
+		linhas = append(linhas, LinhaPlanoContas{
+			Linha: numero,
+			Conta: ContaContabil{
+				Codigo:    valor(record, "codigo"),
+				Descricao: valor(record, "descricao"),
+				Natureza:  valor(record, "natureza"),
+				Tipo:      valor(record, "tipo"),
+			},
+			CodigoPai: valor(record, "codigo_pai"),
+		})
+	}
+	
+	return linhas, erros, nil
+}
+
+// scanContasContabeis lê as contas retornadas por uma consulta
+func scanContasContabeis(rows *sql.Rows) ([]ContaContabil, error) {
+	var contas []ContaContabil
+	
+	for rows.Next() {
+		var c ContaContabil
+		var idContaPai sql.NullInt64
+		if err := rows.Scan(
+			&c.ID, 
+			&c.IdSeguradora, 
+			&c.IdSistemaContabil, 
+			&c.Codigo, 
+			&c.Descricao, 
+			&c.Natureza, 
+			&c.Tipo, 
+			&idContaPai, 
+			&c.CreatedAt, 
+			&c.UpdatedAt, 
+			&c.Ativo,
+			&c.CodigoContaPai,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao ler conta: %w", err)
+		}
+		if idContaPai.Valid {
+			id := idContaPai.Int64
+			c.IdContaPai = &id
+		}
+		contas = append(contas, c)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre contas: %w", err)
+	}
+	
+	return contas, nil
+}
+
+// contaDescendeDe verifica se a conta informada é a própria conta ancestral ou uma de suas descendentes
+func contaDescendeDe(contas map[string]*contaImportada, idConta, idAncestral int64) bool {
+	porID := make(map[int64]*contaImportada, len(contas))
+	for _, c := range contas {
+		porID[c.id] = c
+	}
+	
+	// Subir pela hierarquia a partir da conta informada
+	for visitadas := 0; idConta != 0 && visitadas <= len(porID); visitadas++ {
+		if idConta == idAncestral {
+			return true
+		}
+		c, ok := porID[idConta]
+		if !ok {
+			return false
+		}
+		idConta = c.idPai
+	}
+	
+	return false
+}
+
+// validateSistemaDaConta verifica se o sistema contábil existe e pertence à seguradora da conta
+func validateSistemaDaConta(l contaLookup, idSeguradora, idSistemaContabil int64) error {
+	idSeguradoraSistema, err := l.seguradoraDoSistema(idSistemaContabil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return utils.ValidationError{Field: "idSistemaContabil", Message: "sistema contábil não encontrado"}
+		}
+		return fmt.Errorf("erro ao buscar sistema contábil: %w", err)
+	}
+	if idSeguradoraSistema != idSeguradora {
+		return utils.ValidationError{Field: "idSistemaContabil", Message: "sistema contábil pertence a outra seguradora"}
+	}
+	
+	return nil
+}
+
+// validateHierarquiaConta valida o sistema contábil e a conta pai de uma conta
+func validateHierarquiaConta(l contaLookup, c *ContaContabil) error {
+	if err := validateSistemaDaConta(l, c.IdSeguradora, c.IdSistemaContabil); err != nil {
+		return err
+	}
+	
+	if c.IdContaPai == nil {
+		return nil
+	}
+	
+	// Subir pela hierarquia a partir da conta pai, verificando o plano e referências circulares
+	idAtual := *c.IdContaPai
+	for nivel := 0; idAtual != 0; nivel++ {
+		if idAtual == c.ID || nivel > 100 {
+			return utils.ValidationError{Field: "idContaPai", Message: "conta pai não pode ser a própria conta ou uma de suas filhas"}
+		}
+		
+		pai, err := l.conta(idAtual)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return utils.ValidationError{Field: "idContaPai", Message: "conta pai não encontrada"}
+			}
+			return fmt.Errorf("erro ao buscar conta pai: %w", err)
+		}
+		
+		// A conta pai direta deve ser sintética e do mesmo plano de contas
+		if nivel == 0 {
+			if pai.IdSeguradora != c.IdSeguradora || pai.IdSistemaContabil != c.IdSistemaContabil {
+				return utils.ValidationError{Field: "idContaPai", Message: "conta pai pertence a outro plano de contas"}
+			}
+			if pai.Tipo != TipoContaSintetica {
+				return utils.ValidationError{Field: "idContaPai", Message: "conta pai deve ser sintética"}
+			}
+		}
+		
+		idAtual = 0
+		if pai.IdContaPai != nil {
+			idAtual = *pai.IdContaPai
+		}
+	}
+	
+	return nil
+}
+
+// validateContaContabil valida os dados de uma conta
+func validateContaContabil(c *ContaContabil) error {
+	// Validar código da conta
+	c.Codigo = strings.TrimSpace(c.Codigo)
+	if err := utils.ValidateRequired("codigo", c.Codigo); err != nil {
+		return err
+	}
+	if err := utils.ValidateLength("codigo", c.Codigo, 1, 50); err != nil {
+		return err
+	}
+	
+	// Validar descrição
+	if err := utils.ValidateRequired("descricao", c.Descricao); err != nil {
+		return err
+	}
+	if err := utils.ValidateLength("descricao", c.Descricao, 3, 255); err != nil {
+		return err
+	}
+	
+	// Validar natureza
+	c.Natureza = strings.ToUpper(strings.TrimSpace(c.Natureza))
+	if c.Natureza != NaturezaDebito && c.Natureza != NaturezaCredito {
+		return utils.ValidationError{
+			Field:   "natureza",
+			Message: "deve ser D (devedora) ou C (credora)",
+		}
+	}
+	
+	// Validar tipo
+	c.Tipo = strings.ToUpper(strings.TrimSpace(c.Tipo))
+	if c.Tipo != TipoContaSintetica && c.Tipo != TipoContaAnalitica {
+		return utils.ValidationError{
+			Field:   "tipo",
+			Message: "deve ser S (sintética) ou A (analítica)",
+		}
+	}
+	
+	// Validar seguradora
+	if c.IdSeguradora <= 0 {
+		return utils.ValidationError{
+			Field:   "idSeguradora",
+			Message: "deve ser um número positivo",
+		}
+	}
+	
+	// Validar sistema contábil
+	if c.IdSistemaContabil <= 0 {
+		return utils.ValidationError{
+			Field:   "idSistemaContabil",
+			Message: "deve ser um número positivo",
+		}
+	}
+	
+	return nil
+}
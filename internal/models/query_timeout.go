@@ -0,0 +1,25 @@
+package models
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Tempo limite de cada operação dos repositórios no banco de dados (0: sem tempo limite)
+var queryTimeout atomic.Int64
+
+// SetQueryTimeout define o tempo limite de cada operação dos repositórios no banco de dados.
+// Exportações, importações em lote e a verificação da cadeia de auditoria não têm tempo limite e
+// são interrompidas apenas pelo cancelamento da requisição.
+func SetQueryTimeout(d time.Duration) {
+	queryTimeout.Store(int64(d))
+}
+
+// withQueryTimeout aplica o tempo limite configurado ao contexto de uma operação dos repositórios
+func withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if d := time.Duration(queryTimeout.Load()); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
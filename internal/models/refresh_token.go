@@ -0,0 +1,352 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// Erros da rotação de refresh tokens
+var (
+	// ErrRefreshTokenInvalido indica um refresh token desconhecido, expirado ou revogado
+	ErrRefreshTokenInvalido = errors.New("refresh token inválido ou revogado")
+	// ErrRefreshTokenReutilizado indica o reuso de um refresh token já rotacionado;
+	// toda a família (sessão) é revogada
+	ErrRefreshTokenReutilizado = errors.New("refresh token reutilizado: sessão revogada")
+)
+
+// Motivos de revogação de refresh tokens
+const (
+	MotivoRevogacaoLogout       = "logout"
+	MotivoRevogacaoLogoutGeral  = "logout_all"
+	MotivoRevogacaoReutilizacao = "reutilizacao"
+	MotivoRevogacaoAdmin        = "admin"
+	MotivoRevogacaoUsuario      = "usuario_inativo_ou_bloqueado"
+	MotivoRevogacaoBloqueio     = "conta_bloqueada"
+)
+
+// RefreshToken representa um refresh token emitido e persistido no servidor.
+// Os tokens de uma mesma sessão formam uma família: a cada renovação o token
+// atual é marcado como rotacionado e um novo token da mesma família é emitido.
+type RefreshToken struct {
+	ID              int64      `json:"id"`
+	JTI             string     `json:"-"`
+	Familia         string     `json:"familia"`
+	IdUsuario       int64      `json:"idUsuario"`
+	ExpiresAt       time.Time  `json:"expires_at"`
+	RotatedAt       *time.Time `json:"rotated_at,omitempty"`
+	RevokedAt       *time.Time `json:"revoked_at,omitempty"`
+	MotivoRevogacao string     `json:"motivo_revogacao,omitempty"`
+	IPAddress       string     `json:"ip_address"`
+	UserAgent       string     `json:"user_agent"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// execer representa um banco ou transação capaz de executar comandos
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// RefreshTokenRepository gerencia operações de banco de dados para refresh tokens
+type RefreshTokenRepository struct {
+	DB *sql.DB
+}
+
+// NewRefreshTokenRepository cria um novo repositório de refresh tokens
+func NewRefreshTokenRepository(db *sql.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{DB: db}
+}
+
+// Create persiste um refresh token recém-emitido
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *RefreshToken) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	return insertRefreshToken(ctx, connFor(ctx, r.DB), token)
+}
+
+// Rotate consome o refresh token informado (pelo jti) e persiste o novo token da mesma família,
+// emitido por emitir com os dados atuais do usuário (perfil, seguradora e AdminERP), e não com os
+// do token consumido. Se o token já tiver sido rotacionado, trata-se de reuso: toda a família é
+// revogada e ErrRefreshTokenReutilizado é retornado. O novo token recebe a família e o usuário do
+// token consumido.
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, jti string, emitir func(usuario *Usuario) (*RefreshToken, error)) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Bloquear o token consumido para serializar renovações concorrentes
+	query := `
+	SELECT
+		rt.familia, rt.id_usuario, rt.expires_at, rt.rotated_at, rt.revoked_at,
+		u.login, u.idTipoPerfil, u.idSeguradora, u.AdminERP, u.ativo, u.bloqueado
+	FROM refresh_tokens rt
+	JOIN usuarios u ON u.id = rt.id_usuario
+	WHERE rt.jti = ?` + dialect.Current().ForUpdate()
+	
+	var atual RefreshToken
+	var usuario Usuario
+	var rotatedAt, revokedAt sql.NullTime
+	err = tx.QueryRowContext(ctx, query, jti).Scan(
+		&atual.Familia,
+		&atual.IdUsuario,
+		&atual.ExpiresAt,
+		&rotatedAt,
+		&revokedAt,
+		&usuario.Login,
+		&usuario.IdTipoPerfil,
+		&usuario.IdSeguradora,
+		&usuario.AdminERP,
+		&usuario.Ativo,
+		&usuario.Bloqueado,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrRefreshTokenInvalido
+		}
+		return fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+	
+	// Tokens revogados ou expirados não podem ser renovados
+	if revokedAt.Valid || !atual.ExpiresAt.After(time.Now()) {
+		return ErrRefreshTokenInvalido
+	}
+	
+	// Reuso de um token já rotacionado: revogar toda a família
+	if rotatedAt.Valid {
+		if err := revokeFamilia(ctx, tx, atual.Familia, MotivoRevogacaoReutilizacao); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("erro ao confirmar transação: %w", err)
+		}
+		return ErrRefreshTokenReutilizado
+	}
+	
+	// Usuários inativos ou bloqueados não renovam sessões
+	if !usuario.Ativo || usuario.Bloqueado {
+		if err := revokeFamilia(ctx, tx, atual.Familia, MotivoRevogacaoUsuario); err != nil {
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("erro ao confirmar transação: %w", err)
+		}
+		return ErrRefreshTokenInvalido
+	}
+	
+	// Marcar o token atual como rotacionado
+	if _, err := tx.ExecContext(ctx, "UPDATE refresh_tokens SET rotated_at = CURRENT_TIMESTAMP WHERE jti = ?", jti); err != nil {
+		return fmt.Errorf("erro ao rotacionar refresh token: %w", err)
+	}
+	
+	// Emitir e persistir o novo token na mesma família, com os dados atuais do usuário
+	usuario.ID = atual.IdUsuario
+	novo, err := emitir(&usuario)
+	if err != nil {
+		return err
+	}
+	novo.Familia = atual.Familia
+	novo.IdUsuario = atual.IdUsuario
+	if err := insertRefreshToken(ctx, tx, novo); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return nil
+}
+
+// IsFamiliaAtiva verifica se a sessão ainda possui um refresh token não revogado e não expirado
+func (r *RefreshTokenRepository) IsFamiliaAtiva(ctx context.Context, familia string) (bool, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT EXISTS(
+		SELECT 1 FROM refresh_tokens
+		WHERE familia = ? AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	)`
+	
+	var ativa bool
+	if err := connFor(ctx, r.DB).QueryRowContext(ctx, query, familia).Scan(&ativa); err != nil {
+		return false, fmt.Errorf("erro ao verificar sessão: %w", err)
+	}
+	
+	return ativa, nil
+}
+
+// GetAtivosByUsuario retorna o refresh token vigente de cada sessão ativa de um usuário
+func (r *RefreshTokenRepository) GetAtivosByUsuario(ctx context.Context, idUsuario int64) ([]RefreshToken, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	query := `
+	SELECT
+		id_refresh_token, jti, familia, id_usuario, expires_at, rotated_at, revoked_at,
+		COALESCE(motivo_revogacao, ''), ip_address, user_agent, created_at
+	FROM refresh_tokens
+	WHERE id_usuario = ? AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	ORDER BY created_at DESC`
+	
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, query, idUsuario)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar sessões do usuário: %w", err)
+	}
+	defer rows.Close()
+	
+	var tokens []RefreshToken
+	
+	for rows.Next() {
+		var t RefreshToken
+		var rotatedAt, revokedAt sql.NullTime
+		if err := rows.Scan(
+			&t.ID,
+			&t.JTI,
+			&t.Familia,
+			&t.IdUsuario,
+			&t.ExpiresAt,
+			&rotatedAt,
+			&revokedAt,
+			&t.MotivoRevogacao,
+			&t.IPAddress,
+			&t.UserAgent,
+			&t.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("erro ao ler sessão: %w", err)
+		}
+		if rotatedAt.Valid {
+			t.RotatedAt = &rotatedAt.Time
+		}
+		if revokedAt.Valid {
+			t.RevokedAt = &revokedAt.Time
+		}
+		tokens = append(tokens, t)
+	}
+	
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre sessões: %w", err)
+	}
+	
+	return tokens, nil
+}
+
+// RevokeFamilia revoga todos os refresh tokens de uma sessão
+func (r *RefreshTokenRepository) RevokeFamilia(ctx context.Context, familia, motivo string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	return revokeFamilia(ctx, connFor(ctx, r.DB), familia, motivo)
+}
+
+// RevokeByUsuario revoga todas as sessões de um usuário e retorna quantas sessões ativas foram revogadas
+func (r *RefreshTokenRepository) RevokeByUsuario(ctx context.Context, idUsuario int64, motivo string) (int64, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Contar as sessões ativas antes da revogação
+	var sessoes int64
+	countQuery := `
+	SELECT COUNT(DISTINCT familia) FROM refresh_tokens
+	WHERE id_usuario = ? AND revoked_at IS NULL AND expires_at > CURRENT_TIMESTAMP`
+	if err := tx.QueryRowContext(ctx, countQuery, idUsuario).Scan(&sessoes); err != nil {
+		return 0, fmt.Errorf("erro ao contar sessões do usuário: %w", err)
+	}
+	
+	query := `
+	UPDATE refresh_tokens
+	SET revoked_at = CURRENT_TIMESTAMP, motivo_revogacao = ?
+	WHERE id_usuario = ? AND revoked_at IS NULL`
+	
+	if _, err := tx.ExecContext(ctx, query, motivo, idUsuario); err != nil {
+		return 0, fmt.Errorf("erro ao revogar sessões do usuário: %w", err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	return sessoes, nil
+}
+
+// insertRefreshToken insere um refresh token usando o banco ou a transação informada
+func insertRefreshToken(ctx context.Context, db inserter, token *RefreshToken) error {
+	query := `
+	INSERT INTO refresh_tokens
+	(jti, familia, id_usuario, expires_at, ip_address, user_agent)
+	VALUES (?, ?, ?, ?, ?, ?)`
+	
+	result, err := execInsert(ctx, db, "id_refresh_token",
+		query,
+		token.JTI,
+		token.Familia,
+		token.IdUsuario,
+		token.ExpiresAt,
+		truncate(token.IPAddress, 45),
+		truncate(token.UserAgent, 255),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar refresh token: %w", err)
+	}
+	
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("erro ao obter ID do refresh token: %w", err)
+	}
+	
+	token.ID = id
+	return nil
+}
+
+// revokeFamilia revoga os refresh tokens ainda não revogados de uma família
+func revokeFamilia(ctx context.Context, db execer, familia, motivo string) error {
+	query := `
+	UPDATE refresh_tokens
+	SET revoked_at = CURRENT_TIMESTAMP, motivo_revogacao = ?
+	WHERE familia = ? AND revoked_at IS NULL`
+	
+	if _, err := db.ExecContext(ctx, query, motivo, familia); err != nil {
+		return fmt.Errorf("erro ao revogar sessão: %w", err)
+	}
+	
+	return nil
+}
+
+// revokeSessoesUsuarioInativo revoga as sessões de um usuário caso ele esteja inativo ou bloqueado
+func revokeSessoesUsuarioInativo(ctx context.Context, db execer, idUsuario int64) error {
+	query := `
+	UPDATE refresh_tokens
+	SET revoked_at = CURRENT_TIMESTAMP, motivo_revogacao = ?
+	WHERE id_usuario = ? AND revoked_at IS NULL
+	AND id_usuario IN (SELECT id FROM usuarios WHERE id = ? AND (ativo = false OR bloqueado = true))`
+	
+	if _, err := db.ExecContext(ctx, query, MotivoRevogacaoUsuario, idUsuario, idUsuario); err != nil {
+		return fmt.Errorf("erro ao revogar sessões do usuário: %w", err)
+	}
+	
+	return nil
+}
+
+// truncate limita o tamanho de um texto ao tamanho da coluna
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
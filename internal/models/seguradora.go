@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -21,7 +22,9 @@ type Seguradora struct {
 
 // SeguradoraRepository gerencia operações de banco de dados para seguradoras
 type SeguradoraRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewSeguradoraRepository cria um novo repositório de seguradoras
@@ -29,13 +32,31 @@ func NewSeguradoraRepository(db *sql.DB) *SeguradoraRepository {
 	return &SeguradoraRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *SeguradoraRepository) WithTenant(scope TenantScope) SeguradoraStore {
+	return &SeguradoraRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *SeguradoraRepository) WithActor(actor Actor) SeguradoraStore {
+	return &SeguradoraRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
 // Create insere uma nova seguradora no banco de dados
-func (r *SeguradoraRepository) Create(seguradora *Seguradora) error {
+func (r *SeguradoraRepository) Create(ctx context.Context, seguradora *Seguradora) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados da seguradora
 	if err := validateSeguradora(seguradora); err != nil {
 		return err
 	}
 	
+	// Criar uma nova seguradora exige acesso explícito a todas as seguradoras
+	if r.scope != nil && !r.scope.Todas {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	seguradora.Nome = utils.SanitizeString(seguradora.Nome)
 	seguradora.NomeAbreviado = utils.SanitizeString(seguradora.NomeAbreviado)
@@ -46,41 +67,61 @@ func (r *SeguradoraRepository) Create(seguradora *Seguradora) error {
 	(seguradora, nome_abreviado, codigo_susep, ativo) 
 	VALUES (?, ?, ?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		seguradora.Nome, 
-		seguradora.NomeAbreviado, 
-		seguradora.CodigoSusep, 
-		seguradora.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar seguradora: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID da seguradora: %v", err)
-	}
-	
-	seguradora.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "id_seguradora",
+			query, 
+			seguradora.Nome, 
+			seguradora.NomeAbreviado, 
+			seguradora.CodigoSusep, 
+			seguradora.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar seguradora: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID da seguradora: %w", err)
+		}
+		
+		seguradora.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeSeguradora, id, nil, seguradora)
+	})
 }
 
-// GetAll retorna todas as seguradoras do banco de dados
-func (r *SeguradoraRepository) GetAll() ([]Seguradora, error) {
+// seguradoraListSpec define os campos de ordenação e filtro da listagem de seguradoras
+var seguradoraListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"id":             {Column: "id_seguradora", Type: FieldInt},
+		"nome":           {Column: "seguradora", Type: FieldString},
+		"nome_abreviado": {Column: "nome_abreviado", Type: FieldString},
+		"codigo_susep":   {Column: "codigo_susep", Type: FieldString},
+		"created_at":     {Column: "created_at", Type: FieldTime},
+		"updated_at":     {Column: "updated_at", Type: FieldTime},
+		"ativo":          {Column: "ativo", Type: FieldBool},
+	},
+	Key: "id",
+}
+
+// GetAll retorna as seguradoras com paginação, ordenação e filtros
+func (r *SeguradoraRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[Seguradora], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id_seguradora, seguradora, nome_abreviado, codigo_susep, 
 		created_at, updated_at, ativo 
 	FROM seguradoras 
-	ORDER BY id_seguradora DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar seguradoras: %v", err)
-	}
-	defer rows.Close()
+	WHERE ` + r.scope.condition("id_seguradora")
 	
+	return listPage(ctx, r.DB, &seguradoraListSpec, opts, query, r.scope.args(), scanSeguradoras)
+}
+
+// scanSeguradoras lê as seguradoras retornadas por uma consulta
+func scanSeguradoras(rows *sql.Rows) ([]Seguradora, error) {
 	var seguradoras []Seguradora
 	
 	for rows.Next() {
@@ -94,29 +135,32 @@ func (r *SeguradoraRepository) GetAll() ([]Seguradora, error) {
 			&s.UpdatedAt, 
 			&s.Ativo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler seguradora: %v", err)
+			return nil, fmt.Errorf("erro ao ler seguradora: %w", err)
 		}
 		seguradoras = append(seguradoras, s)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre seguradoras: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre seguradoras: %w", err)
 	}
 	
 	return seguradoras, nil
 }
 
 // GetByID busca uma seguradora pelo ID
-func (r *SeguradoraRepository) GetByID(id int64) (*Seguradora, error) {
+func (r *SeguradoraRepository) GetByID(ctx context.Context, id int64) (*Seguradora, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id_seguradora, seguradora, nome_abreviado, codigo_susep, 
 		created_at, updated_at, ativo 
 	FROM seguradoras 
-	WHERE id_seguradora = ?`
+	WHERE id_seguradora = ? AND ` + r.scope.condition("id_seguradora")
 	
 	var s Seguradora
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&s.ID, 
 		&s.Nome, 
 		&s.NomeAbreviado, 
@@ -127,21 +171,29 @@ func (r *SeguradoraRepository) GetByID(id int64) (*Seguradora, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("seguradora não encontrada")
+			return nil, NotFoundError{Message: "seguradora não encontrada"}
 		}
-		return nil, fmt.Errorf("erro ao buscar seguradora: %v", err)
+		return nil, fmt.Errorf("erro ao buscar seguradora: %w", err)
 	}
 	
 	return &s, nil
 }
 
 // Update atualiza os dados de uma seguradora existente
-func (r *SeguradoraRepository) Update(seguradora *Seguradora) error {
+func (r *SeguradoraRepository) Update(ctx context.Context, seguradora *Seguradora) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados da seguradora
 	if err := validateSeguradora(seguradora); err != nil {
 		return err
 	}
 	
+	// Verificar se a seguradora é a do usuário
+	if !r.scope.Allows(seguradora.ID) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	seguradora.Nome = utils.SanitizeString(seguradora.Nome)
 	seguradora.NomeAbreviado = utils.SanitizeString(seguradora.NomeAbreviado)
@@ -150,37 +202,61 @@ func (r *SeguradoraRepository) Update(seguradora *Seguradora) error {
 	query := `
 	UPDATE seguradoras 
 	SET seguradora = ?, nome_abreviado = ?, codigo_susep = ?, ativo = ? 
-	WHERE id_seguradora = ?`
-	
-	_, err := r.DB.Exec(
-		query, 
-		seguradora.Nome, 
-		seguradora.NomeAbreviado, 
-		seguradora.CodigoSusep, 
-		seguradora.Ativo, 
-		seguradora.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar seguradora: %v", err)
-	}
+	WHERE id_seguradora = ? AND ` + r.scope.condition("id_seguradora")
 	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "seguradoras", "id_seguradora", seguradora.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				seguradora.Nome, 
+				seguradora.NomeAbreviado, 
+				seguradora.CodigoSusep, 
+				seguradora.Ativo, 
+				seguradora.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar seguradora: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeSeguradora, seguradora.ID, anterior, seguradora)
+	})
 }
 
 // Delete remove uma seguradora do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *SeguradoraRepository) Delete(id int64) error {
+func (r *SeguradoraRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM seguradoras WHERE id_seguradora = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE seguradoras SET ativo = false WHERE id_seguradora = ?`
+	query := `UPDATE seguradoras SET ativo = false WHERE id_seguradora = ? AND ` + r.scope.condition("id_seguradora")
 	
-	_, err := r.DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("erro ao excluir seguradora: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "seguradoras", "id_seguradora", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir seguradora: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeSeguradora, id, anterior, &excluido)
+	})
 }
 
 // validateSeguradora valida os dados de uma seguradora
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -20,7 +21,9 @@ type SistemaContabil struct {
 
 // SistemaContabilRepository gerencia operações de banco de dados para sistemas contábeis
 type SistemaContabilRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewSistemaContabilRepository cria um novo repositório de sistemas contábeis
@@ -28,13 +31,31 @@ func NewSistemaContabilRepository(db *sql.DB) *SistemaContabilRepository {
 	return &SistemaContabilRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *SistemaContabilRepository) WithTenant(scope TenantScope) SistemaContabilStore {
+	return &SistemaContabilRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *SistemaContabilRepository) WithActor(actor Actor) SistemaContabilStore {
+	return &SistemaContabilRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
 // Create insere um novo sistema contábil no banco de dados
-func (r *SistemaContabilRepository) Create(sistema *SistemaContabil) error {
+func (r *SistemaContabilRepository) Create(ctx context.Context, sistema *SistemaContabil) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do sistema
 	if err := validateSistemaContabil(sistema); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(sistema.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	sistema.SistemaContabil = utils.SanitizeString(sistema.SistemaContabil)
 	
@@ -43,40 +64,59 @@ func (r *SistemaContabilRepository) Create(sistema *SistemaContabil) error {
 	(SistemaContabil, idSeguradora, ativo) 
 	VALUES (?, ?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		sistema.SistemaContabil, 
-		sistema.IdSeguradora, 
-		sistema.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar sistema contábil: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID do sistema contábil: %v", err)
-	}
-	
-	sistema.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "idSistemaContabil",
+			query, 
+			sistema.SistemaContabil, 
+			sistema.IdSeguradora, 
+			sistema.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar sistema contábil: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID do sistema contábil: %w", err)
+		}
+		
+		sistema.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeSistemaContabil, id, nil, sistema)
+	})
 }
 
-// GetAll retorna todos os sistemas contábeis do banco de dados
-func (r *SistemaContabilRepository) GetAll() ([]SistemaContabil, error) {
+// sistemaContabilListSpec define os campos de ordenação e filtro da listagem de sistemas contábeis
+var sistemaContabilListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idSistemaContabil": {Column: "idSistemaContabil", Type: FieldInt},
+		"sistemaContabil":   {Column: "SistemaContabil", Type: FieldString},
+		"idSeguradora":      {Column: "idSeguradora", Type: FieldInt},
+		"created_at":        {Column: "created_at", Type: FieldTime},
+		"updated_at":        {Column: "updated_at", Type: FieldTime},
+		"ativo":             {Column: "ativo", Type: FieldBool},
+	},
+	Key: "idSistemaContabil",
+}
+
+// GetAll retorna os sistemas contábeis com paginação, ordenação e filtros
+func (r *SistemaContabilRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[SistemaContabil], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		idSistemaContabil, SistemaContabil, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM sistema_contabil 
-	ORDER BY idSistemaContabil DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar sistemas contábeis: %v", err)
-	}
-	defer rows.Close()
+	WHERE ` + r.scope.condition("idSeguradora")
 	
+	return listPage(ctx, r.DB, &sistemaContabilListSpec, opts, query, r.scope.args(), scanSistemasContabeis)
+}
+
+// scanSistemasContabeis lê os sistemas contábeis retornados por uma consulta
+func scanSistemasContabeis(rows *sql.Rows) ([]SistemaContabil, error) {
 	var sistemas []SistemaContabil
 	
 	for rows.Next() {
@@ -89,29 +129,32 @@ func (r *SistemaContabilRepository) GetAll() ([]SistemaContabil, error) {
 			&s.UpdatedAt, 
 			&s.Ativo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler sistema contábil: %v", err)
+			return nil, fmt.Errorf("erro ao ler sistema contábil: %w", err)
 		}
 		sistemas = append(sistemas, s)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre sistemas contábeis: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre sistemas contábeis: %w", err)
 	}
 	
 	return sistemas, nil
 }
 
 // GetByID busca um sistema contábil pelo ID
-func (r *SistemaContabilRepository) GetByID(id int64) (*SistemaContabil, error) {
+func (r *SistemaContabilRepository) GetByID(ctx context.Context, id int64) (*SistemaContabil, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		idSistemaContabil, SistemaContabil, idSeguradora, 
 		created_at, updated_at, ativo 
 	FROM sistema_contabil 
-	WHERE idSistemaContabil = ?`
+	WHERE idSistemaContabil = ? AND ` + r.scope.condition("idSeguradora")
 	
 	var s SistemaContabil
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&s.ID, 
 		&s.SistemaContabil, 
 		&s.IdSeguradora, 
@@ -121,97 +164,99 @@ func (r *SistemaContabilRepository) GetByID(id int64) (*SistemaContabil, error)
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("sistema contábil não encontrado")
+			return nil, NotFoundError{Message: "sistema contábil não encontrado"}
 		}
-		return nil, fmt.Errorf("erro ao buscar sistema contábil: %v", err)
+		return nil, fmt.Errorf("erro ao buscar sistema contábil: %w", err)
 	}
 	
 	return &s, nil
 }
 
-// GetBySeguradora busca sistemas contábeis por seguradora
-func (r *SistemaContabilRepository) GetBySeguradora(idSeguradora int64) ([]SistemaContabil, error) {
-	query := `
-	SELECT 
-		idSistemaContabil, SistemaContabil, idSeguradora, 
-		created_at, updated_at, ativo 
-	FROM sistema_contabil 
-	WHERE idSeguradora = ? 
-	ORDER BY idSistemaContabil DESC`
-	
-	rows, err := r.DB.Query(query, idSeguradora)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar sistemas contábeis por seguradora: %v", err)
+// GetBySeguradora busca sistemas contábeis por seguradora, com paginação, ordenação e filtros
+func (r *SistemaContabilRepository) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[SistemaContabil], error) {
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
 	}
-	defer rows.Close()
-	
-	var sistemas []SistemaContabil
 	
-	for rows.Next() {
-		var s SistemaContabil
-		if err := rows.Scan(
-			&s.ID, 
-			&s.SistemaContabil, 
-			&s.IdSeguradora, 
-			&s.CreatedAt, 
-			&s.UpdatedAt, 
-			&s.Ativo,
-		); err != nil {
-			return nil, fmt.Errorf("erro ao ler sistema contábil: %v", err)
-		}
-		sistemas = append(sistemas, s)
-	}
-	
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre sistemas contábeis: %v", err)
-	}
-	
-	return sistemas, nil
+	return r.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
 }
 
 // Update atualiza os dados de um sistema contábil existente
-func (r *SistemaContabilRepository) Update(sistema *SistemaContabil) error {
+func (r *SistemaContabilRepository) Update(ctx context.Context, sistema *SistemaContabil) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do sistema
 	if err := validateSistemaContabil(sistema); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(sistema.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	// Sanitizar dados
 	sistema.SistemaContabil = utils.SanitizeString(sistema.SistemaContabil)
 	
 	query := `
 	UPDATE sistema_contabil 
 	SET SistemaContabil = ?, idSeguradora = ?, ativo = ? 
-	WHERE idSistemaContabil = ?`
-	
-	_, err := r.DB.Exec(
-		query, 
-		sistema.SistemaContabil, 
-		sistema.IdSeguradora, 
-		sistema.Ativo, 
-		sistema.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar sistema contábil: %v", err)
-	}
+	WHERE idSistemaContabil = ? AND ` + r.scope.condition("idSeguradora")
 	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "sistema_contabil", "idSistemaContabil", sistema.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				sistema.SistemaContabil, 
+				sistema.IdSeguradora, 
+				sistema.Ativo, 
+				sistema.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar sistema contábil: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeSistemaContabil, sistema.ID, anterior, sistema)
+	})
 }
 
 // Delete remove um sistema contábil do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *SistemaContabilRepository) Delete(id int64) error {
+func (r *SistemaContabilRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM sistema_contabil WHERE idSistemaContabil = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE sistema_contabil SET ativo = false WHERE idSistemaContabil = ?`
-	
-	_, err := r.DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("erro ao excluir sistema contábil: %v", err)
-	}
+	query := `UPDATE sistema_contabil SET ativo = false WHERE idSistemaContabil = ? AND ` + r.scope.condition("idSeguradora")
 	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "sistema_contabil", "idSistemaContabil", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir sistema contábil: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeSistemaContabil, id, anterior, &excluido)
+	})
 }
 
 // validateSistemaContabil valida os dados de um sistema contábil
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -18,16 +19,22 @@ type SistemaContabilConfig struct {
 	CreatedAt              time.Time `json:"created_at"`
 	UpdatedAt              time.Time `json:"updated_at"`
 	Ativo                  bool      `json:"ativo"`
+	IdContaDebito          *int64    `json:"idContaDebito"`  // Conta do plano de contas debitada nos lançamentos
+	IdContaCredito         *int64    `json:"idContaCredito"` // Conta do plano de contas creditada nos lançamentos
 	// Campos para exibição de informações relacionadas
 	SistemaContabilNome       string    `json:"sistemaContabilNome,omitempty"`
 	ObjetoContabilizacaoNome  string    `json:"objetoContabilizacaoNome,omitempty"`
 	EventoNumero              int       `json:"eventoNumero,omitempty"`
 	EventoDescricao           string    `json:"eventoDescricao,omitempty"`
+	ContaDebitoCodigo         string    `json:"contaDebitoCodigo,omitempty"`
+	ContaCreditoCodigo        string    `json:"contaCreditoCodigo,omitempty"`
 }
 
 // SistemaContabilConfigRepository gerencia operações de banco de dados para configurações de sistema contábil
 type SistemaContabilConfigRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewSistemaContabilConfigRepository cria um novo repositório de configurações de sistema contábil
@@ -35,103 +42,194 @@ func NewSistemaContabilConfigRepository(db *sql.DB) *SistemaContabilConfigReposi
 	return &SistemaContabilConfigRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *SistemaContabilConfigRepository) WithTenant(scope TenantScope) SistemaContabilConfigStore {
+	return &SistemaContabilConfigRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *SistemaContabilConfigRepository) WithActor(actor Actor) SistemaContabilConfigStore {
+	return &SistemaContabilConfigRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
 // Create insere uma nova configuração de sistema contábil no banco de dados
-func (r *SistemaContabilConfigRepository) Create(config *SistemaContabilConfig) error {
+func (r *SistemaContabilConfigRepository) Create(ctx context.Context, config *SistemaContabilConfig) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados da configuração
 	if err := validateSistemaContabilConfig(config); err != nil {
 		return err
 	}
 	
-	query := `
-	INSERT INTO sistema_contabil_config 
-	(idSistemaContabil, idObjetoContabilizacao, idCodigoEvento, idSeguradora, ativo) 
-	VALUES (?, ?, ?, ?, ?)`
-	
-	result, err := r.DB.Exec(
-		query, 
-		config.IdSistemaContabil, 
-		config.IdObjetoContabilizacao, 
-		config.IdCodigoEvento, 
-		config.IdSeguradora, 
-		config.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar configuração de sistema contábil: %v", err)
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(config.IdSeguradora) {
+		return ErrCrossTenant
 	}
 	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID da configuração: %v", err)
+	// Validar o sistema contábil, o objeto de contabilização e o evento referenciados
+	if err := validateReferenciasConfig(sqlConfigLookup{ctx, connFor(ctx, r.DB)}, config); err != nil {
+		return err
 	}
 	
-	config.ID = id
-	return nil
+	// Validar as contas de débito e crédito no plano de contas
+	if err := validateContasConfig(sqlContaLookup{ctx, connFor(ctx, r.DB)}, config); err != nil {
+		return err
+	}
+	
+	query := `
+	INSERT INTO sistema_contabil_config 
+	(idSistemaContabil, idObjetoContabilizacao, idCodigoEvento, idSeguradora, ativo, idContaDebito, idContaCredito) 
+	VALUES (?, ?, ?, ?, ?, ?, ?)`
+	
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "idSistemaContabilConfig",
+			query, 
+			config.IdSistemaContabil, 
+			config.IdObjetoContabilizacao, 
+			config.IdCodigoEvento, 
+			config.IdSeguradora, 
+			config.Ativo,
+			config.IdContaDebito,
+			config.IdContaCredito,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar configuração de sistema contábil: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID da configuração: %w", err)
+		}
+		
+		config.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeSistemaContabilConfig, id, nil, config)
+	})
 }
 
-// GetAll retorna todas as configurações de sistema contábil do banco de dados
-func (r *SistemaContabilConfigRepository) GetAll() ([]SistemaContabilConfig, error) {
-	query := `
+// sistemaContabilConfigListSpec define os campos de ordenação e filtro da listagem de configurações
+var sistemaContabilConfigListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"idSistemaContabilConfig":  {Column: "scc.idSistemaContabilConfig", Type: FieldInt},
+		"idSistemaContabil":        {Column: "scc.idSistemaContabil", Type: FieldInt},
+		"idObjetoContabilizacao":   {Column: "scc.idObjetoContabilizacao", Type: FieldInt},
+		"idCodigoEvento":           {Column: "scc.idCodigoEvento", Type: FieldInt},
+		"idSeguradora":             {Column: "scc.idSeguradora", Type: FieldInt},
+		"created_at":               {Column: "scc.created_at", Type: FieldTime},
+		"updated_at":               {Column: "scc.updated_at", Type: FieldTime},
+		"ativo":                    {Column: "scc.ativo", Type: FieldBool},
+		"sistemaContabilNome":      {Column: "sc.SistemaContabil", Type: FieldString},
+		"objetoContabilizacaoNome": {Column: "oc.ObjetoContabilizacao", Type: FieldString},
+		"eventoNumero":             {Column: "e.Evento", Type: FieldInt},
+		"eventoDescricao":          {Column: "e.Descricao", Type: FieldString},
+		"contaDebitoCodigo":        {Column: "COALESCE(cd.Codigo, '')", Type: FieldString},
+		"contaCreditoCodigo":       {Column: "COALESCE(ccr.Codigo, '')", Type: FieldString},
+	},
+	Key: "idSistemaContabilConfig",
+}
+
+// sistemaContabilConfigQuery consulta as configurações com os dados do sistema contábil, do objeto de
+// contabilização, do evento e das contas relacionadas
+const sistemaContabilConfigQuery = `
 	SELECT 
 		scc.idSistemaContabilConfig, scc.idSistemaContabil, scc.idObjetoContabilizacao, 
 		scc.idCodigoEvento, scc.idSeguradora, scc.created_at, scc.updated_at, scc.ativo,
-		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao
+		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao,
+		scc.idContaDebito, scc.idContaCredito, COALESCE(cd.Codigo, ''), COALESCE(ccr.Codigo, '')
 	FROM sistema_contabil_config scc
 	JOIN sistema_contabil sc ON scc.idSistemaContabil = sc.idSistemaContabil
 	JOIN objeto_contabilizacao oc ON scc.idObjetoContabilizacao = oc.idObjetoContabilizacao
 	JOIN eventos e ON scc.idCodigoEvento = e.idCodigoEvento
-	ORDER BY scc.idSistemaContabilConfig DESC`
+	LEFT JOIN plano_contas cd ON scc.idContaDebito = cd.idConta
+	LEFT JOIN plano_contas ccr ON scc.idContaCredito = ccr.idConta
+	WHERE `
+
+// GetAll retorna as configurações de sistema contábil com paginação, ordenação e filtros
+func (r *SistemaContabilConfigRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[SistemaContabilConfig], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar configurações: %v", err)
-	}
-	defer rows.Close()
+	query := sistemaContabilConfigQuery + r.scope.condition("scc.idSeguradora")
 	
+	return listPage(ctx, r.DB, &sistemaContabilConfigListSpec, opts, query, r.scope.args(), scanSistemasContabeisConfig)
+}
+
+// Export percorre todas as configurações que atendem aos filtros, na ordem solicitada, sem
+// carregar o resultado completo em memória
+func (r *SistemaContabilConfigRepository) Export(ctx context.Context, opts ListOptions, fn func(SistemaContabilConfig) error) error {
+	query := sistemaContabilConfigQuery + r.scope.condition("scc.idSeguradora")
+	
+	return streamList(ctx, r.DB, &sistemaContabilConfigListSpec, opts, query, r.scope.args(), scanSistemaContabilConfig, fn)
+}
+
+// scanSistemasContabeisConfig lê as configurações retornadas por uma consulta
+func scanSistemasContabeisConfig(rows *sql.Rows) ([]SistemaContabilConfig, error) {
 	var configs []SistemaContabilConfig
 	
 	for rows.Next() {
-		var c SistemaContabilConfig
-		if err := rows.Scan(
-			&c.ID, 
-			&c.IdSistemaContabil, 
-			&c.IdObjetoContabilizacao, 
-			&c.IdCodigoEvento, 
-			&c.IdSeguradora, 
-			&c.CreatedAt, 
-			&c.UpdatedAt, 
-			&c.Ativo,
-			&c.SistemaContabilNome,
-			&c.ObjetoContabilizacaoNome,
-			&c.EventoNumero,
-			&c.EventoDescricao,
-		); err != nil {
-			return nil, fmt.Errorf("erro ao ler configuração: %v", err)
+		c, err := scanSistemaContabilConfig(rows)
+		if err != nil {
+			return nil, err
 		}
 		configs = append(configs, c)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre configurações: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre configurações: %w", err)
 	}
 	
 	return configs, nil
 }
 
+// scanSistemaContabilConfig lê uma configuração retornada por uma consulta
+func scanSistemaContabilConfig(rows *sql.Rows) (SistemaContabilConfig, error) {
+	var c SistemaContabilConfig
+	if err := rows.Scan(
+		&c.ID, 
+		&c.IdSistemaContabil, 
+		&c.IdObjetoContabilizacao, 
+		&c.IdCodigoEvento, 
+		&c.IdSeguradora, 
+		&c.CreatedAt, 
+		&c.UpdatedAt, 
+		&c.Ativo,
+		&c.SistemaContabilNome,
+		&c.ObjetoContabilizacaoNome,
+		&c.EventoNumero,
+		&c.EventoDescricao,
+		&c.IdContaDebito,
+		&c.IdContaCredito,
+		&c.ContaDebitoCodigo,
+		&c.ContaCreditoCodigo,
+	); err != nil {
+		return c, fmt.Errorf("erro ao ler configuração: %w", err)
+	}
+	return c, nil
+}
+
 // GetByID busca uma configuração pelo ID
-func (r *SistemaContabilConfigRepository) GetByID(id int64) (*SistemaContabilConfig, error) {
+func (r *SistemaContabilConfigRepository) GetByID(ctx context.Context, id int64) (*SistemaContabilConfig, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		scc.idSistemaContabilConfig, scc.idSistemaContabil, scc.idObjetoContabilizacao, 
 		scc.idCodigoEvento, scc.idSeguradora, scc.created_at, scc.updated_at, scc.ativo,
-		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao
+		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao,
+		scc.idContaDebito, scc.idContaCredito, COALESCE(cd.Codigo, ''), COALESCE(ccr.Codigo, '')
 	FROM sistema_contabil_config scc
 	JOIN sistema_contabil sc ON scc.idSistemaContabil = sc.idSistemaContabil
 	JOIN objeto_contabilizacao oc ON scc.idObjetoContabilizacao = oc.idObjetoContabilizacao
 	JOIN eventos e ON scc.idCodigoEvento = e.idCodigoEvento
-	WHERE scc.idSistemaContabilConfig = ?`
+	LEFT JOIN plano_contas cd ON scc.idContaDebito = cd.idConta
+	LEFT JOIN plano_contas ccr ON scc.idContaCredito = ccr.idConta
+	WHERE scc.idSistemaContabilConfig = ? AND ` + r.scope.condition("scc.idSeguradora")
 	
 	var c SistemaContabilConfig
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&c.ID, 
 		&c.IdSistemaContabil, 
 		&c.IdObjetoContabilizacao, 
@@ -144,84 +242,66 @@ func (r *SistemaContabilConfigRepository) GetByID(id int64) (*SistemaContabilCon
 		&c.ObjetoContabilizacaoNome,
 		&c.EventoNumero,
 		&c.EventoDescricao,
+		&c.IdContaDebito,
+		&c.IdContaCredito,
+		&c.ContaDebitoCodigo,
+		&c.ContaCreditoCodigo,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("configuração não encontrada")
+			return nil, NotFoundError{Message: "configuração não encontrada"}
 		}
-		return nil, fmt.Errorf("erro ao buscar configuração: %v", err)
+		return nil, fmt.Errorf("erro ao buscar configuração: %w", err)
 	}
 	
 	return &c, nil
 }
 
-// GetBySeguradora busca configurações por seguradora
-func (r *SistemaContabilConfigRepository) GetBySeguradora(idSeguradora int64) ([]SistemaContabilConfig, error) {
-	query := `
-	SELECT 
-		scc.idSistemaContabilConfig, scc.idSistemaContabil, scc.idObjetoContabilizacao, 
-		scc.idCodigoEvento, scc.idSeguradora, scc.created_at, scc.updated_at, scc.ativo,
-		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao
-	FROM sistema_contabil_config scc
-	JOIN sistema_contabil sc ON scc.idSistemaContabil = sc.idSistemaContabil
-	JOIN objeto_contabilizacao oc ON scc.idObjetoContabilizacao = oc.idObjetoContabilizacao
-	JOIN eventos e ON scc.idCodigoEvento = e.idCodigoEvento
-	WHERE scc.idSeguradora = ? 
-	ORDER BY scc.idSistemaContabilConfig DESC`
-	
-	rows, err := r.DB.Query(query, idSeguradora)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar configurações por seguradora: %v", err)
+// GetBySeguradora busca configurações por seguradora, com paginação, ordenação e filtros
+func (r *SistemaContabilConfigRepository) GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[SistemaContabilConfig], error) {
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
 	}
-	defer rows.Close()
-	
-	var configs []SistemaContabilConfig
 	
-	for rows.Next() {
-		var c SistemaContabilConfig
-		if err := rows.Scan(
-			&c.ID, 
-			&c.IdSistemaContabil, 
-			&c.IdObjetoContabilizacao, 
-			&c.IdCodigoEvento, 
-			&c.IdSeguradora, 
-			&c.CreatedAt, 
-			&c.UpdatedAt, 
-			&c.Ativo,
-			&c.SistemaContabilNome,
-			&c.ObjetoContabilizacaoNome,
-			&c.EventoNumero,
-			&c.EventoDescricao,
-		); err != nil {
-			return nil, fmt.Errorf("erro ao ler configuração: %v", err)
-		}
-		configs = append(configs, c)
-	}
+	return r.GetAll(ctx, opts.WithFilter("idSeguradora", idSeguradora))
+}
+
+// GetBySistemaContabil busca configurações por sistema contábil, com paginação, ordenação e filtros
+func (r *SistemaContabilConfigRepository) GetBySistemaContabil(ctx context.Context, idSistemaContabil int64, opts ListOptions) (*Page[SistemaContabilConfig], error) {
+	return r.GetAll(ctx, opts.WithFilter("idSistemaContabil", idSistemaContabil))
+}
+
+// GetAtivasByEventoObjeto busca as configurações ativas, de sistemas contábeis ativos,
+// para um par evento e objeto de contabilização de uma seguradora
+func (r *SistemaContabilConfigRepository) GetAtivasByEventoObjeto(ctx context.Context, idSeguradora, idCodigoEvento, idObjetoContabilizacao int64) ([]SistemaContabilConfig, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
 	
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre configurações: %v", err)
+	// Verificar se a seguradora consultada é visível para o usuário
+	if !r.scope.Allows(idSeguradora) {
+		return nil, ErrCrossTenant
 	}
 	
-	return configs, nil
-}
-
-// GetBySistemaContabil busca configurações por sistema contábil
-func (r *SistemaContabilConfigRepository) GetBySistemaContabil(idSistemaContabil int64) ([]SistemaContabilConfig, error) {
 	query := `
 	SELECT 
 		scc.idSistemaContabilConfig, scc.idSistemaContabil, scc.idObjetoContabilizacao, 
 		scc.idCodigoEvento, scc.idSeguradora, scc.created_at, scc.updated_at, scc.ativo,
-		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao
+		sc.SistemaContabil, oc.ObjetoContabilizacao, e.Evento, e.Descricao,
+		scc.idContaDebito, scc.idContaCredito, COALESCE(cd.Codigo, ''), COALESCE(ccr.Codigo, '')
 	FROM sistema_contabil_config scc
 	JOIN sistema_contabil sc ON scc.idSistemaContabil = sc.idSistemaContabil
 	JOIN objeto_contabilizacao oc ON scc.idObjetoContabilizacao = oc.idObjetoContabilizacao
 	JOIN eventos e ON scc.idCodigoEvento = e.idCodigoEvento
-	WHERE scc.idSistemaContabil = ? 
-	ORDER BY scc.idSistemaContabilConfig DESC`
+	LEFT JOIN plano_contas cd ON scc.idContaDebito = cd.idConta
+	LEFT JOIN plano_contas ccr ON scc.idContaCredito = ccr.idConta
+	WHERE scc.idSeguradora = ? AND scc.idCodigoEvento = ? AND scc.idObjetoContabilizacao = ? 
+	AND scc.ativo = true AND sc.ativo = true 
+	ORDER BY scc.idSistemaContabil`
 	
-	rows, err := r.DB.Query(query, idSistemaContabil)
+	rows, err := connFor(ctx, r.DB).QueryContext(ctx, query, idSeguradora, idCodigoEvento, idObjetoContabilizacao)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar configurações por sistema contábil: %v", err)
+		return nil, fmt.Errorf("erro ao buscar configurações ativas: %w", err)
 	}
 	defer rows.Close()
 	
@@ -242,61 +322,111 @@ func (r *SistemaContabilConfigRepository) GetBySistemaContabil(idSistemaContabil
 			&c.ObjetoContabilizacaoNome,
 			&c.EventoNumero,
 			&c.EventoDescricao,
+			&c.IdContaDebito,
+			&c.IdContaCredito,
+			&c.ContaDebitoCodigo,
+			&c.ContaCreditoCodigo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler configuração: %v", err)
+			return nil, fmt.Errorf("erro ao ler configuração: %w", err)
 		}
 		configs = append(configs, c)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre configurações: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre configurações: %w", err)
 	}
 	
 	return configs, nil
 }
 
 // Update atualiza os dados de uma configuração existente
-func (r *SistemaContabilConfigRepository) Update(config *SistemaContabilConfig) error {
+func (r *SistemaContabilConfigRepository) Update(ctx context.Context, config *SistemaContabilConfig) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados da configuração
 	if err := validateSistemaContabilConfig(config); err != nil {
 		return err
 	}
 	
+	// Verificar se o registro pertence à seguradora do usuário
+	if !r.scope.Allows(config.IdSeguradora) {
+		return ErrCrossTenant
+	}
+	
 	query := `
 	UPDATE sistema_contabil_config 
-	SET idSistemaContabil = ?, idObjetoContabilizacao = ?, idCodigoEvento = ?, idSeguradora = ?, ativo = ? 
-	WHERE idSistemaContabilConfig = ?`
-	
-	_, err := r.DB.Exec(
-		query, 
-		config.IdSistemaContabil, 
-		config.IdObjetoContabilizacao, 
-		config.IdCodigoEvento, 
-		config.IdSeguradora, 
-		config.Ativo, 
-		config.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar configuração: %v", err)
-	}
+	SET idSistemaContabil = ?, idObjetoContabilizacao = ?, idCodigoEvento = ?, idSeguradora = ?, ativo = ?, 
+	idContaDebito = ?, idContaCredito = ? 
+	WHERE idSistemaContabilConfig = ? AND ` + r.scope.condition("idSeguradora")
 	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações (o registro deve
+		// ser visível antes das demais verificações)
+		anterior, err := lockedBefore(ctx, tx, r.scope, "sistema_contabil_config", "idSistemaContabilConfig", config.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		// Validar o sistema contábil, o objeto de contabilização e o evento referenciados
+		if err := validateReferenciasConfig(sqlConfigLookup{ctx, tx}, config); err != nil {
+			return err
+		}
+		
+		// Validar as contas de débito e crédito no plano de contas
+		if err := validateContasConfig(sqlContaLookup{ctx, tx}, config); err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			r.scope.args(
+				config.IdSistemaContabil, 
+				config.IdObjetoContabilizacao, 
+				config.IdCodigoEvento, 
+				config.IdSeguradora, 
+				config.Ativo, 
+				config.IdContaDebito,
+				config.IdContaCredito,
+				config.ID,
+			)...,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar configuração: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeSistemaContabilConfig, config.ID, anterior, config)
+	})
 }
 
 // Delete remove uma configuração do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *SistemaContabilConfigRepository) Delete(id int64) error {
+func (r *SistemaContabilConfigRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM sistema_contabil_config WHERE idSistemaContabilConfig = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE sistema_contabil_config SET ativo = false WHERE idSistemaContabilConfig = ?`
+	query := `UPDATE sistema_contabil_config SET ativo = false WHERE idSistemaContabilConfig = ? AND ` + r.scope.condition("idSeguradora")
 	
-	_, err := r.DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("erro ao excluir configuração: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, r.scope, "sistema_contabil_config", "idSistemaContabilConfig", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(id)...); err != nil {
+			return fmt.Errorf("erro ao excluir configuração: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeSistemaContabilConfig, id, anterior, &excluido)
+	})
 }
 
 // validateSistemaContabilConfig valida os dados de uma configuração
@@ -335,3 +465,171 @@ func validateSistemaContabilConfig(c *SistemaContabilConfig) error {
 	
 	return nil
 }
+
+// referenciaConfig é a seguradora e a situação de um registro referenciado por uma configuração
+type referenciaConfig struct {
+	idSeguradora int64
+	ativo        bool
+}
+
+// configLookup consulta os registros referenciados pelas configurações de sistema contábil.
+// Cada método retorna sql.ErrNoRows se o registro não existir.
+type configLookup interface {
+	sistemaDaConfig(idSistemaContabil int64) (referenciaConfig, error)
+	objetoDaConfig(idObjetoContabilizacao int64) (referenciaConfig, error)
+	eventoDaConfig(idCodigoEvento int64) (referenciaConfig, error)
+	// relacaoDaConfig retorna a relação entre o objeto e o evento, preferindo a relação ativa
+	relacaoDaConfig(idObjetoContabilizacao, idCodigoEvento int64) (referenciaConfig, error)
+}
+
+// sqlConfigLookup consulta os registros referenciados no banco de dados ou em uma transação
+type sqlConfigLookup struct {
+	ctx context.Context
+	q   rowQuerier
+}
+
+func (l sqlConfigLookup) referencia(query string, args ...interface{}) (referenciaConfig, error) {
+	var ref referenciaConfig
+	err := l.q.QueryRowContext(l.ctx, query, args...).Scan(&ref.idSeguradora, &ref.ativo)
+	return ref, err
+}
+
+func (l sqlConfigLookup) sistemaDaConfig(idSistemaContabil int64) (referenciaConfig, error) {
+	return l.referencia("SELECT idSeguradora, ativo FROM sistema_contabil WHERE idSistemaContabil = ?", idSistemaContabil)
+}
+
+func (l sqlConfigLookup) objetoDaConfig(idObjetoContabilizacao int64) (referenciaConfig, error) {
+	return l.referencia("SELECT idSeguradora, ativo FROM objeto_contabilizacao WHERE idObjetoContabilizacao = ?", idObjetoContabilizacao)
+}
+
+func (l sqlConfigLookup) eventoDaConfig(idCodigoEvento int64) (referenciaConfig, error) {
+	return l.referencia("SELECT idSeguradora, ativo FROM eventos WHERE idCodigoEvento = ?", idCodigoEvento)
+}
+
+func (l sqlConfigLookup) relacaoDaConfig(idObjetoContabilizacao, idCodigoEvento int64) (referenciaConfig, error) {
+	return l.referencia(`
+	SELECT idSeguradora, ativo
+	FROM objeto_contabilizacao_evento
+	WHERE idObjetoContabilizacao = ? AND idCodigoEvento = ?
+	ORDER BY ativo DESC
+	LIMIT 1`, idObjetoContabilizacao, idCodigoEvento)
+}
+
+// validateReferenciasConfig valida o sistema contábil, o objeto de contabilização e o evento da
+// configuração: devem existir e pertencer à seguradora da configuração, e o objeto e o evento devem
+// estar relacionados. Em uma configuração ativa, os registros referenciados e a relação também devem
+// estar ativos (uma configuração pode ser desativada depois que eles forem desativados).
+func validateReferenciasConfig(l configLookup, c *SistemaContabilConfig) error {
+	referencias := []struct {
+		field  string
+		nome   string
+		id     int64
+		buscar func(int64) (referenciaConfig, error)
+	}{
+		{"idSistemaContabil", "sistema contábil", c.IdSistemaContabil, l.sistemaDaConfig},
+		{"idObjetoContabilizacao", "objeto de contabilização", c.IdObjetoContabilizacao, l.objetoDaConfig},
+		{"idCodigoEvento", "evento", c.IdCodigoEvento, l.eventoDaConfig},
+	}
+	
+	for _, referencia := range referencias {
+		ref, err := referencia.buscar(referencia.id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ForeignKeyError{
+					Message: fmt.Sprintf("%s %d não encontrado", referencia.nome, referencia.id),
+					Field:   referencia.field,
+				}
+			}
+			return fmt.Errorf("erro ao buscar %s: %w", referencia.nome, err)
+		}
+		
+		if ref.idSeguradora != c.IdSeguradora {
+			return ReferenceError{
+				Message: fmt.Sprintf("%s %d pertence a outra seguradora", referencia.nome, referencia.id),
+				Field:   referencia.field,
+			}
+		}
+		if c.Ativo && !ref.ativo {
+			return ReferenceError{
+				Message: fmt.Sprintf("%s %d está inativo", referencia.nome, referencia.id),
+				Field:   referencia.field,
+			}
+		}
+	}
+	
+	// O par objeto e evento deve estar cadastrado em objeto_contabilizacao_evento
+	relacao, err := l.relacaoDaConfig(c.IdObjetoContabilizacao, c.IdCodigoEvento)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("erro ao buscar relação entre objeto de contabilização e evento: %w", err)
+	}
+	if err == sql.ErrNoRows || relacao.idSeguradora != c.IdSeguradora {
+		return ReferenceError{
+			Message: fmt.Sprintf("evento %d não está relacionado ao objeto de contabilização %d", c.IdCodigoEvento, c.IdObjetoContabilizacao),
+			Field:   "idCodigoEvento",
+		}
+	}
+	if c.Ativo && !relacao.ativo {
+		return ReferenceError{
+			Message: fmt.Sprintf("relação entre o objeto de contabilização %d e o evento %d está inativa", c.IdObjetoContabilizacao, c.IdCodigoEvento),
+			Field:   "idCodigoEvento",
+		}
+	}
+	
+	return nil
+}
+
+// validateContasConfig valida as contas de débito e crédito da configuração no plano de contas:
+// devem ser informadas em conjunto, ser diferentes, ativas, analíticas e do mesmo plano (seguradora e sistema contábil)
+func validateContasConfig(l contaLookup, c *SistemaContabilConfig) error {
+	if c.IdContaDebito == nil && c.IdContaCredito == nil {
+		return nil
+	}
+	if c.IdContaDebito == nil || c.IdContaCredito == nil {
+		return utils.ValidationError{
+			Field:   "idContaDebito",
+			Message: "contas de débito e crédito devem ser informadas em conjunto",
+		}
+	}
+	if *c.IdContaDebito == *c.IdContaCredito {
+		return utils.ValidationError{
+			Field:   "idContaCredito",
+			Message: "deve ser diferente da conta de débito",
+		}
+	}
+	
+	contas := []struct {
+		field string
+		id    int64
+	}{
+		{"idContaDebito", *c.IdContaDebito},
+		{"idContaCredito", *c.IdContaCredito},
+	}
+	
+	for _, conta := range contas {
+		cadastrada, err := l.conta(conta.id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return utils.ValidationError{Field: conta.field, Message: "conta não encontrada no plano de contas"}
+			}
+			return fmt.Errorf("erro ao buscar conta: %w", err)
+		}
+		
+		if cadastrada.IdSeguradora != c.IdSeguradora || cadastrada.IdSistemaContabil != c.IdSistemaContabil {
+			return ReferenceError{
+				Message: fmt.Sprintf("conta %d pertence a outro plano de contas", conta.id),
+				Field:   conta.field,
+			}
+		}
+		if !cadastrada.Ativo {
+			return ReferenceError{
+				Message: fmt.Sprintf("conta %d está inativa", conta.id),
+				Field:   conta.field,
+			}
+		}
+		if cadastrada.Tipo != TipoContaAnalitica {
+			return utils.ValidationError{Field: conta.field, Message: "conta deve ser analítica"}
+		}
+	}
+	
+	return nil
+}
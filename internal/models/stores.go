@@ -0,0 +1,276 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// UsuarioStore armazena os usuários. WithTenant e WithActor retornam uma cópia restrita à
+// seguradora do escopo e que registra o histórico de alterações em nome do usuário informado.
+type UsuarioStore interface {
+	WithTenant(scope TenantScope) UsuarioStore
+	WithActor(actor Actor) UsuarioStore
+	// Create cria um usuário, gravando o hash da senha
+	Create(ctx context.Context, usuario *Usuario) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[Usuario], error)
+	GetByID(ctx context.Context, id int64) (*Usuario, error)
+	// GetByLogin busca um usuário pelo login, com o hash da senha, sem restrição de seguradora
+	GetByLogin(ctx context.Context, login string) (*Usuario, error)
+	Update(ctx context.Context, usuario *Usuario) error
+	UpdatePassword(ctx context.Context, id int64, novaSenha string) error
+	Delete(ctx context.Context, id int64) error
+	// VerifyPassword verifica a senha do usuário ativo e não bloqueado com o login informado
+	VerifyPassword(ctx context.Context, login, senha string) (*Usuario, error)
+}
+
+// TipoPerfilStore armazena os tipos de perfil
+type TipoPerfilStore interface {
+	WithActor(actor Actor) TipoPerfilStore
+	Create(ctx context.Context, tipoPerfil *TipoPerfil) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[TipoPerfil], error)
+	GetByID(ctx context.Context, id int64) (*TipoPerfil, error)
+	Update(ctx context.Context, tipoPerfil *TipoPerfil) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// PermissaoStore armazena as permissões e as permissões concedidas a cada tipo de perfil
+type PermissaoStore interface {
+	WithActor(actor Actor) PermissaoStore
+	Create(ctx context.Context, permissao *Permissao) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[Permissao], error)
+	GetByID(ctx context.Context, id int64) (*Permissao, error)
+	GetByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]Permissao, error)
+	// GetNomesByTipoPerfil retorna os nomes das permissões ativas de um tipo de perfil ativo
+	GetNomesByTipoPerfil(ctx context.Context, idTipoPerfil int64) ([]string, error)
+	Update(ctx context.Context, permissao *Permissao) error
+	Delete(ctx context.Context, id int64) error
+	Grant(ctx context.Context, idTipoPerfil int64, nome string) error
+	Revoke(ctx context.Context, idTipoPerfil int64, nome string) error
+	// ReplaceForTipoPerfil substitui todas as permissões do tipo de perfil pelas informadas
+	ReplaceForTipoPerfil(ctx context.Context, idTipoPerfil int64, nomes []string) error
+}
+
+// SeguradoraStore armazena as seguradoras
+type SeguradoraStore interface {
+	WithTenant(scope TenantScope) SeguradoraStore
+	WithActor(actor Actor) SeguradoraStore
+	Create(ctx context.Context, seguradora *Seguradora) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[Seguradora], error)
+	GetByID(ctx context.Context, id int64) (*Seguradora, error)
+	Update(ctx context.Context, seguradora *Seguradora) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// EventoStore armazena os eventos
+type EventoStore interface {
+	WithTenant(scope TenantScope) EventoStore
+	WithActor(actor Actor) EventoStore
+	Create(ctx context.Context, evento *Evento) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[Evento], error)
+	GetByID(ctx context.Context, id int64) (*Evento, error)
+	GetByNumero(ctx context.Context, idSeguradora int64, numero int) (*Evento, error)
+	GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[Evento], error)
+	Update(ctx context.Context, evento *Evento) error
+	Delete(ctx context.Context, id int64) error
+	// Import valida e cria em lote os eventos das linhas de um arquivo (somente valida na simulação)
+	Import(ctx context.Context, idSeguradora int64, linhas []LinhaArquivo, simular bool) (*ResultadoImportacao, error)
+}
+
+// ObjetoContabilizacaoStore armazena os objetos de contabilização
+type ObjetoContabilizacaoStore interface {
+	WithTenant(scope TenantScope) ObjetoContabilizacaoStore
+	WithActor(actor Actor) ObjetoContabilizacaoStore
+	Create(ctx context.Context, objeto *ObjetoContabilizacao) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[ObjetoContabilizacao], error)
+	GetByID(ctx context.Context, id int64) (*ObjetoContabilizacao, error)
+	GetByCodigo(ctx context.Context, idSeguradora int64, codigo string) (*ObjetoContabilizacao, error)
+	GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[ObjetoContabilizacao], error)
+	Update(ctx context.Context, objeto *ObjetoContabilizacao) error
+	Delete(ctx context.Context, id int64) error
+	// Import valida e cria em lote os objetos das linhas de um arquivo (somente valida na simulação)
+	Import(ctx context.Context, idSeguradora int64, linhas []LinhaArquivo, simular bool) (*ResultadoImportacao, error)
+}
+
+// ObjetoContabilizacaoEventoStore armazena as relações entre objetos de contabilização e eventos
+type ObjetoContabilizacaoEventoStore interface {
+	WithTenant(scope TenantScope) ObjetoContabilizacaoEventoStore
+	WithActor(actor Actor) ObjetoContabilizacaoEventoStore
+	Create(ctx context.Context, relacao *ObjetoContabilizacaoEvento) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[ObjetoContabilizacaoEvento], error)
+	GetByID(ctx context.Context, id int64) (*ObjetoContabilizacaoEvento, error)
+	GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[ObjetoContabilizacaoEvento], error)
+	Update(ctx context.Context, relacao *ObjetoContabilizacaoEvento) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// SistemaContabilStore armazena os sistemas contábeis
+type SistemaContabilStore interface {
+	WithTenant(scope TenantScope) SistemaContabilStore
+	WithActor(actor Actor) SistemaContabilStore
+	Create(ctx context.Context, sistema *SistemaContabil) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[SistemaContabil], error)
+	GetByID(ctx context.Context, id int64) (*SistemaContabil, error)
+	GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[SistemaContabil], error)
+	Update(ctx context.Context, sistema *SistemaContabil) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// SistemaContabilConfigStore armazena as configurações do sistema contábil
+type SistemaContabilConfigStore interface {
+	WithTenant(scope TenantScope) SistemaContabilConfigStore
+	WithActor(actor Actor) SistemaContabilConfigStore
+	Create(ctx context.Context, config *SistemaContabilConfig) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[SistemaContabilConfig], error)
+	// Export percorre todas as configurações que atendem aos filtros, na ordem solicitada
+	Export(ctx context.Context, opts ListOptions, fn func(SistemaContabilConfig) error) error
+	GetByID(ctx context.Context, id int64) (*SistemaContabilConfig, error)
+	GetBySeguradora(ctx context.Context, idSeguradora int64, opts ListOptions) (*Page[SistemaContabilConfig], error)
+	GetBySistemaContabil(ctx context.Context, idSistemaContabil int64, opts ListOptions) (*Page[SistemaContabilConfig], error)
+	// GetAtivasByEventoObjeto retorna as configurações ativas usadas na geração dos lançamentos
+	GetAtivasByEventoObjeto(ctx context.Context, idSeguradora, idCodigoEvento, idObjetoContabilizacao int64) ([]SistemaContabilConfig, error)
+	Update(ctx context.Context, config *SistemaContabilConfig) error
+	Delete(ctx context.Context, id int64) error
+}
+
+// PlanoContasStore armazena as contas contábeis dos planos de contas
+type PlanoContasStore interface {
+	WithTenant(scope TenantScope) PlanoContasStore
+	WithActor(actor Actor) PlanoContasStore
+	Create(ctx context.Context, conta *ContaContabil) error
+	GetAll(ctx context.Context, opts ListOptions) (*Page[ContaContabil], error)
+	GetBySistemaContabil(ctx context.Context, idSistemaContabil int64, opts ListOptions) (*Page[ContaContabil], error)
+	GetByID(ctx context.Context, id int64) (*ContaContabil, error)
+	Update(ctx context.Context, conta *ContaContabil) error
+	Delete(ctx context.Context, id int64) error
+	// Import cria ou atualiza as contas das linhas informadas; nada é gravado se houver erros
+	Import(ctx context.Context, idSeguradora, idSistemaContabil int64, linhas []LinhaPlanoContas) (*ResultadoImportacaoPlanoContas, error)
+}
+
+// LancamentoStore armazena os lotes de lançamentos contábeis
+type LancamentoStore interface {
+	WithTenant(scope TenantScope) LancamentoStore
+	// CreateLote grava o lote com os lançamentos e as partidas informados
+	CreateLote(ctx context.Context, lote *LoteLancamento, lancamentos []Lancamento) error
+	GetLoteByID(ctx context.Context, id int64) (*LoteLancamento, error)
+	GetByLote(ctx context.Context, idLote int64) ([]Lancamento, error)
+	GetAll(ctx context.Context, opts ListOptions) (*Page[Lancamento], error)
+	GetByID(ctx context.Context, id int64) (*Lancamento, error)
+}
+
+// RefreshTokenStore armazena os refresh tokens das sessões
+type RefreshTokenStore interface {
+	Create(ctx context.Context, token *RefreshToken) error
+	// Rotate substitui o token informado pelo emitido por emitir, na mesma família, com os dados
+	// atuais do usuário. O reuso de um token já substituído revoga a família e retorna
+	// ErrRefreshTokenReutilizado.
+	Rotate(ctx context.Context, jti string, emitir func(usuario *Usuario) (*RefreshToken, error)) error
+	IsFamiliaAtiva(ctx context.Context, familia string) (bool, error)
+	GetAtivosByUsuario(ctx context.Context, idUsuario int64) ([]RefreshToken, error)
+	RevokeFamilia(ctx context.Context, familia, motivo string) error
+	// RevokeByUsuario revoga todas as sessões do usuário e retorna quantas estavam ativas
+	RevokeByUsuario(ctx context.Context, idUsuario int64, motivo string) (int64, error)
+}
+
+// AuditLogStore armazena o log de auditoria, encadeado por hash, e os pontos de verificação da cadeia
+type AuditLogStore interface {
+	Create(ctx context.Context, entry *AuditLog) error
+	// CreateBatch registra as entradas em uma única operação, encadeadas na ordem informada
+	CreateBatch(ctx context.Context, entries []*AuditLog) error
+	GetHistorico(ctx context.Context, entityType string, entityID int64, opts ListOptions) (*Page[AuditLog], error)
+	GetAll(ctx context.Context, opts ListOptions) (*Page[AuditLog], error)
+	Export(ctx context.Context, opts ListOptions, fn func(AuditLog) error) error
+	GetLoginAttempts(ctx context.Context, opts ListOptions) (*Page[LoginAttempt], error)
+	ExportLoginAttempts(ctx context.Context, opts ListOptions, fn func(LoginAttempt) error) error
+	// ChainHead retorna a última entrada encadeada do log
+	ChainHead(ctx context.Context) (*AuditChainHead, error)
+	CountChained(ctx context.Context, lastID int64) (int64, error)
+	CreateCheckpoint(ctx context.Context, cp *AuditCheckpoint) error
+	GetLastCheckpoint(ctx context.Context) (*AuditCheckpoint, error)
+	GetCheckpoints(ctx context.Context, opts ListOptions) (*Page[AuditCheckpoint], error)
+	AllCheckpoints(ctx context.Context) ([]AuditCheckpoint, error)
+	// VerifyChain percorre a cadeia e a compara com os pontos de verificação informados
+	VerifyChain(ctx context.Context, checkpoints []AuditCheckpoint) (*AuditChainVerification, error)
+}
+
+// LoginAttemptStore armazena as tentativas de login e o bloqueio temporário das contas
+type LoginAttemptStore interface {
+	Create(ctx context.Context, attempt *LoginAttempt) error
+	// CountRecentFailures conta as tentativas malsucedidas do login ou do IP nos últimos minutos
+	CountRecentFailures(ctx context.Context, login, ipAddress string, minutes int) (int, error)
+	// LockAccount bloqueia a conta e revoga as suas sessões
+	LockAccount(ctx context.Context, login string, until time.Time) error
+	// GetAccountLock retorna o status de bloqueio da conta (não bloqueada se o login não existir)
+	GetAccountLock(ctx context.Context, login string) (bool, time.Time, error)
+	UnlockAccount(ctx context.Context, login string) error
+}
+
+// UnitOfWork executa operações de vários armazenamentos de forma atômica: as operações chamadas
+// com o contexto recebido por fn são confirmadas juntas, ou desfeitas se fn retornar erro ou entrar
+// em pânico
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// Stores reúne os armazenamentos usados pelos handlers e serviços
+type Stores struct {
+	Usuarios                    UsuarioStore
+	TiposPerfil                 TipoPerfilStore
+	Permissoes                  PermissaoStore
+	Seguradoras                 SeguradoraStore
+	Eventos                     EventoStore
+	ObjetosContabilizacao       ObjetoContabilizacaoStore
+	ObjetosContabilizacaoEvento ObjetoContabilizacaoEventoStore
+	SistemasContabeis           SistemaContabilStore
+	SistemasContabeisConfig     SistemaContabilConfigStore
+	PlanoContas                 PlanoContasStore
+	Lancamentos                 LancamentoStore
+	RefreshTokens               RefreshTokenStore
+	AuditLog                    AuditLogStore
+	LoginAttempts               LoginAttemptStore
+	UnitOfWork                  UnitOfWork
+}
+
+// NewStores cria os armazenamentos sobre o banco de dados
+func NewStores(db *sql.DB) *Stores {
+	return &Stores{
+		Usuarios:                    NewUsuarioRepository(db),
+		TiposPerfil:                 NewTipoPerfilRepository(db),
+		Permissoes:                  NewPermissaoRepository(db),
+		Seguradoras:                 NewSeguradoraRepository(db),
+		Eventos:                     NewEventoRepository(db),
+		ObjetosContabilizacao:       NewObjetoContabilizacaoRepository(db),
+		ObjetosContabilizacaoEvento: NewObjetoContabilizacaoEventoRepository(db),
+		SistemasContabeis:           NewSistemaContabilRepository(db),
+		SistemasContabeisConfig:     NewSistemaContabilConfigRepository(db),
+		PlanoContas:                 NewPlanoContasRepository(db),
+		Lancamentos:                 NewLancamentoRepository(db),
+		RefreshTokens:               NewRefreshTokenRepository(db),
+		AuditLog:                    NewAuditLogRepository(db),
+		LoginAttempts:               NewLoginAttemptRepository(db),
+		UnitOfWork:                  NewUnitOfWork(db),
+	}
+}
+
+// NewMemoryStores cria armazenamentos em memória, que compartilham os mesmos dados, para executar
+// a aplicação sem banco de dados (testes e demonstrações)
+func NewMemoryStores() *Stores {
+	db := NewMemoryDB()
+	return &Stores{
+		Usuarios:                    NewMemoryUsuarioStore(db),
+		TiposPerfil:                 NewMemoryTipoPerfilStore(db),
+		Permissoes:                  NewMemoryPermissaoStore(db),
+		Seguradoras:                 NewMemorySeguradoraStore(db),
+		Eventos:                     NewMemoryEventoStore(db),
+		ObjetosContabilizacao:       NewMemoryObjetoContabilizacaoStore(db),
+		ObjetosContabilizacaoEvento: NewMemoryObjetoContabilizacaoEventoStore(db),
+		SistemasContabeis:           NewMemorySistemaContabilStore(db),
+		SistemasContabeisConfig:     NewMemorySistemaContabilConfigStore(db),
+		PlanoContas:                 NewMemoryPlanoContasStore(db),
+		Lancamentos:                 NewMemoryLancamentoStore(db),
+		RefreshTokens:               NewMemoryRefreshTokenStore(db),
+		AuditLog:                    NewMemoryAuditLogStore(db),
+		LoginAttempts:               NewMemoryLoginAttemptStore(db),
+		UnitOfWork:                  NewMemoryUnitOfWork(db),
+	}
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ErrCrossTenant indica uma tentativa de acessar dados de outra seguradora
+var ErrCrossTenant = errors.New("acesso a dados de outra seguradora não permitido")
+
+// TenantScope define a seguradora visível para as consultas de um repositório
+type TenantScope struct {
+	IdSeguradora int64 // Seguradora do usuário (ou a selecionada explicitamente por um AdminERP)
+	AdminERP     bool  // Indica se o usuário é administrador do ERP
+	Todas        bool  // AdminERP acessando explicitamente todas as seguradoras
+}
+
+// Allows verifica se o escopo permite acessar dados da seguradora informada
+func (s *TenantScope) Allows(idSeguradora int64) bool {
+	// Repositórios sem escopo são usados apenas internamente (ex.: login)
+	if s == nil || s.Todas {
+		return true
+	}
+	return s.IdSeguradora > 0 && s.IdSeguradora == idSeguradora
+}
+
+// condition retorna a condição SQL de filtro por seguradora para a coluna informada
+func (s *TenantScope) condition(column string) string {
+	if s == nil || s.Todas {
+		return "1 = 1"
+	}
+	return column + " = ?"
+}
+
+// args retorna os argumentos correspondentes à condição de filtro por seguradora
+func (s *TenantScope) args(args ...interface{}) []interface{} {
+	if s == nil || s.Todas {
+		return args
+	}
+	return append(args, s.IdSeguradora)
+}
+
+// crossTenant trata o registro não encontrado no escopo em uma alteração ou exclusão: se ele existir
+// em outra seguradora, retorna ErrCrossTenant, para que a tentativa seja recusada (403) e registrada na
+// auditoria; caso contrário, retorna o erro original
+func (s *TenantScope) crossTenant(ctx context.Context, db dbConn, table, idColumn string, id int64, err error) error {
+	if s == nil || s.Todas || !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	
+	var existe int
+	errExiste := db.QueryRowContext(ctx, "SELECT 1 FROM "+table+" WHERE "+idColumn+" = ?", id).Scan(&existe)
+	switch {
+	case errExiste == nil:
+		return ErrCrossTenant
+	case errExiste == sql.ErrNoRows:
+		return err
+	default:
+		return fmt.Errorf("erro ao verificar a seguradora do registro: %w", errExiste)
+	}
+}
+
+// scopeCopy retorna uma cópia do escopo para ser armazenada em um repositório
+func scopeCopy(scope TenantScope) *TenantScope {
+	return &scope
+}
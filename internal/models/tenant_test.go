@@ -0,0 +1,140 @@
+package models_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/database"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// newSQLiteStores cria os armazenamentos sobre um banco SQLite temporário, com todas as migrações aplicadas
+func newSQLiteStores(t *testing.T) *models.Stores {
+	t.Helper()
+
+	dialect.Set(dialect.SQLite)
+	db, err := database.Connect(dialect.SQLite, "file:"+filepath.Join(t.TempDir(), "teste.db")+"?_foreign_keys=on")
+	if err != nil {
+		t.Fatalf("erro ao conectar ao SQLite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := database.Migrate(db); err != nil {
+		t.Fatalf("erro ao aplicar as migrações: %v", err)
+	}
+	return models.NewStores(db)
+}
+
+// storeFactories retorna as implementações dos armazenamentos exercitadas pelos testes
+func storeFactories() map[string]func(t *testing.T) *models.Stores {
+	return map[string]func(t *testing.T) *models.Stores{
+		"sqlite": newSQLiteStores,
+		"memory": func(t *testing.T) *models.Stores { return models.NewMemoryStores() },
+	}
+}
+
+// createSeguradoras cria duas seguradoras ativas e retorna os seus IDs
+func createSeguradoras(t *testing.T, stores *models.Stores) (int64, int64) {
+	t.Helper()
+
+	var ids []int64
+	for _, nome := range []string{"Seguradora A", "Seguradora B"} {
+		s := &models.Seguradora{Nome: nome, Ativo: true}
+		if err := stores.Seguradoras.Create(context.Background(), s); err != nil {
+			t.Fatalf("erro ao criar seguradora: %v", err)
+		}
+		ids = append(ids, s.ID)
+	}
+	return ids[0], ids[1]
+}
+
+func TestEscritaEmOutraSeguradora(t *testing.T) {
+	for name, newStores := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stores := newStores(t)
+			seguradoraA, seguradoraB := createSeguradoras(t, stores)
+
+			evento := &models.Evento{Evento: 101, Descricao: "Emissão de apólice", IdSeguradora: seguradoraB, Ativo: true}
+			if err := stores.Eventos.Create(ctx, evento); err != nil {
+				t.Fatalf("erro ao criar evento: %v", err)
+			}
+
+			repo := stores.Eventos.WithTenant(models.TenantScope{IdSeguradora: seguradoraA})
+
+			// Alterar ou excluir um registro existente de outra seguradora é ErrCrossTenant
+			alterado := &models.Evento{ID: evento.ID, Evento: 101, Descricao: "Alterado", IdSeguradora: seguradoraA, Ativo: true}
+			if err := repo.Update(ctx, alterado); !errors.Is(err, models.ErrCrossTenant) {
+				t.Errorf("Update em outra seguradora: %v, esperado ErrCrossTenant", err)
+			}
+			if err := repo.Delete(ctx, evento.ID); !errors.Is(err, models.ErrCrossTenant) {
+				t.Errorf("Delete em outra seguradora: %v, esperado ErrCrossTenant", err)
+			}
+			if err := stores.Seguradoras.WithTenant(models.TenantScope{IdSeguradora: seguradoraA}).Delete(ctx, seguradoraB); !errors.Is(err, models.ErrCrossTenant) {
+				t.Errorf("Delete de outra seguradora: %v, esperado ErrCrossTenant", err)
+			}
+
+			// A leitura continua como não encontrado, e um registro inexistente também
+			if _, err := repo.GetByID(ctx, evento.ID); !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("GetByID em outra seguradora: %v, esperado ErrNotFound", err)
+			}
+			if err := repo.Delete(ctx, 999); !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("Delete de registro inexistente: %v, esperado ErrNotFound", err)
+			}
+
+			// O evento não foi alterado
+			gravado, err := stores.Eventos.GetByID(ctx, evento.ID)
+			if err != nil {
+				t.Fatalf("erro ao buscar evento: %v", err)
+			}
+			if gravado.Descricao != evento.Descricao || !gravado.Ativo {
+				t.Errorf("evento de outra seguradora alterado: %+v", gravado)
+			}
+		})
+	}
+}
+
+func TestUpdatePasswordForaDoEscopo(t *testing.T) {
+	for name, newStores := range storeFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			stores := newStores(t)
+			seguradoraA, seguradoraB := createSeguradoras(t, stores)
+
+			tipoPerfil := &models.TipoPerfil{Perfil: "Operador", Ativo: true}
+			if err := stores.TiposPerfil.Create(ctx, tipoPerfil); err != nil {
+				t.Fatalf("erro ao criar tipo de perfil: %v", err)
+			}
+			usuario := &models.Usuario{Nome: "Maria", Email: "maria@exemplo.com.br", Login: "maria", Senha: "Senha@Forte123", IdTipoPerfil: int(tipoPerfil.ID), IdSeguradora: int(seguradoraB), Ativo: true}
+			if err := stores.Usuarios.Create(ctx, usuario); err != nil {
+				t.Fatalf("erro ao criar usuário: %v", err)
+			}
+
+			// Usuário de outra seguradora e usuário inexistente: a senha não é trocada nem registrada
+			repo := stores.Usuarios.WithTenant(models.TenantScope{IdSeguradora: seguradoraA})
+			if err := repo.UpdatePassword(ctx, usuario.ID, "Outra@Senha456"); !errors.Is(err, models.ErrCrossTenant) {
+				t.Errorf("UpdatePassword em outra seguradora: %v, esperado ErrCrossTenant", err)
+			}
+			if err := stores.Usuarios.UpdatePassword(ctx, 999, "Outra@Senha456"); !errors.Is(err, models.ErrNotFound) {
+				t.Errorf("UpdatePassword de usuário inexistente: %v, esperado ErrNotFound", err)
+			}
+			for _, id := range []int64{usuario.ID, 999} {
+				historico, err := stores.AuditLog.GetHistorico(ctx, models.EntidadeUsuario, id, models.ListOptions{Page: 1, PageSize: 10})
+				if err != nil {
+					t.Fatalf("erro ao buscar histórico: %v", err)
+				}
+				for _, entry := range historico.Items {
+					if entry.Action != models.AcaoCriacao {
+						t.Errorf("usuário %d com entrada %s no histórico", id, entry.Action)
+					}
+				}
+			}
+			if _, err := stores.Usuarios.VerifyPassword(ctx, "maria", "Senha@Forte123"); err != nil {
+				t.Errorf("senha original não aceita: %v", err)
+			}
+		})
+	}
+}
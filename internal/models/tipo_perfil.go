@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -19,7 +20,8 @@ type TipoPerfil struct {
 
 // TipoPerfilRepository gerencia operações de banco de dados para tipos de perfil
 type TipoPerfilRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	actor *Actor
 }
 
 // NewTipoPerfilRepository cria um novo repositório de tipos de perfil
@@ -27,8 +29,16 @@ func NewTipoPerfilRepository(db *sql.DB) *TipoPerfilRepository {
 	return &TipoPerfilRepository{DB: db}
 }
 
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *TipoPerfilRepository) WithActor(actor Actor) TipoPerfilStore {
+	return &TipoPerfilRepository{DB: r.DB, actor: &actor}
+}
+
 // Create insere um novo tipo de perfil no banco de dados
-func (r *TipoPerfilRepository) Create(tipoPerfil *TipoPerfil) error {
+func (r *TipoPerfilRepository) Create(ctx context.Context, tipoPerfil *TipoPerfil) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do tipo de perfil
 	if err := validateTipoPerfil(tipoPerfil); err != nil {
 		return err
@@ -42,38 +52,56 @@ func (r *TipoPerfilRepository) Create(tipoPerfil *TipoPerfil) error {
 	(perfil, ativo) 
 	VALUES (?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		tipoPerfil.Perfil, 
-		tipoPerfil.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar tipo de perfil: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID do tipo de perfil: %v", err)
-	}
-	
-	tipoPerfil.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "id_tipo_perfil",
+			query, 
+			tipoPerfil.Perfil, 
+			tipoPerfil.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar tipo de perfil: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID do tipo de perfil: %w", err)
+		}
+		
+		tipoPerfil.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeTipoPerfil, id, nil, tipoPerfil)
+	})
+}
+
+// tipoPerfilListSpec define os campos de ordenação e filtro da listagem de tipos de perfil
+var tipoPerfilListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"id":         {Column: "id_tipo_perfil", Type: FieldInt},
+		"perfil":     {Column: "perfil", Type: FieldString},
+		"created_at": {Column: "created_at", Type: FieldTime},
+		"updated_at": {Column: "updated_at", Type: FieldTime},
+		"ativo":      {Column: "ativo", Type: FieldBool},
+	},
+	Key: "id",
 }
 
-// GetAll retorna todos os tipos de perfil do banco de dados
-func (r *TipoPerfilRepository) GetAll() ([]TipoPerfil, error) {
+// GetAll retorna os tipos de perfil com paginação, ordenação e filtros
+func (r *TipoPerfilRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[TipoPerfil], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id_tipo_perfil, perfil, created_at, updated_at, ativo 
 	FROM tipo_perfil 
-	ORDER BY id_tipo_perfil DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar tipos de perfil: %v", err)
-	}
-	defer rows.Close()
+	WHERE 1 = 1`
 	
+	return listPage(ctx, r.DB, &tipoPerfilListSpec, opts, query, nil, scanTiposPerfil)
+}
+
+// scanTiposPerfil lê os tipos de perfil retornados por uma consulta
+func scanTiposPerfil(rows *sql.Rows) ([]TipoPerfil, error) {
 	var tiposPerfil []TipoPerfil
 	
 	for rows.Next() {
@@ -85,20 +113,23 @@ func (r *TipoPerfilRepository) GetAll() ([]TipoPerfil, error) {
 			&tp.UpdatedAt, 
 			&tp.Ativo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler tipo de perfil: %v", err)
+			return nil, fmt.Errorf("erro ao ler tipo de perfil: %w", err)
 		}
 		tiposPerfil = append(tiposPerfil, tp)
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre tipos de perfil: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre tipos de perfil: %w", err)
 	}
 	
 	return tiposPerfil, nil
 }
 
 // GetByID busca um tipo de perfil pelo ID
-func (r *TipoPerfilRepository) GetByID(id int64) (*TipoPerfil, error) {
+func (r *TipoPerfilRepository) GetByID(ctx context.Context, id int64) (*TipoPerfil, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id_tipo_perfil, perfil, created_at, updated_at, ativo 
@@ -106,7 +137,7 @@ func (r *TipoPerfilRepository) GetByID(id int64) (*TipoPerfil, error) {
 	WHERE id_tipo_perfil = ?`
 	
 	var tp TipoPerfil
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, id).Scan(
 		&tp.ID, 
 		&tp.Perfil, 
 		&tp.CreatedAt, 
@@ -115,16 +146,19 @@ func (r *TipoPerfilRepository) GetByID(id int64) (*TipoPerfil, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("tipo de perfil não encontrado")
+			return nil, NotFoundError{Message: "tipo de perfil não encontrado"}
 		}
-		return nil, fmt.Errorf("erro ao buscar tipo de perfil: %v", err)
+		return nil, fmt.Errorf("erro ao buscar tipo de perfil: %w", err)
 	}
 	
 	return &tp, nil
 }
 
 // Update atualiza os dados de um tipo de perfil existente
-func (r *TipoPerfilRepository) Update(tipoPerfil *TipoPerfil) error {
+func (r *TipoPerfilRepository) Update(ctx context.Context, tipoPerfil *TipoPerfil) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do tipo de perfil
 	if err := validateTipoPerfil(tipoPerfil); err != nil {
 		return err
@@ -138,33 +172,55 @@ func (r *TipoPerfilRepository) Update(tipoPerfil *TipoPerfil) error {
 	SET perfil = ?, ativo = ? 
 	WHERE id_tipo_perfil = ?`
 	
-	_, err := r.DB.Exec(
-		query, 
-		tipoPerfil.Perfil, 
-		tipoPerfil.Ativo, 
-		tipoPerfil.ID,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar tipo de perfil: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, nil, "tipo_perfil", "id_tipo_perfil", tipoPerfil.ID, r.GetByID)
+		if err != nil {
+			return err
+		}
+		
+		_, err = tx.ExecContext(ctx, 
+			query, 
+			tipoPerfil.Perfil, 
+			tipoPerfil.Ativo, 
+			tipoPerfil.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar tipo de perfil: %w", err)
+		}
+		
+		// Registrar a alteração no histórico
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeTipoPerfil, tipoPerfil.ID, anterior, tipoPerfil)
+	})
 }
 
 // Delete remove um tipo de perfil do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *TipoPerfilRepository) Delete(id int64) error {
+func (r *TipoPerfilRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM tipo_perfil WHERE id_tipo_perfil = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
 	query := `UPDATE tipo_perfil SET ativo = false WHERE id_tipo_perfil = ?`
 	
-	_, err := r.DB.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("erro ao excluir tipo de perfil: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+		anterior, err := lockedBefore(ctx, tx, nil, "tipo_perfil", "id_tipo_perfil", id, r.GetByID)
+		if err != nil {
+			return err
+		}
+		excluido := *anterior
+		excluido.Ativo = false
+		
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("erro ao excluir tipo de perfil: %w", err)
+		}
+		
+		// Registrar a exclusão no histórico
+		return recordChange(ctx, tx, r.actor, AcaoExclusao, EntidadeTipoPerfil, id, anterior, &excluido)
+	})
 }
 
 // validateTipoPerfil valida os dados de um tipo de perfil
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Tentativas de uma unidade de trabalho interrompida por deadlock e espera entre elas
+const (
+	maxTentativasDeadlock = 3
+	esperaDeadlock        = 50 * time.Millisecond
+)
+
+// txKey identifica no contexto a transação da unidade de trabalho em andamento
+type txKey struct{}
+
+// InTransaction informa se o contexto pertence a uma unidade de trabalho em andamento
+func InTransaction(ctx context.Context) bool {
+	return ctx.Value(txKey{}) != nil
+}
+
+// dbConn representa um banco ou transação capaz de executar instruções e consultas
+type dbConn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// withTx retorna o contexto com a transação informada, para que os repositórios chamados com ele participem dela
+func withTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// connFor retorna a transação da unidade de trabalho do contexto ou, fora de uma unidade de trabalho, o banco
+func connFor(ctx context.Context, db *sql.DB) dbConn {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// unitTx é uma transação dos repositórios. Dentro de uma unidade de trabalho, é a transação da
+// unidade de trabalho, que só é confirmada ou desfeita ao final dela: Commit e Rollback não têm efeito.
+type unitTx struct {
+	*sql.Tx
+	joined bool
+	ctx    context.Context
+	db     *sql.DB
+}
+
+// Commit confirma a transação, exceto dentro de uma unidade de trabalho, e encadeia as entradas de
+// auditoria gravadas nela
+func (t *unitTx) Commit() error {
+	if t.joined {
+		return nil
+	}
+	if err := t.Tx.Commit(); err != nil {
+		return err
+	}
+	
+	sealAuditLog(t.ctx, t.db)
+	return nil
+}
+
+// Rollback desfaz a transação, exceto dentro de uma unidade de trabalho
+func (t *unitTx) Rollback() error {
+	if t.joined {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// beginTx inicia uma transação, ou reutiliza a transação da unidade de trabalho do contexto
+func beginTx(ctx context.Context, db *sql.DB) (*unitTx, error) {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return &unitTx{Tx: tx, joined: true}, nil
+	}
+	
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &unitTx{Tx: tx, ctx: ctx, db: db}, nil
+}
+
+// SQLUnitOfWork executa unidades de trabalho em transações do banco de dados
+type SQLUnitOfWork struct {
+	DB *sql.DB
+}
+
+// NewUnitOfWork cria as unidades de trabalho sobre o banco de dados
+func NewUnitOfWork(db *sql.DB) *SQLUnitOfWork {
+	return &SQLUnitOfWork{DB: db}
+}
+
+// Do executa fn em uma transação, confirmada somente se fn não retornar erro e desfeita em caso de
+// erro ou pânico. Os repositórios chamados com o contexto recebido por fn participam da transação.
+// Interrompida por deadlock, a transação é desfeita e fn é executada novamente (até 3 tentativas),
+// por isso fn não deve ter efeitos fora do banco de dados. Dentro de outra unidade de trabalho, fn
+// participa da transação existente.
+func (u *SQLUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if InTransaction(ctx) {
+		return fn(ctx)
+	}
+	
+	for tentativa := 1; ; tentativa++ {
+		err := u.run(ctx, fn)
+		if err == nil || !isDeadlock(err) || tentativa == maxTentativasDeadlock {
+			return err
+		}
+		
+		// Aguardar antes de tentar novamente, para que a transação concorrente termine
+		select {
+		case <-time.After(time.Duration(tentativa) * esperaDeadlock):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// run executa uma tentativa da unidade de trabalho
+func (u *SQLUnitOfWork) run(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := u.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	// Desfaz a transação em caso de erro ou pânico (sem efeito após a confirmação)
+	defer tx.Rollback()
+	
+	if err := fn(withTx(ctx, tx)); err != nil {
+		return ClassifyDBError(err)
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+	
+	// Encadear as entradas de auditoria gravadas na unidade de trabalho
+	sealAuditLog(ctx, u.DB)
+	return nil
+}
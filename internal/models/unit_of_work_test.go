@@ -0,0 +1,147 @@
+package models_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// errDeadlock simula a interrupção da transação por deadlock no MySQL, com o erro embrulhado pelo repositório
+var errDeadlock = fmt.Errorf("erro ao alterar evento: %w", &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+
+// countEventos retorna a quantidade de eventos gravados
+func countEventos(t *testing.T, stores *models.Stores) int64 {
+	t.Helper()
+
+	page, err := stores.Eventos.GetAll(context.Background(), models.ListOptions{Page: 1, PageSize: 10})
+	if err != nil {
+		t.Fatalf("erro ao listar eventos: %v", err)
+	}
+	return page.Total
+}
+
+func TestUnitOfWorkRepeteAposDeadlock(t *testing.T) {
+	ctx := context.Background()
+	stores := newSQLiteStores(t)
+	seguradoraA, _ := createSeguradoras(t, stores)
+
+	// As duas primeiras tentativas são interrompidas depois de gravar o evento
+	tentativas := 0
+	err := stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		tentativas++
+		evento := &models.Evento{Evento: 100 + tentativas, Descricao: "Emissão de apólice", IdSeguradora: seguradoraA, Ativo: true}
+		if err := stores.Eventos.Create(ctx, evento); err != nil {
+			return err
+		}
+		if tentativas < 3 {
+			return errDeadlock
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("erro na unidade de trabalho: %v", err)
+	}
+	if tentativas != 3 {
+		t.Errorf("%d tentativas, esperadas 3", tentativas)
+	}
+
+	// Somente o evento da tentativa confirmada foi gravado, com o histórico encadeado
+	if n := countEventos(t, stores); n != 1 {
+		t.Errorf("%d eventos gravados, esperado 1", n)
+	}
+	if _, err := stores.Eventos.GetByNumero(ctx, seguradoraA, 103); err != nil {
+		t.Errorf("evento da terceira tentativa não encontrado: %v", err)
+	}
+	verification, err := stores.AuditLog.VerifyChain(ctx, nil)
+	if err != nil {
+		t.Fatalf("erro ao verificar a cadeia: %v", err)
+	}
+	if !verification.Valid || verification.CheckedEntries != 3 {
+		t.Errorf("verificação da cadeia = %+v, esperadas 3 entradas válidas (duas seguradoras e um evento)", verification)
+	}
+}
+
+func TestUnitOfWorkLimiteDeTentativas(t *testing.T) {
+	ctx := context.Background()
+	stores := newSQLiteStores(t)
+
+	// Deadlock em todas as tentativas (PostgreSQL): o erro é devolvido após a última
+	tentativas := 0
+	err := stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		tentativas++
+		return &pq.Error{Code: "40P01", Message: "deadlock detected"}
+	})
+	var postgresErr *pq.Error
+	if !errors.As(err, &postgresErr) || postgresErr.Code != "40P01" {
+		t.Errorf("erro = %v, esperado o deadlock da última tentativa", err)
+	}
+	if tentativas != 3 {
+		t.Errorf("%d tentativas, esperadas 3", tentativas)
+	}
+
+	// Outros erros não são repetidos
+	tentativas = 0
+	errNegocio := errors.New("saldo insuficiente")
+	err = stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		tentativas++
+		return errNegocio
+	})
+	if !errors.Is(err, errNegocio) || tentativas != 1 {
+		t.Errorf("erro %v após %d tentativas, esperado %v após 1", err, tentativas, errNegocio)
+	}
+}
+
+func TestUnitOfWorkCanceladaDuranteEspera(t *testing.T) {
+	stores := newSQLiteStores(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancelado o contexto, a espera pela próxima tentativa é interrompida
+	tentativas := 0
+	err := stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		tentativas++
+		cancel()
+		return errDeadlock
+	})
+	if !errors.Is(err, context.Canceled) || tentativas != 1 {
+		t.Errorf("erro %v após %d tentativas, esperado context.Canceled após 1", err, tentativas)
+	}
+}
+
+func TestUnitOfWorkAninhadaRepeteATransacaoExterna(t *testing.T) {
+	ctx := context.Background()
+	stores := newSQLiteStores(t)
+	seguradoraA, _ := createSeguradoras(t, stores)
+
+	// A unidade de trabalho interna participa da externa: o deadlock repete a transação inteira
+	externas, internas := 0, 0
+	err := stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		externas++
+		evento := &models.Evento{Evento: 101, Descricao: "Emissão de apólice", IdSeguradora: seguradoraA, Ativo: true}
+		if err := stores.Eventos.Create(ctx, evento); err != nil {
+			return err
+		}
+		return stores.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+			internas++
+			if internas == 1 {
+				return errDeadlock
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("erro na unidade de trabalho: %v", err)
+	}
+	if externas != 2 || internas != 2 {
+		t.Errorf("%d execuções externas e %d internas, esperadas 2 e 2", externas, internas)
+	}
+	if n := countEventos(t, stores); n != 1 {
+		t.Errorf("%d eventos gravados, esperado 1", n)
+	}
+}
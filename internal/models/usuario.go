@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -28,7 +29,9 @@ type Usuario struct {
 
 // UsuarioRepository gerencia operações de banco de dados para usuários
 type UsuarioRepository struct {
-	DB *sql.DB
+	DB    *sql.DB
+	scope *TenantScope
+	actor *Actor
 }
 
 // NewUsuarioRepository cria um novo repositório de usuários
@@ -36,17 +39,50 @@ func NewUsuarioRepository(db *sql.DB) *UsuarioRepository {
 	return &UsuarioRepository{DB: db}
 }
 
+// WithTenant retorna uma cópia do repositório restrita ao escopo de seguradora informado
+func (r *UsuarioRepository) WithTenant(scope TenantScope) UsuarioStore {
+	return &UsuarioRepository{DB: r.DB, scope: scopeCopy(scope), actor: r.actor}
+}
+
+// WithActor retorna uma cópia do repositório que registra as alterações no histórico em nome do usuário informado
+func (r *UsuarioRepository) WithActor(actor Actor) UsuarioStore {
+	return &UsuarioRepository{DB: r.DB, scope: r.scope, actor: &actor}
+}
+
+// checkTenant verifica se o usuário pode ser gravado dentro do escopo do repositório
+func (r *UsuarioRepository) checkTenant(usuario *Usuario) error {
+	// O usuário deve pertencer a uma seguradora visível
+	if !r.scope.Allows(int64(usuario.IdSeguradora)) {
+		return ErrCrossTenant
+	}
+	
+	// Somente administradores do ERP podem conceder o privilégio AdminERP
+	if usuario.AdminERP && r.scope != nil && !r.scope.AdminERP {
+		return ErrCrossTenant
+	}
+	
+	return nil
+}
+
 // Create insere um novo usuário no banco de dados
-func (r *UsuarioRepository) Create(usuario *Usuario) error {
+func (r *UsuarioRepository) Create(ctx context.Context, usuario *Usuario) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do usuário
 	if err := validateUsuario(usuario); err != nil {
 		return err
 	}
 	
+	// Verificar se o usuário pertence à seguradora do usuário autenticado
+	if err := r.checkTenant(usuario); err != nil {
+		return err
+	}
+	
 	// Hash da senha
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(usuario.Senha), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("erro ao gerar hash da senha: %v", err)
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
 	}
 	
 	query := `
@@ -54,46 +90,70 @@ func (r *UsuarioRepository) Create(usuario *Usuario) error {
 	(nome, email, login, senha, idTipoPerfil, idSeguradora, AdminERP, bloqueado, ativo) 
 	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
 	
-	result, err := r.DB.Exec(
-		query, 
-		usuario.Nome, 
-		usuario.Email, 
-		usuario.Login, 
-		string(hashedPassword), 
-		usuario.IdTipoPerfil, 
-		usuario.IdSeguradora, 
-		usuario.AdminERP,
-		usuario.Bloqueado,
-		usuario.Ativo,
-	)
-	if err != nil {
-		return fmt.Errorf("erro ao criar usuário: %v", err)
-	}
-	
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("erro ao obter ID do usuário: %v", err)
-	}
-	
-	usuario.ID = id
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		result, err := execInsert(ctx, tx, "id",
+			query, 
+			usuario.Nome, 
+			usuario.Email, 
+			usuario.Login, 
+			string(hashedPassword), 
+			usuario.IdTipoPerfil, 
+			usuario.IdSeguradora, 
+			usuario.AdminERP,
+			usuario.Bloqueado,
+			usuario.Ativo,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao criar usuário: %w", err)
+		}
+		
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("erro ao obter ID do usuário: %w", err)
+		}
+		
+		usuario.ID = id
+		
+		// Registrar a criação no histórico
+		return recordChange(ctx, tx, r.actor, AcaoCriacao, EntidadeUsuario, id, nil, usuario)
+	})
+}
+
+// usuarioListSpec define os campos de ordenação e filtro da listagem de usuários
+var usuarioListSpec = ListSpec{
+	Fields: map[string]ListField{
+		"id":           {Column: "id", Type: FieldInt},
+		"nome":         {Column: "nome", Type: FieldString},
+		"email":        {Column: "email", Type: FieldString},
+		"login":        {Column: "login", Type: FieldString},
+		"idTipoPerfil": {Column: "idTipoPerfil", Type: FieldInt},
+		"idSeguradora": {Column: "idSeguradora", Type: FieldInt},
+		"adminERP":     {Column: "AdminERP", Type: FieldBool},
+		"bloqueado":    {Column: "bloqueado", Type: FieldBool},
+		"created_at":   {Column: "created_at", Type: FieldTime},
+		"updated_at":   {Column: "updated_at", Type: FieldTime},
+		"ativo":        {Column: "ativo", Type: FieldBool},
+	},
+	Key: "id",
 }
 
-// GetAll retorna todos os usuários do banco de dados
-func (r *UsuarioRepository) GetAll() ([]Usuario, error) {
+// GetAll retorna os usuários com paginação, ordenação e filtros
+func (r *UsuarioRepository) GetAll(ctx context.Context, opts ListOptions) (*Page[Usuario], error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id, nome, email, login, idTipoPerfil, idSeguradora, 
 		AdminERP, bloqueado, bloqueado_ate, created_at, updated_at, ativo 
 	FROM usuarios 
-	ORDER BY id DESC`
-	
-	rows, err := r.DB.Query(query)
-	if err != nil {
-		return nil, fmt.Errorf("erro ao buscar usuários: %v", err)
-	}
-	defer rows.Close()
+	WHERE ` + r.scope.condition("idSeguradora")
 	
+	return listPage(ctx, r.DB, &usuarioListSpec, opts, query, r.scope.args(), scanUsuarios)
+}
+
+// scanUsuarios lê os usuários retornados por uma consulta
+func scanUsuarios(rows *sql.Rows) ([]Usuario, error) {
 	var usuarios []Usuario
 	
 	for rows.Next() {
@@ -114,7 +174,7 @@ func (r *UsuarioRepository) GetAll() ([]Usuario, error) {
 			&u.UpdatedAt, 
 			&u.Ativo,
 		); err != nil {
-			return nil, fmt.Errorf("erro ao ler usuário: %v", err)
+			return nil, fmt.Errorf("erro ao ler usuário: %w", err)
 		}
 		
 		if bloqueadoAte.Valid {
@@ -125,25 +185,28 @@ func (r *UsuarioRepository) GetAll() ([]Usuario, error) {
 	}
 	
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("erro ao iterar sobre usuários: %v", err)
+		return nil, fmt.Errorf("erro ao iterar sobre usuários: %w", err)
 	}
 	
 	return usuarios, nil
 }
 
 // GetByID busca um usuário pelo ID
-func (r *UsuarioRepository) GetByID(id int64) (*Usuario, error) {
+func (r *UsuarioRepository) GetByID(ctx context.Context, id int64) (*Usuario, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id, nome, email, login, idTipoPerfil, idSeguradora, 
 		AdminERP, bloqueado, bloqueado_ate, created_at, updated_at, ativo 
 	FROM usuarios 
-	WHERE id = ?`
+	WHERE id = ? AND ` + r.scope.condition("idSeguradora")
 	
 	var u Usuario
 	var bloqueadoAte sql.NullTime
 	
-	err := r.DB.QueryRow(query, id).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, r.scope.args(id)...).Scan(
 		&u.ID, 
 		&u.Nome, 
 		&u.Email, 
@@ -159,9 +222,9 @@ func (r *UsuarioRepository) GetByID(id int64) (*Usuario, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("usuário não encontrado")
+			return nil, NotFoundError{Message: "usuário não encontrado"}
 		}
-		return nil, fmt.Errorf("erro ao buscar usuário: %v", err)
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
 	}
 	
 	if bloqueadoAte.Valid {
@@ -172,7 +235,10 @@ func (r *UsuarioRepository) GetByID(id int64) (*Usuario, error) {
 }
 
 // GetByLogin busca um usuário pelo login
-func (r *UsuarioRepository) GetByLogin(login string) (*Usuario, error) {
+func (r *UsuarioRepository) GetByLogin(ctx context.Context, login string) (*Usuario, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	query := `
 	SELECT 
 		id, nome, email, login, senha, idTipoPerfil, idSeguradora, 
@@ -183,7 +249,7 @@ func (r *UsuarioRepository) GetByLogin(login string) (*Usuario, error) {
 	var u Usuario
 	var bloqueadoAte sql.NullTime
 	
-	err := r.DB.QueryRow(query, login).Scan(
+	err := connFor(ctx, r.DB).QueryRowContext(ctx, query, login).Scan(
 		&u.ID, 
 		&u.Nome, 
 		&u.Email, 
@@ -200,9 +266,9 @@ func (r *UsuarioRepository) GetByLogin(login string) (*Usuario, error) {
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("usuário não encontrado")
+			return nil, NotFoundError{Message: "usuário não encontrado"}
 		}
-		return nil, fmt.Errorf("erro ao buscar usuário: %v", err)
+		return nil, fmt.Errorf("erro ao buscar usuário: %w", err)
 	}
 	
 	if bloqueadoAte.Valid {
@@ -212,40 +278,83 @@ func (r *UsuarioRepository) GetByLogin(login string) (*Usuario, error) {
 	return &u, nil
 }
 
-// Update atualiza os dados de um usuário existente
-func (r *UsuarioRepository) Update(usuario *Usuario) error {
+// Update atualiza os dados de um usuário existente.
+// Se o usuário ficar inativo ou bloqueado, todas as suas sessões são revogadas.
+func (r *UsuarioRepository) Update(ctx context.Context, usuario *Usuario) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar dados do usuário (exceto senha que é tratada separadamente)
 	if err := validateUsuarioUpdate(usuario); err != nil {
 		return err
 	}
 	
+	// Verificar se o usuário pertence à seguradora do usuário autenticado
+	if err := r.checkTenant(usuario); err != nil {
+		return err
+	}
+	
 	query := `
 	UPDATE usuarios 
 	SET nome = ?, email = ?, login = ?, idTipoPerfil = ?, 
 		idSeguradora = ?, AdminERP = ?, bloqueado = ?, ativo = ? 
-	WHERE id = ?`
+	WHERE id = ? AND ` + r.scope.condition("idSeguradora")
 	
-	_, err := r.DB.Exec(
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+	anterior, err := lockedBefore(ctx, tx.Tx, r.scope, "usuarios", "id", usuario.ID, r.GetByID)
+	if err != nil {
+		return err
+	}
+	
+	_, err = tx.ExecContext(ctx, 
 		query, 
-		usuario.Nome, 
-		usuario.Email, 
-		usuario.Login, 
-		usuario.IdTipoPerfil, 
-		usuario.IdSeguradora, 
-		usuario.AdminERP,
-		usuario.Bloqueado,
-		usuario.Ativo, 
-		usuario.ID,
+		r.scope.args(
+			usuario.Nome, 
+			usuario.Email, 
+			usuario.Login, 
+			usuario.IdTipoPerfil, 
+			usuario.IdSeguradora, 
+			usuario.AdminERP,
+			usuario.Bloqueado,
+			usuario.Ativo, 
+			usuario.ID,
+		)...,
 	)
 	if err != nil {
-		return fmt.Errorf("erro ao atualizar usuário: %v", err)
+		return fmt.Errorf("erro ao atualizar usuário: %w", err)
+	}
+	
+	// Invalidar as sessões de usuários desativados ou bloqueados
+	if err := revokeSessoesUsuarioInativo(ctx, tx, usuario.ID); err != nil {
+		return err
+	}
+	
+	// Registrar a alteração no histórico (a senha é registrada por UpdatePassword)
+	atual := *usuario
+	atual.Senha = ""
+	if err := recordChange(ctx, tx.Tx, r.actor, AcaoAlteracao, EntidadeUsuario, usuario.ID, anterior, &atual); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
 	}
 	
 	return nil
 }
 
-// UpdatePassword atualiza apenas a senha do usuário
-func (r *UsuarioRepository) UpdatePassword(id int64, novaSenha string) error {
+// UpdatePassword atualiza apenas a senha do usuário. Retorna ErrNotFound (ou ErrCrossTenant, se o
+// usuário for de outra seguradora) sem registrar a troca quando o usuário não está no escopo.
+func (r *UsuarioRepository) UpdatePassword(ctx context.Context, id int64, novaSenha string) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Validar a nova senha
 	if err := utils.ValidatePassword(novaSenha); err != nil {
 		return err
@@ -254,39 +363,83 @@ func (r *UsuarioRepository) UpdatePassword(id int64, novaSenha string) error {
 	// Hash da nova senha
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(novaSenha), bcrypt.DefaultCost)
 	if err != nil {
-		return fmt.Errorf("erro ao gerar hash da senha: %v", err)
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
 	}
 	
-	query := `UPDATE usuarios SET senha = ? WHERE id = ?`
+	query := `UPDATE usuarios SET senha = ? WHERE id = ? AND ` + r.scope.condition("idSeguradora")
 	
-	_, err = r.DB.Exec(query, string(hashedPassword), id)
-	if err != nil {
-		return fmt.Errorf("erro ao atualizar senha: %v", err)
-	}
-	
-	return nil
+	return inTx(ctx, r.DB, func(tx *sql.Tx) error {
+		// Verificar, com o registro bloqueado, se o usuário existe e pertence ao escopo
+		if _, err := lockedBefore(ctx, tx, r.scope, "usuarios", "id", id, r.GetByID); err != nil {
+			return err
+		}
+		
+		if _, err := tx.ExecContext(ctx, query, r.scope.args(string(hashedPassword), id)...); err != nil {
+			return fmt.Errorf("erro ao atualizar senha: %w", err)
+		}
+		
+		// Registrar a troca de senha no histórico (o valor é ocultado)
+		anterior := map[string]string{"senha": ""}
+		atual := map[string]string{"senha": string(hashedPassword)}
+		return recordChange(ctx, tx, r.actor, AcaoAlteracao, EntidadeUsuario, id, anterior, atual)
+	})
 }
 
-// Delete remove um usuário do banco de dados (ou desativa, dependendo da regra de negócio)
-func (r *UsuarioRepository) Delete(id int64) error {
+// Delete remove um usuário do banco de dados (ou desativa, dependendo da regra de negócio).
+// As sessões do usuário desativado são revogadas.
+func (r *UsuarioRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Opção 1: Exclusão física
 	// query := `DELETE FROM usuarios WHERE id = ?`
 	
 	// Opção 2: Exclusão lógica (recomendada)
-	query := `UPDATE usuarios SET ativo = false WHERE id = ?`
+	query := `UPDATE usuarios SET ativo = false WHERE id = ? AND ` + r.scope.condition("idSeguradora")
+	
+	tx, err := beginTx(ctx, r.DB)
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+	
+	// Estado anterior, lido com o registro bloqueado, para o histórico de alterações
+	anterior, err := lockedBefore(ctx, tx.Tx, r.scope, "usuarios", "id", id, r.GetByID)
+	if err != nil {
+		return err
+	}
+	excluido := *anterior
+	excluido.Ativo = false
 	
-	_, err := r.DB.Exec(query, id)
+	_, err = tx.ExecContext(ctx, query, r.scope.args(id)...)
 	if err != nil {
-		return fmt.Errorf("erro ao excluir usuário: %v", err)
+		return fmt.Errorf("erro ao excluir usuário: %w", err)
+	}
+	
+	// Invalidar as sessões do usuário desativado
+	if err := revokeSessoesUsuarioInativo(ctx, tx, id); err != nil {
+		return err
+	}
+	
+	// Registrar a exclusão no histórico
+	if err := recordChange(ctx, tx.Tx, r.actor, AcaoExclusao, EntidadeUsuario, id, anterior, &excluido); err != nil {
+		return err
+	}
+	
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
 	}
 	
 	return nil
 }
 
 // VerifyPassword verifica se a senha fornecida corresponde à senha armazenada
-func (r *UsuarioRepository) VerifyPassword(login, senha string) (*Usuario, error) {
+func (r *UsuarioRepository) VerifyPassword(ctx context.Context, login, senha string) (*Usuario, error) {
+	ctx, cancel := withQueryTimeout(ctx)
+	defer cancel()
+	
 	// Buscar o usuário pelo login
-	usuario, err := r.GetByLogin(login)
+	usuario, err := r.GetByLogin(ctx, login)
 	if err != nil {
 		return nil, err
 	}
@@ -311,9 +464,9 @@ func (r *UsuarioRepository) VerifyPassword(login, senha string) (*Usuario, error
 			SET bloqueado = false, bloqueado_ate = NULL 
 			WHERE id = ?`
 			
-			_, err := r.DB.Exec(updateQuery, usuario.ID)
+			_, err := connFor(ctx, r.DB).ExecContext(ctx, updateQuery, usuario.ID)
 			if err != nil {
-				return nil, fmt.Errorf("erro ao desbloquear usuário: %v", err)
+				return nil, fmt.Errorf("erro ao desbloquear usuário: %w", err)
 			}
 		}
 	}
@@ -363,7 +516,7 @@ func validateUsuario(u *Usuario) error {
 		return err
 	}
 	if err := utils.ValidatePassword(u.Senha); err != nil {
-		return utils.ValidationError{Field: "senha", Message: err.Error()}
+		return err
 	}
 	
 	// Validar tipo de perfil
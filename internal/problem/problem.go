@@ -0,0 +1,169 @@
+package problem
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/logging"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/utils"
+)
+
+// ContentType é o tipo das respostas de erro (RFC 7807)
+const ContentType = "application/problem+json"
+
+// StatusClientClosedRequest indica que o cliente encerrou a conexão antes da resposta (convenção do nginx)
+const StatusClientClosedRequest = 499
+
+// Códigos estáveis dos erros, para tratamento pelos clientes
+const (
+	CodeBadRequest         = "bad_request"
+	CodeValidation         = "validation_error"
+	CodeUnauthorized       = "unauthorized"
+	CodeForbidden          = "forbidden"
+	CodeCrossTenant        = "cross_tenant"
+	CodeCSRFMissing        = "csrf_token_missing"
+	CodeCSRFInvalid        = "csrf_token_invalid"
+	CodeNotFound           = "not_found"
+	CodeMethodNotAllowed   = "method_not_allowed"
+	CodeConflict           = "conflict"
+	CodePayloadTooLarge    = "payload_too_large"
+	CodeForeignKey         = "foreign_key_violation"
+	CodeInvalidReference   = "invalid_reference"
+	CodeUnprocessable      = "unprocessable_entity"
+	CodeTooManyRequests    = "too_many_requests"
+	CodeClientClosed       = "client_closed_request"
+	CodeInternal           = "internal_error"
+	CodeServiceUnavailable = "service_unavailable"
+	CodeTimeout            = "timeout"
+)
+
+// codesByStatus define o código padrão de cada status HTTP
+var codesByStatus = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusRequestEntityTooLarge: CodePayloadTooLarge,
+	http.StatusUnprocessableEntity:   CodeUnprocessable,
+	http.StatusTooManyRequests:       CodeTooManyRequests,
+	http.StatusInternalServerError:   CodeInternal,
+	StatusClientClosedRequest:        CodeClientClosed,
+	http.StatusServiceUnavailable:    CodeServiceUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+}
+
+// Problem representa o corpo de uma resposta de erro no formato da RFC 7807
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError detalha o erro de um campo da requisição
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Write responde com um erro no formato application/problem+json, usando o código padrão do status
+func Write(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	WriteCode(w, r, status, "", detail)
+}
+
+// WriteCode responde com um erro no formato application/problem+json e o código informado
+func WriteCode(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	write(w, r, &Problem{Status: status, Code: code, Detail: detail})
+}
+
+// Error responde com o erro convertido no status e no código correspondentes: erros de validação
+// (400), registros não encontrados (404), conflitos (409), violações de chave estrangeira e
+// referências inválidas (422) e acesso a outra seguradora (403). A requisição cancelada pelo
+// cliente é respondida com 499, o tempo esgotado da consulta com 504 e o banco de dados
+// indisponível com 503. Os demais erros são registrados no log e respondidos com 500 e a mensagem
+// informada, sem expor os detalhes internos ao cliente.
+func Error(w http.ResponseWriter, r *http.Request, err error, message string) {
+	err = models.ClassifyDBError(err)
+	
+	var validationErr utils.ValidationError
+	var notFoundErr models.NotFoundError
+	var conflictErr models.ConflictError
+	var foreignKeyErr models.ForeignKeyError
+	var referenceErr models.ReferenceError
+	
+	switch {
+	case errors.As(err, &validationErr):
+		write(w, r, &Problem{
+			Status: http.StatusBadRequest,
+			Code:   CodeValidation,
+			Detail: validationErr.Error(),
+			Errors: []FieldError{{Field: validationErr.Field, Message: validationErr.Message}},
+		})
+	case errors.As(err, &notFoundErr):
+		write(w, r, &Problem{Status: http.StatusNotFound, Code: CodeNotFound, Detail: notFoundErr.Message})
+	case errors.Is(err, models.ErrNotFound):
+		write(w, r, &Problem{Status: http.StatusNotFound, Code: CodeNotFound, Detail: err.Error()})
+	case errors.As(err, &conflictErr):
+		write(w, r, &Problem{Status: http.StatusConflict, Code: CodeConflict, Detail: conflictErr.Message})
+	case errors.As(err, &foreignKeyErr):
+		p := &Problem{Status: http.StatusUnprocessableEntity, Code: CodeForeignKey, Detail: foreignKeyErr.Message}
+		if foreignKeyErr.Field != "" {
+			p.Errors = []FieldError{{Field: foreignKeyErr.Field, Message: foreignKeyErr.Message}}
+		}
+		write(w, r, p)
+	case errors.As(err, &referenceErr):
+		write(w, r, &Problem{
+			Status: http.StatusUnprocessableEntity,
+			Code:   CodeInvalidReference,
+			Detail: referenceErr.Message,
+			Errors: []FieldError{{Field: referenceErr.Field, Message: referenceErr.Message}},
+		})
+	case errors.Is(err, models.ErrCrossTenant):
+		write(w, r, &Problem{Status: http.StatusForbidden, Code: CodeCrossTenant, Detail: err.Error()})
+	case errors.Is(err, context.Canceled):
+		write(w, r, &Problem{Status: StatusClientClosedRequest, Detail: "requisição cancelada pelo cliente"})
+	case errors.Is(err, context.DeadlineExceeded):
+		slog.WarnContext(r.Context(), message, "error", err)
+		write(w, r, &Problem{Status: http.StatusGatewayTimeout, Detail: "tempo limite da consulta ao banco de dados esgotado"})
+	case errors.Is(err, models.ErrUnavailable):
+		slog.ErrorContext(r.Context(), message, "error", err)
+		write(w, r, &Problem{Status: http.StatusServiceUnavailable, Detail: "banco de dados indisponível"})
+	default:
+		slog.ErrorContext(r.Context(), message, "error", err)
+		write(w, r, &Problem{Status: http.StatusInternalServerError, Code: CodeInternal, Detail: message})
+	}
+}
+
+// write completa e grava o corpo da resposta de erro
+func write(w http.ResponseWriter, r *http.Request, p *Problem) {
+	p.Type = "about:blank"
+	p.Title = http.StatusText(p.Status)
+	if p.Status == StatusClientClosedRequest {
+		p.Title = "Client Closed Request"
+	}
+	if p.Code == "" {
+		p.Code = codesByStatus[p.Status]
+		if p.Code == "" {
+			p.Code = CodeInternal
+		}
+	}
+	if r != nil {
+		p.Instance = r.URL.Path
+		p.RequestID = logging.RequestIDFromContext(r.Context())
+	}
+	
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
@@ -6,6 +6,9 @@ import (
 	"net/http"
 	"sync"
 	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/metrics"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
 )
 
 // CSRFToken representa um token CSRF
@@ -73,6 +76,14 @@ func (c *CSRFProtection) ValidateToken(token string) bool {
 	return false
 }
 
+// Size retorna a quantidade de tokens CSRF armazenados
+func (c *CSRFProtection) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	return len(c.tokens)
+}
+
 // cleanExpiredTokens remove tokens expirados
 func (c *CSRFProtection) cleanExpiredTokens() {
 	now := time.Now()
@@ -95,12 +106,14 @@ func (c *CSRFProtection) Middleware(next http.Handler) http.Handler {
 		// Verificar o token CSRF
 		token := r.Header.Get("X-CSRF-Token")
 		if token == "" {
-			http.Error(w, "Token CSRF ausente", http.StatusForbidden)
+			metrics.CSRFRejections.WithLabelValues("missing").Inc()
+			problem.WriteCode(w, r, http.StatusForbidden, problem.CodeCSRFMissing, "Token CSRF ausente")
 			return
 		}
 		
 		if !c.ValidateToken(token) {
-			http.Error(w, "Token CSRF inválido ou expirado", http.StatusForbidden)
+			metrics.CSRFRejections.WithLabelValues("invalid").Inc()
+			problem.WriteCode(w, r, http.StatusForbidden, problem.CodeCSRFInvalid, "Token CSRF inválido ou expirado")
 			return
 		}
 		
@@ -114,7 +127,7 @@ func (c *CSRFProtection) GetTokenHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token, err := c.GenerateToken()
 		if err != nil {
-			http.Error(w, "Erro ao gerar token CSRF", http.StatusInternalServerError)
+			problem.Write(w, r, http.StatusInternalServerError, "Erro ao gerar token CSRF")
 			return
 		}
 		
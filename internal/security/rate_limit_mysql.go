@@ -0,0 +1,173 @@
+package security
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+)
+
+// Configuração do armazenamento no MySQL
+const (
+	mysqlRateLimitKeySize       = 191             // Tamanho da coluna chave
+	mysqlRateLimitSweepInterval = 1 * time.Minute // Intervalo mínimo entre limpezas dos registros expirados
+	mysqlRateLimitSweepBatch    = 1000            // Registros removidos por limpeza
+)
+
+// MySQLRateLimitStore mantém os contadores nas tabelas rate_limit_contadores e
+// rate_limit_bloqueios, compartilhando os limites entre todas as instâncias que usam o
+// mesmo banco. Os instantes são gravados em milissegundos Unix, calculados pela aplicação.
+// As instruções seguem o dialeto configurado, de forma que o armazenamento também funciona
+// no PostgreSQL e no SQLite.
+type MySQLRateLimitStore struct {
+	DB        *sql.DB
+	mu        sync.Mutex
+	lastSweep time.Time
+}
+
+// NewMySQLRateLimitStore cria um armazenamento de limitação de taxa no MySQL
+func NewMySQLRateLimitStore(db *sql.DB) *MySQLRateLimitStore {
+	return &MySQLRateLimitStore{
+		DB:        db,
+		lastSweep: time.Now(),
+	}
+}
+
+// Increment registra uma requisição na janela atual da chave
+func (s *MySQLRateLimitStore) Increment(key string, windowStart time.Time, window time.Duration) (int64, int64, error) {
+	s.sweep()
+	
+	key = storageKey(key)
+	start := windowStart.UnixMilli()
+	previousStart := windowStart.Add(-window).UnixMilli()
+	expiresAt := windowStart.Add(2 * window).UnixMilli()
+	
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao iniciar transação: %v", err)
+	}
+	defer tx.Rollback()
+	
+	// Incrementar o contador da janela atual de forma atômica
+	query := `
+	INSERT INTO rate_limit_contadores (chave, inicio_janela, contador, expira_em)
+	VALUES (?, ?, 1, ?)
+	` + dialect.Current().Upsert("chave, inicio_janela", "contador = rate_limit_contadores.contador + 1")
+	
+	if _, err := tx.Exec(query, key, start, expiresAt); err != nil {
+		return 0, 0, fmt.Errorf("erro ao incrementar contador de requisições: %v", err)
+	}
+	
+	// Ler os contadores da janela atual e da anterior
+	rows, err := tx.Query(
+		"SELECT inicio_janela, contador FROM rate_limit_contadores WHERE chave = ? AND inicio_janela IN (?, ?)",
+		key, start, previousStart,
+	)
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao buscar contadores de requisições: %v", err)
+	}
+	defer rows.Close()
+	
+	var current, previous int64
+	for rows.Next() {
+		var inicio, contador int64
+		if err := rows.Scan(&inicio, &contador); err != nil {
+			return 0, 0, fmt.Errorf("erro ao ler contador de requisições: %v", err)
+		}
+		if inicio == start {
+			current = contador
+		} else {
+			previous = contador
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, fmt.Errorf("erro ao iterar sobre contadores de requisições: %v", err)
+	}
+	rows.Close()
+	
+	if err := tx.Commit(); err != nil {
+		return 0, 0, fmt.Errorf("erro ao confirmar transação: %v", err)
+	}
+	
+	return current, previous, nil
+}
+
+// Block bloqueia a chave até o instante informado, sem encurtar um bloqueio existente
+func (s *MySQLRateLimitStore) Block(key string, until time.Time) error {
+	d := dialect.Current()
+	query := `
+	INSERT INTO rate_limit_bloqueios (chave, bloqueado_ate)
+	VALUES (?, ?)
+	` + d.Upsert("chave", "bloqueado_ate = "+d.Greatest("rate_limit_bloqueios.bloqueado_ate", d.Excluded("bloqueado_ate")))
+	
+	if _, err := s.DB.Exec(query, storageKey(key), until.UnixMilli()); err != nil {
+		return fmt.Errorf("erro ao registrar bloqueio: %v", err)
+	}
+	
+	return nil
+}
+
+// BlockedUntil retorna até quando a chave está bloqueada
+func (s *MySQLRateLimitStore) BlockedUntil(key string) (time.Time, error) {
+	var until int64
+	err := s.DB.QueryRow(
+		"SELECT bloqueado_ate FROM rate_limit_bloqueios WHERE chave = ? AND bloqueado_ate > ?",
+		storageKey(key), time.Now().UnixMilli(),
+	).Scan(&until)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("erro ao verificar bloqueio: %v", err)
+	}
+	
+	return time.UnixMilli(until), nil
+}
+
+// Size retorna a quantidade de contadores e de bloqueios gravados (incluindo os expirados ainda não removidos)
+func (s *MySQLRateLimitStore) Size() (int64, int64, error) {
+	var counters, blocks int64
+	err := s.DB.QueryRow(
+		"SELECT (SELECT COUNT(*) FROM rate_limit_contadores), (SELECT COUNT(*) FROM rate_limit_bloqueios)",
+	).Scan(&counters, &blocks)
+	if err != nil {
+		return 0, 0, fmt.Errorf("erro ao contar registros da limitação de taxa: %v", err)
+	}
+	
+	return counters, blocks, nil
+}
+
+// sweep remove periodicamente contadores e bloqueios expirados
+func (s *MySQLRateLimitStore) sweep() {
+	s.mu.Lock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) < mysqlRateLimitSweepInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastSweep = now
+	s.mu.Unlock()
+	
+	d := dialect.Current()
+	nowMilli := now.UnixMilli()
+	if _, err := s.DB.Exec(d.DeleteLimit("rate_limit_contadores", "expira_em <= ?"), nowMilli, mysqlRateLimitSweepBatch); err != nil {
+		slog.Error("Erro ao remover contadores de requisições expirados", "error", err)
+	}
+	if _, err := s.DB.Exec(d.DeleteLimit("rate_limit_bloqueios", "bloqueado_ate <= ?"), nowMilli, mysqlRateLimitSweepBatch); err != nil {
+		slog.Error("Erro ao remover bloqueios expirados", "error", err)
+	}
+}
+
+// storageKey limita a chave ao tamanho da coluna, substituindo chaves longas pelo seu hash
+func storageKey(key string) string {
+	if len(key) <= mysqlRateLimitKeySize {
+		return key
+	}
+	sum := sha256.Sum256([]byte(key))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
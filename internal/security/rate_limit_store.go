@@ -0,0 +1,166 @@
+package security
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateLimitStore armazena os contadores e bloqueios da limitação de taxa.
+// Implementações compartilhadas (como o MySQLRateLimitStore) fazem com que várias
+// instâncias da aplicação respeitem o mesmo limite.
+type RateLimitStore interface {
+	// Increment registra uma requisição na janela iniciada em windowStart e retorna
+	// o contador da janela atual (já incluindo a requisição) e o da janela anterior
+	Increment(key string, windowStart time.Time, window time.Duration) (current, previous int64, err error)
+	// Block bloqueia a chave até o instante informado
+	Block(key string, until time.Time) error
+	// BlockedUntil retorna até quando a chave está bloqueada (instante zero se não estiver)
+	BlockedUntil(key string) (time.Time, error)
+	// Size retorna a quantidade de contadores e de bloqueios armazenados
+	Size() (counters, blocks int64, err error)
+}
+
+// Configuração padrão do armazenamento em memória
+const (
+	DefaultMemoryRateLimitKeys = 100000          // Número máximo de chaves mantidas em memória
+	memorySweepInterval        = 1 * time.Minute // Intervalo mínimo entre limpezas das chaves expiradas
+)
+
+// memoryCounter representa os contadores de uma chave no armazenamento em memória
+type memoryCounter struct {
+	windowStart time.Time
+	current     int64
+	previous    int64
+	expiresAt   time.Time
+}
+
+// MemoryRateLimitStore mantém os contadores em memória, descartando chaves expiradas
+// e, ao atingir o número máximo de chaves, as chaves que expiram primeiro.
+// Os limites não são compartilhados entre instâncias nem sobrevivem a reinícios.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	blocks    map[string]time.Time
+	maxKeys   int
+	lastSweep time.Time
+}
+
+// NewMemoryRateLimitStore cria um armazenamento em memória com o número máximo de chaves informado
+func NewMemoryRateLimitStore(maxKeys int) *MemoryRateLimitStore {
+	if maxKeys <= 0 {
+		maxKeys = DefaultMemoryRateLimitKeys
+	}
+	return &MemoryRateLimitStore{
+		counters:  make(map[string]*memoryCounter),
+		blocks:    make(map[string]time.Time),
+		maxKeys:   maxKeys,
+		lastSweep: time.Now(),
+	}
+}
+
+// Increment registra uma requisição na janela atual da chave
+func (s *MemoryRateLimitStore) Increment(key string, windowStart time.Time, window time.Duration) (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	c, exists := s.counters[key]
+	if !exists {
+		s.evict(time.Now())
+		c = &memoryCounter{windowStart: windowStart}
+		s.counters[key] = c
+	}
+	
+	// Avançar a janela, preservando o contador da janela imediatamente anterior
+	if !c.windowStart.Equal(windowStart) {
+		if c.windowStart.Add(window).Equal(windowStart) {
+			c.previous = c.current
+		} else {
+			c.previous = 0
+		}
+		c.current = 0
+		c.windowStart = windowStart
+	}
+	
+	c.current++
+	c.expiresAt = windowStart.Add(2 * window)
+	
+	return c.current, c.previous, nil
+}
+
+// Block bloqueia a chave até o instante informado
+func (s *MemoryRateLimitStore) Block(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	if until.After(s.blocks[key]) {
+		s.blocks[key] = until
+	}
+	return nil
+}
+
+// BlockedUntil retorna até quando a chave está bloqueada
+func (s *MemoryRateLimitStore) BlockedUntil(key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	until, blocked := s.blocks[key]
+	if !blocked {
+		return time.Time{}, nil
+	}
+	
+	// Remover bloqueios vencidos
+	if !time.Now().Before(until) {
+		delete(s.blocks, key)
+		return time.Time{}, nil
+	}
+	
+	return until, nil
+}
+
+// Size retorna a quantidade de contadores e de bloqueios mantidos em memória
+func (s *MemoryRateLimitStore) Size() (int64, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	
+	return int64(len(s.counters)), int64(len(s.blocks)), nil
+}
+
+// evict libera espaço para uma nova chave: remove periodicamente as chaves expiradas e,
+// se o limite de chaves ainda tiver sido atingido, as chaves que expiram primeiro
+func (s *MemoryRateLimitStore) evict(now time.Time) {
+	if len(s.counters) < s.maxKeys && now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	
+	// Remover contadores e bloqueios expirados
+	for key, c := range s.counters {
+		if !now.Before(c.expiresAt) {
+			delete(s.counters, key)
+		}
+	}
+	for key, until := range s.blocks {
+		if !now.Before(until) {
+			delete(s.blocks, key)
+		}
+	}
+	s.lastSweep = now
+	
+	if len(s.counters) < s.maxKeys {
+		return
+	}
+	
+	// Remover as chaves que expiram primeiro até liberar 10% da capacidade
+	keys := make([]string, 0, len(s.counters))
+	for key := range s.counters {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return s.counters[keys[i]].expiresAt.Before(s.counters[keys[j]].expiresAt)
+	})
+	
+	excess := len(s.counters) - s.maxKeys + s.maxKeys/10 + 1
+	for _, key := range keys[:excess] {
+		delete(s.counters, key)
+	}
+}
@@ -0,0 +1,77 @@
+package security
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemoryRateLimitStoreDescarte(t *testing.T) {
+	janela := time.Minute
+	atual := time.Now().Truncate(janela)
+	expirada := atual.Add(-3 * janela)
+
+	tests := []struct {
+		name      string
+		expiradas int // Chaves com a janela já expirada, das 10 armazenadas
+		removidas []string
+		mantidas  []string
+	}{
+		// Chaves expiradas são removidas primeiro, liberando espaço sem descartar as demais
+		{"com chaves expiradas", 3, []string{"chave-0", "chave-1", "chave-2"}, []string{"chave-3", "chave-9", "nova"}},
+		// Sem chaves expiradas, as que expiram primeiro são descartadas até liberar 10% da capacidade
+		{"sem chaves expiradas", 0, []string{"chave-0", "chave-1"}, []string{"chave-2", "chave-9", "nova"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryRateLimitStore(10)
+			for i := 0; i < 10; i++ {
+				windowStart := atual.Add(time.Duration(i) * time.Second)
+				if i < tt.expiradas {
+					windowStart = expirada.Add(time.Duration(i) * time.Second)
+				}
+				store.Increment(fmt.Sprintf("chave-%d", i), windowStart, janela)
+			}
+			store.Block("bloqueio-vencido", time.Now().Add(-time.Second))
+
+			// Atingido o limite de chaves, uma nova chave dispara o descarte
+			store.Increment("nova", atual, janela)
+
+			for _, key := range tt.removidas {
+				if _, ok := store.counters[key]; ok {
+					t.Errorf("chave %s mantida, esperado descarte", key)
+				}
+			}
+			for _, key := range tt.mantidas {
+				if _, ok := store.counters[key]; !ok {
+					t.Errorf("chave %s descartada, esperado mantida", key)
+				}
+			}
+			if counters, blocks, _ := store.Size(); counters != int64(11-len(tt.removidas)) || blocks != 0 {
+				t.Errorf("Size() = %d contadores e %d bloqueios, esperado %d e 0", counters, blocks, 11-len(tt.removidas))
+			}
+		})
+	}
+}
+
+func TestMemoryRateLimitStoreJanelas(t *testing.T) {
+	store := NewMemoryRateLimitStore(0)
+	janela := time.Minute
+	inicio := time.Now().Truncate(janela)
+
+	// O contador da janela anterior só é preservado se ela for imediatamente anterior
+	for _, tt := range []struct {
+		windowStart       time.Time
+		current, previous int64
+	}{
+		{inicio, 1, 0},
+		{inicio, 2, 0},
+		{inicio.Add(janela), 1, 2},
+		{inicio.Add(3 * janela), 1, 0},
+	} {
+		current, previous, err := store.Increment("cliente", tt.windowStart, janela)
+		if err != nil || current != tt.current || previous != tt.previous {
+			t.Errorf("Increment(%v) = %d, %d, %v, esperado %d, %d", tt.windowStart.Sub(inicio), current, previous, err, tt.current, tt.previous)
+		}
+	}
+}
@@ -1,103 +1,176 @@
 package security
 
 import (
+	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
-	"sync"
+	"strconv"
 	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/clientip"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/metrics"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/problem"
+)
+
+// Cabeçalhos de limitação de taxa (draft IETF "RateLimit header fields for HTTP")
+const (
+	HeaderRateLimitLimit     = "RateLimit-Limit"
+	HeaderRateLimitRemaining = "RateLimit-Remaining"
+	HeaderRateLimitReset     = "RateLimit-Reset"
+	HeaderRateLimitPolicy    = "RateLimit-Policy"
+	HeaderRetryAfter         = "Retry-After"
 )
 
-// RateLimiter implementa limitação de taxa de requisições por IP
+// KeyFunc identifica o cliente de uma requisição para a limitação de taxa.
+// Retornar uma string vazia faz o limitador usar o IP do cliente.
+type KeyFunc func(r *http.Request) string
+
+// RateLimitRule define um limite de requisições
+type RateLimitRule struct {
+	Name   string        // Nome da regra, usado para separar os contadores de regras diferentes
+	Limit  int           // Número máximo de requisições permitidas na janela
+	Window time.Duration // Janela de tempo para contagem de requisições
+	Block  time.Duration // Duração do bloqueio quando o limite é excedido (0 para não bloquear)
+	Key    KeyFunc       // Identificação do cliente (por padrão, o IP)
+}
+
+// RateLimitResult representa o resultado da verificação de uma requisição
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // Tempo até a janela atual terminar
+	RetryAfter time.Duration // Tempo até o cliente poder tentar novamente (apenas quando recusado)
+}
+
+// RateLimiter implementa limitação de taxa com contador de janela deslizante.
+// A estimativa de requisições na última janela é a soma do contador da janela atual
+// com o contador da janela anterior, ponderado pela fração dela que ainda se sobrepõe
+// à janela deslizante. Os contadores ficam em um RateLimitStore, que pode ser
+// compartilhado entre instâncias da aplicação.
 type RateLimiter struct {
-	requests     map[string][]time.Time
-	mu           sync.Mutex
-	maxRequests  int           // Número máximo de requisições permitidas
-	timeWindow   time.Duration // Janela de tempo para contagem de requisições
-	blockDuration time.Duration // Duração do bloqueio quando o limite é excedido
-	blockedIPs    map[string]time.Time
+	store RateLimitStore
+	rule  RateLimitRule
+	now   func() time.Time // Relógio das janelas (substituído nos testes)
 }
 
-// NewRateLimiter cria um novo limitador de taxa
-func NewRateLimiter(maxRequests int, timeWindow, blockDuration time.Duration) *RateLimiter {
+// NewRateLimiter cria um novo limitador de taxa para a regra informada
+func NewRateLimiter(store RateLimitStore, rule RateLimitRule) *RateLimiter {
+	if rule.Key == nil {
+		rule.Key = KeyByIP
+	}
 	return &RateLimiter{
-		requests:      make(map[string][]time.Time),
-		maxRequests:   maxRequests,
-		timeWindow:    timeWindow,
-		blockDuration: blockDuration,
-		blockedIPs:    make(map[string]time.Time),
+		store: store,
+		rule:  rule,
+		now:   time.Now,
 	}
 }
 
-// IsAllowed verifica se um IP está permitido a fazer uma requisição
-func (rl *RateLimiter) IsAllowed(ip string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+// KeyByIP identifica o cliente pelo endereço IP
+func KeyByIP(r *http.Request) string {
+	return "ip:" + clientip.FromRequest(r)
+}
+
+// Allow registra uma requisição do cliente identificado pela chave e verifica se ela está dentro do limite.
+// Falhas do armazenamento não bloqueiam a requisição: são registradas no log e a requisição é permitida.
+func (rl *RateLimiter) Allow(key string) RateLimitResult {
+	now := rl.now()
+	windowStart := now.Truncate(rl.rule.Window)
+	reset := windowStart.Add(rl.rule.Window).Sub(now)
 	
-	// Verificar se o IP está bloqueado
-	if blockedUntil, blocked := rl.blockedIPs[ip]; blocked {
-		// Se o tempo de bloqueio já passou, remover o bloqueio
-		if time.Now().After(blockedUntil) {
-			delete(rl.blockedIPs, ip)
-		} else {
-			return false
-		}
+	result := RateLimitResult{
+		Allowed:   true,
+		Limit:     rl.rule.Limit,
+		Remaining: rl.rule.Limit,
+		Reset:     reset,
 	}
 	
-	// Obter o tempo atual
-	now := time.Now()
+	// Verificar se o cliente está bloqueado
+	blockedUntil, err := rl.store.BlockedUntil(key)
+	if err != nil {
+		slog.Error("Erro ao verificar bloqueio de limitação de taxa", "error", err)
+		return result
+	}
+	if now.Before(blockedUntil) {
+		result.Allowed = false
+		result.Remaining = 0
+		result.RetryAfter = blockedUntil.Sub(now)
+		result.Reset = result.RetryAfter
+		return result
+	}
 	
-	// Limpar requisições antigas
-	if times, exists := rl.requests[ip]; exists {
-		var validTimes []time.Time
-		for _, t := range times {
-			if now.Sub(t) <= rl.timeWindow {
-				validTimes = append(validTimes, t)
-			}
-		}
-		rl.requests[ip] = validTimes
+	// Registrar a requisição na janela atual
+	current, previous, err := rl.store.Increment(key, windowStart, rl.rule.Window)
+	if err != nil {
+		slog.Error("Erro ao registrar requisição na limitação de taxa", "error", err)
+		return result
 	}
 	
-	// Verificar se o IP excedeu o limite
-	if len(rl.requests[ip]) >= rl.maxRequests {
-		// Bloquear o IP
-		rl.blockedIPs[ip] = now.Add(rl.blockDuration)
-		return false
+	// Estimar as requisições na janela deslizante
+	weight := 1 - float64(now.Sub(windowStart))/float64(rl.rule.Window)
+	estimate := float64(previous)*weight + float64(current)
+	
+	result.Remaining = rl.rule.Limit - int(math.Ceil(estimate))
+	if result.Remaining < 0 {
+		result.Remaining = 0
 	}
 	
-	// Adicionar a requisição atual
-	rl.requests[ip] = append(rl.requests[ip], now)
-	return true
-}
-
-// GetRemainingTime retorna o tempo restante de bloqueio para um IP
-func (rl *RateLimiter) GetRemainingTime(ip string) time.Duration {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
+	// Dentro do limite
+	if estimate <= float64(rl.rule.Limit) {
+		return result
+	}
 	
-	if blockedUntil, blocked := rl.blockedIPs[ip]; blocked {
-		if time.Now().After(blockedUntil) {
-			delete(rl.blockedIPs, ip)
-			return 0
+	// Limite excedido: bloquear o cliente, se a regra exigir
+	result.Allowed = false
+	result.RetryAfter = reset
+	if rl.rule.Block > 0 {
+		if err := rl.store.Block(key, now.Add(rl.rule.Block)); err != nil {
+			slog.Error("Erro ao bloquear cliente na limitação de taxa", "error", err)
+		} else {
+			metrics.RateLimitBlocks.WithLabelValues(rl.rule.Name).Inc()
+			result.RetryAfter = rl.rule.Block
+			result.Reset = rl.rule.Block
 		}
-		return blockedUntil.Sub(time.Now())
 	}
 	
-	return 0
+	return result
 }
 
-// Middleware cria um middleware HTTP para limitação de taxa
+// Middleware cria um middleware HTTP para limitação de taxa, com contadores compartilhados por todas as rotas
 func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return rl.limit(rl.rule.Name, next)
+}
+
+// Route cria um middleware HTTP para limitação de taxa com contadores próprios da rota informada
+func (rl *RateLimiter) Route(route string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return rl.limit(rl.rule.Name+":"+route, next)
+	}
+}
+
+// limit aplica a regra à requisição, usando o escopo informado para separar os contadores
+func (rl *RateLimiter) limit(scope string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Obter o IP do cliente
-		ip := getClientIP(r)
+		// Identificar o cliente (usuário, IP, ...)
+		client := rl.rule.Key(r)
+		if client == "" {
+			client = KeyByIP(r)
+		}
 		
-		// Verificar se o IP está permitido
-		if !rl.IsAllowed(ip) {
-			// Calcular tempo restante de bloqueio
-			remainingTime := rl.GetRemainingTime(ip)
-			
-			// Configurar cabeçalhos de resposta
-			w.Header().Set("Retry-After", remainingTime.String())
-			http.Error(w, "Muitas requisições. Tente novamente mais tarde.", http.StatusTooManyRequests)
+		// Verificar se a requisição está dentro do limite
+		result := rl.Allow(scope + "|" + client)
+		
+		// Configurar cabeçalhos de resposta
+		w.Header().Set(HeaderRateLimitLimit, strconv.Itoa(result.Limit))
+		w.Header().Set(HeaderRateLimitRemaining, strconv.Itoa(result.Remaining))
+		w.Header().Set(HeaderRateLimitReset, strconv.Itoa(seconds(result.Reset)))
+		w.Header().Set(HeaderRateLimitPolicy, fmt.Sprintf("%d;w=%d", rl.rule.Limit, seconds(rl.rule.Window)))
+		
+		if !result.Allowed {
+			metrics.RateLimitRejections.WithLabelValues(rl.rule.Name).Inc()
+			w.Header().Set(HeaderRetryAfter, strconv.Itoa(seconds(result.RetryAfter)))
+			problem.Write(w, r, http.StatusTooManyRequests, "Muitas requisições. Tente novamente mais tarde.")
 			return
 		}
 		
@@ -106,15 +179,10 @@ func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
 	})
 }
 
-// getClientIP obtém o endereço IP do cliente
-func getClientIP(r *http.Request) string {
-	// Tentar obter o IP real se estiver atrás de um proxy
-	ip := r.Header.Get("X-Real-IP")
-	if ip == "" {
-		ip = r.Header.Get("X-Forwarded-For")
-	}
-	if ip == "" {
-		ip = r.RemoteAddr
+// seconds converte uma duração em segundos inteiros, arredondando para cima
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
 	}
-	return ip
+	return int(math.Ceil(d.Seconds()))
 }
@@ -0,0 +1,170 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// relogio é um relógio ajustável pelos testes, iniciado no começo de um minuto futuro
+// (os bloqueios do armazenamento em memória vencem pelo relógio real)
+type relogio struct {
+	agora time.Time
+}
+
+func novoRelogio() *relogio {
+	return &relogio{agora: time.Now().Add(24 * time.Hour).Truncate(time.Minute)}
+}
+
+func (r *relogio) now() time.Time {
+	return r.agora
+}
+
+// newTestRateLimiter cria um limitador sobre um armazenamento em memória, com o relógio informado
+func newTestRateLimiter(rule RateLimitRule, clock *relogio) (*RateLimiter, *MemoryRateLimitStore) {
+	store := NewMemoryRateLimitStore(0)
+	rl := NewRateLimiter(store, rule)
+	rl.now = clock.now
+	return rl, store
+}
+
+func TestJanelaDeslizante(t *testing.T) {
+	// 10 requisições por minuto; o cliente fez requisições na janela anterior e faz uma na atual
+	tests := []struct {
+		name       string
+		anteriores int
+		decorrido  time.Duration // Tempo decorrido da janela atual
+		allowed    bool
+		remaining  int
+	}{
+		{"início da janela: anterior com peso 1", 8, 0, true, 1},
+		{"um quarto da janela: peso 0,75", 8, 15 * time.Second, true, 3},
+		{"três quartos da janela: peso 0,25", 8, 45 * time.Second, true, 7},
+		{"anterior no limite, com peso 0,9", 10, 6 * time.Second, true, 0},
+		{"anterior no limite, com peso 1", 10, 0, false, 0},
+		{"sem requisições na janela anterior", 0, 30 * time.Second, true, 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := novoRelogio()
+			rl, _ := newTestRateLimiter(RateLimitRule{Name: "teste", Limit: 10, Window: time.Minute}, clock)
+
+			for i := 0; i < tt.anteriores; i++ {
+				rl.Allow("cliente")
+			}
+			clock.agora = clock.agora.Add(time.Minute + tt.decorrido)
+
+			result := rl.Allow("cliente")
+			if result.Allowed != tt.allowed || result.Remaining != tt.remaining {
+				t.Errorf("Allow() = permitida %v, restantes %d, esperado %v, %d", result.Allowed, result.Remaining, tt.allowed, tt.remaining)
+			}
+			if want := time.Minute - tt.decorrido; result.Reset != want {
+				t.Errorf("Reset = %v, esperado %v", result.Reset, want)
+			}
+		})
+	}
+
+	// Requisições de duas janelas atrás não contam
+	clock := novoRelogio()
+	rl, _ := newTestRateLimiter(RateLimitRule{Name: "teste", Limit: 10, Window: time.Minute}, clock)
+	for i := 0; i < 10; i++ {
+		rl.Allow("cliente")
+	}
+	clock.agora = clock.agora.Add(2 * time.Minute)
+	if result := rl.Allow("cliente"); !result.Allowed || result.Remaining != 9 {
+		t.Errorf("Allow() duas janelas depois = permitida %v, restantes %d, esperado true, 9", result.Allowed, result.Remaining)
+	}
+}
+
+func TestBloqueioAoExcederOLimite(t *testing.T) {
+	clock := novoRelogio()
+	rl, store := newTestRateLimiter(RateLimitRule{Name: "login", Limit: 2, Window: time.Minute, Block: 10 * time.Minute}, clock)
+
+	for i := 0; i < 2; i++ {
+		if result := rl.Allow("cliente"); !result.Allowed {
+			t.Fatalf("requisição %d recusada dentro do limite", i+1)
+		}
+	}
+
+	// A requisição acima do limite bloqueia o cliente pela duração da regra
+	result := rl.Allow("cliente")
+	if result.Allowed || result.RetryAfter != 10*time.Minute || result.Reset != 10*time.Minute {
+		t.Errorf("Allow() acima do limite = %+v, esperado recusada com nova tentativa em 10m", result)
+	}
+	if _, blocks, _ := store.Size(); blocks != 1 {
+		t.Errorf("%d bloqueios armazenados, esperado 1", blocks)
+	}
+
+	// Na janela seguinte, sem requisições acumuladas suficientes, o bloqueio ainda vale
+	clock.agora = clock.agora.Add(5 * time.Minute)
+	result = rl.Allow("cliente")
+	if result.Allowed || result.RetryAfter != 5*time.Minute {
+		t.Errorf("Allow() durante o bloqueio = %+v, esperado recusada com nova tentativa em 5m", result)
+	}
+
+	// Outros clientes não são afetados
+	if result := rl.Allow("outro"); !result.Allowed {
+		t.Error("cliente sem bloqueio recusado")
+	}
+
+	// Vencido o bloqueio, o cliente volta a ser atendido
+	clock.agora = clock.agora.Add(5 * time.Minute)
+	if result := rl.Allow("cliente"); !result.Allowed {
+		t.Errorf("Allow() após o bloqueio = %+v, esperado permitida", result)
+	}
+
+	// Sem bloqueio na regra, a recusa dura somente até o fim da janela
+	clock = novoRelogio()
+	clock.agora = clock.agora.Add(20 * time.Second)
+	rl, store = newTestRateLimiter(RateLimitRule{Name: "api", Limit: 1, Window: time.Minute}, clock)
+	rl.Allow("cliente")
+	result = rl.Allow("cliente")
+	if result.Allowed || result.RetryAfter != 40*time.Second {
+		t.Errorf("Allow() sem bloqueio = %+v, esperado recusada com nova tentativa em 40s", result)
+	}
+	if _, blocks, _ := store.Size(); blocks != 0 {
+		t.Errorf("%d bloqueios armazenados, esperado 0", blocks)
+	}
+}
+
+func TestCabecalhosDeLimitacao(t *testing.T) {
+	clock := novoRelogio()
+	clock.agora = clock.agora.Add(15 * time.Second)
+	rl, _ := newTestRateLimiter(RateLimitRule{Name: "login", Limit: 2, Window: time.Minute, Block: 10 * time.Minute}, clock)
+	handler := rl.Route("/auth/login")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		status     int
+		remaining  string
+		reset      string
+		retryAfter string
+	}{
+		{http.StatusNoContent, "1", "45", ""},
+		{http.StatusNoContent, "0", "45", ""},
+		{http.StatusTooManyRequests, "0", "600", "600"},
+	}
+	for i, tt := range tests {
+		r := httptest.NewRequest(http.MethodPost, "/auth/login", nil)
+		r.RemoteAddr = "203.0.113.5:51234"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("requisição %d: status %d, esperado %d", i+1, w.Code, tt.status)
+		}
+		for header, want := range map[string]string{
+			HeaderRateLimitLimit:     "2",
+			HeaderRateLimitRemaining: tt.remaining,
+			HeaderRateLimitReset:     tt.reset,
+			HeaderRateLimitPolicy:    "2;w=60",
+			HeaderRetryAfter:         tt.retryAfter,
+		} {
+			if got := w.Header().Get(header); got != want {
+				t.Errorf("requisição %d: %s = %q, esperado %q", i+1, header, got, want)
+			}
+		}
+	}
+}
@@ -2,6 +2,7 @@ package security
 
 import (
 	"net/http"
+	"strconv"
 )
 
 // SecurityHeaders adiciona cabeçalhos de segurança às respostas HTTP
@@ -40,7 +41,7 @@ func (sh *SecurityHeaders) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Strict-Transport-Security (HSTS)
 		if sh.HSTS {
-			hstsValue := "max-age=" + string(sh.HSTSMaxAge)
+			hstsValue := "max-age=" + strconv.Itoa(sh.HSTSMaxAge)
 			if sh.HSTSIncludeSubdomains {
 				hstsValue += "; includeSubDomains"
 			}
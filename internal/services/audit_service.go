@@ -2,27 +2,57 @@ package services
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/clientip"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/metrics"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
 )
 
 // AuditService gerencia o registro de ações de auditoria
 type AuditService struct {
-	DB *sql.DB
+	repo     models.AuditLogStore
+	attempts models.LoginAttemptStore
 }
 
-// NewAuditService cria um novo serviço de auditoria
-func NewAuditService(db *sql.DB) *AuditService {
+// Gravação assíncrona compartilhada do log de auditoria (nil: gravação direta)
+var (
+	auditWriterMu sync.RWMutex
+	auditWriter   *AuditWriter
+)
+
+// SetAuditWriter define a gravação assíncrona usada por todos os serviços de auditoria
+func SetAuditWriter(w *AuditWriter) {
+	auditWriterMu.Lock()
+	defer auditWriterMu.Unlock()
+	auditWriter = w
+}
+
+// currentAuditWriter retorna a gravação assíncrona em uso
+func currentAuditWriter() *AuditWriter {
+	auditWriterMu.RLock()
+	defer auditWriterMu.RUnlock()
+	return auditWriter
+}
+
+// NewAuditService cria um novo serviço de auditoria sobre o log de auditoria e as tentativas de login
+func NewAuditService(repo models.AuditLogStore, attempts models.LoginAttemptStore) *AuditService {
 	return &AuditService{
-		DB: db,
+		repo:     repo,
+		attempts: attempts,
 	}
 }
 
-// LogAction registra uma ação no log de auditoria
+// LogAction registra uma ação no log de auditoria. Com a gravação assíncrona configurada, a entrada é
+// apenas enfileirada, e a gravação ocorre em segundo plano. Dentro de uma unidade de trabalho
+// (models.UnitOfWork), a entrada é sempre gravada diretamente, na transação da unidade de trabalho.
 func (s *AuditService) LogAction(ctx context.Context, r *http.Request, action, entityType, entityID string, details string) error {
 	// Obter informações do usuário do contexto, se disponíveis
 	var userID int64
@@ -38,93 +68,181 @@ func (s *AuditService) LogAction(ctx context.Context, r *http.Request, action, e
 		username = usernameFromCtx
 	}
 	
-	// Obter o endereço IP do cliente
-	ipAddress := getIPAddress(r)
-	
-	// Inserir o registro de auditoria
-	query := `
-	INSERT INTO audit_log 
-	(user_id, username, action, entity_type, entity_id, details, ip_address) 
-	VALUES (?, ?, ?, ?, ?, ?, ?)`
-	
-	var userIDNullable sql.NullInt64
+	// Montar o registro de auditoria
+	entry := &models.AuditLog{
+		Username:   username,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		Details:    details,
+		IPAddress:  clientip.FromRequest(r),
+	}
+	if requestID, ok := middleware.GetRequestIDFromContext(ctx); ok {
+		entry.RequestID = requestID
+	}
 	if userID > 0 {
-		userIDNullable.Int64 = userID
-		userIDNullable.Valid = true
+		entry.UserID = &userID
+	}
+	
+	if writer := currentAuditWriter(); writer != nil && !models.InTransaction(ctx) {
+		return writer.Write(entry)
 	}
 	
-	var usernameNullable sql.NullString
-	if username != "" {
-		usernameNullable.String = username
-		usernameNullable.Valid = true
+	// A entrada é gravada mesmo que o cliente encerre a requisição, mas respeitando o tempo limite da consulta
+	if err := s.repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		metrics.AuditEntries.WithLabelValues(metrics.AuditFailed).Inc()
+		return err
+	}
+	metrics.AuditEntries.WithLabelValues(metrics.AuditWritten).Inc()
+	return nil
+}
+
+// WriterStats retorna os contadores da gravação assíncrona do log de auditoria, ou nil se a gravação for direta
+func (s *AuditService) WriterStats() *AuditWriterStats {
+	writer := currentAuditWriter()
+	if writer == nil {
+		return nil
 	}
 	
-	var entityIDNullable sql.NullString
-	if entityID != "" {
-		entityIDNullable.String = entityID
-		entityIDNullable.Valid = true
+	stats := writer.Stats()
+	return &stats
+}
+
+// GetHistorico retorna o histórico de alterações estruturadas de um registro
+func (s *AuditService) GetHistorico(ctx context.Context, entityType string, entityID int64, opts models.ListOptions) (*models.Page[models.AuditLog], error) {
+	return s.repo.GetHistorico(ctx, entityType, entityID, opts)
+}
+
+// CreateCheckpoint grava um ponto de verificação assinado da última entrada da cadeia do log de auditoria.
+// Retorna nil (sem erro) se não houver entradas novas desde o último ponto de verificação.
+func (s *AuditService) CreateCheckpoint(ctx context.Context) (*models.AuditCheckpoint, error) {
+	head, err := s.repo.ChainHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.LastID == 0 {
+		return nil, nil
 	}
 	
-	var detailsNullable sql.NullString
-	if details != "" {
-		detailsNullable.String = details
-		detailsNullable.Valid = true
+	last, err := s.repo.GetLastCheckpoint(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && last.LastAuditID == head.LastID {
+		return nil, nil
 	}
 	
-	_, err := s.DB.Exec(
-		query,
-		userIDNullable,
-		usernameNullable,
-		action,
-		entityType,
-		entityIDNullable,
-		detailsNullable,
-		ipAddress,
-	)
+	entries, err := s.repo.CountChained(ctx, head.LastID)
+	if err != nil {
+		return nil, err
+	}
 	
+	// Assinar com a chave ativa dos tokens
+	signature, kid, err := auth.SignCheckpoint(head.LastID, head.LastHash, entries)
 	if err != nil {
-		return fmt.Errorf("erro ao registrar ação de auditoria: %v", err)
+		return nil, err
 	}
 	
-	return nil
+	checkpoint := &models.AuditCheckpoint{
+		LastAuditID: head.LastID,
+		LastHash:    head.LastHash,
+		Entries:     entries,
+		KeyID:       kid,
+		Signature:   signature,
+	}
+	if err := s.repo.CreateCheckpoint(ctx, checkpoint); err != nil {
+		return nil, err
+	}
+	
+	return checkpoint, nil
 }
 
-// LogLoginAttempt registra uma tentativa de login
-func (s *AuditService) LogLoginAttempt(r *http.Request, login string, success bool) error {
-	// Obter o endereço IP do cliente
-	ipAddress := getIPAddress(r)
+// StartCheckpoints grava pontos de verificação no intervalo informado, até que a função retornada seja chamada
+func (s *AuditService) StartCheckpoints(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
 	
-	// Inserir o registro de tentativa de login
-	query := `
-	INSERT INTO login_attempts 
-	(login, ip_address, success) 
-	VALUES (?, ?, ?)`
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		
+		for {
+			select {
+			case <-ticker.C:
+				checkpoint, err := s.CreateCheckpoint(context.Background())
+				if err != nil {
+					slog.Error("Erro ao gravar ponto de verificação da auditoria", "error", err)
+				} else if checkpoint != nil {
+					slog.Info("Ponto de verificação da auditoria gravado", "last_audit_id", checkpoint.LastAuditID)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
 	
-	_, err := s.DB.Exec(query, login, ipAddress, success)
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// GetCheckpoints retorna uma página dos pontos de verificação do log de auditoria
+func (s *AuditService) GetCheckpoints(ctx context.Context, opts models.ListOptions) (*models.Page[models.AuditCheckpoint], error) {
+	return s.repo.GetCheckpoints(ctx, opts)
+}
+
+// VerifyChain verifica as assinaturas dos pontos de verificação e percorre a cadeia do log de auditoria,
+// retornando o primeiro encadeamento quebrado
+func (s *AuditService) VerifyChain(ctx context.Context) (*models.AuditChainVerification, error) {
+	checkpoints, err := s.repo.AllCheckpoints(ctx)
 	if err != nil {
-		return fmt.Errorf("erro ao registrar tentativa de login: %v", err)
+		return nil, err
 	}
 	
-	return nil
+	// Somente pontos de verificação com assinatura válida são comparados com a cadeia
+	var assinados []models.AuditCheckpoint
+	naoVerificados := 0
+	for _, checkpoint := range checkpoints {
+		claims, err := auth.VerifyCheckpoint(checkpoint.Signature)
+		if errors.Is(err, auth.ErrUnknownKey) {
+			naoVerificados++
+			continue
+		}
+		if err != nil || claims.LastAuditID != checkpoint.LastAuditID || claims.LastHash != checkpoint.LastHash || claims.Entries != checkpoint.Entries {
+			brokenAt := checkpoint.LastAuditID
+			return &models.AuditChainVerification{
+				BrokenAt:              &brokenAt,
+				Reason:                fmt.Sprintf("assinatura inválida no ponto de verificação %d", checkpoint.ID),
+				UnverifiedCheckpoints: naoVerificados,
+			}, nil
+		}
+		assinados = append(assinados, checkpoint)
+	}
+	
+	result, err := s.repo.VerifyChain(ctx, assinados)
+	if err != nil {
+		return nil, err
+	}
+	result.UnverifiedCheckpoints = naoVerificados
+	
+	return result, nil
+}
+
+// LogLoginAttempt registra uma tentativa de login, mesmo que o cliente encerre a requisição
+func (s *AuditService) LogLoginAttempt(r *http.Request, login string, success bool) error {
+	return s.attempts.Create(context.WithoutCancel(r.Context()), &models.LoginAttempt{
+		Login:     login,
+		IPAddress: clientip.FromRequest(r),
+		Success:   success,
+	})
 }
 
 // CheckLoginAttempts verifica se um usuário ou IP excedeu o limite de tentativas de login
 func (s *AuditService) CheckLoginAttempts(r *http.Request, login string) (bool, time.Time, error) {
-	// Obter o endereço IP do cliente
-	ipAddress := getIPAddress(r)
-	
 	// Verificar tentativas de login recentes (últimos 15 minutos)
-	query := `
-	SELECT COUNT(*) 
-	FROM login_attempts 
-	WHERE (login = ? OR ip_address = ?) 
-	AND success = false 
-	AND attempt_time > DATE_SUB(NOW(), INTERVAL 15 MINUTE)`
-	
-	var count int
-	err := s.DB.QueryRow(query, login, ipAddress).Scan(&count)
+	count, err := s.attempts.CountRecentFailures(r.Context(), login, clientip.FromRequest(r), 15)
 	if err != nil {
-		return false, time.Time{}, fmt.Errorf("erro ao verificar tentativas de login: %v", err)
+		return false, time.Time{}, err
 	}
 	
 	// Se houver mais de 5 tentativas falhas nos últimos 15 minutos, bloquear a conta
@@ -132,15 +250,8 @@ func (s *AuditService) CheckLoginAttempts(r *http.Request, login string) (bool,
 		// Bloquear a conta por 30 minutos
 		blockUntil := time.Now().Add(30 * time.Minute)
 		
-		// Atualizar o status de bloqueio do usuário
-		updateQuery := `
-		UPDATE usuarios 
-		SET bloqueado = true, bloqueado_ate = ? 
-		WHERE login = ?`
-		
-		_, err := s.DB.Exec(updateQuery, blockUntil, login)
-		if err != nil {
-			return true, blockUntil, fmt.Errorf("erro ao bloquear conta: %v", err)
+		if err := s.attempts.LockAccount(context.WithoutCancel(r.Context()), login, blockUntil); err != nil {
+			return true, blockUntil, err
 		}
 		
 		return true, blockUntil, nil
@@ -150,51 +261,20 @@ func (s *AuditService) CheckLoginAttempts(r *http.Request, login string) (bool,
 }
 
 // IsAccountLocked verifica se uma conta está bloqueada
-func (s *AuditService) IsAccountLocked(login string) (bool, time.Time, error) {
-	query := `
-	SELECT bloqueado, bloqueado_ate 
-	FROM usuarios 
-	WHERE login = ?`
-	
-	var bloqueado bool
-	var bloqueadoAte sql.NullTime
-	
-	err := s.DB.QueryRow(query, login).Scan(&bloqueado, &bloqueadoAte)
+func (s *AuditService) IsAccountLocked(ctx context.Context, login string) (bool, time.Time, error) {
+	bloqueado, bloqueadoAte, err := s.attempts.GetAccountLock(ctx, login)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return false, time.Time{}, nil
-		}
-		return false, time.Time{}, fmt.Errorf("erro ao verificar status de bloqueio: %v", err)
+		return false, time.Time{}, err
 	}
 	
 	// Se a conta estiver bloqueada, mas o tempo de bloqueio já passou, desbloquear
-	if bloqueado && bloqueadoAte.Valid && bloqueadoAte.Time.Before(time.Now()) {
-		// Desbloquear a conta
-		updateQuery := `
-		UPDATE usuarios 
-		SET bloqueado = false, bloqueado_ate = NULL 
-		WHERE login = ?`
-		
-		_, err := s.DB.Exec(updateQuery, login)
-		if err != nil {
-			return true, bloqueadoAte.Time, fmt.Errorf("erro ao desbloquear conta: %v", err)
+	if bloqueado && !bloqueadoAte.IsZero() && bloqueadoAte.Before(time.Now()) {
+		if err := s.attempts.UnlockAccount(ctx, login); err != nil {
+			return true, bloqueadoAte, err
 		}
 		
 		return false, time.Time{}, nil
 	}
 	
-	return bloqueado, bloqueadoAte.Time, nil
-}
-
-// getIPAddress obtém o endereço IP do cliente a partir da requisição
-func getIPAddress(r *http.Request) string {
-	// Tentar obter o IP real se estiver atrás de um proxy
-	ip := r.Header.Get("X-Real-IP")
-	if ip == "" {
-		ip = r.Header.Get("X-Forwarded-For")
-	}
-	if ip == "" {
-		ip = r.RemoteAddr
-	}
-	return ip
+	return bloqueado, bloqueadoAte, nil
 }
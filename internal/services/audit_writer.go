@@ -0,0 +1,416 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/metrics"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// Políticas aplicadas quando a fila do log de auditoria está cheia
+const (
+	AuditOverflowBlock = "block" // Aguarda espaço na fila (a requisição fica bloqueada)
+	AuditOverflowDrop  = "drop"  // Descarta a entrada e incrementa o contador de descartes
+	AuditOverflowSpill = "spill" // Grava a entrada no arquivo de transbordo, regravado no próximo início
+)
+
+// Sufixo do arquivo, ao lado do transbordo, que recebe as linhas ilegíveis encontradas na regravação
+const corruptSpillSuffix = ".corrupt"
+
+// ErrAuditDropped indica que a entrada foi descartada por a fila do log de auditoria estar cheia
+var ErrAuditDropped = errors.New("fila do log de auditoria cheia: entrada descartada")
+
+// AuditWriterConfig armazena as configurações da gravação assíncrona do log de auditoria
+type AuditWriterConfig struct {
+	QueueSize     int           // Capacidade da fila
+	BatchSize     int           // Máximo de entradas por INSERT
+	FlushInterval time.Duration // Intervalo máximo até a gravação de um lote incompleto
+	Overflow      string        // block, drop ou spill
+	SpillFile     string        // Arquivo JSON Lines de transbordo (política spill e lotes com falha)
+}
+
+// AuditWriterStats representa os contadores da gravação assíncrona do log de auditoria
+type AuditWriterStats struct {
+	Queued  int   `json:"queued"`  // Entradas aguardando gravação
+	Written int64 `json:"written"` // Entradas gravadas
+	Dropped int64 `json:"dropped"` // Entradas descartadas com a fila cheia
+	Spilled int64 `json:"spilled"` // Entradas gravadas no arquivo de transbordo
+	Failed  int64 `json:"failed"`  // Entradas perdidas por falha na gravação
+}
+
+// AuditWriter grava o log de auditoria em segundo plano: as entradas são enfileiradas sem acessar o
+// banco e um único worker as grava em lotes, encadeadas na ordem da fila.
+type AuditWriter struct {
+	repo   models.AuditLogStore
+	config AuditWriterConfig
+	queue  chan *models.AuditLog
+	done   chan struct{} // Fechado ao encerrar: o worker grava o restante da fila e termina
+	exited chan struct{} // Fechado quando o worker termina
+
+	// Fechado no início do encerramento: libera as entradas aguardando espaço na fila (política block)
+	closing chan struct{}
+
+	mu     sync.RWMutex // Protege closed: nenhuma entrada é enfileirada depois do encerramento
+	closed bool
+	once   sync.Once
+
+	spillMu sync.Mutex
+
+	written  atomic.Int64
+	dropped  atomic.Int64
+	spilled  atomic.Int64
+	failed   atomic.Int64
+	reported int64 // Descartes já informados no log (usado apenas pelo worker)
+}
+
+// NewAuditWriter cria a gravação assíncrona do log de auditoria e inicia o worker.
+// Entradas do arquivo de transbordo de uma execução anterior são regravadas antes das novas.
+func NewAuditWriter(repo models.AuditLogStore, config AuditWriterConfig) *AuditWriter {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	
+	w := &AuditWriter{
+		repo:    repo,
+		config:  config,
+		queue:   make(chan *models.AuditLog, config.QueueSize),
+		done:    make(chan struct{}),
+		exited:  make(chan struct{}),
+		closing: make(chan struct{}),
+	}
+	
+	w.replaySpill()
+	go w.run()
+	
+	return w
+}
+
+// Write enfileira uma entrada do log de auditoria. Com a fila cheia, aplica a política configurada.
+// Depois do encerramento, a entrada é gravada diretamente.
+func (w *AuditWriter) Write(entry *models.AuditLog) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	
+	if handled, err := w.enqueue(entry); handled {
+		return err
+	}
+	
+	// Encerrada a gravação assíncrona, gravar diretamente, sem manter o bloqueio aguardado por Close
+	return w.repo.Create(context.Background(), entry)
+}
+
+// enqueue enfileira a entrada e, com a fila cheia, aplica a política configurada. Retorna false se a
+// gravação assíncrona estiver encerrada (ou for encerrada durante a espera por espaço na fila), para
+// que a entrada seja gravada diretamente.
+func (w *AuditWriter) enqueue(entry *models.AuditLog) (bool, error) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	
+	if w.closed {
+		return false, nil
+	}
+	
+	select {
+	case w.queue <- entry:
+		return true, nil
+	default:
+	}
+	
+	// Fila cheia
+	switch w.config.Overflow {
+	case AuditOverflowDrop:
+		w.dropped.Add(1)
+		metrics.AuditEntries.WithLabelValues(metrics.AuditDropped).Inc()
+		return true, ErrAuditDropped
+	case AuditOverflowSpill:
+		if err := w.spill([]*models.AuditLog{entry}); err != nil {
+			w.failed.Add(1)
+			metrics.AuditEntries.WithLabelValues(metrics.AuditFailed).Inc()
+			return true, err
+		}
+		return true, nil
+	default:
+		// Aguardar espaço na fila. O encerramento interrompe a espera: Close aguarda o bloqueio de
+		// leitura mantido aqui, e o worker pode estar parado em um lote lento.
+		select {
+		case w.queue <- entry:
+			return true, nil
+		case <-w.closing:
+			return false, nil
+		}
+	}
+}
+
+// Stats retorna os contadores da gravação assíncrona
+func (w *AuditWriter) Stats() AuditWriterStats {
+	return AuditWriterStats{
+		Queued:  len(w.queue),
+		Written: w.written.Load(),
+		Dropped: w.dropped.Load(),
+		Spilled: w.spilled.Load(),
+		Failed:  w.failed.Load(),
+	}
+}
+
+// Close encerra a gravação assíncrona, aguardando a gravação das entradas enfileiradas até o fim do
+// contexto. Entradas registradas depois do encerramento são gravadas diretamente.
+func (w *AuditWriter) Close(ctx context.Context) error {
+	w.once.Do(func() {
+		close(w.closing)
+		w.mu.Lock()
+		w.closed = true
+		w.mu.Unlock()
+		close(w.done)
+	})
+	
+	select {
+	case <-w.exited:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("erro ao gravar o log de auditoria: %d entradas não gravadas no encerramento", len(w.queue))
+	}
+}
+
+// run agrupa as entradas da fila em lotes, gravados quando completos ou a cada intervalo
+func (w *AuditWriter) run() {
+	defer close(w.exited)
+	
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	
+	batch := make([]*models.AuditLog, 0, w.config.BatchSize)
+	for {
+		select {
+		case entry := <-w.queue:
+			batch = append(batch, entry)
+			if len(batch) >= w.config.BatchSize {
+				batch = w.flush(batch)
+			}
+		case <-ticker.C:
+			batch = w.flush(batch)
+			w.reportDropped()
+		case <-w.done:
+			// Gravar o restante da fila: nenhuma entrada é enfileirada depois do encerramento
+			for {
+				select {
+				case entry := <-w.queue:
+					batch = append(batch, entry)
+					if len(batch) >= w.config.BatchSize {
+						batch = w.flush(batch)
+					}
+				default:
+					w.flush(batch)
+					w.reportDropped()
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush grava um lote e retorna o lote vazio para reutilização. Lotes com falha são gravados
+// no arquivo de transbordo, se configurado, ou contados como perdidos.
+func (w *AuditWriter) flush(batch []*models.AuditLog) []*models.AuditLog {
+	if len(batch) == 0 {
+		return batch
+	}
+	
+	if err := w.repo.CreateBatch(context.Background(), batch); err != nil {
+		slog.Error("Erro ao gravar lote de registros de auditoria", "entries", len(batch), "error", err)
+		if w.config.SpillFile == "" || w.spill(batch) != nil {
+			w.failed.Add(int64(len(batch)))
+			metrics.AuditEntries.WithLabelValues(metrics.AuditFailed).Add(float64(len(batch)))
+		}
+	} else {
+		w.written.Add(int64(len(batch)))
+		metrics.AuditEntries.WithLabelValues(metrics.AuditWritten).Add(float64(len(batch)))
+	}
+	
+	for i := range batch {
+		batch[i] = nil
+	}
+	return batch[:0]
+}
+
+// reportDropped informa no log as entradas descartadas desde o último aviso
+func (w *AuditWriter) reportDropped() {
+	dropped := w.dropped.Load()
+	if dropped > w.reported {
+		slog.Warn("Registros de auditoria descartados com a fila cheia", "dropped", dropped-w.reported, "total", dropped)
+		w.reported = dropped
+	}
+}
+
+// spill acrescenta as entradas ao arquivo de transbordo, uma por linha em JSON
+func (w *AuditWriter) spill(entries []*models.AuditLog) error {
+	if w.config.SpillFile == "" {
+		return fmt.Errorf("erro ao gravar transbordo da auditoria: arquivo não configurado")
+	}
+	
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+	
+	file, err := os.OpenFile(w.config.SpillFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		slog.Error("Erro ao abrir o arquivo de transbordo da auditoria", "error", err)
+		return fmt.Errorf("erro ao abrir o arquivo de transbordo da auditoria: %v", err)
+	}
+	defer file.Close()
+	
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			slog.Error("Erro ao gravar o arquivo de transbordo da auditoria", "error", err)
+			return fmt.Errorf("erro ao gravar o arquivo de transbordo da auditoria: %v", err)
+		}
+	}
+	
+	w.spilled.Add(int64(len(entries)))
+	metrics.AuditEntries.WithLabelValues(metrics.AuditSpilled).Add(float64(len(entries)))
+	return nil
+}
+
+// replaySpill regrava no log de auditoria as entradas do arquivo de transbordo. O arquivo é removido
+// somente se todas as entradas forem gravadas. Linhas ilegíveis (como a última linha de uma gravação
+// interrompida) são movidas para o arquivo .corrupt ao lado do transbordo, e as demais são regravadas.
+func (w *AuditWriter) replaySpill() {
+	if w.config.SpillFile == "" {
+		return
+	}
+	
+	file, err := os.Open(w.config.SpillFile)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		slog.Error("Erro ao abrir o arquivo de transbordo da auditoria", "error", err)
+		return
+	}
+	defer file.Close()
+	
+	var entries []*models.AuditLog
+	var corrupt [][]byte
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var entry models.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			slog.Error("Linha ilegível no arquivo de transbordo da auditoria", "line", line, "error", err)
+			corrupt = append(corrupt, append([]byte(nil), scanner.Bytes()...))
+			continue
+		}
+		// O ID e os hashes são atribuídos na gravação
+		entry.ID, entry.PrevHash, entry.Hash = 0, "", ""
+		entries = append(entries, &entry)
+	}
+	if err := scanner.Err(); err != nil {
+		slog.Error("Erro ao ler o arquivo de transbordo da auditoria", "error", err)
+		return
+	}
+	
+	// Sem guardar as linhas ilegíveis, o arquivo é mantido como está para não perdê-las
+	if len(corrupt) > 0 {
+		if err := w.saveCorruptSpill(corrupt); err != nil {
+			slog.Error("Erro ao guardar as linhas ilegíveis do transbordo da auditoria", "error", err)
+			return
+		}
+		slog.Warn("Linhas ilegíveis do transbordo da auditoria movidas", "lines", len(corrupt), "file", w.config.SpillFile+corruptSpillSuffix)
+	}
+	
+	for start := 0; start < len(entries); start += w.config.BatchSize {
+		end := start + w.config.BatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		if err := w.repo.CreateBatch(context.Background(), entries[start:end]); err != nil {
+			slog.Error("Erro ao regravar o transbordo da auditoria", "replayed", start, "entries", len(entries), "error", err)
+			// Manter no arquivo somente as entradas não regravadas
+			file.Close()
+			if err := w.rewriteSpill(entries[start:]); err != nil {
+				slog.Error("Erro ao regravar o arquivo de transbordo da auditoria", "error", err)
+			}
+			return
+		}
+	}
+	
+	file.Close()
+	if err := os.Remove(w.config.SpillFile); err != nil {
+		slog.Error("Erro ao remover o arquivo de transbordo da auditoria", "error", err)
+	}
+	slog.Info("Registros de auditoria regravados do arquivo de transbordo", "entries", len(entries))
+}
+
+// saveCorruptSpill acrescenta as linhas ilegíveis do transbordo ao arquivo .corrupt, para análise manual
+func (w *AuditWriter) saveCorruptSpill(lines [][]byte) error {
+	file, err := os.OpenFile(w.config.SpillFile+corruptSpillSuffix, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir o arquivo de linhas ilegíveis: %w", err)
+	}
+	
+	for _, line := range lines {
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			file.Close()
+			return fmt.Errorf("erro ao gravar o arquivo de linhas ilegíveis: %w", err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("erro ao gravar o arquivo de linhas ilegíveis: %w", err)
+	}
+	return file.Close()
+}
+
+// rewriteSpill substitui o arquivo de transbordo pelas entradas informadas. As entradas são gravadas
+// em um arquivo temporário no mesmo diretório, renomeado sobre o original: com erro, o arquivo original
+// é mantido e nenhuma entrada se perde (as já regravadas são regravadas novamente no próximo início).
+func (w *AuditWriter) rewriteSpill(entries []*models.AuditLog) error {
+	w.spillMu.Lock()
+	defer w.spillMu.Unlock()
+	
+	tmp, err := os.CreateTemp(filepath.Dir(w.config.SpillFile), filepath.Base(w.config.SpillFile)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("erro ao criar o arquivo temporário de transbordo: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // Sem efeito após a renomeação
+	
+	encoder := json.NewEncoder(tmp)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			tmp.Close()
+			return fmt.Errorf("erro ao gravar o arquivo temporário de transbordo: %w", err)
+		}
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao gravar o arquivo temporário de transbordo: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar o arquivo temporário de transbordo: %w", err)
+	}
+	
+	if err := os.Rename(tmp.Name(), w.config.SpillFile); err != nil {
+		return fmt.Errorf("erro ao substituir o arquivo de transbordo: %w", err)
+	}
+	return nil
+}
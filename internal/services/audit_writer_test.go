@@ -0,0 +1,204 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// stubAuditStore é o log de auditoria em memória com a gravação em lote controlada pelo teste
+type stubAuditStore struct {
+	models.AuditLogStore
+	createBatch func(entries []*models.AuditLog) error
+}
+
+func (s *stubAuditStore) CreateBatch(ctx context.Context, entries []*models.AuditLog) error {
+	if err := s.createBatch(entries); err != nil {
+		return err
+	}
+	return s.AuditLogStore.CreateBatch(ctx, entries)
+}
+
+func newStubAuditStore(createBatch func(entries []*models.AuditLog) error) *stubAuditStore {
+	return &stubAuditStore{AuditLogStore: models.NewMemoryStores().AuditLog, createBatch: createBatch}
+}
+
+// writeSpillFile grava as entradas no arquivo de transbordo, uma por linha
+func writeSpillFile(t *testing.T, path string, actions ...string) {
+	t.Helper()
+
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("erro ao criar o arquivo de transbordo: %v", err)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	for _, action := range actions {
+		if err := encoder.Encode(&models.AuditLog{Action: action, EntityType: "TESTE", IPAddress: "127.0.0.1", CreatedAt: time.Now()}); err != nil {
+			t.Fatalf("erro ao gravar o arquivo de transbordo: %v", err)
+		}
+	}
+}
+
+// readSpillActions retorna as ações das entradas do arquivo de transbordo
+func readSpillActions(t *testing.T, path string) []string {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("erro ao abrir o arquivo de transbordo: %v", err)
+	}
+	defer file.Close()
+
+	var actions []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry models.AuditLog
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("erro ao ler o arquivo de transbordo: %v", err)
+		}
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestReplaySpillMantemEntradasNaoRegravadas(t *testing.T) {
+	dir := t.TempDir()
+	spillFile := filepath.Join(dir, "audit-spill.jsonl")
+	writeSpillFile(t, spillFile, "A", "B", "C")
+
+	// O segundo lote falha: somente a primeira entrada é regravada
+	lotes := 0
+	store := newStubAuditStore(func(entries []*models.AuditLog) error {
+		lotes++
+		if lotes == 2 {
+			return errors.New("banco indisponível")
+		}
+		return nil
+	})
+
+	w := NewAuditWriter(store, AuditWriterConfig{QueueSize: 10, BatchSize: 1, FlushInterval: time.Hour, SpillFile: spillFile})
+	defer w.Close(context.Background())
+
+	actions := readSpillActions(t, spillFile)
+	if len(actions) != 2 || actions[0] != "B" || actions[1] != "C" {
+		t.Errorf("entradas no transbordo = %v, esperado [B C]", actions)
+	}
+
+	// O arquivo temporário é renomeado sobre o original
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("erro ao listar o diretório: %v", err)
+	}
+	if len(files) != 1 {
+		t.Errorf("%d arquivos no diretório do transbordo, esperado 1", len(files))
+	}
+}
+
+func TestReplaySpillIgnoraLinhaIncompleta(t *testing.T) {
+	dir := t.TempDir()
+	spillFile := filepath.Join(dir, "audit-spill.jsonl")
+	writeSpillFile(t, spillFile, "A", "B")
+
+	// Gravação interrompida no meio da última linha
+	file, err := os.OpenFile(spillFile, os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		t.Fatalf("erro ao abrir o arquivo de transbordo: %v", err)
+	}
+	if _, err := file.WriteString(`{"action":"C","entity_ty`); err != nil {
+		t.Fatalf("erro ao gravar o arquivo de transbordo: %v", err)
+	}
+	file.Close()
+
+	var replayed []string
+	store := newStubAuditStore(func(entries []*models.AuditLog) error {
+		for _, entry := range entries {
+			replayed = append(replayed, entry.Action)
+		}
+		return nil
+	})
+	w := NewAuditWriter(store, AuditWriterConfig{QueueSize: 10, BatchSize: 10, FlushInterval: time.Hour, SpillFile: spillFile})
+	defer w.Close(context.Background())
+
+	// As entradas legíveis são regravadas e o transbordo removido
+	if len(replayed) != 2 || replayed[0] != "A" || replayed[1] != "B" {
+		t.Errorf("entradas regravadas = %v, esperado [A B]", replayed)
+	}
+	if _, err := os.Stat(spillFile); !os.IsNotExist(err) {
+		t.Errorf("arquivo de transbordo mantido após a regravação (erro %v)", err)
+	}
+
+	// A linha incompleta é guardada no arquivo .corrupt
+	corrupt, err := os.ReadFile(spillFile + ".corrupt")
+	if err != nil {
+		t.Fatalf("erro ao ler as linhas ilegíveis: %v", err)
+	}
+	if string(corrupt) != `{"action":"C","entity_ty`+"\n" {
+		t.Errorf("linhas ilegíveis = %q", corrupt)
+	}
+}
+
+func TestCloseLiberaEntradasAguardandoFila(t *testing.T) {
+	// O worker fica parado no primeiro lote até o fim do teste
+	liberar := make(chan struct{})
+	var once sync.Once
+	store := newStubAuditStore(func(entries []*models.AuditLog) error {
+		if entries[0].Action == "lento" {
+			<-liberar
+		}
+		return nil
+	})
+	defer once.Do(func() { close(liberar) })
+
+	w := NewAuditWriter(store, AuditWriterConfig{QueueSize: 1, BatchSize: 1, FlushInterval: time.Hour, Overflow: AuditOverflowBlock})
+
+	// Primeira entrada em gravação, segunda na fila e terceira aguardando espaço na fila
+	if err := w.Write(&models.AuditLog{Action: "lento", EntityType: "TESTE", IPAddress: "127.0.0.1"}); err != nil {
+		t.Fatalf("erro ao enfileirar: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(w.queue) != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if err := w.Write(&models.AuditLog{Action: "fila", EntityType: "TESTE", IPAddress: "127.0.0.1"}); err != nil {
+		t.Fatalf("erro ao enfileirar: %v", err)
+	}
+	bloqueada := make(chan error, 1)
+	go func() {
+		bloqueada <- w.Write(&models.AuditLog{Action: "bloqueada", EntityType: "TESTE", IPAddress: "127.0.0.1"})
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// O encerramento respeita o prazo do contexto e a entrada bloqueada é gravada diretamente
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := w.Close(ctx); err == nil {
+		t.Error("Close retornou sem erro com o worker parado, esperado o erro do prazo")
+	}
+	select {
+	case err := <-bloqueada:
+		if err != nil {
+			t.Errorf("erro ao gravar a entrada bloqueada: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("entrada aguardando espaço na fila não foi liberada pelo encerramento")
+	}
+
+	// Com o worker liberado, a entrada da fila também é gravada
+	once.Do(func() { close(liberar) })
+	if err := w.Close(context.Background()); err != nil {
+		t.Errorf("erro ao encerrar: %v", err)
+	}
+	if stats := w.Stats(); stats.Written != 2 {
+		t.Errorf("entradas gravadas pelo worker = %d, esperado 2", stats.Written)
+	}
+}
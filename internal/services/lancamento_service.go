@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// Quantidade máxima de transações aceitas em uma única geração
+const MaxTransacoesPorLote = 1000
+
+// ErrLoteVazio indica uma requisição de geração sem transações
+var ErrLoteVazio = errors.New("nenhuma transação informada")
+
+// ErrLoteExcedido indica uma requisição com mais transações que o permitido
+var ErrLoteExcedido = fmt.Errorf("quantidade máxima de %d transações por lote excedida", MaxTransacoesPorLote)
+
+// ResultadoGeracao representa o resultado da geração de lançamentos de um lote.
+// Lote é nil se nenhuma transação for aceita: nesse caso, nada é gravado.
+type ResultadoGeracao struct {
+	Lote        *models.LoteLancamento      `json:"lote,omitempty"`
+	Lancamentos []models.Lancamento         `json:"lancamentos"`
+	Rejeitadas  []models.TransacaoRejeitada `json:"rejeitadas"`
+}
+
+// LancamentoService gera lançamentos contábeis a partir das configurações de sistema contábil
+type LancamentoService struct {
+	eventoRepo     models.EventoStore
+	objetoRepo     models.ObjetoContabilizacaoStore
+	configRepo     models.SistemaContabilConfigStore
+	lancamentoRepo models.LancamentoStore
+}
+
+// NewLancamentoService cria um novo serviço de geração de lançamentos
+func NewLancamentoService(
+	eventoRepo models.EventoStore,
+	objetoRepo models.ObjetoContabilizacaoStore,
+	configRepo models.SistemaContabilConfigStore,
+	lancamentoRepo models.LancamentoStore,
+) *LancamentoService {
+	return &LancamentoService{
+		eventoRepo:     eventoRepo,
+		objetoRepo:     objetoRepo,
+		configRepo:     configRepo,
+		lancamentoRepo: lancamentoRepo,
+	}
+}
+
+// Gerar processa as transações de negócio e gera, para cada configuração ativa do par evento/objeto,
+// um lançamento balanceado (débito e crédito) no sistema contábil de destino.
+// As transações aceitas são gravadas em um único lote; as demais são devolvidas com os motivos da rejeição.
+// Se todas as transações forem rejeitadas, nenhum lote é gravado. Todas as transações de um lote devem ser da seguradora do lote (ver seguradoraDoLote).
+func (s *LancamentoService) Gerar(ctx context.Context, scope models.TenantScope, idUsuario int64, transacoes []models.TransacaoNegocio) (*ResultadoGeracao, error) {
+	// Validar o tamanho do lote
+	if len(transacoes) == 0 {
+		return nil, ErrLoteVazio
+	}
+	if len(transacoes) > MaxTransacoesPorLote {
+		return nil, ErrLoteExcedido
+	}
+	
+	eventoRepo := s.eventoRepo.WithTenant(scope)
+	objetoRepo := s.objetoRepo.WithTenant(scope)
+	configRepo := s.configRepo.WithTenant(scope)
+	
+	resultado := &ResultadoGeracao{
+		Lancamentos: []models.Lancamento{},
+		Rejeitadas:  []models.TransacaoRejeitada{},
+	}
+	idSeguradora := seguradoraDoLote(scope, transacoes)
+	
+	for i, transacao := range transacoes {
+		// A seguradora padrão é a do lote
+		if transacao.IdSeguradora == 0 {
+			transacao.IdSeguradora = idSeguradora
+		}
+		
+		lancamentos, motivos, err := s.gerarTransacao(ctx, eventoRepo, objetoRepo, configRepo, &scope, idSeguradora, transacao)
+		if err != nil {
+			return nil, err
+		}
+		
+		if len(motivos) > 0 {
+			resultado.Rejeitadas = append(resultado.Rejeitadas, models.TransacaoRejeitada{
+				Indice:    i,
+				Transacao: transacao,
+				Motivos:   motivos,
+			})
+			continue
+		}
+		
+		resultado.Lancamentos = append(resultado.Lancamentos, lancamentos...)
+	}
+	
+	// Garantir que os lançamentos de cada sistema contábil estejam balanceados
+	if err := verificarBalanceamento(resultado.Lancamentos); err != nil {
+		return nil, err
+	}
+	
+	// Sem transações aceitas, não há lançamentos a gravar nem lote
+	if len(resultado.Rejeitadas) == len(transacoes) {
+		return resultado, nil
+	}
+	
+	resultado.Lote = &models.LoteLancamento{
+		IdSeguradora:    idSeguradora,
+		IdUsuario:       idUsuario,
+		TotalTransacoes: len(transacoes),
+		TotalAceitas:    len(transacoes) - len(resultado.Rejeitadas),
+		TotalRejeitadas: len(resultado.Rejeitadas),
+	}
+	
+	// Gravar o lote e os lançamentos gerados em uma única transação
+	if err := s.lancamentoRepo.WithTenant(scope).CreateLote(ctx, resultado.Lote, resultado.Lancamentos); err != nil {
+		return nil, err
+	}
+	
+	return resultado, nil
+}
+
+// seguradoraDoLote retorna a seguradora do lote: a do escopo do usuário ou, para o AdminERP com acesso
+// a todas as seguradoras, a da primeira transação que informar a seguradora
+func seguradoraDoLote(scope models.TenantScope, transacoes []models.TransacaoNegocio) int64 {
+	if scope.Todas {
+		for _, transacao := range transacoes {
+			if transacao.IdSeguradora > 0 {
+				return transacao.IdSeguradora
+			}
+		}
+	}
+	return scope.IdSeguradora
+}
+
+// gerarTransacao valida uma transação e gera seus lançamentos, retornando os motivos caso seja rejeitada
+func (s *LancamentoService) gerarTransacao(
+	ctx context.Context,
+	eventoRepo models.EventoStore,
+	objetoRepo models.ObjetoContabilizacaoStore,
+	configRepo models.SistemaContabilConfigStore,
+	scope *models.TenantScope,
+	idSeguradoraLote int64,
+	transacao models.TransacaoNegocio,
+) ([]models.Lancamento, []string, error) {
+	var motivos []string
+	
+	// Validar os dados da transação
+	if transacao.IdSeguradora <= 0 {
+		motivos = append(motivos, "seguradora é obrigatória")
+	} else if !scope.Allows(transacao.IdSeguradora) {
+		motivos = append(motivos, fmt.Sprintf("seguradora %d não permitida para o usuário", transacao.IdSeguradora))
+	} else if transacao.IdSeguradora != idSeguradoraLote {
+		motivos = append(motivos, fmt.Sprintf("seguradora %d diferente da seguradora %d do lote", transacao.IdSeguradora, idSeguradoraLote))
+	}
+	
+	if transacao.Valor <= 0 {
+		motivos = append(motivos, "valor deve ser maior que zero")
+	}
+	
+	dataMovimento, err := time.Parse("2006-01-02", transacao.DataMovimento)
+	if err != nil {
+		motivos = append(motivos, "data do movimento inválida (use o formato AAAA-MM-DD)")
+	}
+	
+	documento := strings.TrimSpace(transacao.DocumentoReferencia)
+	if documento == "" {
+		motivos = append(motivos, "documento de referência é obrigatório")
+	} else if len(documento) > 100 {
+		motivos = append(motivos, "documento de referência deve ter no máximo 100 caracteres")
+	}
+	
+	objetoCodigo := strings.TrimSpace(transacao.ObjetoContabilizacao)
+	if objetoCodigo == "" {
+		motivos = append(motivos, "objeto de contabilização é obrigatório")
+	}
+	
+	// Sem seguradora válida não é possível localizar o evento e o objeto
+	if len(motivos) > 0 {
+		return nil, motivos, nil
+	}
+	
+	// Localizar o evento pelo número
+	evento, err := eventoRepo.GetByNumero(ctx, transacao.IdSeguradora, transacao.Evento)
+	if err != nil {
+		return nil, nil, err
+	}
+	if evento == nil {
+		motivos = append(motivos, fmt.Sprintf("evento %d não encontrado para a seguradora %d", transacao.Evento, transacao.IdSeguradora))
+	} else if !evento.Ativo {
+		motivos = append(motivos, fmt.Sprintf("evento %d está inativo", transacao.Evento))
+	}
+	
+	// Localizar o objeto de contabilização pelo código
+	objeto, err := objetoRepo.GetByCodigo(ctx, transacao.IdSeguradora, objetoCodigo)
+	if err != nil {
+		return nil, nil, err
+	}
+	if objeto == nil {
+		motivos = append(motivos, fmt.Sprintf("objeto de contabilização %s não encontrado para a seguradora %d", objetoCodigo, transacao.IdSeguradora))
+	} else if !objeto.Ativo {
+		motivos = append(motivos, fmt.Sprintf("objeto de contabilização %s está inativo", objetoCodigo))
+	}
+	
+	if len(motivos) > 0 {
+		return nil, motivos, nil
+	}
+	
+	// Buscar as configurações ativas do par evento/objeto
+	configs, err := configRepo.GetAtivasByEventoObjeto(ctx, transacao.IdSeguradora, evento.ID, objeto.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(configs) == 0 {
+		motivos = append(motivos, fmt.Sprintf(
+			"nenhuma configuração ativa de sistema contábil para o evento %d e o objeto %s",
+			transacao.Evento, objetoCodigo,
+		))
+		return nil, motivos, nil
+	}
+	
+	// Todas as configurações precisam ter as contas de débito e crédito definidas
+	for _, config := range configs {
+		if config.IdContaDebito == nil || config.IdContaCredito == nil {
+			motivos = append(motivos, fmt.Sprintf(
+				"configuração %d do sistema contábil %s não possui contas de débito e crédito",
+				config.ID, config.SistemaContabilNome,
+			))
+		}
+	}
+	if len(motivos) > 0 {
+		return nil, motivos, nil
+	}
+	
+	// Gerar um lançamento balanceado para cada sistema contábil de destino
+	valor := transacao.Valor
+	lancamentos := make([]models.Lancamento, 0, len(configs))
+	
+	for _, config := range configs {
+		lancamentos = append(lancamentos, models.Lancamento{
+			IdSeguradora:            transacao.IdSeguradora,
+			IdSistemaContabil:       config.IdSistemaContabil,
+			IdSistemaContabilConfig: config.ID,
+			IdCodigoEvento:          evento.ID,
+			IdObjetoContabilizacao:  objeto.ID,
+			DataMovimento:           dataMovimento,
+			Valor:                   valor,
+			DocumentoReferencia:     documento,
+			Partidas: []models.PartidaLancamento{
+				{Natureza: models.NaturezaDebito, Valor: valor, IdConta: config.IdContaDebito},
+				{Natureza: models.NaturezaCredito, Valor: valor, IdConta: config.IdContaCredito},
+			},
+		})
+	}
+	
+	return lancamentos, nil, nil
+}
+
+// verificarBalanceamento confere se o total de débitos é igual ao de créditos em cada sistema contábil
+func verificarBalanceamento(lancamentos []models.Lancamento) error {
+	saldos := make(map[int64]models.Centavos)
+	
+	for _, l := range lancamentos {
+		for _, p := range l.Partidas {
+			switch p.Natureza {
+			case models.NaturezaDebito:
+				saldos[l.IdSistemaContabil] += p.Valor
+			case models.NaturezaCredito:
+				saldos[l.IdSistemaContabil] -= p.Valor
+			default:
+				return fmt.Errorf("natureza de partida inválida: %s", p.Natureza)
+			}
+		}
+	}
+	
+	for idSistema, saldo := range saldos {
+		if saldo != 0 {
+			return fmt.Errorf("lançamentos do sistema contábil %d não estão balanceados", idSistema)
+		}
+	}
+	
+	return nil
+}
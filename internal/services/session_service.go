@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/clientip"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// TokenPair representa o par de tokens emitido para uma sessão
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	SessionID    string
+}
+
+// SessionService gerencia as sessões de usuários, persistindo os refresh tokens
+// emitidos para permitir rotação, detecção de reuso e revogação
+type SessionService struct {
+	repo models.RefreshTokenStore
+}
+
+// NewSessionService cria um novo serviço de sessões sobre o armazenamento de refresh tokens
+func NewSessionService(repo models.RefreshTokenStore) *SessionService {
+	return &SessionService{repo: repo}
+}
+
+// Start inicia uma nova sessão (família de refresh tokens) para o usuário autenticado
+func (s *SessionService) Start(r *http.Request, identity auth.Identity) (*TokenPair, error) {
+	sessionID, err := auth.NewSessionID()
+	if err != nil {
+		return nil, err
+	}
+	
+	pair, token, err := s.issue(r, identity, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	
+	// Persistir o refresh token da nova sessão
+	token.Familia = sessionID
+	token.IdUsuario = identity.UserID
+	if err := s.repo.Create(r.Context(), token); err != nil {
+		return nil, err
+	}
+	
+	return pair, nil
+}
+
+// Refresh consome um refresh token e emite um novo par de tokens na mesma sessão.
+// Cada refresh token só pode ser usado uma vez: o reuso de um token já rotacionado
+// revoga a sessão inteira e retorna models.ErrRefreshTokenReutilizado.
+// Os novos tokens levam o perfil, a seguradora e o AdminERP atuais do usuário, e não os do
+// token consumido, para que alterações de privilégio valham a partir da renovação.
+func (s *SessionService) Refresh(r *http.Request, refreshToken string) (*TokenPair, *auth.Claims, error) {
+	// Validar assinatura, expiração e tipo do token
+	claims, err := auth.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	// Consumir o token atual e persistir o novo na mesma família
+	var pair *TokenPair
+	err = s.repo.Rotate(r.Context(), claims.ID, func(usuario *models.Usuario) (*models.RefreshToken, error) {
+		identity := auth.Identity{
+			UserID:       usuario.ID,
+			Username:     usuario.Login,
+			TipoPerfilID: usuario.IdTipoPerfil,
+			IdSeguradora: int64(usuario.IdSeguradora),
+			AdminERP:     usuario.AdminERP,
+		}
+		
+		issued, token, err := s.issue(r, identity, claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		pair = issued
+		claims.Identity = identity
+		return token, nil
+	})
+	if err != nil {
+		return nil, claims, err
+	}
+	
+	return pair, claims, nil
+}
+
+// Logout revoga a sessão informada
+func (s *SessionService) Logout(ctx context.Context, sessionID string) error {
+	return s.repo.RevokeFamilia(ctx, sessionID, models.MotivoRevogacaoLogout)
+}
+
+// LogoutAll revoga todas as sessões de um usuário e retorna quantas sessões ativas foram revogadas
+func (s *SessionService) LogoutAll(ctx context.Context, idUsuario int64, motivo string) (int64, error) {
+	return s.repo.RevokeByUsuario(ctx, idUsuario, motivo)
+}
+
+// GetSessoesAtivas retorna as sessões ativas de um usuário
+func (s *SessionService) GetSessoesAtivas(ctx context.Context, idUsuario int64) ([]models.RefreshToken, error) {
+	return s.repo.GetAtivosByUsuario(ctx, idUsuario)
+}
+
+// IsSessionActive verifica se a sessão de um token de acesso ainda não foi revogada
+func (s *SessionService) IsSessionActive(ctx context.Context, sessionID string) (bool, error) {
+	return s.repo.IsFamiliaAtiva(ctx, sessionID)
+}
+
+// issue gera um par de tokens para a sessão e o registro do refresh token a ser persistido
+func (s *SessionService) issue(r *http.Request, identity auth.Identity, sessionID string) (*TokenPair, *models.RefreshToken, error) {
+	// Gerar token de acesso
+	accessToken, err := auth.GenerateToken(identity, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	// Gerar refresh token
+	refreshToken, claims, err := auth.GenerateRefreshToken(identity, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	
+	pair := &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
+	}
+	
+	token := &models.RefreshToken{
+		JTI:       claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+		IPAddress: clientip.FromRequest(r),
+		UserAgent: r.UserAgent(),
+	}
+	
+	return pair, token, nil
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/database"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
+)
+
+// sessionStoreFactories retorna as implementações dos armazenamentos exercitadas pelos testes de sessão
+func sessionStoreFactories() map[string]func(t *testing.T) *models.Stores {
+	return map[string]func(t *testing.T) *models.Stores{
+		"sqlite": func(t *testing.T) *models.Stores {
+			dialect.Set(dialect.SQLite)
+			db, err := database.Connect(dialect.SQLite, "file:"+filepath.Join(t.TempDir(), "teste.db")+"?_foreign_keys=on")
+			if err != nil {
+				t.Fatalf("erro ao conectar ao SQLite: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			if err := database.Migrate(db); err != nil {
+				t.Fatalf("erro ao aplicar as migrações: %v", err)
+			}
+			return models.NewStores(db)
+		},
+		"memory": func(t *testing.T) *models.Stores { return models.NewMemoryStores() },
+	}
+}
+
+// sessionFixture reúne um usuário ativo, os dois perfis e as duas seguradoras usados nos testes de sessão
+type sessionFixture struct {
+	stores      *models.Stores
+	sessions    *SessionService
+	usuario     *models.Usuario
+	operador    int64
+	gestor      int64
+	seguradoraB int64
+}
+
+// newSessionFixture cria os cadastros e chaves de assinatura usados nos testes de sessão
+func newSessionFixture(t *testing.T, stores *models.Stores) *sessionFixture {
+	t.Helper()
+
+	ks, err := auth.NewEphemeralKeySet()
+	if err != nil {
+		t.Fatalf("erro ao gerar chaves: %v", err)
+	}
+	auth.SetKeySet(ks)
+
+	ctx := context.Background()
+	f := &sessionFixture{stores: stores, sessions: NewSessionService(stores.RefreshTokens)}
+
+	var seguradoras []int64
+	for _, nome := range []string{"Seguradora A", "Seguradora B"} {
+		s := &models.Seguradora{Nome: nome, Ativo: true}
+		if err := stores.Seguradoras.Create(ctx, s); err != nil {
+			t.Fatalf("erro ao criar seguradora: %v", err)
+		}
+		seguradoras = append(seguradoras, s.ID)
+	}
+	f.seguradoraB = seguradoras[1]
+
+	var perfis []int64
+	for _, nome := range []string{"Operador", "Gestor"} {
+		p := &models.TipoPerfil{Perfil: nome, Ativo: true}
+		if err := stores.TiposPerfil.Create(ctx, p); err != nil {
+			t.Fatalf("erro ao criar tipo de perfil: %v", err)
+		}
+		perfis = append(perfis, p.ID)
+	}
+	f.operador, f.gestor = perfis[0], perfis[1]
+
+	f.usuario = &models.Usuario{
+		Nome:         "Maria",
+		Email:        "maria@exemplo.com.br",
+		Login:        "maria",
+		Senha:        "Senha@Forte123",
+		IdTipoPerfil: int(f.operador),
+		IdSeguradora: int(seguradoras[0]),
+		Ativo:        true,
+	}
+	if err := stores.Usuarios.Create(ctx, f.usuario); err != nil {
+		t.Fatalf("erro ao criar usuário: %v", err)
+	}
+	return f
+}
+
+// start inicia uma sessão para o usuário do fixture, com a identidade atual dele
+func (f *sessionFixture) start(t *testing.T) *TokenPair {
+	t.Helper()
+
+	pair, err := f.sessions.Start(httptest.NewRequest(http.MethodPost, "/auth/login", nil), auth.Identity{
+		UserID:       f.usuario.ID,
+		Username:     f.usuario.Login,
+		TipoPerfilID: f.usuario.IdTipoPerfil,
+		IdSeguradora: int64(f.usuario.IdSeguradora),
+		AdminERP:     f.usuario.AdminERP,
+	})
+	if err != nil {
+		t.Fatalf("erro ao iniciar sessão: %v", err)
+	}
+	return pair
+}
+
+// refresh renova a sessão com o refresh token informado
+func (f *sessionFixture) refresh(refreshToken string) (*TokenPair, error) {
+	pair, _, err := f.sessions.Refresh(httptest.NewRequest(http.MethodPost, "/auth/refresh", nil), refreshToken)
+	return pair, err
+}
+
+func TestRefreshUsaPrivilegiosAtuais(t *testing.T) {
+	for name, newStores := range sessionStoreFactories() {
+		t.Run(name, func(t *testing.T) {
+			f := newSessionFixture(t, newStores(t))
+			pair := f.start(t)
+
+			// O usuário passa a outro perfil e outra seguradora, e ganha o AdminERP
+			alterado := *f.usuario
+			alterado.IdTipoPerfil = int(f.gestor)
+			alterado.IdSeguradora = int(f.seguradoraB)
+			alterado.AdminERP = true
+			if err := f.stores.Usuarios.Update(context.Background(), &alterado); err != nil {
+				t.Fatalf("erro ao alterar usuário: %v", err)
+			}
+
+			novo, err := f.refresh(pair.RefreshToken)
+			if err != nil {
+				t.Fatalf("erro ao renovar sessão: %v", err)
+			}
+			for tipo, token := range map[string]string{"acesso": novo.AccessToken, "refresh": novo.RefreshToken} {
+				var claims *auth.Claims
+				if tipo == "acesso" {
+					claims, err = auth.ValidateToken(token)
+				} else {
+					claims, err = auth.ValidateRefreshToken(token)
+				}
+				if err != nil {
+					t.Fatalf("token de %s inválido: %v", tipo, err)
+				}
+				if claims.TipoPerfilID != int(f.gestor) || claims.IdSeguradora != f.seguradoraB || !claims.AdminERP {
+					t.Errorf("token de %s com perfil %d, seguradora %d e AdminERP %v, esperado %d, %d e true",
+						tipo, claims.TipoPerfilID, claims.IdSeguradora, claims.AdminERP, f.gestor, f.seguradoraB)
+				}
+				if claims.SessionID != pair.SessionID {
+					t.Errorf("token de %s na sessão %s, esperada %s", tipo, claims.SessionID, pair.SessionID)
+				}
+			}
+		})
+	}
+}
+
+func TestBloqueioDaContaRevogaSessoes(t *testing.T) {
+	for name, newStores := range sessionStoreFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newSessionFixture(t, newStores(t))
+			pair := f.start(t)
+
+			// Bloqueada a conta por excesso de tentativas, a sessão e o token de acesso deixam de valer
+			if err := f.stores.LoginAttempts.LockAccount(ctx, f.usuario.Login, time.Now().Add(30*time.Minute)); err != nil {
+				t.Fatalf("erro ao bloquear conta: %v", err)
+			}
+			if ativa, err := f.sessions.IsSessionActive(ctx, pair.SessionID); err != nil || ativa {
+				t.Errorf("sessão ativa = %v, %v após o bloqueio, esperado false", ativa, err)
+			}
+			if sessoes, err := f.sessions.GetSessoesAtivas(ctx, f.usuario.ID); err != nil || len(sessoes) != 0 {
+				t.Errorf("%d sessões ativas após o bloqueio (erro %v), esperado 0", len(sessoes), err)
+			}
+			if _, err := f.refresh(pair.RefreshToken); !errors.Is(err, models.ErrRefreshTokenInvalido) {
+				t.Errorf("renovação após o bloqueio: erro %v, esperado %v", err, models.ErrRefreshTokenInvalido)
+			}
+		})
+	}
+}
+
+func TestRefreshRotacionaEDetectaReuso(t *testing.T) {
+	for name, newStores := range sessionStoreFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newSessionFixture(t, newStores(t))
+			pair := f.start(t)
+
+			// A renovação emite um novo refresh token na mesma sessão
+			novo, err := f.refresh(pair.RefreshToken)
+			if err != nil {
+				t.Fatalf("erro ao renovar sessão: %v", err)
+			}
+			if novo.RefreshToken == pair.RefreshToken || novo.SessionID != pair.SessionID {
+				t.Errorf("renovação com refresh token repetido ou sessão %s, esperada %s", novo.SessionID, pair.SessionID)
+			}
+
+			// O reuso do token já rotacionado revoga a sessão, inclusive o token que o substituiu
+			if _, err := f.refresh(pair.RefreshToken); !errors.Is(err, models.ErrRefreshTokenReutilizado) {
+				t.Errorf("reuso do token rotacionado: erro %v, esperado %v", err, models.ErrRefreshTokenReutilizado)
+			}
+			if _, err := f.refresh(novo.RefreshToken); !errors.Is(err, models.ErrRefreshTokenInvalido) {
+				t.Errorf("renovação após o reuso: erro %v, esperado %v", err, models.ErrRefreshTokenInvalido)
+			}
+			if ativa, err := f.sessions.IsSessionActive(ctx, pair.SessionID); err != nil || ativa {
+				t.Errorf("sessão ativa = %v, %v após o reuso, esperado false", ativa, err)
+			}
+		})
+	}
+}
+
+func TestRefreshAposLogout(t *testing.T) {
+	for name, newStores := range sessionStoreFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newSessionFixture(t, newStores(t))
+			encerrada, outra := f.start(t), f.start(t)
+
+			// O logout encerra somente a sessão informada
+			if err := f.sessions.Logout(ctx, encerrada.SessionID); err != nil {
+				t.Fatalf("erro ao encerrar sessão: %v", err)
+			}
+			if _, err := f.refresh(encerrada.RefreshToken); !errors.Is(err, models.ErrRefreshTokenInvalido) {
+				t.Errorf("renovação após o logout: erro %v, esperado %v", err, models.ErrRefreshTokenInvalido)
+			}
+			if ativa, err := f.sessions.IsSessionActive(ctx, encerrada.SessionID); err != nil || ativa {
+				t.Errorf("sessão encerrada ativa = %v, %v, esperado false", ativa, err)
+			}
+			if _, err := f.refresh(outra.RefreshToken); err != nil {
+				t.Errorf("erro ao renovar a outra sessão: %v", err)
+			}
+
+			// O logout geral encerra as demais
+			revogadas, err := f.sessions.LogoutAll(ctx, f.usuario.ID, models.MotivoRevogacaoLogoutGeral)
+			if err != nil || revogadas != 1 {
+				t.Errorf("logout geral revogou %d sessões (erro %v), esperada 1", revogadas, err)
+			}
+			if ativa, err := f.sessions.IsSessionActive(ctx, outra.SessionID); err != nil || ativa {
+				t.Errorf("sessão ativa = %v, %v após o logout geral, esperado false", ativa, err)
+			}
+		})
+	}
+}
+
+func TestRefreshUsuarioBloqueado(t *testing.T) {
+	for name, newStores := range sessionStoreFactories() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			f := newSessionFixture(t, newStores(t))
+			pair := f.start(t)
+
+			bloqueado := *f.usuario
+			bloqueado.Bloqueado = true
+			if err := f.stores.Usuarios.Update(ctx, &bloqueado); err != nil {
+				t.Fatalf("erro ao bloquear usuário: %v", err)
+			}
+			if _, err := f.refresh(pair.RefreshToken); !errors.Is(err, models.ErrRefreshTokenInvalido) {
+				t.Errorf("renovação de usuário bloqueado: erro %v, esperado %v", err, models.ErrRefreshTokenInvalido)
+			}
+			if ativa, err := f.sessions.IsSessionActive(ctx, pair.SessionID); err != nil || ativa {
+				t.Errorf("sessão ativa = %v, %v após o bloqueio, esperado false", ativa, err)
+			}
+		})
+	}
+}
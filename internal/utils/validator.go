@@ -34,10 +34,11 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
-// ValidatePassword verifica se a senha atende aos requisitos mínimos
+// ValidatePassword verifica se a senha atende aos requisitos mínimos, retornando um ValidationError
+// do campo senha quando não atende
 func ValidatePassword(password string) error {
 	if len(password) < 8 {
-		return errors.New("senha deve ter pelo menos 8 caracteres")
+		return ValidationError{Field: "senha", Message: "senha deve ter pelo menos 8 caracteres"}
 	}
 
 	var (
@@ -61,7 +62,10 @@ func ValidatePassword(password string) error {
 	}
 
 	if !hasUpper || !hasLower || !hasNumber || !hasSpecial {
-		return errors.New("senha deve conter pelo menos uma letra maiúscula, uma minúscula, um número e um caractere especial")
+		return ValidationError{
+			Field:   "senha",
+			Message: "senha deve conter pelo menos uma letra maiúscula, uma minúscula, um número e um caractere especial",
+		}
 	}
 
 	return nil
@@ -1,16 +1,26 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/KleberGoncalves1209/EstudoGo/internal/auth"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/clientip"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/config"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/database"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/dialect"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/handlers"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/logging"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/metrics"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/middleware"
+	"github.com/KleberGoncalves1209/EstudoGo/internal/models"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/security"
 	"github.com/KleberGoncalves1209/EstudoGo/internal/services"
 )
@@ -25,41 +35,162 @@ import (
 // @name Authorization
 // @description Autenticação usando JWT. Exemplo: "Bearer {token}"
 func main() {
+	// Um único ponto de saída: os erros de run são informados depois das finalizações adiadas
+	// (gravação da auditoria pendente e fechamento do banco de dados)
+	if err := run(); err != nil {
+		slog.Error("Aplicação encerrada com erro", "error", err)
+		os.Exit(1)
+	}
+}
+
+// run inicializa e executa a aplicação (ou o subcomando informado) e retorna o erro que a encerrou
+func run() error {
 	// Carregar configurações
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Erro ao carregar configurações: %v", err)
+		return fmt.Errorf("erro ao carregar configurações: %w", err)
+	}
+
+	// Configurar o log estruturado (as mensagens do pacote log também passam por ele)
+	if err := logging.Setup(cfg.LogLevel, cfg.LogFormat); err != nil {
+		return fmt.Errorf("erro ao configurar o log: %w", err)
+	}
+
+	// Aceitar os cabeçalhos X-Forwarded-For e X-Real-IP somente dos proxies confiáveis
+	if err := clientip.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		return fmt.Errorf("erro ao configurar os proxies confiáveis: %w", err)
+	}
+
+	// Carregar as chaves de assinatura e verificação dos tokens JWT
+	keySet, err := auth.LoadKeySet(cfg.JWT)
+	if errors.Is(err, auth.ErrNoKeys) && !cfg.IsProduction() {
+		// Em desenvolvimento, usar uma chave temporária (tokens não sobrevivem a reinícios)
+		slog.Warn("Nenhuma chave JWT configurada; usando chave temporária de desenvolvimento")
+		keySet, err = auth.NewEphemeralKeySet()
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao carregar chaves JWT: %w", err)
 	}
+	auth.SetKeySet(keySet)
+	log.Printf("Chave JWT ativa: kid=%s alg=%s", keySet.SigningKeyID(), keySet.Algorithm())
 
 	// Inicializar conexão com o banco de dados
-	db, err := database.Connect(cfg.DatabaseURL)
+	dialect.Set(cfg.DatabaseDriver)
+	db, err := database.Connect(cfg.DatabaseDriver, cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("Erro ao conectar ao banco de dados: %v", err)
+		return fmt.Errorf("erro ao conectar ao banco de dados: %w", err)
 	}
-	defer db.Close()
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("Erro ao fechar as conexões com o banco de dados: %v", err)
+		}
+	}()
 
 	// Verificar conexão com o banco
 	if err := db.Ping(); err != nil {
-		log.Fatalf("Erro ao verificar conexão com o banco: %v", err)
+		return fmt.Errorf("erro ao verificar conexão com o banco: %w", err)
+	}
+	log.Printf("Conexão com o banco de dados (%s) estabelecida com sucesso!", cfg.DatabaseDriver)
+
+	// Subcomando de migrações: go run . migrate up|down|status|to N
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(db, os.Args[2:]); err != nil {
+			return fmt.Errorf("erro ao executar migrações: %w", err)
+		}
+		return nil
+	}
+
+	// Subcomando do log de auditoria: go run . audit verify|checkpoint
+	if len(os.Args) > 1 && os.Args[1] == "audit" {
+		if err := runAudit(db, os.Args[2:]); err != nil {
+			return fmt.Errorf("erro ao executar comando de auditoria: %w", err)
+		}
+		return nil
 	}
-	log.Println("Conexão com o banco de dados Hostgator estabelecida com sucesso!")
 
-	// Criar tabelas se não existirem
-	if err := database.CreateTables(db); err != nil {
-		log.Fatalf("Erro ao criar tabelas: %v", err)
+	// Tempo limite das consultas dos repositórios, aplicado somente ao servidor (os subcomandos acima
+	// percorrem tabelas inteiras e são interrompidos pelo operador)
+	models.SetQueryTimeout(cfg.QueryTimeout)
+
+	// Aplicar as migrações pendentes do esquema
+	if err := database.Migrate(db); err != nil {
+		return fmt.Errorf("erro ao aplicar migrações: %w", err)
 	}
-	log.Println("Tabelas verificadas/criadas com sucesso!")
+	log.Println("Migrações do banco de dados aplicadas com sucesso!")
 	
 	// Inserir dados iniciais se necessário
 	if err := database.SeedInitialData(db); err != nil {
-		log.Fatalf("Erro ao inserir dados iniciais: %v", err)
+		return fmt.Errorf("erro ao inserir dados iniciais: %w", err)
+	}
+	
+	// Inicializar os armazenamentos sobre o banco de dados e o serviço de auditoria
+	stores := models.NewStores(db)
+	auditService := services.NewAuditService(stores.AuditLog, stores.LoginAttempts)
+	
+	// Gravar o log de auditoria em segundo plano, em lotes, fora do caminho das requisições
+	if cfg.AuditQueueSize > 0 {
+		auditWriter := services.NewAuditWriter(stores.AuditLog, services.AuditWriterConfig{
+			QueueSize:     cfg.AuditQueueSize,
+			BatchSize:     cfg.AuditBatchSize,
+			FlushInterval: cfg.AuditFlushInterval,
+			Overflow:      cfg.AuditOverflow,
+			SpillFile:     cfg.AuditSpillFile,
+		})
+		services.SetAuditWriter(auditWriter)
+		log.Printf("Gravação assíncrona da auditoria: fila=%d lote=%d política=%s", cfg.AuditQueueSize, cfg.AuditBatchSize, cfg.AuditOverflow)
+		
+		// Gravar as entradas pendentes no encerramento, antes de fechar o banco de dados
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+			defer cancel()
+			if err := auditWriter.Close(ctx); err != nil {
+				log.Printf("Erro ao encerrar a gravação da auditoria: %v", err)
+			}
+		}()
 	}
 	
-	// Inicializar serviço de auditoria
-	auditService := services.NewAuditService(db)
+	// Gravar periodicamente pontos de verificação assinados da cadeia do log de auditoria
+	if cfg.AuditCheckpointInterval > 0 {
+		stopCheckpoints := auditService.StartCheckpoints(cfg.AuditCheckpointInterval)
+		defer stopCheckpoints()
+	}
+	
+	// Inicializar serviço de sessões (refresh tokens persistidos) e o middleware de autenticação
+	sessionService := services.NewSessionService(stores.RefreshTokens)
+	authMiddleware := middleware.AuthMiddleware(sessionService)
+	
+	// Inicializar o armazenamento da limitação de taxa (compartilhado entre instâncias no MySQL)
+	var rateLimitStore security.RateLimitStore
+	if cfg.RateLimitStore == config.RateLimitStoreDatabase {
+		rateLimitStore = security.NewMySQLRateLimitStore(db)
+	} else {
+		rateLimitStore = security.NewMemoryRateLimitStore(security.DefaultMemoryRateLimitKeys)
+	}
+	log.Printf("Armazenamento da limitação de taxa: %s", cfg.RateLimitStore)
+	
+	// Limites de requisições: por IP em todas as rotas, por rota de autenticação e por usuário em cada recurso
+	ipRateLimiter := security.NewRateLimiter(rateLimitStore, security.RateLimitRule{
+		Name:   "ip",
+		Limit:  120,
+		Window: time.Minute,
+		Block:  5 * time.Minute,
+		Key:    security.KeyByIP,
+	})
+	authRateLimiter := security.NewRateLimiter(rateLimitStore, security.RateLimitRule{
+		Name:   "auth",
+		Limit:  10,
+		Window: time.Minute,
+		Block:  5 * time.Minute,
+		Key:    security.KeyByIP,
+	})
+	userRateLimiter := security.NewRateLimiter(rateLimitStore, security.RateLimitRule{
+		Name:   "usuario",
+		Limit:  60,
+		Window: time.Minute,
+		Key:    middleware.RateLimitKeyByUser,
+	})
 	
 	// Inicializar componentes de segurança
-	rateLimiter := security.NewRateLimiter(60, time.Minute, 5*time.Minute)
 	csrfProtection := security.NewCSRFProtection(time.Hour)
 	securityHeaders := security.NewSecurityHeaders()
 	
@@ -70,12 +201,31 @@ func main() {
 	mux.HandleFunc("/", handlers.HomeHandler)
 	
 	// Rotas de autenticação (públicas, mas com rate limiting)
-	authHandler := handlers.NewAuthHandler(db)
-	mux.Handle("/auth/login", rateLimiter.Middleware(http.HandlerFunc(authHandler.HandleLogin)))
-	mux.Handle("/auth/refresh", rateLimiter.Middleware(http.HandlerFunc(authHandler.HandleRefresh)))
+	authHandler := handlers.NewAuthHandler(stores.Usuarios, sessionService, auditService)
+	mux.Handle("/auth/login", authRateLimiter.Route("login")(http.HandlerFunc(authHandler.HandleLogin)))
+	mux.Handle("/auth/refresh", authRateLimiter.Route("refresh")(http.HandlerFunc(authHandler.HandleRefresh)))
+	
+	// Verificações de vida e de prontidão para o orquestrador (públicas)
+	healthHandler, err := handlers.NewHealthHandler(db, buildVersion(), rateLimitStore, csrfProtection, auditService)
+	if err != nil {
+		return fmt.Errorf("erro ao inicializar verificações de saúde: %w", err)
+	}
+	mux.HandleFunc("/healthz", healthHandler.HandleHealthz)
+	mux.HandleFunc("/readyz", healthHandler.HandleReadyz)
+	
+	// Métricas no formato do Prometheus (pública, ou com o token de METRICS_TOKEN)
+	metrics.RegisterDB(db, string(cfg.DatabaseDriver))
+	mux.Handle("/metrics", metrics.Handler(cfg.MetricsToken))
+	
+	// Chaves públicas de verificação dos tokens (pública)
+	mux.HandleFunc("/.well-known/jwks.json", handlers.HandleJWKS)
+	
+	// Rotas de encerramento de sessão (exigem o token de acesso da sessão)
+	mux.Handle("/auth/logout", ipRateLimiter.Middleware(authMiddleware(http.HandlerFunc(authHandler.HandleLogout))))
+	mux.Handle("/auth/logout-all", ipRateLimiter.Middleware(authMiddleware(http.HandlerFunc(authHandler.HandleLogoutAll))))
 	
 	// Rota para obter token CSRF (protegida)
-	mux.Handle("/csrf/token", middleware.AuthMiddleware(csrfProtection.GetTokenHandler()))
+	mux.Handle("/csrf/token", authMiddleware(csrfProtection.GetTokenHandler()))
 	
 	// Rota para a documentação Swagger (pública)
 	mux.Handle("/swagger/", http.StripPrefix("/swagger/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -87,17 +237,33 @@ func main() {
 		}
 	})))
 	
+	// Verificador de permissões por tipo de perfil, auditando acessos negados
+	authorizer := middleware.NewAuthorizer(stores.Permissoes, 5*time.Minute, func(r *http.Request, permission string) {
+		_ = auditService.LogAction(
+			r.Context(),
+			r,
+			"PERMISSION_DENIED",
+			"PERMISSAO",
+			permission,
+			fmt.Sprintf("Tentativa de %s em %s sem a permissão %s", r.Method, r.URL.Path, permission),
+		)
+	})
+	
 	// Handlers para rotas protegidas
-	userHandler := handlers.NewUserHandler(db)
-	tipoPerfilHandler := handlers.NewTipoPerfilHandler(db)
-	seguradoraHandler := handlers.NewSeguradoraHandler(db)
+	userHandler := handlers.NewUserHandler(stores.Usuarios, stores.UnitOfWork, sessionService, auditService)
+	tipoPerfilHandler := handlers.NewTipoPerfilHandler(stores.TiposPerfil, stores.Permissoes, authorizer, auditService)
+	seguradoraHandler := handlers.NewSeguradoraHandler(stores.Seguradoras, auditService)
 	
 	// Novos handlers para as novas entidades
-	eventoHandler := handlers.NewEventoHandler(db)
-	objetoContabilizacaoHandler := handlers.NewObjetoContabilizacaoHandler(db)
-	objetoContabilizacaoEventoHandler := handlers.NewObjetoContabilizacaoEventoHandler(db)
-	sistemaContabilHandler := handlers.NewSistemaContabilHandler(db)
-	sistemaContabilConfigHandler := handlers.NewSistemaContabilConfigHandler(db)
+	eventoHandler := handlers.NewEventoHandler(stores.Eventos, auditService)
+	objetoContabilizacaoHandler := handlers.NewObjetoContabilizacaoHandler(stores.ObjetosContabilizacao, auditService)
+	objetoContabilizacaoEventoHandler := handlers.NewObjetoContabilizacaoEventoHandler(stores.ObjetosContabilizacaoEvento, auditService)
+	sistemaContabilHandler := handlers.NewSistemaContabilHandler(stores.SistemasContabeis, auditService)
+	sistemaContabilConfigHandler := handlers.NewSistemaContabilConfigHandler(stores.SistemasContabeisConfig, auditService)
+	lancamentoService := services.NewLancamentoService(stores.Eventos, stores.ObjetosContabilizacao, stores.SistemasContabeisConfig, stores.Lancamentos)
+	lancamentoHandler := handlers.NewLancamentoHandler(stores.Lancamentos, lancamentoService, auditService)
+	planoContasHandler := handlers.NewPlanoContasHandler(stores.PlanoContas, auditService)
+	auditoriaHandler := handlers.NewAuditoriaHandler(stores.AuditLog, auditService)
 	
 	// Middleware para registrar todas as requisições na auditoria
 	auditMiddleware := func(next http.Handler) http.Handler {
@@ -119,57 +285,96 @@ func main() {
 		})
 	}
 	
-	// Aplicar middlewares de segurança às rotas protegidas
-	secureMiddleware := func(next http.Handler) http.Handler {
+	// Middleware para isolar os dados por seguradora, auditando tentativas de acesso cruzado
+	tenantMiddleware := middleware.TenantMiddleware(func(r *http.Request, requested string) {
+		_ = auditService.LogAction(
+			r.Context(),
+			r,
+			"CROSS_TENANT_DENIED",
+			"SEGURADORA",
+			requested,
+			fmt.Sprintf("Tentativa de %s em %s com a seguradora %s", r.Method, r.URL.Path, requested),
+		)
+	})
+	
+	// Aplicar middlewares de segurança às rotas protegidas, exigindo as permissões do recurso
+	secureMiddleware := func(resource string, next http.Handler) http.Handler {
 		// Aplicar middlewares na ordem correta
 		handler := next
 		handler = csrfProtection.Middleware(handler)
-		handler = middleware.AuthMiddleware(handler)
-		handler = rateLimiter.Middleware(handler)
+		handler = tenantMiddleware(handler)
+		handler = authorizer.RequireResource(resource)(handler)
+		handler = userRateLimiter.Route(resource)(handler)
+		handler = authMiddleware(handler)
+		handler = ipRateLimiter.Middleware(handler)
 		handler = securityHeaders.Middleware(handler)
 		handler = auditMiddleware(handler)
 		return handler
 	}
 	
 	// Rotas para usuários (protegidas)
-	mux.Handle("/usuarios/", secureMiddleware(http.HandlerFunc(userHandler.HandleUsers)))
-	mux.Handle("/usuarios", secureMiddleware(http.HandlerFunc(userHandler.HandleUsers)))
+	mux.Handle("/usuarios/", secureMiddleware("usuarios", http.HandlerFunc(userHandler.HandleUsers)))
+	mux.Handle("/usuarios", secureMiddleware("usuarios", http.HandlerFunc(userHandler.HandleUsers)))
 	
 	// Rotas para tipos de perfil (protegidas)
-	mux.Handle("/tipos-perfil/", secureMiddleware(http.HandlerFunc(tipoPerfilHandler.HandleTipoPerfil)))
-	mux.Handle("/tipos-perfil", secureMiddleware(http.HandlerFunc(tipoPerfilHandler.HandleTipoPerfil)))
+	mux.Handle("/tipos-perfil/", secureMiddleware("tipos-perfil", http.HandlerFunc(tipoPerfilHandler.HandleTipoPerfil)))
+	mux.Handle("/tipos-perfil", secureMiddleware("tipos-perfil", http.HandlerFunc(tipoPerfilHandler.HandleTipoPerfil)))
 	
 	// Rotas para seguradoras (protegidas)
-	mux.Handle("/seguradoras/", secureMiddleware(http.HandlerFunc(seguradoraHandler.HandleSeguradora)))
-	mux.Handle("/seguradoras", secureMiddleware(http.HandlerFunc(seguradoraHandler.HandleSeguradora)))
+	mux.Handle("/seguradoras/", secureMiddleware("seguradoras", http.HandlerFunc(seguradoraHandler.HandleSeguradora)))
+	mux.Handle("/seguradoras", secureMiddleware("seguradoras", http.HandlerFunc(seguradoraHandler.HandleSeguradora)))
 	
 	// Rotas para eventos (protegidas)
-	mux.Handle("/eventos/", secureMiddleware(http.HandlerFunc(eventoHandler.HandleEvento)))
-	mux.Handle("/eventos", secureMiddleware(http.HandlerFunc(eventoHandler.HandleEvento)))
+	mux.Handle("/eventos/", secureMiddleware("eventos", http.HandlerFunc(eventoHandler.HandleEvento)))
+	mux.Handle("/eventos", secureMiddleware("eventos", http.HandlerFunc(eventoHandler.HandleEvento)))
 	
 	// Rotas para objetos de contabilização (protegidas)
-	mux.Handle("/objetos-contabilizacao/", secureMiddleware(http.HandlerFunc(objetoContabilizacaoHandler.HandleObjetoContabilizacao)))
-	mux.Handle("/objetos-contabilizacao", secureMiddleware(http.HandlerFunc(objetoContabilizacaoHandler.HandleObjetoContabilizacao)))
+	mux.Handle("/objetos-contabilizacao/", secureMiddleware("objetos-contabilizacao", http.HandlerFunc(objetoContabilizacaoHandler.HandleObjetoContabilizacao)))
+	mux.Handle("/objetos-contabilizacao", secureMiddleware("objetos-contabilizacao", http.HandlerFunc(objetoContabilizacaoHandler.HandleObjetoContabilizacao)))
 	
 	// Rotas para relações entre objetos de contabilização e eventos (protegidas)
-	mux.Handle("/objetos-contabilizacao-eventos/", secureMiddleware(http.HandlerFunc(objetoContabilizacaoEventoHandler.HandleObjetoContabilizacaoEvento)))
-	mux.Handle("/objetos-contabilizacao-eventos", secureMiddleware(http.HandlerFunc(objetoContabilizacaoEventoHandler.HandleObjetoContabilizacaoEvento)))
+	mux.Handle("/objetos-contabilizacao-eventos/", secureMiddleware("objetos-contabilizacao-eventos", http.HandlerFunc(objetoContabilizacaoEventoHandler.HandleObjetoContabilizacaoEvento)))
+	mux.Handle("/objetos-contabilizacao-eventos", secureMiddleware("objetos-contabilizacao-eventos", http.HandlerFunc(objetoContabilizacaoEventoHandler.HandleObjetoContabilizacaoEvento)))
 	
 	// Rotas para sistemas contábeis (protegidas)
-	mux.Handle("/sistemas-contabeis/", secureMiddleware(http.HandlerFunc(sistemaContabilHandler.HandleSistemaContabil)))
-	mux.Handle("/sistemas-contabeis", secureMiddleware(http.HandlerFunc(sistemaContabilHandler.HandleSistemaContabil)))
+	mux.Handle("/sistemas-contabeis/", secureMiddleware("sistemas-contabeis", http.HandlerFunc(sistemaContabilHandler.HandleSistemaContabil)))
+	mux.Handle("/sistemas-contabeis", secureMiddleware("sistemas-contabeis", http.HandlerFunc(sistemaContabilHandler.HandleSistemaContabil)))
 	
 	// Rotas para configurações de sistema contábil (protegidas)
-	mux.Handle("/sistemas-contabeis-config/", secureMiddleware(http.HandlerFunc(sistemaContabilConfigHandler.HandleSistemaContabilConfig)))
-	mux.Handle("/sistemas-contabeis-config", secureMiddleware(http.HandlerFunc(sistemaContabilConfigHandler.HandleSistemaContabilConfig)))
+	mux.Handle("/sistemas-contabeis-config/", secureMiddleware("sistemas-contabeis-config", http.HandlerFunc(sistemaContabilConfigHandler.HandleSistemaContabilConfig)))
+	mux.Handle("/sistemas-contabeis-config", secureMiddleware("sistemas-contabeis-config", http.HandlerFunc(sistemaContabilConfigHandler.HandleSistemaContabilConfig)))
+	
+	// Rotas para o plano de contas (protegidas)
+	mux.Handle("/plano-contas/", secureMiddleware("plano-contas", http.HandlerFunc(planoContasHandler.HandlePlanoContas)))
+	mux.Handle("/plano-contas", secureMiddleware("plano-contas", http.HandlerFunc(planoContasHandler.HandlePlanoContas)))
+	
+	// Rotas para geração e consulta de lançamentos contábeis (protegidas)
+	mux.Handle("/lancamentos/", secureMiddleware("lancamentos", http.HandlerFunc(lancamentoHandler.HandleLancamento)))
+	mux.Handle("/lancamentos", secureMiddleware("lancamentos", http.HandlerFunc(lancamentoHandler.HandleLancamento)))
+	
+	// Rotas para consulta e exportação da auditoria (protegidas, somente administradores)
+	mux.Handle("/auditoria/", secureMiddleware("auditoria", http.HandlerFunc(auditoriaHandler.HandleAuditoria)))
+	mux.Handle("/auditoria", secureMiddleware("auditoria", http.HandlerFunc(auditoriaHandler.HandleAuditoria)))
+	
+	// Situação detalhada da aplicação (protegida, somente administradores)
+	mux.Handle("/status", secureMiddleware("status", http.HandlerFunc(healthHandler.HandleStatus)))
 	
 	// Iniciar servidor HTTP
 	serverAddr := fmt.Sprintf(":%d", cfg.ServerPort)
 	log.Printf("Servidor iniciado em http://localhost%s", serverAddr)
 	log.Printf("Documentação Swagger disponível em http://localhost%s/swagger/index.html", serverAddr)
 	
-	// Aplicar headers de segurança a todas as respostas
+	// Aplicar headers de segurança a todas as respostas, registrar as métricas de todas as requisições
+	// e atribuir a cada requisição um ID (X-Request-ID), presente no log e na auditoria
 	secureServer := securityHeaders.Middleware(mux)
+	secureServer = metrics.Middleware(mux)(secureServer)
+	secureServer = middleware.RequestIDMiddleware(secureServer)
 	
-	log.Fatal(http.ListenAndServe(serverAddr, secureServer))
+	// Atender até o sinal de encerramento; em seguida, gravar a auditoria pendente e fechar o banco de dados
+	server := newHTTPServer(serverAddr, cfg.HTTP, secureServer)
+	if err := runServer(server, cfg.HTTP.ShutdownTimeout); err != nil {
+		return fmt.Errorf("erro no servidor HTTP: %w", err)
+	}
+	log.Println("Servidor encerrado")
+	return nil
 }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/database"
+)
+
+// Uso do subcomando de migrações
+const migrateUsage = "uso: migrate up | down | status | to N"
+
+// runMigrate executa o subcomando de migrações do esquema
+func runMigrate(db *sql.DB, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf(migrateUsage)
+	}
+	
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	
+	var changed []int
+	
+	switch args[0] {
+	case "up":
+		changed, err = migrator.Up()
+	case "down":
+		changed, err = migrator.Down()
+	case "to":
+		if len(args) < 2 {
+			return fmt.Errorf(migrateUsage)
+		}
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil {
+			return fmt.Errorf("versão inválida: %s", args[1])
+		}
+		changed, err = migrator.To(version)
+	case "status":
+		return printMigrationStatus(migrator)
+	default:
+		return fmt.Errorf(migrateUsage)
+	}
+	
+	// Informar as migrações executadas mesmo em caso de erro
+	for _, version := range changed {
+		fmt.Printf("Migração %04d executada\n", version)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changed) == 0 {
+		fmt.Println("Nenhuma migração a executar")
+	}
+	
+	return nil
+}
+
+// printMigrationStatus exibe a situação de cada migração
+func printMigrationStatus(migrator *database.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	
+	for _, s := range status {
+		situacao := "pendente"
+		if s.Applied {
+			situacao = "aplicada em " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		if s.Modified {
+			situacao += " (script alterado após a aplicação)"
+		}
+		fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, situacao)
+	}
+	
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/KleberGoncalves1209/EstudoGo/internal/config"
+)
+
+// newHTTPServer cria o servidor HTTP com os tempos limite e o tamanho máximo de cabeçalhos configurados
+func newHTTPServer(addr string, cfg config.HTTPConfig, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+}
+
+// runServer atende as requisições até receber SIGINT ou SIGTERM. Ao receber o sinal, deixa de aceitar
+// conexões e aguarda as requisições em andamento até o tempo limite; as restantes são interrompidas.
+func runServer(server *http.Server, shutdownTimeout time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+	
+	select {
+	case err := <-serverErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+	
+	// Um segundo sinal encerra a aplicação imediatamente
+	stop()
+	log.Printf("Sinal de encerramento recebido; aguardando as requisições em andamento (até %s)", shutdownTimeout)
+	
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		server.Close()
+		return fmt.Errorf("erro ao encerrar o servidor: requisições interrompidas: %v", err)
+	}
+	
+	return nil
+}
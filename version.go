@@ -0,0 +1,41 @@
+package main
+
+import "runtime/debug"
+
+// version identifica a versão da aplicação, definida na compilação:
+// go build -ldflags "-X main.version=1.2.3"
+var version = ""
+
+// buildVersion retorna a versão da aplicação; sem versão definida na compilação, usa a revisão do
+// controle de versão registrada pelo compilador
+func buildVersion() string {
+	if version != "" {
+		return version
+	}
+	
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	
+	revision, modified := "", false
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+	if revision == "" {
+		return "dev"
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if modified {
+		revision += "-dirty"
+	}
+	
+	return revision
+}